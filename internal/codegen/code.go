@@ -260,7 +260,13 @@ print(response.text)
 
 func (svc *Service) GenerateCurlCommand(reqSpec *domain.HTTPRequestSpec, collectionHeaders []domain.KeyValue, collectionAuth *domain.Auth) (string, error) {
 	// Define a Go template to generate the `curl` command
-	const curlTemplate = `{{- if eq .Method "HEAD" }}curl --head "{{ .URL }}"{{- else }}curl -X {{ .Method }} "{{ .URL }}"{{- end }}{{ if .Request.Headers }} \
+	const curlTemplate = `{{- if eq .Method "HEAD" }}curl --head "{{ .URL }}"{{- else }}curl -X {{ .Method }} "{{ .URL }}"{{- end }}
+{{- if and (eq .Request.Auth.Type "awsSigV4") .Request.Auth.AWSSigV4Auth }}
+{{- with .Request.Auth.AWSSigV4Auth }} --aws-sigv4 "aws:amz:{{ .Region }}:{{ .Service }}" --user "{{ .AccessKeyID }}:{{ .SecretAccessKey }}"
+{{- if .SessionToken }} -H "x-amz-security-token: {{ .SessionToken }}"{{ end }}
+{{- end }}
+{{- end }}
+{{- if and (eq .Request.Auth.Type "digest") .Request.Auth.DigestAuth }} --digest --user "{{ .Request.Auth.DigestAuth.Username }}:{{ .Request.Auth.DigestAuth.Password }}"{{ end }}{{ if .Request.Headers }} \
 {{- range $i, $header := .Request.Headers }}
 	{{- if $header.Enable }}
     -H "{{ $header.Key }}: {{ $header.Value }}" \
@@ -65,6 +65,40 @@ func TestGenerateCurlCommand(t *testing.T) {
 			},
 			contains: []string{"curl", "-X POST", "-d \"name=test\""},
 		},
+		{
+			name: "GET with aws signature",
+			spec: &domain.HTTPRequestSpec{
+				Method: domain.RequestMethodGET,
+				URL:    "https://example.amazonaws.com/",
+				Request: &domain.HTTPRequest{
+					Auth: domain.Auth{
+						Type: domain.AuthTypeAWSSigV4,
+						AWSSigV4Auth: &domain.AWSSigV4Auth{
+							AccessKeyID:     "AKID",
+							SecretAccessKey: "secret",
+							SessionToken:    "session",
+							Region:          "us-east-1",
+							Service:         "execute-api",
+						},
+					},
+				},
+			},
+			contains: []string{"--aws-sigv4 \"aws:amz:us-east-1:execute-api\"", "--user \"AKID:secret\"", "-H \"x-amz-security-token: session\""},
+		},
+		{
+			name: "GET with digest auth",
+			spec: &domain.HTTPRequestSpec{
+				Method: domain.RequestMethodGET,
+				URL:    "https://api.example.com/users",
+				Request: &domain.HTTPRequest{
+					Auth: domain.Auth{
+						Type:       domain.AuthTypeDigest,
+						DigestAuth: &domain.DigestAuth{Username: "user", Password: "pass"},
+					},
+				},
+			},
+			contains: []string{"--digest --user \"user:pass\""},
+		},
 	}
 
 	svc := &Service{}
@@ -0,0 +1,315 @@
+// Package digest implements the client side of HTTP Digest access authentication as described in RFC 7616.
+package digest
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrNoChallenge          = errors.New("no digest challenge found")
+	ErrUnsupportedAlgorithm = errors.New("unsupported digest algorithm")
+	ErrUnsupportedQop       = errors.New("unsupported digest qop, only auth is supported")
+)
+
+// Challenge is a parsed WWW-Authenticate digest challenge.
+type Challenge struct {
+	Realm     string
+	Nonce     string
+	Opaque    string
+	Algorithm string
+	Qop       []string
+	UserHash  bool
+
+	nc int
+}
+
+// algorithms ordered by preference, when a server offers several challenges the strongest one is used.
+var algorithms = []string{"SHA-256-sess", "SHA-256", "MD5-sess", "MD5"}
+
+// FindChallenge returns the strongest supported digest challenge among the given WWW-Authenticate header values.
+func FindChallenge(headers []string) (*Challenge, error) {
+	var found *Challenge
+	rank := len(algorithms)
+	var lastErr error = ErrNoChallenge
+
+	for _, h := range headers {
+		c, err := ParseChallenge(h)
+		if err != nil {
+			if !errors.Is(err, ErrNoChallenge) {
+				lastErr = err
+			}
+			continue
+		}
+
+		for i, alg := range algorithms {
+			if strings.EqualFold(alg, c.Algorithm) && i < rank {
+				found, rank = c, i
+			}
+		}
+	}
+
+	if found == nil {
+		return nil, lastErr
+	}
+
+	return found, nil
+}
+
+// ParseChallenge parses a single WWW-Authenticate header value.
+func ParseChallenge(header string) (*Challenge, error) {
+	scheme, params, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return nil, ErrNoChallenge
+	}
+
+	c := &Challenge{Algorithm: "MD5"}
+	for k, v := range parseParams(params) {
+		switch k {
+		case "realm":
+			c.Realm = v
+		case "nonce":
+			c.Nonce = v
+		case "opaque":
+			c.Opaque = v
+		case "algorithm":
+			c.Algorithm = v
+		case "qop":
+			for _, q := range strings.Split(v, ",") {
+				if q = strings.TrimSpace(q); q != "" {
+					c.Qop = append(c.Qop, q)
+				}
+			}
+		case "userhash":
+			c.UserHash = strings.EqualFold(v, "true")
+		}
+	}
+
+	if c.Nonce == "" {
+		return nil, errors.New("digest challenge has no nonce")
+	}
+
+	if _, err := c.hasher(); err != nil {
+		return nil, err
+	}
+
+	if len(c.Qop) > 0 && !c.supportsQopAuth() {
+		return nil, ErrUnsupportedQop
+	}
+
+	return c, nil
+}
+
+// parseParams parses a comma separated list of key=value pairs where values may be quoted strings.
+func parseParams(s string) map[string]string {
+	out := make(map[string]string)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			value = b.String()
+			s = s[min(i+1, len(s)):]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+
+		out[key] = value
+	}
+	return out
+}
+
+func (c *Challenge) supportsQopAuth() bool {
+	for _, q := range c.Qop {
+		if strings.EqualFold(q, "auth") {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Challenge) isSess() bool {
+	return strings.HasSuffix(strings.ToLower(c.Algorithm), "-sess")
+}
+
+func (c *Challenge) hasher() (func() hash.Hash, error) {
+	switch strings.ToUpper(strings.TrimSuffix(strings.ToLower(c.Algorithm), "-sess")) {
+	case "MD5":
+		return md5.New, nil
+	case "SHA-256":
+		return sha256.New, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, c.Algorithm)
+	}
+}
+
+// Authorize returns the Authorization header value for the given request method and uri.
+func (c *Challenge) Authorize(method, uri, username, password string) (string, error) {
+	cnonce, err := newCnonce()
+	if err != nil {
+		return "", err
+	}
+	return c.authorize(method, uri, username, password, cnonce)
+}
+
+func (c *Challenge) authorize(method, uri, username, password, cnonce string) (string, error) {
+	newHash, err := c.hasher()
+	if err != nil {
+		return "", err
+	}
+
+	h := func(parts ...string) string {
+		hh := newHash()
+		hh.Write([]byte(strings.Join(parts, ":")))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	c.nc++
+	nc := fmt.Sprintf("%08x", c.nc)
+
+	ha1 := h(username, c.Realm, password)
+	if c.isSess() {
+		ha1 = h(ha1, c.Nonce, cnonce)
+	}
+	ha2 := h(method, uri)
+
+	var response string
+	qop := ""
+	if len(c.Qop) > 0 {
+		qop = "auth"
+		response = h(ha1, c.Nonce, nc, cnonce, qop, ha2)
+	} else {
+		response = h(ha1, c.Nonce, ha2)
+	}
+
+	user := username
+	if c.UserHash {
+		user = h(username, c.Realm)
+	}
+
+	params := []string{
+		fmt.Sprintf("username=%q", user),
+		fmt.Sprintf("realm=%q", c.Realm),
+		fmt.Sprintf("uri=%q", uri),
+		"algorithm=" + c.Algorithm,
+		fmt.Sprintf("nonce=%q", c.Nonce),
+	}
+
+	if qop != "" {
+		params = append(params, "nc="+nc, fmt.Sprintf("cnonce=%q", cnonce), "qop="+qop)
+	}
+
+	params = append(params, fmt.Sprintf("response=%q", response))
+	if c.Opaque != "" {
+		params = append(params, fmt.Sprintf("opaque=%q", c.Opaque))
+	}
+
+	if c.UserHash {
+		params = append(params, "userhash=true")
+	}
+
+	return "Digest " + strings.Join(params, ", "), nil
+}
+
+func newCnonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Transport answers digest challenges transparently, a request which gets a 401 with a digest
+// challenge is sent again with the computed Authorization header.
+type Transport struct {
+	Username string
+	Password string
+
+	// Base is the underlying round tripper, http.DefaultTransport is used if it is nil.
+	Base http.RoundTripper
+
+	// OnChallenge, if set, is called before the request is retried.
+	OnChallenge func(req *http.Request, c *Challenge)
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base().RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	c, err := FindChallenge(res.Header.Values("WWW-Authenticate"))
+	if err != nil {
+		if errors.Is(err, ErrNoChallenge) {
+			return res, nil
+		}
+		_ = res.Body.Close()
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			// the body is already consumed, nothing we can do but return the challenge
+			return res, nil
+		}
+
+		body, err := req.GetBody()
+		if err != nil {
+			_ = res.Body.Close()
+			return nil, err
+		}
+		retry.Body = body
+	}
+
+	authorization, err := c.Authorize(req.Method, req.URL.RequestURI(), t.Username, t.Password)
+	if err != nil {
+		_ = res.Body.Close()
+		return nil, err
+	}
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	if t.OnChallenge != nil {
+		t.OnChallenge(req, c)
+	}
+
+	retry.Header.Set("Authorization", authorization)
+	return t.base().RoundTrip(retry)
+}
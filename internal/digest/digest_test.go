@@ -0,0 +1,99 @@
+package digest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// values from the example in section 3.9.1 of RFC 7616.
+const (
+	rfcRealm  = "http-auth@example.org"
+	rfcNonce  = "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v"
+	rfcOpaque = "FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"
+	rfcCnonce = "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ"
+)
+
+func TestAuthorizeRFCExample(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		response  string
+	}{
+		{algorithm: "MD5", response: "8ca523f5e9506fed4657c9700eebdbec"},
+		{algorithm: "SHA-256", response: "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			c, err := ParseChallenge(`Digest realm="` + rfcRealm + `", qop="auth, auth-int", algorithm=` + tt.algorithm + `, nonce="` + rfcNonce + `", opaque="` + rfcOpaque + `"`)
+			require.NoError(t, err)
+
+			header, err := c.authorize(http.MethodGet, "/dir/index.html", "Mufasa", "Circle of Life", rfcCnonce)
+			require.NoError(t, err)
+
+			assert.Contains(t, header, `response="`+tt.response+`"`)
+			assert.Contains(t, header, "nc=00000001")
+			assert.Contains(t, header, "qop=auth,")
+			assert.Contains(t, header, `opaque="`+rfcOpaque+`"`)
+		})
+	}
+}
+
+func TestFindChallengePrefersStrongestAlgorithm(t *testing.T) {
+	c, err := FindChallenge([]string{
+		`Basic realm="test"`,
+		`Digest realm="test", qop="auth", algorithm=MD5, nonce="a"`,
+		`Digest realm="test", qop="auth", algorithm=SHA-256-sess, nonce="b"`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SHA-256-sess", c.Algorithm)
+	assert.Equal(t, "b", c.Nonce)
+
+	_, err = FindChallenge([]string{`Digest realm="test", qop="auth-int", nonce="a"`})
+	assert.ErrorIs(t, err, ErrUnsupportedQop)
+
+	_, err = FindChallenge([]string{`Basic realm="test"`})
+	assert.ErrorIs(t, err, ErrNoChallenge)
+}
+
+func TestTransport(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		auth := r.Header.Get("Authorization")
+		if auth == "" {
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", qop="auth", algorithm=MD5-sess, nonce="abc"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		assert.True(t, strings.HasPrefix(auth, "Digest "))
+		assert.Contains(t, auth, `username="user"`)
+		assert.Contains(t, auth, `uri="/path?q=1"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var challenged bool
+	client := &http.Client{Transport: &Transport{
+		Username: "user",
+		Password: "pass",
+		OnChallenge: func(_ *http.Request, c *Challenge) {
+			challenged = true
+			assert.Equal(t, "test", c.Realm)
+		},
+	}}
+
+	res, err := client.Post(srv.URL+"/path?q=1", "text/plain", strings.NewReader("body"))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.True(t, challenged)
+}
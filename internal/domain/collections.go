@@ -37,6 +37,9 @@ type ColSpec struct {
 	Headers  []KeyValue `yaml:"headers"`
 	Auth     Auth       `yaml:"auth"`
 	Notes    string     `yaml:"notes"`
+
+	// GraphQLSchemaFile is the schema of the graphql requests of the collection that don't set their own.
+	GraphQLSchemaFile string `yaml:"graphqlSchemaFile,omitempty"`
 }
 
 func (c *Collection) Clone() *Collection {
@@ -65,6 +68,7 @@ func (c *Collection) Clone() *Collection {
 
 	// Clone notes
 	clone.Spec.Notes = c.Spec.Notes
+	clone.Spec.GraphQLSchemaFile = c.Spec.GraphQLSchemaFile
 
 	for _, req := range c.Spec.Requests {
 		cloneReq := req.Clone()
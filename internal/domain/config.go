@@ -42,6 +42,8 @@ type GeneralConfig struct {
 	FollowRedirects        bool   `yaml:"followRedirects"`
 	VaidateTLSCertificates bool   `yaml:"validateTLSCertificates"`
 	Theme                  string `yaml:"theme"`
+
+	Proxy ProxyConfig `yaml:"proxy"`
 }
 
 func (g GeneralConfig) Changed(other GeneralConfig) bool {
@@ -53,7 +55,8 @@ func (g GeneralConfig) Changed(other GeneralConfig) bool {
 		g.SendChaparAgentHeader != other.SendChaparAgentHeader ||
 		g.FollowRedirects != other.FollowRedirects ||
 		g.VaidateTLSCertificates != other.VaidateTLSCertificates ||
-		g.Theme != other.Theme
+		g.Theme != other.Theme ||
+		g.Proxy.Changed(other.Proxy)
 }
 
 const (
@@ -147,6 +150,9 @@ func GetDefaultGlobalConfig() *GlobalConfig {
 				FollowRedirects:        true,
 				VaidateTLSCertificates: true,
 				Theme:                  "light",
+				Proxy: ProxyConfig{
+					Mode: ProxyModeSystem,
+				},
 			},
 			Editor: EditorConfig{
 				FontFamily:        "JetBrains Mono",
@@ -226,6 +232,11 @@ func GlobalConfigFromValues(initial GlobalConfig, values map[string]any) GlobalC
 	g.Spec.General.FollowRedirects = getOrDefault(values, "followRedirects", g.Spec.General.FollowRedirects).(bool)
 	g.Spec.General.VaidateTLSCertificates = getOrDefault(values, "validateTLSCertificates", g.Spec.General.VaidateTLSCertificates).(bool)
 	g.Spec.General.Theme = getOrDefault(values, "theme", g.Spec.General.Theme).(string)
+	g.Spec.General.Proxy.Mode = getOrDefault(values, "proxyMode", g.Spec.General.Proxy.Mode).(string)
+	g.Spec.General.Proxy.URL = getOrDefault(values, "proxyURL", g.Spec.General.Proxy.URL).(string)
+	g.Spec.General.Proxy.Username = getOrDefault(values, "proxyUsername", g.Spec.General.Proxy.Username).(string)
+	g.Spec.General.Proxy.Password = getOrDefault(values, "proxyPassword", g.Spec.General.Proxy.Password).(string)
+	g.Spec.General.Proxy.Bypass = getOrDefault(values, "proxyBypass", g.Spec.General.Proxy.Bypass).(string)
 
 	g.Spec.Editor.FontFamily = getOrDefault(values, "fontFamily", g.Spec.Editor.FontFamily).(string)
 	g.Spec.Editor.FontSize = getOrDefault(values, "fontSize", g.Spec.Editor.FontSize).(int)
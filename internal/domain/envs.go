@@ -36,11 +36,15 @@ func (e *Environment) MarshalYaml() ([]byte, error) {
 
 type EnvSpec struct {
 	Values []KeyValue `yaml:"values"`
+
+	// Proxy overrides the global proxy settings for the requests sent with this environment.
+	Proxy *ProxyConfig `yaml:"proxy,omitempty"`
 }
 
 func (e *EnvSpec) Clone() EnvSpec {
 	clone := EnvSpec{
 		Values: make([]KeyValue, len(e.Values)),
+		Proxy:  e.Proxy.Clone(),
 	}
 
 	for i, v := range e.Values {
@@ -154,6 +158,10 @@ func (e *Environment) ApplyToGRPCRequest(req *GRPCRequestSpec) {
 					req.Auth.TokenAuth.Token = strings.ReplaceAll(req.Auth.TokenAuth.Token, "{{"+envKv.Key+"}}", envKv.Value)
 				}
 			}
+
+			req.Auth.OAuth2Auth.ApplyVariable(envKv.Key, envKv.Value)
+			req.Auth.AWSSigV4Auth.ApplyVariable(envKv.Key, envKv.Value)
+			req.Auth.DigestAuth.ApplyVariable(envKv.Key, envKv.Value)
 		}
 	}
 }
@@ -235,6 +243,10 @@ func (e *Environment) ApplyToHTTPRequest(req *HTTPRequestSpec) {
 				req.Request.Auth.APIKeyAuth.Value = strings.ReplaceAll(req.Request.Auth.APIKeyAuth.Value, "{{"+envKv.Key+"}}", envKv.Value)
 			}
 		}
+
+		req.Request.Auth.OAuth2Auth.ApplyVariable(envKv.Key, envKv.Value)
+		req.Request.Auth.AWSSigV4Auth.ApplyVariable(envKv.Key, envKv.Value)
+		req.Request.Auth.DigestAuth.ApplyVariable(envKv.Key, envKv.Value)
 	}
 }
 
@@ -287,6 +299,71 @@ func (e *Environment) ApplyToGraphQLRequest(req *GraphQLRequestSpec) {
 					req.Auth.TokenAuth.Token = strings.ReplaceAll(req.Auth.TokenAuth.Token, "{{"+envKv.Key+"}}", envKv.Value)
 				}
 			}
+
+			req.Auth.OAuth2Auth.ApplyVariable(envKv.Key, envKv.Value)
+			req.Auth.AWSSigV4Auth.ApplyVariable(envKv.Key, envKv.Value)
+			req.Auth.DigestAuth.ApplyVariable(envKv.Key, envKv.Value)
+		}
+	}
+}
+
+func (e *Environment) ApplyToWebSocketRequest(req *WebSocketRequestSpec) {
+	if e == nil || req == nil {
+		return
+	}
+
+	for _, envKv := range e.Spec.Values {
+		if strings.Contains(req.URL, "{{"+envKv.Key+"}}") {
+			req.URL = strings.ReplaceAll(req.URL, "{{"+envKv.Key+"}}", envKv.Value)
+		}
+
+		for i, kv := range req.Headers {
+			if strings.Contains(kv.Value, "{{"+envKv.Key+"}}") {
+				req.Headers[i].Value = strings.ReplaceAll(kv.Value, "{{"+envKv.Key+"}}", envKv.Value)
+			}
+		}
+
+		for i, p := range req.Subprotocols {
+			if strings.Contains(p, "{{"+envKv.Key+"}}") {
+				req.Subprotocols[i] = strings.ReplaceAll(p, "{{"+envKv.Key+"}}", envKv.Value)
+			}
+		}
+
+		for i, m := range req.Messages {
+			if m.Type == WebSocketMessageTypeText && strings.Contains(m.Data, "{{"+envKv.Key+"}}") {
+				req.Messages[i].Data = strings.ReplaceAll(m.Data, "{{"+envKv.Key+"}}", envKv.Value)
+			}
+		}
+
+		if req.Auth != (Auth{}) {
+			if req.Auth.APIKeyAuth != nil {
+				if strings.Contains(req.Auth.APIKeyAuth.Key, "{{"+envKv.Key+"}}") {
+					req.Auth.APIKeyAuth.Key = strings.ReplaceAll(req.Auth.APIKeyAuth.Key, "{{"+envKv.Key+"}}", envKv.Value)
+				}
+				if strings.Contains(req.Auth.APIKeyAuth.Value, "{{"+envKv.Key+"}}") {
+					req.Auth.APIKeyAuth.Value = strings.ReplaceAll(req.Auth.APIKeyAuth.Value, "{{"+envKv.Key+"}}", envKv.Value)
+				}
+			}
+
+			if req.Auth.BasicAuth != nil {
+				if strings.Contains(req.Auth.BasicAuth.Username, "{{"+envKv.Key+"}}") {
+					req.Auth.BasicAuth.Username = strings.ReplaceAll(req.Auth.BasicAuth.Username, "{{"+envKv.Key+"}}", envKv.Value)
+				}
+
+				if strings.Contains(req.Auth.BasicAuth.Password, "{{"+envKv.Key+"}}") {
+					req.Auth.BasicAuth.Password = strings.ReplaceAll(req.Auth.BasicAuth.Password, "{{"+envKv.Key+"}}", envKv.Value)
+				}
+			}
+
+			if req.Auth.TokenAuth != nil {
+				if strings.Contains(req.Auth.TokenAuth.Token, "{{"+envKv.Key+"}}") {
+					req.Auth.TokenAuth.Token = strings.ReplaceAll(req.Auth.TokenAuth.Token, "{{"+envKv.Key+"}}", envKv.Value)
+				}
+			}
+
+			req.Auth.OAuth2Auth.ApplyVariable(envKv.Key, envKv.Value)
+			req.Auth.AWSSigV4Auth.ApplyVariable(envKv.Key, envKv.Value)
+			req.Auth.DigestAuth.ApplyVariable(envKv.Key, envKv.Value)
 		}
 	}
 }
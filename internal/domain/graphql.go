@@ -1,11 +1,20 @@
 package domain
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// GraphQLSubscriptionTransportWebSocket runs subscriptions over graphql-transport-ws, servers which only
+	// speak the legacy subscriptions-transport-ws protocol are supported as well.
+	GraphQLSubscriptionTransportWebSocket = "websocket"
+	// GraphQLSubscriptionTransportSSE runs subscriptions over the GraphQL over SSE protocol.
+	GraphQLSubscriptionTransportSSE = "sse"
+)
+
 type GraphQLRequestSpec struct {
 	URL       string     `yaml:"url"`
 	Query     string     `yaml:"query"`
@@ -13,12 +22,41 @@ type GraphQLRequestSpec struct {
 	Headers   []KeyValue `yaml:"headers"`
 	Auth      Auth       `yaml:"auth"`
 
+	// OperationName is the operation of the query which is run, it's required when the query has more than
+	// one operation.
+	OperationName string `yaml:"operationName,omitempty"`
+
 	LastUsedEnvironment LastUsedEnvironment `yaml:"lastUsedEnvironment"`
 
 	VariablesList []Variable `yaml:"variablesList"`
 
 	PreRequest  PreRequest  `yaml:"preRequest"`
 	PostRequest PostRequest `yaml:"postRequest"`
+
+	// Proxy overrides the environment and global proxy settings, nil means inherit.
+	Proxy *ProxyConfig `yaml:"proxy,omitempty"`
+
+	// SchemaFile is a SDL or introspection json file describing the schema, when empty the schema of the
+	// collection is used or the server is asked with an introspection query.
+	SchemaFile string `yaml:"schemaFile,omitempty"`
+
+	// SubscriptionTransport is how subscription operations are sent, empty means websocket.
+	SubscriptionTransport string `yaml:"subscriptionTransport,omitempty"`
+
+	// Uploads map variables to local files, when there are any the request is sent following the GraphQL
+	// multipart request spec.
+	Uploads []GraphQLUpload `yaml:"uploads,omitempty"`
+
+	// PersistedQueries enables automatic persisted queries, the sha256 hash of the query is sent first and
+	// the query itself only when the server doesn't know the hash yet.
+	PersistedQueries bool `yaml:"persistedQueries,omitempty"`
+}
+
+// GraphQLUpload maps a variable to a local file, Variable is the path of the variable like "file",
+// "files.0" or "input.avatar".
+type GraphQLUpload struct {
+	Variable string `yaml:"variable"`
+	File     string `yaml:"file"`
 }
 
 func (g *GraphQLRequestSpec) Clone() *GraphQLRequestSpec {
@@ -40,6 +78,13 @@ func (g *GraphQLRequestSpec) Clone() *GraphQLRequestSpec {
 		clone.Auth = g.Auth.Clone()
 	}
 
+	if len(g.Uploads) > 0 {
+		clone.Uploads = make([]GraphQLUpload, len(g.Uploads))
+		copy(clone.Uploads, g.Uploads)
+	}
+
+	clone.Proxy = g.Proxy.Clone()
+
 	return &clone
 }
 
@@ -50,9 +95,18 @@ type GraphQLResponseDetail struct {
 	StatusCode      int
 	Duration        time.Duration
 	Size            int
+	Timing          Timing
+	Tests           []TestResult
 	Error           error
 }
 
+// GraphQLSubscriptionMessage is a payload sent by the server for a subscription.
+type GraphQLSubscriptionMessage struct {
+	Payload string
+	Size    int
+	Time    time.Time
+}
+
 func NewGraphQLRequest(name string) *Request {
 	return &Request{
 		ApiVersion: ApiVersion,
@@ -81,7 +135,12 @@ func CompareGraphQLRequestSpecs(a, b *GraphQLRequestSpec) bool {
 		return false
 	}
 
-	if a.URL != b.URL || a.Query != b.Query || a.Variables != b.Variables {
+	if a.URL != b.URL || a.Query != b.Query || a.Variables != b.Variables || a.OperationName != b.OperationName || a.SchemaFile != b.SchemaFile ||
+		a.SubscriptionTransport != b.SubscriptionTransport || a.PersistedQueries != b.PersistedQueries {
+		return false
+	}
+
+	if !slices.Equal(a.Uploads, b.Uploads) {
 		return false
 	}
 
@@ -105,6 +164,10 @@ func CompareGraphQLRequestSpecs(a, b *GraphQLRequestSpec) bool {
 		return false
 	}
 
+	if !CompareProxyConfig(a.Proxy, b.Proxy) {
+		return false
+	}
+
 	return true
 }
 
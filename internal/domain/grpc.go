@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"bytes"
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -18,6 +20,11 @@ type GRPCRequestSpec struct {
 	Services          []GRPCService `yaml:"services"`
 	Variables         []Variable    `yaml:"variables"`
 
+	// BodyFormat is the format of Body, empty means protojson. binary bodies are read from BodyFile
+	// and sent as they are.
+	BodyFormat string `yaml:"bodyFormat,omitempty"`
+	BodyFile   string `yaml:"bodyFile,omitempty"`
+
 	PreRequest  PreRequest  `yaml:"preRequest"`
 	PostRequest PostRequest `yaml:"postRequest"`
 }
@@ -35,6 +42,8 @@ type ServerInfo struct {
 }
 
 type GRPCSettings struct {
+	// Protocol is the wire protocol of the calls, empty means native grpc.
+	Protocol            string `yaml:"protocol,omitempty"`
 	Insecure            bool   `yaml:"insecure"`
 	TimeoutMilliseconds int    `yaml:"timeoutMilliseconds"`
 	NameOverride        string `yaml:"nameOverride"`
@@ -42,8 +51,48 @@ type GRPCSettings struct {
 	RootCertFile   string `yaml:"rootCertFile"`
 	ClientCertFile string `yaml:"clientCertFile"`
 	ClientKeyFile  string `yaml:"clientKeyFile"`
+
+	// Compression is the compressor of the messages sent, empty means no compression.
+	Compression string `yaml:"compression,omitempty"`
+	// the max size of the messages in megabytes, zero keeps the grpc defaults of 4MB received and unlimited sent
+	MaxSendMessageSizeMB    int  `yaml:"maxSendMessageSizeMB,omitempty"`
+	MaxReceiveMessageSizeMB int  `yaml:"maxReceiveMessageSizeMB,omitempty"`
+	WaitForReady            bool `yaml:"waitForReady,omitempty"`
+
+	// keepalive pings are sent after KeepaliveTimeSeconds without activity, zero disables them.
+	KeepaliveTimeSeconds         int  `yaml:"keepaliveTimeSeconds,omitempty"`
+	KeepaliveTimeoutSeconds      int  `yaml:"keepaliveTimeoutSeconds,omitempty"`
+	KeepalivePermitWithoutStream bool `yaml:"keepalivePermitWithoutStream,omitempty"`
+
+	// Proxy overrides the environment and global proxy settings, nil means inherit.
+	Proxy *ProxyConfig `yaml:"proxy,omitempty"`
 }
 
+const (
+	GRPCProtocolNative       = "grpc"
+	GRPCProtocolWeb          = "grpc-web"
+	GRPCProtocolWebText      = "grpc-web-text"
+	GRPCProtocolConnect      = "connect"
+	GRPCProtocolConnectProto = "connect-proto"
+)
+
+const GRPCCompressionGzip = "gzip"
+
+// formats of the grpc request bodies
+const (
+	GRPCBodyFormatJSON   = "json"
+	GRPCBodyFormatText   = "text"
+	GRPCBodyFormatBinary = "binary"
+)
+
+// states of the grpc connections shown for the requests
+const (
+	GRPCConnIdle       = "idle"
+	GRPCConnConnecting = "connecting"
+	GRPCConnReady      = "ready"
+	GRPCConnFailure    = "failure"
+)
+
 type GRPCMethod struct {
 	FullName          string `yaml:"fullName"`
 	Name              string `yaml:"name"`
@@ -51,8 +100,55 @@ type GRPCMethod struct {
 	IsStreamingServer bool   `yaml:"IsStreamingServer"`
 }
 
+const (
+	GRPCMessageSent     = "sent"
+	GRPCMessageReceived = "received"
+)
+
+// GRPCStreamMessage is a message sent or received on a streaming call, Body is the message as JSON
+// and Size is its size on the wire.
+type GRPCStreamMessage struct {
+	Direction string
+	Body      string
+	// Text and Hex are the message in the text format and the hex dump of its wire bytes.
+	Text string
+	Hex  string
+	Size int
+	Time time.Time
+}
+
+// GRPCStreamMessagesToJSONL returns the messages in the JSON Lines format, each line holds the message
+// next to its direction, time and size.
+func GRPCStreamMessagesToJSONL(messages []GRPCStreamMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, m := range messages {
+		line, err := json.Marshal(struct {
+			Direction string          `json:"direction"`
+			Time      time.Time       `json:"time"`
+			Size      int             `json:"size"`
+			Message   json.RawMessage `json:"message"`
+		}{
+			Direction: m.Direction,
+			Time:      m.Time,
+			Size:      m.Size,
+			Message:   json.RawMessage(m.Body),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
 type GRPCResponseDetail struct {
-	Response         string
+	Response string
+	// ResponseText and ResponseHex are the response in the proto text format and its hex dumped wire bytes.
+	ResponseText     string
+	ResponseHex      string
 	RequestMetadata  []KeyValue
 	ResponseMetadata []KeyValue
 	Trailers         []KeyValue
@@ -60,9 +156,14 @@ type GRPCResponseDetail struct {
 	Duration         time.Duration
 	Size             int
 	Error            error
+	// ErrorDetails are the details of the status error as a json array.
+	ErrorDetails string
 
 	StatueCode int
 	Status     string
+
+	// Tests are the results of the tests of the post request script.
+	Tests []TestResult
 }
 
 func (r *GRPCRequestSpec) Clone() *GRPCRequestSpec {
@@ -101,6 +202,8 @@ func (r *GRPCRequestSpec) Clone() *GRPCRequestSpec {
 		clone.Auth = r.Auth.Clone()
 	}
 
+	clone.Settings.Proxy = r.Settings.Proxy.Clone()
+
 	return &clone
 }
 
@@ -116,6 +219,19 @@ func (r *GRPCRequestSpec) HasMethod(method string) bool {
 	return false
 }
 
+// GetMethod returns the method with the given full name, it returns nil if none of the services has it.
+func (r *GRPCRequestSpec) GetMethod(fullName string) *GRPCMethod {
+	for i := range r.Services {
+		for j := range r.Services[i].Methods {
+			if r.Services[i].Methods[j].FullName == fullName {
+				return &r.Services[i].Methods[j]
+			}
+		}
+	}
+
+	return nil
+}
+
 func NewGRPCRequest(name string) *Request {
 	return &Request{
 		ApiVersion: ApiVersion,
@@ -148,7 +264,7 @@ func CompareGRPCRequestSpecs(a, b *GRPCRequestSpec) bool {
 		return false
 	}
 
-	if a.Body != b.Body {
+	if a.Body != b.Body || a.BodyFormat != b.BodyFormat || a.BodyFile != b.BodyFile {
 		return false
 	}
 
@@ -198,12 +314,24 @@ func (r *Request) SetDefaultValuesForGRPC() {
 }
 
 func CompareGRPCSettings(a, b GRPCSettings) bool {
-	if a.Insecure != b.Insecure ||
+	if a.Protocol != b.Protocol ||
+		a.Insecure != b.Insecure ||
 		a.TimeoutMilliseconds != b.TimeoutMilliseconds ||
 		a.NameOverride != b.NameOverride ||
 		a.RootCertFile != b.RootCertFile ||
 		a.ClientCertFile != b.ClientCertFile ||
-		a.ClientKeyFile != b.ClientKeyFile {
+		a.ClientKeyFile != b.ClientKeyFile ||
+		a.Compression != b.Compression ||
+		a.MaxSendMessageSizeMB != b.MaxSendMessageSizeMB ||
+		a.MaxReceiveMessageSizeMB != b.MaxReceiveMessageSizeMB ||
+		a.WaitForReady != b.WaitForReady ||
+		a.KeepaliveTimeSeconds != b.KeepaliveTimeSeconds ||
+		a.KeepaliveTimeoutSeconds != b.KeepaliveTimeoutSeconds ||
+		a.KeepalivePermitWithoutStream != b.KeepalivePermitWithoutStream {
+		return false
+	}
+
+	if !CompareProxyConfig(a.Proxy, b.Proxy) {
 		return false
 	}
 
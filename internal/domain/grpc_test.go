@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCStreamMessagesToJSONL(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	messages := []GRPCStreamMessage{
+		{Direction: GRPCMessageSent, Body: "{\n  \"name\": \"chapar\"\n}", Size: 8, Time: at},
+		{Direction: GRPCMessageReceived, Body: "{}", Size: 0, Time: at.Add(time.Second)},
+	}
+
+	out, err := GRPCStreamMessagesToJSONL(messages)
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"direction":"sent","time":"2024-05-01T10:00:00Z","size":8,"message":{"name":"chapar"}}`+"\n"+
+			`{"direction":"received","time":"2024-05-01T10:00:01Z","size":0,"message":{}}`+"\n",
+		string(out))
+
+	_, err = GRPCStreamMessagesToJSONL([]GRPCStreamMessage{{Body: "not json"}})
+	require.Error(t, err)
+
+	out, err = GRPCStreamMessagesToJSONL(nil)
+	require.NoError(t, err)
+	require.Empty(t, out)
+}
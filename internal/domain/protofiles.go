@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"path/filepath"
+	"strings"
+
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v2"
 )
@@ -35,9 +38,25 @@ func (p *ProtoFile) MarshalYaml() ([]byte, error) {
 type ProtoFileSpec struct {
 	Path string `yaml:"path"`
 	// TODO should it be a dedicated type?
-	IsImportPath bool     `yaml:"isImportPath"`
-	Package      string   `yaml:"package"`
-	Services     []string `yaml:"services"`
+	IsImportPath bool `yaml:"isImportPath"`
+	// IsDescriptorSet is true for compiled FileDescriptorSets, such as the output of protoc --descriptor_set_out
+	IsDescriptorSet bool     `yaml:"isDescriptorSet,omitempty"`
+	Package         string   `yaml:"package"`
+	Services        []string `yaml:"services"`
+}
+
+// DescriptorSetExtensions are the extensions of the compiled FileDescriptorSet files.
+var DescriptorSetExtensions = []string{"protoset", "binpb", "pb"}
+
+// IsDescriptorSetFile returns true if the path has the extension of a FileDescriptorSet file.
+func IsDescriptorSetFile(path string) bool {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	for _, e := range DescriptorSetExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
 }
 
 func NewProtoFile(name string) *ProtoFile {
@@ -64,6 +83,7 @@ func CompareProtoFileSpecs(a, b ProtoFileSpec) bool {
 	return a.Path == b.Path &&
 		a.Package == b.Package &&
 		a.IsImportPath == b.IsImportPath &&
+		a.IsDescriptorSet == b.IsDescriptorSet &&
 		compareStringSlices(a.Services, b.Services)
 }
 
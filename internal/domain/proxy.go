@@ -0,0 +1,60 @@
+package domain
+
+const (
+	// ProxyModeSystem uses the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	ProxyModeSystem = "system"
+	ProxyModeNone   = "none"
+	ProxyModeManual = "manual"
+)
+
+// ProxyConfig describes how requests reach the network.
+// an empty mode is treated as ProxyModeSystem so existing configs keep working with environment proxies.
+type ProxyConfig struct {
+	Mode string `yaml:"mode"`
+	// URL of the proxy, the scheme can be http, https or socks5 e.g. socks5://127.0.0.1:1080
+	URL      string `yaml:"url,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// Bypass is a comma separated list of hosts which are reached directly,
+	// entries can be hostnames, wildcards like *.example.com, IPs or CIDRs.
+	Bypass string `yaml:"bypass,omitempty"`
+}
+
+func (p *ProxyConfig) Clone() *ProxyConfig {
+	if p == nil {
+		return nil
+	}
+
+	clone := *p
+	return &clone
+}
+
+func (p ProxyConfig) Changed(other ProxyConfig) bool {
+	return p != other
+}
+
+func CompareProxyConfig(a, b *ProxyConfig) bool {
+	if a == nil && b == nil {
+		return true
+	}
+
+	if a == nil || b == nil {
+		return false
+	}
+
+	return *a == *b
+}
+
+// ResolveProxy returns the proxy config to use, the request override wins over the environment
+// override which wins over the global config.
+func ResolveProxy(global ProxyConfig, env *Environment, request *ProxyConfig) ProxyConfig {
+	if request != nil {
+		return *request
+	}
+
+	if env != nil && env.Spec.Proxy != nil {
+		return *env.Spec.Proxy
+	}
+
+	return global
+}
@@ -11,9 +11,10 @@ import (
 type RequestType string
 
 const (
-	RequestTypeHTTP    RequestType = "http"
-	RequestTypeGRPC    RequestType = "grpc"
-	RequestTypeGraphQL RequestType = "graphql"
+	RequestTypeHTTP      RequestType = "http"
+	RequestTypeGRPC      RequestType = "grpc"
+	RequestTypeGraphQL   RequestType = "graphql"
+	RequestTypeWebSocket RequestType = "websocket"
 
 	RequestMethodGET     = "GET"
 	RequestMethodPOST    = "POST"
@@ -37,6 +38,7 @@ const (
 	PrePostTypeTriggerRequest = "triggerRequest"
 	PrePostTypeSetEnv         = "setEnv"
 	PrePostTypePython         = "python"
+	PrePostTypeJavaScript     = "javascript"
 	PrePostTypeShell          = "ssh"
 	PrePostTypeSSHTunnel      = "sshTunnel"
 	PrePostTypeK8sTunnel      = "k8sTunnel"
@@ -90,6 +92,13 @@ type ResponseDetail struct {
 	GraphQL *GraphQLResponseDetail
 }
 
+// TestResult is the result of a test of a script, Message tells why it failed.
+type TestResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
 type RequestMeta struct {
 	ID   string      `yaml:"id"`
 	Name string      `yaml:"name"`
@@ -97,9 +106,10 @@ type RequestMeta struct {
 }
 
 type RequestSpec struct {
-	GRPC    *GRPCRequestSpec    `yaml:"grpc,omitempty"`
-	HTTP    *HTTPRequestSpec    `yaml:"http,omitempty"`
-	GraphQL *GraphQLRequestSpec `yaml:"graphql,omitempty"`
+	GRPC      *GRPCRequestSpec      `yaml:"grpc,omitempty"`
+	HTTP      *HTTPRequestSpec      `yaml:"http,omitempty"`
+	GraphQL   *GraphQLRequestSpec   `yaml:"graphql,omitempty"`
+	WebSocket *WebSocketRequestSpec `yaml:"websocket,omitempty"`
 }
 
 func (r *RequestSpec) GetGRPC() *GRPCRequestSpec {
@@ -146,13 +156,18 @@ func DoablePreRequest(preReq PreRequest) bool {
 		return true
 	}
 
-	if preReq.Type == PrePostTypePython && preReq.Script != "" {
+	if IsScriptType(preReq.Type) && preReq.Script != "" {
 		return true
 	}
 
 	return false
 }
 
+// IsScriptType reports whether the pre or post request type runs a script.
+func IsScriptType(t string) bool {
+	return t == PrePostTypePython || t == PrePostTypeJavaScript
+}
+
 func DoablePostRequest(postReq PostRequest) bool {
 	if postReq == (PostRequest{}) {
 		return false
@@ -162,7 +177,7 @@ func DoablePostRequest(postReq PostRequest) bool {
 		return true
 	}
 
-	if postReq.Type == PrePostTypePython && postReq.Script != "" {
+	if IsScriptType(postReq.Type) && postReq.Script != "" {
 		return true
 	}
 
@@ -269,18 +284,64 @@ type LastUsedEnvironment struct {
 }
 
 const (
-	AuthTypeNone    = "none"
-	AuthTypeBasic   = "basic"
-	AuthTypeToken   = "token"
-	AuthTypeAPIKey  = "apiKey"
-	AuthTypeInherit = "inherit"
+	AuthTypeNone     = "none"
+	AuthTypeBasic    = "basic"
+	AuthTypeToken    = "token"
+	AuthTypeAPIKey   = "apiKey"
+	AuthTypeOAuth2   = "oauth2"
+	AuthTypeAWSSigV4 = "awsSigV4"
+	AuthTypeDigest   = "digest"
+	AuthTypeInherit  = "inherit"
+)
+
+const (
+	OAuth2GrantTypeClientCredentials = "client_credentials"
+	OAuth2GrantTypePassword          = "password"
+	OAuth2GrantTypeAuthorizationCode = "authorization_code"
 )
 
 type Auth struct {
-	Type       string      `yaml:"type"`
-	BasicAuth  *BasicAuth  `yaml:"basicAuth,omitempty"`
-	TokenAuth  *TokenAuth  `yaml:"tokenAuth,omitempty"`
-	APIKeyAuth *APIKeyAuth `yaml:"apiKey,omitempty"`
+	Type         string        `yaml:"type"`
+	BasicAuth    *BasicAuth    `yaml:"basicAuth,omitempty"`
+	TokenAuth    *TokenAuth    `yaml:"tokenAuth,omitempty"`
+	APIKeyAuth   *APIKeyAuth   `yaml:"apiKey,omitempty"`
+	OAuth2Auth   *OAuth2Auth   `yaml:"oauth2,omitempty"`
+	AWSSigV4Auth *AWSSigV4Auth `yaml:"awsSigV4,omitempty"`
+	DigestAuth   *DigestAuth   `yaml:"digest,omitempty"`
+}
+
+// DigestAuth holds the credentials used to answer HTTP Digest challenges,
+// the algorithm and qop are picked from the challenge sent by the server.
+type DigestAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// AWSSigV4Auth holds the credentials and scope used to sign requests with AWS Signature Version 4.
+type AWSSigV4Auth struct {
+	AccessKeyID     string `yaml:"accessKeyID"`
+	SecretAccessKey string `yaml:"secretAccessKey"`
+	SessionToken    string `yaml:"sessionToken,omitempty"`
+	Region          string `yaml:"region"`
+	Service         string `yaml:"service"`
+}
+
+// OAuth2Auth holds the configuration needed to acquire an access token from an OAuth 2.0 authorization server.
+type OAuth2Auth struct {
+	GrantType    string `yaml:"grantType"`
+	AuthURL      string `yaml:"authURL,omitempty"`
+	TokenURL     string `yaml:"tokenURL"`
+	ClientID     string `yaml:"clientID"`
+	ClientSecret string `yaml:"clientSecret,omitempty"`
+	Scopes       string `yaml:"scopes,omitempty"`
+	Audience     string `yaml:"audience,omitempty"`
+
+	// password grant
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+
+	// authorization code grant, it must point to a loopback address e.g. http://127.0.0.1:8085/callback
+	RedirectURL string `yaml:"redirectURL,omitempty"`
 }
 
 type APIKeyAuth struct {
@@ -302,6 +363,18 @@ func (a *Auth) Clone() Auth {
 		clone.APIKeyAuth = a.APIKeyAuth.Clone()
 	}
 
+	if a.OAuth2Auth != nil {
+		clone.OAuth2Auth = a.OAuth2Auth.Clone()
+	}
+
+	if a.AWSSigV4Auth != nil {
+		clone.AWSSigV4Auth = a.AWSSigV4Auth.Clone()
+	}
+
+	if a.DigestAuth != nil {
+		clone.DigestAuth = a.DigestAuth.Clone()
+	}
+
 	return clone
 }
 
@@ -325,6 +398,60 @@ func (a *APIKeyAuth) Clone() *APIKeyAuth {
 	}
 }
 
+func (a *OAuth2Auth) Clone() *OAuth2Auth {
+	clone := *a
+	return &clone
+}
+
+// ApplyVariable replaces {{key}} with value in all the fields of the oauth2 config.
+func (a *OAuth2Auth) ApplyVariable(key, value string) {
+	if a == nil {
+		return
+	}
+
+	for _, f := range []*string{&a.AuthURL, &a.TokenURL, &a.ClientID, &a.ClientSecret, &a.Scopes, &a.Audience, &a.Username, &a.Password, &a.RedirectURL} {
+		if strings.Contains(*f, "{{"+key+"}}") {
+			*f = strings.ReplaceAll(*f, "{{"+key+"}}", value)
+		}
+	}
+}
+
+func (a *AWSSigV4Auth) Clone() *AWSSigV4Auth {
+	clone := *a
+	return &clone
+}
+
+// ApplyVariable replaces {{key}} with value in all the fields of the sigv4 config.
+func (a *AWSSigV4Auth) ApplyVariable(key, value string) {
+	if a == nil {
+		return
+	}
+
+	for _, f := range []*string{&a.AccessKeyID, &a.SecretAccessKey, &a.SessionToken, &a.Region, &a.Service} {
+		if strings.Contains(*f, "{{"+key+"}}") {
+			*f = strings.ReplaceAll(*f, "{{"+key+"}}", value)
+		}
+	}
+}
+
+func (a *DigestAuth) Clone() *DigestAuth {
+	clone := *a
+	return &clone
+}
+
+// ApplyVariable replaces {{key}} with value in the digest credentials.
+func (a *DigestAuth) ApplyVariable(key, value string) {
+	if a == nil {
+		return
+	}
+
+	for _, f := range []*string{&a.Username, &a.Password} {
+		if strings.Contains(*f, "{{"+key+"}}") {
+			*f = strings.ReplaceAll(*f, "{{"+key+"}}", value)
+		}
+	}
+}
+
 type BasicAuth struct {
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
@@ -360,6 +487,8 @@ const (
 	PostRequestSetFromResponseCookie   = "responseCookie"
 	PostRequestSetFromResponseMetaData = "responseMetaData"
 	PostRequestSetFromResponseTrailers = "responseTrailers"
+	// PostRequestSetFromResponseErrorDetails reads from the details of a failed grpc call with a JSON path.
+	PostRequestSetFromResponseErrorDetails = "responseErrorDetails"
 )
 
 type PostRequestSet struct {
@@ -414,7 +543,7 @@ func (pr *PostRequest) IsValid() bool {
 		return true
 	}
 
-	if pr.Type == PrePostTypePython && pr.Script != "" {
+	if IsScriptType(pr.Type) && pr.Script != "" {
 		return true
 	}
 
@@ -426,7 +555,8 @@ func (ps *PostRequestSet) IsValid() bool {
 		ps.From == PostRequestSetFromResponseBody ||
 		ps.From == PostRequestSetFromResponseCookie ||
 		ps.From == PostRequestSetFromResponseMetaData ||
-		ps.From == PostRequestSetFromResponseTrailers)
+		ps.From == PostRequestSetFromResponseTrailers ||
+		ps.From == PostRequestSetFromResponseErrorDetails)
 }
 
 type VariableFrom string
@@ -441,6 +571,8 @@ const (
 	VariableFromCookies  VariableFrom = "cookies"
 	VariableFromMetaData VariableFrom = "metadata"
 	VariableFromTrailers VariableFrom = "trailers"
+	// VariableFromErrorDetails reads from the details of a failed grpc call with a JSON path.
+	VariableFromErrorDetails VariableFrom = "errorDetails"
 )
 
 type Variable struct {
@@ -481,6 +613,10 @@ func CompareRequests(a, b *Request) bool {
 		return false
 	}
 
+	if !CompareWebSocketRequestSpecs(a.Spec.WebSocket, b.Spec.WebSocket) {
+		return false
+	}
+
 	return true
 }
 
@@ -501,9 +637,57 @@ func CompareAuth(a, b Auth) bool {
 		return false
 	}
 
+	if !CompareOAuth2Auth(a.OAuth2Auth, b.OAuth2Auth) {
+		return false
+	}
+
+	if !CompareAWSSigV4Auth(a.AWSSigV4Auth, b.AWSSigV4Auth) {
+		return false
+	}
+
+	if !CompareDigestAuth(a.DigestAuth, b.DigestAuth) {
+		return false
+	}
+
 	return true
 }
 
+func CompareDigestAuth(a, b *DigestAuth) bool {
+	if a == nil && b == nil {
+		return true
+	}
+
+	if a == nil || b == nil {
+		return false
+	}
+
+	return *a == *b
+}
+
+func CompareAWSSigV4Auth(a, b *AWSSigV4Auth) bool {
+	if a == nil && b == nil {
+		return true
+	}
+
+	if a == nil || b == nil {
+		return false
+	}
+
+	return *a == *b
+}
+
+func CompareOAuth2Auth(a, b *OAuth2Auth) bool {
+	if a == nil && b == nil {
+		return true
+	}
+
+	if a == nil || b == nil {
+		return false
+	}
+
+	return *a == *b
+}
+
 func CompareBasicAuth(a, b *BasicAuth) bool {
 	if a == nil && b == nil {
 		return true
@@ -692,5 +876,10 @@ func (r *Request) SetDefaultValues() {
 
 	if r.MetaData.Type == RequestTypeGraphQL {
 		r.SetDefaultValuesForGraphQL()
+		return
+	}
+
+	if r.MetaData.Type == RequestTypeWebSocket {
+		r.SetDefaultValuesForWebSocket()
 	}
 }
@@ -40,6 +40,9 @@ type HTTPRequest struct {
 
 	PreRequest  PreRequest  `yaml:"preRequest"`
 	PostRequest PostRequest `yaml:"postRequest"`
+
+	// Proxy overrides the environment and global proxy settings, nil means inherit.
+	Proxy *ProxyConfig `yaml:"proxy,omitempty"`
 }
 
 type Body struct {
@@ -132,6 +135,8 @@ func (r *HTTPRequest) Clone() *HTTPRequest {
 		clone.Auth = r.Auth.Clone()
 	}
 
+	clone.Proxy = r.Proxy.Clone()
+
 	return &clone
 }
 
@@ -143,6 +148,9 @@ func (r *RequestSpec) Clone() *RequestSpec {
 	if r.HTTP != nil {
 		clone.HTTP = r.HTTP.Clone()
 	}
+	if r.WebSocket != nil {
+		clone.WebSocket = r.WebSocket.Clone()
+	}
 	return &clone
 }
 
@@ -248,6 +256,10 @@ func CompareHTTPRequests(a, b *HTTPRequest) bool {
 		return false
 	}
 
+	if !CompareProxyConfig(a.Proxy, b.Proxy) {
+		return false
+	}
+
 	return true
 }
 
@@ -469,6 +481,8 @@ type HTTPResponseDetail struct {
 	StatusCode      int
 	Duration        time.Duration
 	Size            int
+	Timing          Timing
+	Tests           []TestResult
 
 	Error error
 }
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSEEvent is a single event of a text/event-stream response.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	// Retry is the reconnection time the server asked for, zero if it wasn't set.
+	Retry time.Duration
+
+	ReceivedAt time.Time
+}
+
+// String returns the event in the text/event-stream format.
+func (e SSEEvent) String() string {
+	var sb strings.Builder
+	if e.ID != "" {
+		sb.WriteString("id: " + e.ID + "\n")
+	}
+
+	if e.Event != "" {
+		sb.WriteString("event: " + e.Event + "\n")
+	}
+
+	if e.Retry > 0 {
+		sb.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	for _, line := range strings.Split(e.Data, "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+
+	return sb.String()
+}
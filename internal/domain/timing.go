@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+const (
+	TimingPhaseDNSLookup       = "DNS Lookup"
+	TimingPhaseTCPConnect      = "TCP Connect"
+	TimingPhaseTLSHandshake    = "TLS Handshake"
+	TimingPhaseWaiting         = "Waiting (TTFB)"
+	TimingPhaseContentTransfer = "Content Transfer"
+)
+
+// Timing is the breakdown of where the time of an http request went.
+type Timing struct {
+	// Phases are in the order they happened, phases which didn't happen
+	// such as dns lookup on a reused connection are left out.
+	Phases []TimingPhase
+
+	// TimeToFirstByte is the time from the start of the request until the first byte of the response arrived.
+	TimeToFirstByte time.Duration
+	Total           time.Duration
+
+	// ConnectionReused reports whether the request was sent on a connection from a previous request.
+	ConnectionReused bool
+}
+
+// TimingPhase is a single phase of the request, Start is relative to the start of the request.
+type TimingPhase struct {
+	Name     string
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// Duration returns the duration of the given phase, or zero if it didn't happen.
+func (t Timing) Duration(phase string) time.Duration {
+	for _, p := range t.Phases {
+		if p.Name == phase {
+			return p.Duration
+		}
+	}
+	return 0
+}
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebSocketMessageTypeText   = "text"
+	WebSocketMessageTypeBinary = "binary"
+
+	WebSocketFrameText   = "text"
+	WebSocketFrameBinary = "binary"
+	WebSocketFramePing   = "ping"
+	WebSocketFramePong   = "pong"
+	WebSocketFrameClose  = "close"
+
+	WebSocketDirectionSent     = "sent"
+	WebSocketDirectionReceived = "received"
+)
+
+type WebSocketRequestSpec struct {
+	URL          string     `yaml:"url"`
+	Headers      []KeyValue `yaml:"headers"`
+	Subprotocols []string   `yaml:"subprotocols,omitempty"`
+	Auth         Auth       `yaml:"auth"`
+
+	// Messages are the saved message templates, they can contain variables which are applied when sent.
+	Messages []WebSocketMessage `yaml:"messages,omitempty"`
+
+	LastUsedEnvironment LastUsedEnvironment `yaml:"lastUsedEnvironment"`
+
+	// Proxy overrides the environment and global proxy settings, nil means inherit.
+	Proxy *ProxyConfig `yaml:"proxy,omitempty"`
+}
+
+// WebSocketMessage is a message template, binary messages hold their data base64 encoded.
+type WebSocketMessage struct {
+	ID   string `yaml:"id"`
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	Data string `yaml:"data"`
+}
+
+// WebSocketFrame is a frame sent or received on an open connection.
+type WebSocketFrame struct {
+	Direction string
+	Type      string
+	Data      []byte
+	Time      time.Time
+}
+
+// String returns the frame data as text, binary data which is not valid utf-8 is shown as hex.
+func (f WebSocketFrame) String() string {
+	if f.Type == WebSocketFrameBinary && !utf8.Valid(f.Data) {
+		return fmt.Sprintf("% x", f.Data)
+	}
+	return string(f.Data)
+}
+
+func (w *WebSocketRequestSpec) Clone() *WebSocketRequestSpec {
+	clone := *w
+
+	if len(w.Headers) > 0 {
+		clone.Headers = make([]KeyValue, len(w.Headers))
+		copy(clone.Headers, w.Headers)
+	}
+
+	if len(w.Subprotocols) > 0 {
+		clone.Subprotocols = make([]string, len(w.Subprotocols))
+		copy(clone.Subprotocols, w.Subprotocols)
+	}
+
+	if len(w.Messages) > 0 {
+		clone.Messages = make([]WebSocketMessage, len(w.Messages))
+		copy(clone.Messages, w.Messages)
+	}
+
+	if w.Auth != (Auth{}) {
+		clone.Auth = w.Auth.Clone()
+	}
+
+	clone.Proxy = w.Proxy.Clone()
+
+	return &clone
+}
+
+func (r *RequestSpec) GetWebSocket() *WebSocketRequestSpec {
+	if r.WebSocket != nil {
+		return r.WebSocket
+	}
+	return nil
+}
+
+// ParseSubprotocols splits a comma separated list of subprotocols.
+func ParseSubprotocols(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func NewWebSocketRequest(name string) *Request {
+	return &Request{
+		ApiVersion: ApiVersion,
+		Kind:       KindRequest,
+		MetaData: RequestMeta{
+			ID:   uuid.NewString(),
+			Name: name,
+			Type: RequestTypeWebSocket,
+		},
+		Spec: RequestSpec{
+			WebSocket: &WebSocketRequestSpec{
+				URL: "wss://echo.websocket.org",
+				Auth: Auth{
+					Type: "None",
+				},
+			},
+		},
+	}
+}
+
+func CompareWebSocketMessages(a, b []WebSocketMessage) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func CompareWebSocketRequestSpecs(a, b *WebSocketRequestSpec) bool {
+	if a == nil && b == nil {
+		return true
+	}
+
+	if a == nil || b == nil {
+		return false
+	}
+
+	if a.URL != b.URL || strings.Join(a.Subprotocols, ",") != strings.Join(b.Subprotocols, ",") {
+		return false
+	}
+
+	if !CompareKeyValues(a.Headers, b.Headers) {
+		return false
+	}
+
+	if !CompareAuth(a.Auth, b.Auth) {
+		return false
+	}
+
+	if !CompareWebSocketMessages(a.Messages, b.Messages) {
+		return false
+	}
+
+	if !CompareProxyConfig(a.Proxy, b.Proxy) {
+		return false
+	}
+
+	return true
+}
+
+func (r *Request) SetDefaultValuesForWebSocket() {
+	if r.Spec.WebSocket == nil {
+		r.Spec.WebSocket = &WebSocketRequestSpec{}
+	}
+
+	if r.Spec.WebSocket.URL == "" {
+		r.Spec.WebSocket.URL = "wss://echo.websocket.org"
+	}
+
+	if r.Spec.WebSocket.Auth == (Auth{}) {
+		r.Spec.WebSocket.Auth = Auth{
+			Type: "None",
+		}
+	}
+
+	for i, m := range r.Spec.WebSocket.Messages {
+		if m.ID == "" {
+			r.Spec.WebSocket.Messages[i].ID = uuid.NewString()
+		}
+
+		if m.Type == "" {
+			r.Spec.WebSocket.Messages[i].Type = WebSocketMessageTypeText
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"path"
+	"strings"
+
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v2"
 )
@@ -8,9 +11,45 @@ import (
 const DefaultWorkspaceName = "Default Workspace"
 
 type Workspace struct {
-	ApiVersion string   `yaml:"apiVersion"`
-	Kind       string   `yaml:"kind"`
-	MetaData   MetaData `yaml:"metadata"`
+	ApiVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	MetaData   MetaData      `yaml:"metadata"`
+	Spec       WorkspaceSpec `yaml:"spec,omitempty"`
+}
+
+type WorkspaceSpec struct {
+	Certificates []HostCertificate `yaml:"certificates,omitempty"`
+}
+
+// HostCertificate holds the tls files used when talking to the hosts matching Host.
+// Host is a glob pattern such as *.internal.example.com, it can also include a port e.g. api.example.com:8443.
+type HostCertificate struct {
+	Host           string `yaml:"host"`
+	CACertFile     string `yaml:"caCertFile,omitempty"`
+	ClientCertFile string `yaml:"clientCertFile,omitempty"`
+	ClientKeyFile  string `yaml:"clientKeyFile,omitempty"`
+}
+
+// FindCertificate returns the first certificate whose host pattern matches the given host and port.
+func (s *WorkspaceSpec) FindCertificate(hostname, port string) *HostCertificate {
+	hostname = strings.ToLower(hostname)
+	for i := range s.Certificates {
+		pattern := strings.ToLower(strings.TrimSpace(s.Certificates[i].Host))
+		if pattern == "" {
+			continue
+		}
+
+		target := hostname
+		if strings.Contains(pattern, ":") {
+			target = hostname + ":" + port
+		}
+
+		if ok, _ := path.Match(pattern, target); ok {
+			return &s.Certificates[i]
+		}
+	}
+
+	return nil
 }
 
 func (w *Workspace) ID() string {
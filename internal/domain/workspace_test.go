@@ -0,0 +1,38 @@
+package domain
+
+import "testing"
+
+func TestWorkspaceSpec_FindCertificate(t *testing.T) {
+	spec := WorkspaceSpec{
+		Certificates: []HostCertificate{
+			{Host: "api.example.com:8443", ClientCertFile: "port.pem"},
+			{Host: "*.internal.example.com", ClientCertFile: "wildcard.pem"},
+			{Host: "API.example.com", ClientCertFile: "exact.pem"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		hostname string
+		port     string
+		want     string
+	}{
+		{name: "Port specific", hostname: "api.example.com", port: "8443", want: "port.pem"},
+		{name: "Case insensitive", hostname: "api.example.com", port: "443", want: "exact.pem"},
+		{name: "Wildcard", hostname: "svc.internal.example.com", port: "443", want: "wildcard.pem"},
+		{name: "No match", hostname: "example.org", port: "443", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if c := spec.FindCertificate(tt.hostname, tt.port); c != nil {
+				got = c.ClientCertFile
+			}
+
+			if got != tt.want {
+				t.Errorf("FindCertificate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
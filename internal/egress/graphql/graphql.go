@@ -2,19 +2,27 @@ package graphql
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/http2"
 
+	"github.com/chapar-rest/chapar/internal/digest"
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/gql"
+	"github.com/chapar-rest/chapar/internal/logger"
+	"github.com/chapar-rest/chapar/internal/oauth2"
 	"github.com/chapar-rest/chapar/internal/prefs"
+	"github.com/chapar-rest/chapar/internal/proxy"
+	"github.com/chapar-rest/chapar/internal/sigv4"
 	"github.com/chapar-rest/chapar/internal/state"
 	"github.com/chapar-rest/chapar/internal/util"
 	"github.com/chapar-rest/chapar/internal/variables"
@@ -24,16 +32,78 @@ import (
 type Service struct {
 	requests     *state.Requests
 	environments *state.Environments
+	workspaces   *state.Workspaces
+
+	oauth2 *oauth2.Service
+
+	schemasMx sync.Mutex
+	// schemas are the loaded schemas by their source, see schemaKey
+	schemas map[string]*gql.Schema
 }
 
-func New(requests *state.Requests, environments *state.Environments) *Service {
+func New(requests *state.Requests, environments *state.Environments, workspaces *state.Workspaces, oauth2Service *oauth2.Service) *Service {
 	return &Service{
 		requests:     requests,
 		environments: environments,
+		workspaces:   workspaces,
+		oauth2:       oauth2Service,
+		schemas:      make(map[string]*gql.Schema),
+	}
+}
+
+func (s *Service) SendRequest(ctx context.Context, requestID, activeEnvironmentID string) (*egress.Response, error) {
+	spec, activeEnvironment, err := s.resolveRequest(ctx, requestID, activeEnvironmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.sendRequest(ctx, spec, activeEnvironment)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// resolveRequest returns the request as a pre request script in ctx left it, or a clone of the request with the
+// headers, auth and schema of its collection merged in, along with the active environment.
+func (s *Service) resolveRequest(ctx context.Context, requestID, activeEnvironmentID string) (*domain.GraphQLRequestSpec, *domain.Environment, error) {
+	// the request of a pre request script is merged with its collection already
+	r := egress.ScriptRequest(ctx, requestID)
+	if r == nil {
+		var err error
+		if r, err = s.mergedRequest(requestID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	activeEnvironment, err := s.environment(activeEnvironmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r.Spec.GraphQL, activeEnvironment, nil
+}
+
+// ResolveRequest returns a clone of the request with the headers, auth and schema of its collection merged in and
+// the variables and the values of the active environment applied.
+func (s *Service) ResolveRequest(requestID, activeEnvironmentID string) (*domain.Request, error) {
+	r, err := s.mergedRequest(requestID)
+	if err != nil {
+		return nil, err
 	}
+
+	activeEnvironment, err := s.environment(activeEnvironmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyVariables(r.Spec.GraphQL, activeEnvironment)
+	return r, nil
 }
 
-func (s *Service) SendRequest(requestID, activeEnvironmentID string) (*egress.Response, error) {
+// mergedRequest returns a clone of the request with the headers, auth and schema of its collection merged in.
+func (s *Service) mergedRequest(requestID string) (*domain.Request, error) {
 	req := s.requests.GetRequest(requestID)
 	if req == nil {
 		return nil, fmt.Errorf("request with id %s not found", requestID)
@@ -41,9 +111,12 @@ func (s *Service) SendRequest(requestID, activeEnvironmentID string) (*egress.Re
 
 	// clone the request to make sure we do not modify the original request
 	r := req.Clone()
+	if r.Spec.GraphQL == nil {
+		return nil, fmt.Errorf("request with id %s is not a graphql request", requestID)
+	}
 
 	// Merge collection headers and auth if request belongs to a collection
-	if r.CollectionID != "" && r.Spec.GraphQL != nil {
+	if r.CollectionID != "" {
 		collection := s.requests.GetCollection(r.CollectionID)
 		if collection != nil {
 			// Merge headers: collection headers as base, request headers override
@@ -53,33 +126,125 @@ func (s *Service) SendRequest(requestID, activeEnvironmentID string) (*egress.Re
 			if r.Spec.GraphQL.Auth.Type == domain.AuthTypeInherit {
 				r.Spec.GraphQL.Auth = collection.Spec.Auth
 			}
+
+			if r.Spec.GraphQL.SchemaFile == "" {
+				r.Spec.GraphQL.SchemaFile = collection.Spec.GraphQLSchemaFile
+			}
 		}
 	}
 
-	var activeEnvironment *domain.Environment
-	// Get environment if provided
-	if activeEnvironmentID != "" {
-		activeEnvironment = s.environments.GetEnvironment(activeEnvironmentID)
-		if activeEnvironment == nil {
-			return nil, fmt.Errorf("environment with id %s not found", activeEnvironmentID)
+	return r, nil
+}
+
+// environment returns the environment of id, nil when id is empty.
+func (s *Service) environment(id string) (*domain.Environment, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	env := s.environments.GetEnvironment(id)
+	if env == nil {
+		return nil, fmt.Errorf("environment with id %s not found", id)
+	}
+	return env, nil
+}
+
+func (s *Service) sendRequest(ctx context.Context, req *domain.GraphQLRequestSpec, e *domain.Environment) (*egress.Response, error) {
+	applyVariables(req, e)
+
+	payload, err := requestPayload(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if isSubscription(req.Query, req.OperationName) {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
+		return s.subscribe(ctx, req, e, body)
+	}
+
+	if !req.PersistedQueries {
+		return s.post(ctx, req, e, payload)
+	}
+
+	// the hash is sent alone first, the query is added only when the server doesn't know the hash
+	payload["extensions"] = persistedQueryExtensions(req.Query)
+	delete(payload, "query")
+	res, err := s.post(ctx, req, e, payload)
+	if err != nil || !isPersistedQueryNotFound(res.Body) {
+		return res, err
+	}
+
+	payload["query"] = req.Query
+	return s.post(ctx, req, e, payload)
+}
+
+// post sends the payload and reads the response.
+func (s *Service) post(ctx context.Context, req *domain.GraphQLRequestSpec, e *domain.Environment, payload map[string]any) (*egress.Response, error) {
+	body, contentType, err := encodePayload(payload, req.Uploads)
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := s.sendRequest(r.Spec.GraphQL, activeEnvironment)
+	client, httpReq, err := s.newHTTPRequest(ctx, req, e, body, contentType)
 	if err != nil {
 		return nil, err
 	}
 
+	start := time.Now()
+	trace := egress.NewTimingTrace(start)
+	res, err := client.Do(httpReq.WithContext(trace.WithContext(httpReq.Context())))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	// read body
+	body, err = io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// measure time
+	end := time.Now()
+	elapsed := end.Sub(start)
+
+	// handle response
+	response := &egress.Response{
+		StatusCode:      res.StatusCode,
+		ResponseHeaders: map[string]string{},
+		RequestHeaders:  map[string]string{},
+		Body:            body,
+		TimePassed:      elapsed,
+		Timing:          trace.Timing(end),
+		IsJSON:          false,
+	}
+
+	if util.IsJSON(string(body)) {
+		response.IsJSON = true
+		if js, err := util.PrettyJSON(body); err != nil {
+			return nil, err
+		} else {
+			response.JSON = js
+		}
+	}
+
+	// handle headers
+	for k, v := range res.Header {
+		response.ResponseHeaders[k] = strings.Join(v, ", ")
+	}
+
+	for k, v := range httpReq.Header {
+		response.RequestHeaders[k] = strings.Join(v, ", ")
+	}
+
 	return response, nil
 }
 
-// nolint: gocyclo
-func (s *Service) sendRequest(req *domain.GraphQLRequestSpec, e *domain.Environment) (*egress.Response, error) {
-	// prepare request
-	// - apply environment
-	// - apply variables
-	// - apply authentication (if any) is not already applied to the headers
-
+// applyVariables applies the global variables and the environment to the request.
+func applyVariables(req *domain.GraphQLRequestSpec, e *domain.Environment) {
 	vars := variables.GetVariables()
 	variables.ApplyToGraphQLRequest(vars, req)
 
@@ -87,33 +252,82 @@ func (s *Service) sendRequest(req *domain.GraphQLRequestSpec, e *domain.Environm
 		variables.ApplyToEnv(vars, &e.Spec)
 		e.ApplyToGraphQLRequest(req)
 	}
+}
 
-	// Prepare GraphQL request body
-	requestBody := map[string]interface{}{
+// requestPayload returns the payload of the request, it's sent as the json body or the operations of a
+// multipart request and it's the payload of subscriptions as well.
+func requestPayload(req *domain.GraphQLRequestSpec) (map[string]any, error) {
+	payload := map[string]any{
 		"query": req.Query,
 	}
 
+	if req.OperationName != "" {
+		payload["operationName"] = req.OperationName
+	}
+
 	// Parse variables JSON string to map
 	if req.Variables != "" && req.Variables != "{}" {
-		var variablesMap map[string]interface{}
+		var variablesMap map[string]any
 		if err := json.Unmarshal([]byte(req.Variables), &variablesMap); err != nil {
 			return nil, fmt.Errorf("invalid variables JSON: %w", err)
 		}
-		requestBody["variables"] = variablesMap
+		payload["variables"] = variablesMap
 	}
 
-	bodyBytes, err := json.Marshal(requestBody)
+	return payload, nil
+}
+
+// isSubscription reports whether the selected operation of the query is a subscription, queries which
+// don't parse are sent as is so the server reports the error.
+func isSubscription(query, operationName string) bool {
+	doc, err := gql.ParseQuery(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		return false
 	}
 
-	httpReq, err := http.NewRequest("POST", req.URL, bytes.NewReader(bodyBytes))
+	op := doc.Operation(operationName)
+	return op != nil && op.Type == "subscription"
+}
+
+// applyAuth sets the headers of the auth types which don't depend on the request, digest and aws
+// signature are handled by newHTTPRequest.
+func (s *Service) applyAuth(ctx context.Context, header http.Header, auth domain.Auth, e *domain.Environment, requestProxy *domain.ProxyConfig) error {
+	switch auth.Type {
+	case domain.AuthTypeToken:
+		if auth.TokenAuth != nil && auth.TokenAuth.Token != "" {
+			header.Add("Authorization", "Bearer "+auth.TokenAuth.Token)
+		}
+	case domain.AuthTypeBasic:
+		if auth.BasicAuth != nil && auth.BasicAuth.Username != "" && auth.BasicAuth.Password != "" {
+			credentials := base64.StdEncoding.EncodeToString([]byte(auth.BasicAuth.Username + ":" + auth.BasicAuth.Password))
+			header.Set("Authorization", "Basic "+credentials)
+		}
+	case domain.AuthTypeAPIKey:
+		if auth.APIKeyAuth != nil && auth.APIKeyAuth.Key != "" && auth.APIKeyAuth.Value != "" {
+			header.Add(auth.APIKeyAuth.Key, auth.APIKeyAuth.Value)
+		}
+	case domain.AuthTypeOAuth2:
+		if auth.OAuth2Auth != nil {
+			token, err := s.oauth2.Token(ctx, e, auth.OAuth2Auth, requestProxy)
+			if err != nil {
+				return fmt.Errorf("failed to get oauth2 token: %w", err)
+			}
+			header.Set("Authorization", token.AuthorizationHeader())
+		}
+	}
+
+	return nil
+}
+
+// newHTTPRequest returns the POST request of the given body with the headers and auth of the request,
+// along with the client to send it with.
+func (s *Service) newHTTPRequest(ctx context.Context, req *domain.GraphQLRequestSpec, e *domain.Environment, body []byte, contentType string) (*http.Client, *http.Request, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", req.URL, bytes.NewReader(body))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Set Content-Type header for GraphQL
-	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Content-Type", contentType)
 
 	// apply headers
 	for _, h := range req.Headers {
@@ -124,36 +338,30 @@ func (s *Service) sendRequest(req *domain.GraphQLRequestSpec, e *domain.Environm
 	}
 
 	// apply authentication
-	if req.Auth != (domain.Auth{}) {
-		if req.Auth.Type == domain.AuthTypeToken {
-			if req.Auth.TokenAuth != nil && req.Auth.TokenAuth.Token != "" {
-				httpReq.Header.Add("Authorization", "Bearer "+req.Auth.TokenAuth.Token)
-			}
-		}
-
-		if req.Auth.Type == domain.AuthTypeBasic {
-			if req.Auth.BasicAuth != nil && req.Auth.BasicAuth.Username != "" && req.Auth.BasicAuth.Password != "" {
-				httpReq.SetBasicAuth(req.Auth.BasicAuth.Username, req.Auth.BasicAuth.Password)
-			}
-		}
-
-		if req.Auth.Type == domain.AuthTypeAPIKey {
-			if req.Auth.APIKeyAuth != nil && req.Auth.APIKeyAuth.Key != "" && req.Auth.APIKeyAuth.Value != "" {
-				httpReq.Header.Add(req.Auth.APIKeyAuth.Key, req.Auth.APIKeyAuth.Value)
-			}
-		}
+	if err := s.applyAuth(ctx, httpReq.Header, req.Auth, e, req.Proxy); err != nil {
+		return nil, nil, err
 	}
 
 	// send request
 	globalConfig := prefs.GetGlobalConfig()
 
-	start := time.Now()
+	tlsConfig, err := egress.TLSConfig(s.workspaces.GetActiveWorkspace(), httpReq.URL, globalConfig.Spec.General.VaidateTLSCertificates)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proxyDialer, err := proxy.NewDialer(domain.ResolveProxy(globalConfig.Spec.General.Proxy, e, req.Proxy))
+	if err != nil {
+		return nil, nil, err
+	}
 
 	client := &http.Client{
 		Timeout: time.Duration(globalConfig.Spec.General.RequestTimeoutSec) * time.Second,
 		Transport: &http.Transport{
 			MaxIdleConns:           10,
 			MaxResponseHeaderBytes: int64(globalConfig.Spec.General.ResponseSizeMb * 1024 * 1024),
+			TLSClientConfig:        tlsConfig,
+			Proxy:                  proxyDialer.ProxyFunc(),
 		},
 	}
 	if !globalConfig.Spec.General.FollowRedirects {
@@ -161,16 +369,24 @@ func (s *Service) sendRequest(req *domain.GraphQLRequestSpec, e *domain.Environm
 			return http.ErrUseLastResponse
 		}
 	}
-	if !globalConfig.Spec.General.VaidateTLSCertificates {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
-
 	if globalConfig.Spec.General.HTTPVersion == "http/2" {
 		client.Transport = &http2.Transport{
 			AllowHTTP:        true,
 			MaxReadFrameSize: uint32(globalConfig.Spec.General.ResponseSizeMb * 1024 * 1024),
+			TLSClientConfig:  tlsConfig,
+			DialTLSContext:   proxyDialer.DialTLSContext,
+		}
+	}
+
+	// digest auth needs the challenge from the server, so it's answered by the transport
+	if req.Auth.Type == domain.AuthTypeDigest && req.Auth.DigestAuth != nil {
+		client.Transport = &digest.Transport{
+			Username: req.Auth.DigestAuth.Username,
+			Password: req.Auth.DigestAuth.Password,
+			Base:     client.Transport,
+			OnChallenge: func(r *http.Request, c *digest.Challenge) {
+				logger.Info(fmt.Sprintf("%s %s: got digest challenge (realm=%q, algorithm=%s, qop=%s), retrying with credentials", r.Method, r.URL, c.Realm, c.Algorithm, strings.Join(c.Qop, ",")))
+			},
 		}
 	}
 
@@ -182,48 +398,12 @@ func (s *Service) sendRequest(req *domain.GraphQLRequestSpec, e *domain.Environm
 		httpReq.Header.Add("User-Agent", version.GetAgentName())
 	}
 
-	res, err := client.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = res.Body.Close() }()
-
-	// read body
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	// measure time
-	elapsed := time.Since(start)
-
-	// handle response
-	response := &egress.Response{
-		StatusCode:      res.StatusCode,
-		ResponseHeaders: map[string]string{},
-		RequestHeaders:  map[string]string{},
-		Body:            body,
-		TimePassed:      elapsed,
-		IsJSON:          false,
-	}
-
-	if util.IsJSON(string(body)) {
-		response.IsJSON = true
-		if js, err := util.PrettyJSON(body); err != nil {
-			return nil, err
-		} else {
-			response.JSON = js
+	// aws signature covers the final headers and body, so it has to be the last thing before sending
+	if req.Auth.Type == domain.AuthTypeAWSSigV4 && req.Auth.AWSSigV4Auth != nil {
+		if err := sigv4.Sign(httpReq, req.Auth.AWSSigV4Auth, time.Now()); err != nil {
+			return nil, nil, fmt.Errorf("failed to sign request: %w", err)
 		}
 	}
 
-	// handle headers
-	for k, v := range res.Header {
-		response.ResponseHeaders[k] = strings.Join(v, ", ")
-	}
-
-	for k, v := range httpReq.Header {
-		response.RequestHeaders[k] = strings.Join(v, ", ")
-	}
-
-	return response, nil
+	return client, httpReq, nil
 }
@@ -0,0 +1,45 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// persistedQueryExtensions returns the extensions which tell the server the hash of an automatic persisted query.
+func persistedQueryExtensions(query string) map[string]any {
+	hash := sha256.Sum256([]byte(query))
+	return map[string]any{
+		"persistedQuery": map[string]any{
+			"version":    1,
+			"sha256Hash": hex.EncodeToString(hash[:]),
+		},
+	}
+}
+
+// isPersistedQueryNotFound reports whether the server answered the hash of a persisted query with
+// PersistedQueryNotFound, servers without support for persisted queries answer PersistedQueryNotSupported
+// and get the full query as well.
+func isPersistedQueryNotFound(body []byte) bool {
+	var res struct {
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(body, &res); err != nil {
+		return false
+	}
+
+	for _, e := range res.Errors {
+		switch {
+		case e.Message == "PersistedQueryNotFound", e.Extensions.Code == "PERSISTED_QUERY_NOT_FOUND",
+			e.Message == "PersistedQueryNotSupported", e.Extensions.Code == "PERSISTED_QUERY_NOT_SUPPORTED":
+			return true
+		}
+	}
+	return false
+}
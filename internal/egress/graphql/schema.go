@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/gql"
+)
+
+// schemaKey identifies the source of the schema of a request, requests with the same schema file or the
+// same server share the schema.
+func schemaKey(spec *domain.GraphQLRequestSpec, activeEnvironmentID string) string {
+	if spec.SchemaFile != "" {
+		return "file:" + spec.SchemaFile
+	}
+	return "url:" + activeEnvironmentID + ":" + spec.URL
+}
+
+// GetSchema returns the schema of the request, it's read from the schema file of the request or its
+// collection or asked from the server with an introspection query. the schema is cached until refresh is set.
+func (s *Service) GetSchema(ctx context.Context, requestID, activeEnvironmentID string, refresh bool) (*gql.Schema, error) {
+	spec, env, err := s.resolveRequest(ctx, requestID, activeEnvironmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := schemaKey(spec, activeEnvironmentID)
+	if !refresh {
+		if schema := s.cachedSchema(key); schema != nil {
+			return schema, nil
+		}
+	}
+
+	var schema *gql.Schema
+	if spec.SchemaFile != "" {
+		schema, err = loadSchemaFile(spec.SchemaFile)
+	} else {
+		schema, err = s.introspect(ctx, spec, env)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.schemasMx.Lock()
+	s.schemas[key] = schema
+	s.schemasMx.Unlock()
+	return schema, nil
+}
+
+// CachedSchema returns the schema of the request without asking the server, the schema files are read
+// when they're not cached yet. it returns nil when the schema has to be introspected.
+func (s *Service) CachedSchema(requestID, activeEnvironmentID string) (*gql.Schema, error) {
+	spec, _, err := s.resolveRequest(context.Background(), requestID, activeEnvironmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema := s.cachedSchema(schemaKey(spec, activeEnvironmentID)); schema != nil || spec.SchemaFile == "" {
+		return schema, nil
+	}
+
+	return s.GetSchema(context.Background(), requestID, activeEnvironmentID, false)
+}
+
+func (s *Service) cachedSchema(key string) *gql.Schema {
+	s.schemasMx.Lock()
+	defer s.schemasMx.Unlock()
+	return s.schemas[key]
+}
+
+func loadSchemaFile(path string) (*gql.Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the schema file: %w", err)
+	}
+
+	schema, err := gql.ParseSchemaFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema file: %w", err)
+	}
+	return schema, nil
+}
+
+// introspect sends the introspection query with the headers and auth of the request.
+func (s *Service) introspect(ctx context.Context, spec *domain.GraphQLRequestSpec, env *domain.Environment) (*gql.Schema, error) {
+	spec.Query = gql.IntrospectionQuery
+	spec.Variables = ""
+
+	response, err := s.sendRequest(ctx, spec, env)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("introspection query failed with status code %d", response.StatusCode)
+	}
+
+	return gql.ParseIntrospection(response.Body)
+}
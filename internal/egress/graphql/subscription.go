@@ -0,0 +1,457 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/prefs"
+	"github.com/chapar-rest/chapar/internal/proxy"
+	"github.com/chapar-rest/chapar/internal/sse"
+	"github.com/chapar-rest/chapar/internal/util"
+	"github.com/chapar-rest/chapar/internal/websocket"
+	"github.com/chapar-rest/chapar/version"
+)
+
+const (
+	// protocolTransportWS is the graphql-transport-ws protocol, see
+	// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+	protocolTransportWS = "graphql-transport-ws"
+	// protocolLegacyWS is the subprotocol of the legacy subscriptions-transport-ws protocol, see
+	// https://github.com/apollographql/subscriptions-transport-ws/blob/master/PROTOCOL.md
+	protocolLegacyWS = "graphql-ws"
+
+	// subscriptionID is the id of the operation, each subscription has its own connection
+	subscriptionID = "1"
+
+	// closeTimeout is how long we wait for the server to answer our close frame before dropping the connection.
+	closeTimeout = 5 * time.Second
+)
+
+// wsMessage is a message of both websocket protocols.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Subscription is a running subscription, the payloads sent by the server are delivered on Messages until
+// the server completes it, it fails or it's closed.
+type Subscription struct {
+	messages chan domain.GraphQLSubscriptionMessage
+	done     chan struct{}
+	// stop tells the server the subscription is over and closes the connection
+	stop func()
+
+	mx        sync.Mutex
+	err       error
+	closeOnce sync.Once
+}
+
+func newSubscription(stop func()) *Subscription {
+	return &Subscription{
+		messages: make(chan domain.GraphQLSubscriptionMessage, 64),
+		done:     make(chan struct{}),
+		stop:     stop,
+	}
+}
+
+// Messages returns the payloads sent by the server, it's closed once the subscription ends.
+func (s *Subscription) Messages() <-chan domain.GraphQLSubscriptionMessage {
+	return s.messages
+}
+
+// Err returns the reason the subscription ended, it should be called after Messages is closed.
+func (s *Subscription) Err() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.err
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.stop()
+	})
+}
+
+// deliver passes the payload to the reader, it returns false once the subscription is closed.
+func (s *Subscription) deliver(payload []byte) bool {
+	msg := domain.GraphQLSubscriptionMessage{
+		Payload: string(payload),
+		Size:    len(payload),
+		Time:    time.Now(),
+	}
+	if js, err := util.PrettyJSON(payload); err == nil {
+		msg.Payload = js
+	}
+
+	select {
+	case s.messages <- msg:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// end closes Messages, err is dropped when the subscription was closed by Close.
+func (s *Subscription) end(err error) {
+	select {
+	case <-s.done:
+		err = nil
+	default:
+	}
+
+	s.mx.Lock()
+	s.err = err
+	s.mx.Unlock()
+	close(s.messages)
+}
+
+// subscribe starts the subscription of the request over its transport, the response carries the subscription.
+func (s *Service) subscribe(ctx context.Context, req *domain.GraphQLRequestSpec, e *domain.Environment, body []byte) (*egress.Response, error) {
+	if req.SubscriptionTransport == domain.GraphQLSubscriptionTransportSSE {
+		return s.subscribeSSE(ctx, req, e, body)
+	}
+	return s.subscribeWebSocket(ctx, req, e, body)
+}
+
+// subscribeSSE posts the operation and reads the payloads from the text/event-stream response, the
+// distinct connections mode of https://github.com/enisdenjo/graphql-sse/blob/master/PROTOCOL.md
+func (s *Service) subscribeSSE(ctx context.Context, req *domain.GraphQLRequestSpec, e *domain.Environment, body []byte) (*egress.Response, error) {
+	req.Headers = append(req.Headers, domain.KeyValue{Key: "Accept", Value: sse.ContentType, Enable: true})
+
+	client, httpReq, err := s.newHTTPRequest(ctx, req, e, body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	// the stream stays open as long as the subscription, it's stopped by the request context
+	client.Timeout = 0
+
+	start := time.Now()
+	res, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &egress.Response{
+		StatusCode:      res.StatusCode,
+		ResponseHeaders: map[string]string{},
+		RequestHeaders:  map[string]string{},
+		TimePassed:      time.Since(start),
+	}
+
+	for k, v := range res.Header {
+		response.ResponseHeaders[k] = strings.Join(v, ", ")
+	}
+
+	for k, v := range httpReq.Header {
+		response.RequestHeaders[k] = strings.Join(v, ", ")
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode <= 299 && sse.IsEventStream(res.Header.Get("Content-Type")) {
+		response.SubscriptionStream = readEvents(res.Body)
+		return response, nil
+	}
+
+	// the server answered with a regular response, e.g. the errors of the operation
+	defer func() { _ = res.Body.Close() }()
+	response.Body, err = io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if util.IsJSON(string(response.Body)) {
+		response.IsJSON = true
+		if response.JSON, err = util.PrettyJSON(response.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// readEvents delivers the data of the next events of the stream until the complete event.
+func readEvents(body io.ReadCloser) *Subscription {
+	stream := sse.NewStream(body)
+	sub := newSubscription(func() { _ = stream.Close() })
+
+	go func() {
+		err := func() error {
+			for event := range stream.Events() {
+				switch event.Event {
+				case "next":
+					if !sub.deliver([]byte(event.Data)) {
+						return nil
+					}
+				case "complete":
+					return nil
+				}
+			}
+			return stream.Err()
+		}()
+
+		_ = stream.Close()
+		sub.end(err)
+	}()
+
+	return sub
+}
+
+// subscribeWebSocket runs the subscription over graphql-transport-ws, the legacy subscriptions-transport-ws
+// is used when the server picks its subprotocol.
+func (s *Service) subscribeWebSocket(ctx context.Context, req *domain.GraphQLRequestSpec, e *domain.Environment, body []byte) (*egress.Response, error) {
+	if req.Auth.Type == domain.AuthTypeAWSSigV4 || req.Auth.Type == domain.AuthTypeDigest {
+		return nil, fmt.Errorf("%s auth is not supported for subscriptions over websocket", req.Auth.Type)
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	// the tls config is looked up by the http url of the endpoint
+	httpURL := *u
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws":
+		httpURL.Scheme = "http"
+	case "wss":
+		httpURL.Scheme = "https"
+	}
+
+	header := make(http.Header)
+	for _, h := range req.Headers {
+		if !h.Enable {
+			continue
+		}
+		header.Add(h.Key, h.Value)
+	}
+
+	if err := s.applyAuth(ctx, header, req.Auth, e, req.Proxy); err != nil {
+		return nil, err
+	}
+
+	globalConfig := prefs.GetGlobalConfig()
+	if globalConfig.Spec.General.SendChaparAgentHeader {
+		header.Add("User-Agent", version.GetAgentName())
+	}
+
+	tlsConfig, err := egress.TLSConfig(s.workspaces.GetActiveWorkspace(), &httpURL, globalConfig.Spec.General.VaidateTLSCertificates)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyDialer, err := proxy.NewDialer(domain.ResolveProxy(globalConfig.Spec.General.Proxy, e, req.Proxy))
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &websocket.Dialer{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           proxyDialer.ProxyFunc(),
+		},
+		MaxMessageSize: int64(globalConfig.Spec.General.ResponseSizeMb * 1024 * 1024),
+	}
+
+	// the timeout covers the handshake and the connection init, not the subscription
+	handshakeCtx := ctx
+	if timeout := time.Duration(globalConfig.Spec.General.RequestTimeoutSec) * time.Second; timeout > 0 {
+		var cancel context.CancelFunc
+		handshakeCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	conn, res, err := dialer.Dial(handshakeCtx, u.String(), header, []string{protocolTransportWS, protocolLegacyWS})
+	if err != nil {
+		return nil, err
+	}
+
+	legacy := conn.Subprotocol() == protocolLegacyWS
+
+	// the connection is dropped if the server does not acknowledge it in time
+	stopWatch := context.AfterFunc(handshakeCtx, func() { _ = conn.CloseNow() })
+	err = initConnection(conn)
+	if !stopWatch() {
+		err = handshakeCtx.Err()
+	}
+	if err != nil {
+		_ = conn.CloseNow()
+		return nil, err
+	}
+
+	subscribe := wsMessage{ID: subscriptionID, Type: "subscribe", Payload: body}
+	if legacy {
+		subscribe.Type = "start"
+	}
+	if err := writeMessage(conn, subscribe); err != nil {
+		_ = conn.CloseNow()
+		return nil, err
+	}
+
+	sub := newSubscription(func() {
+		stop := wsMessage{ID: subscriptionID, Type: "complete"}
+		if legacy {
+			stop.Type = "stop"
+		}
+		_ = writeMessage(conn, stop)
+		if legacy {
+			_ = writeMessage(conn, wsMessage{Type: "connection_terminate"})
+		}
+
+		time.AfterFunc(closeTimeout, func() { _ = conn.CloseNow() })
+		if err := conn.Close(websocket.CloseNormal, ""); err != nil {
+			_ = conn.CloseNow()
+		}
+	})
+
+	go readMessages(conn, sub)
+
+	response := &egress.Response{
+		StatusCode:         res.StatusCode,
+		ResponseHeaders:    map[string]string{},
+		RequestHeaders:     map[string]string{},
+		TimePassed:         time.Since(start),
+		SubscriptionStream: sub,
+	}
+
+	for k, v := range res.Header {
+		response.ResponseHeaders[k] = strings.Join(v, ", ")
+	}
+
+	for k, v := range header {
+		response.RequestHeaders[k] = strings.Join(v, ", ")
+	}
+
+	return response, nil
+}
+
+// initConnection sends connection_init and waits for the server to acknowledge it.
+func initConnection(conn *websocket.Conn) error {
+	if err := writeMessage(conn, wsMessage{Type: "connection_init", Payload: json.RawMessage("{}")}); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := readMessage(conn)
+		if err != nil {
+			return err
+		}
+
+		switch msg.Type {
+		case "connection_ack":
+			return nil
+		case "connection_error":
+			return fmt.Errorf("connection rejected: %w", payloadError(msg.Payload))
+		case "ping":
+			if err := writeMessage(conn, wsMessage{Type: "pong", Payload: msg.Payload}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readMessages delivers the payloads of the subscription until it completes or the connection is closed.
+func readMessages(conn *websocket.Conn, sub *Subscription) {
+	err := func() error {
+		for {
+			msg, err := readMessage(conn)
+			if err != nil {
+				var closeErr *websocket.CloseError
+				if errors.As(err, &closeErr) && (closeErr.Code == websocket.CloseNormal || closeErr.Code == websocket.CloseNoStatus || closeErr.Code == websocket.CloseGoingAway) {
+					return nil
+				}
+				return err
+			}
+
+			switch msg.Type {
+			// data is the payload message of subscriptions-transport-ws
+			case "next", "data":
+				if msg.ID == subscriptionID && !sub.deliver(msg.Payload) {
+					return nil
+				}
+			case "error":
+				return payloadError(msg.Payload)
+			case "complete":
+				return nil
+			case "ping":
+				_ = writeMessage(conn, wsMessage{Type: "pong", Payload: msg.Payload})
+			}
+		}
+	}()
+
+	_ = conn.Close(websocket.CloseNormal, "")
+	_ = conn.CloseNow()
+	sub.end(err)
+}
+
+// readMessage returns the next protocol message, control frames are skipped.
+func readMessage(conn *websocket.Conn) (wsMessage, error) {
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			return wsMessage{}, err
+		}
+
+		if data.Opcode != websocket.OpText {
+			continue
+		}
+
+		var msg wsMessage
+		if err := json.Unmarshal(data.Data, &msg); err != nil {
+			return wsMessage{}, fmt.Errorf("invalid message from the server: %w", err)
+		}
+		return msg, nil
+	}
+}
+
+func writeMessage(conn *websocket.Conn, msg wsMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.OpText, data)
+}
+
+// payloadError returns the errors sent by the server, graphql-transport-ws sends a list of graphql errors
+// while subscriptions-transport-ws may send a single one.
+func payloadError(payload json.RawMessage) error {
+	type graphqlError struct {
+		Message string `json:"message"`
+	}
+
+	var list []graphqlError
+	if err := json.Unmarshal(payload, &list); err == nil && len(list) > 0 {
+		messages := make([]string, 0, len(list))
+		for _, e := range list {
+			messages = append(messages, e.Message)
+		}
+		return errors.New(strings.Join(messages, ", "))
+	}
+
+	var single graphqlError
+	if err := json.Unmarshal(payload, &single); err == nil && single.Message != "" {
+		return errors.New(single.Message)
+	}
+
+	if len(payload) == 0 {
+		return errors.New("subscription failed")
+	}
+	return fmt.Errorf("subscription failed: %s", payload)
+}
@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEvents(t *testing.T) {
+	body := "event: next\ndata: {\"data\":{\"count\":1}}\n\n" +
+		": keep alive\n\n" +
+		"event: next\ndata: {\"data\":{\"count\":2}}\n\n" +
+		"event: complete\ndata:\n\n" +
+		"event: next\ndata: {\"data\":{\"count\":3}}\n\n"
+
+	sub := readEvents(io.NopCloser(strings.NewReader(body)))
+
+	var payloads []string
+	for msg := range sub.Messages() {
+		payloads = append(payloads, strings.Join(strings.Fields(msg.Payload), ""))
+	}
+
+	require.Equal(t, []string{`{"data":{"count":1}}`, `{"data":{"count":2}}`}, payloads)
+	require.NoError(t, sub.Err())
+}
+
+func TestPayloadError(t *testing.T) {
+	tests := []struct {
+		payload string
+		err     string
+	}{
+		{`[{"message":"unknown field"},{"message":"not allowed"}]`, "unknown field, not allowed"},
+		{`{"message":"unauthorized"}`, "unauthorized"},
+		{`"oops"`, `subscription failed: "oops"`},
+		{``, "subscription failed"},
+	}
+
+	for _, tt := range tests {
+		require.EqualError(t, payloadError(json.RawMessage(tt.payload)), tt.err)
+	}
+}
+
+func TestIsSubscription(t *testing.T) {
+	query := "query Users { users { id } }\nsubscription OnUser { userAdded { id } }"
+
+	require.True(t, isSubscription(query, "OnUser"))
+	require.False(t, isSubscription(query, "Users"))
+	// the operation is ambiguous without a name
+	require.False(t, isSubscription(query, ""))
+	require.True(t, isSubscription("subscription { userAdded { id } }", ""))
+}
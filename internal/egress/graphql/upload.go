@@ -0,0 +1,161 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// encodePayload returns the body of the payload and its content type, requests with uploads are sent as
+// multipart requests and the rest as json.
+func encodePayload(payload map[string]any, uploads []domain.GraphQLUpload) ([]byte, string, error) {
+	files := make([]domain.GraphQLUpload, 0, len(uploads))
+	for _, u := range uploads {
+		if u.Variable != "" && u.File != "" {
+			files = append(files, u)
+		}
+	}
+
+	if len(files) == 0 {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		return body, "application/json", nil
+	}
+
+	return multipartBody(payload, files)
+}
+
+// multipartBody encodes the payload following the GraphQL multipart request spec, the operations part is
+// the payload with the file variables set to null and the map part tells which file goes to which variable.
+func multipartBody(payload map[string]any, uploads []domain.GraphQLUpload) ([]byte, string, error) {
+	vars, _ := payload["variables"].(map[string]any)
+	if vars == nil {
+		vars = map[string]any{}
+		payload["variables"] = vars
+	}
+
+	fileMap := make(map[string][]string, len(uploads))
+	for i, u := range uploads {
+		path := strings.TrimPrefix(u.Variable, "variables.")
+		if _, err := setNull(vars, strings.Split(path, ".")); err != nil {
+			return nil, "", fmt.Errorf("invalid upload variable %q: %w", u.Variable, err)
+		}
+		fileMap[strconv.Itoa(i)] = []string{"variables." + path}
+	}
+
+	operations, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	mapping, err := json.Marshal(fileMap)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal upload map: %w", err)
+	}
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	if err := w.WriteField("operations", string(operations)); err != nil {
+		return nil, "", err
+	}
+	if err := w.WriteField("map", string(mapping)); err != nil {
+		return nil, "", err
+	}
+
+	for i, u := range uploads {
+		if err := writeFile(w, strconv.Itoa(i), u.File); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return b.Bytes(), w.FormDataContentType(), nil
+}
+
+func writeFile(w *multipart.Writer, field, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, filepath.Base(path)))
+	header.Set("Content-Type", contentType)
+
+	fw, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, file)
+	return err
+}
+
+// setNull sets the value at the path to null and returns the updated value, the objects and lists on the way
+// are created when they're missing.
+func setNull(v any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	key, rest := path[0], path[1:]
+	if key == "" {
+		return nil, fmt.Errorf("empty path segment")
+	}
+
+	if i, err := strconv.Atoi(key); err == nil && i >= 0 {
+		if v == nil {
+			v = []any{}
+		}
+
+		list, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s is used as a list index of a value which isn't a list", key)
+		}
+		for len(list) <= i {
+			list = append(list, nil)
+		}
+
+		item, err := setNull(list[i], rest)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = item
+		return list, nil
+	}
+
+	if v == nil {
+		v = map[string]any{}
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s is used as a field of a value which isn't an object", key)
+	}
+
+	item, err := setNull(obj[key], rest)
+	if err != nil {
+		return nil, err
+	}
+	obj[key] = item
+	return obj, nil
+}
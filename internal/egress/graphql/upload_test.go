@@ -0,0 +1,83 @@
+package graphql
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestMultipartBody(t *testing.T) {
+	dir := t.TempDir()
+	avatar := filepath.Join(dir, "avatar.png")
+	require.NoError(t, os.WriteFile(avatar, []byte("png"), 0o600))
+	doc := filepath.Join(dir, "doc.txt")
+	require.NoError(t, os.WriteFile(doc, []byte("txt"), 0o600))
+
+	payload := map[string]any{
+		"query":     "mutation($input: Input!) { upload(input: $input) }",
+		"variables": map[string]any{"input": map[string]any{"name": "me"}},
+	}
+	uploads := []domain.GraphQLUpload{
+		{Variable: "input.avatar", File: avatar},
+		{Variable: "variables.input.docs.1", File: doc},
+	}
+
+	body, contentType, err := multipartBody(payload, uploads)
+	require.NoError(t, err)
+
+	_, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	r := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+
+	parts := map[string]string{}
+	var fileNames []string
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts[part.FormName()] = string(data)
+		if part.FileName() != "" {
+			fileNames = append(fileNames, part.FileName())
+		}
+	}
+
+	require.JSONEq(t, `{
+		"query": "mutation($input: Input!) { upload(input: $input) }",
+		"variables": {"input": {"name": "me", "avatar": null, "docs": [null, null]}}
+	}`, parts["operations"])
+	require.JSONEq(t, `{"0": ["variables.input.avatar"], "1": ["variables.input.docs.1"]}`, parts["map"])
+	require.Equal(t, "png", parts["0"])
+	require.Equal(t, "txt", parts["1"])
+	require.Equal(t, []string{"avatar.png", "doc.txt"}, fileNames)
+}
+
+func TestSetNull(t *testing.T) {
+	_, err := setNull(map[string]any{"input": "text"}, []string{"input", "file"})
+	require.Error(t, err)
+
+	_, err = setNull(map[string]any{"files": map[string]any{}}, []string{"files", "0"})
+	require.Error(t, err)
+
+	_, err = setNull(map[string]any{}, []string{"input", ""})
+	require.Error(t, err)
+}
+
+func TestIsPersistedQueryNotFound(t *testing.T) {
+	require.True(t, isPersistedQueryNotFound([]byte(`{"errors":[{"message":"PersistedQueryNotFound"}]}`)))
+	require.True(t, isPersistedQueryNotFound([]byte(`{"errors":[{"message":"not found","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`)))
+	require.False(t, isPersistedQueryNotFound([]byte(`{"errors":[{"message":"unauthorized"}]}`)))
+	require.False(t, isPersistedQueryNotFound([]byte(`{"data":{"me":null}}`)))
+}
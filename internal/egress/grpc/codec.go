@@ -0,0 +1,163 @@
+package grpc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// rawMessage is a message that keeps its wire bytes, messages read from binary files are sent as they
+// are and the received ones keep the bytes the server sent.
+type rawMessage struct {
+	proto.Message
+
+	// data is the wire format of the message, when set it's sent instead of marshaling the message.
+	data []byte
+}
+
+// rawCodec is the proto codec of grpc, it writes and reads the wire bytes of raw messages as they are.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) (mem.BufferSlice, error) {
+	if m, ok := v.(*rawMessage); ok && m.data != nil {
+		return mem.BufferSlice{mem.SliceBuffer(m.data)}, nil
+	}
+
+	return encoding.GetCodecV2(rawCodec{}.Name()).Marshal(v)
+}
+
+func (rawCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return encoding.GetCodecV2(rawCodec{}.Name()).Unmarshal(data, v)
+	}
+
+	m.data = data.Materialize()
+	return proto.Unmarshal(m.data, m.Message)
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// newRequestMessage decodes the body of the request in its format into a message of desc.
+func newRequestMessage(spec *domain.GRPCRequestSpec, desc protoreflect.MessageDescriptor) (proto.Message, error) {
+	msg := dynamicpb.NewMessage(desc)
+
+	switch spec.BodyFormat {
+	case domain.GRPCBodyFormatText:
+		if err := (prototext.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(spec.Body), msg); err != nil {
+			return nil, fmt.Errorf("failed to parse the text format body: %w", err)
+		}
+		return msg, nil
+	case domain.GRPCBodyFormatBinary:
+		if spec.BodyFile == "" {
+			return nil, fmt.Errorf("no body file selected")
+		}
+
+		data, err := os.ReadFile(spec.BodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the body file: %w", err)
+		}
+
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return nil, fmt.Errorf("body file is not a %s message: %w", desc.FullName(), err)
+		}
+		return &rawMessage{Message: msg, data: data}, nil
+	default:
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(spec.Body), msg); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+}
+
+// ConvertBody converts the body of a message of desc between the json and text formats.
+func ConvertBody(desc protoreflect.MessageDescriptor, body, from, to string) (string, error) {
+	if strings.TrimSpace(body) == "" {
+		return body, nil
+	}
+
+	msg, err := newRequestMessage(&domain.GRPCRequestSpec{Body: body, BodyFormat: from}, desc)
+	if err != nil {
+		return "", err
+	}
+
+	if to == domain.GRPCBodyFormatText {
+		return marshalText(msg)
+	}
+
+	return marshalJSON(msg)
+}
+
+func marshalJSON(msg proto.Message) (string, error) {
+	out, err := (protojson.MarshalOptions{Indent: "  "}).Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func marshalText(msg proto.Message) (string, error) {
+	out, err := (prototext.MarshalOptions{Multiline: true, Indent: "  "}).Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// formatResponses returns the received messages as json, in the text format and as hex dumps of their
+// wire bytes. numbered prefixes each message with its index, it's used for the streaming calls.
+func formatResponses(msgs []*rawMessage, numbered bool) (string, string, string, error) {
+	var jsonOut, textOut, hexOut strings.Builder
+	for i, msg := range msgs {
+		js, txt, hexDump, err := formatMessage(msg)
+		if err != nil {
+			return "", "", "", err
+		}
+
+		if !numbered {
+			return js, txt, hexDump, nil
+		}
+
+		fmt.Fprintf(&jsonOut, "// Message %d:\n%s\n\n", i, js)
+		fmt.Fprintf(&textOut, "# Message %d:\n%s\n", i, txt)
+		fmt.Fprintf(&hexOut, "Message %d:\n%s\n", i, hexDump)
+	}
+
+	return jsonOut.String(), textOut.String(), hexOut.String(), nil
+}
+
+// formatMessage returns the message as json, in the text format and as the hex dump of its wire bytes,
+// messages which were not read from the wire are marshaled for the dump.
+func formatMessage(msg *rawMessage) (string, string, string, error) {
+	js, err := marshalJSON(msg.Message)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	txt, err := marshalText(msg.Message)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	data := msg.data
+	if data == nil {
+		if data, err = proto.Marshal(msg.Message); err != nil {
+			return "", "", "", err
+		}
+	}
+
+	return js, txt, hex.Dump(data), nil
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestRequestMessageFormats(t *testing.T) {
+	desc := (&timestamppb.Timestamp{}).ProtoReflect().Descriptor()
+
+	msg, err := newRequestMessage(&domain.GRPCRequestSpec{Body: "seconds: 10\nnanos: 5", BodyFormat: domain.GRPCBodyFormatText}, desc)
+	require.NoError(t, err)
+	js, err := marshalJSON(msg)
+	require.NoError(t, err)
+	require.JSONEq(t, `"1970-01-01T00:00:10.000000005Z"`, js)
+
+	converted, err := ConvertBody(desc, js, domain.GRPCBodyFormatJSON, domain.GRPCBodyFormatText)
+	require.NoError(t, err)
+	back, err := ConvertBody(desc, converted, domain.GRPCBodyFormatText, domain.GRPCBodyFormatJSON)
+	require.NoError(t, err)
+	require.JSONEq(t, js, back)
+
+	// the fields are written out of order, the bytes of the file are sent as they are
+	var data []byte
+	data = protowire.AppendTag(data, 2, protowire.VarintType)
+	data = protowire.AppendVarint(data, 5)
+	data = protowire.AppendTag(data, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, 10)
+
+	path := filepath.Join(t.TempDir(), "body.bin")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	msg, err = newRequestMessage(&domain.GRPCRequestSpec{BodyFile: path, BodyFormat: domain.GRPCBodyFormatBinary}, desc)
+	require.NoError(t, err)
+
+	out, err := rawCodec{}.Marshal(msg)
+	require.NoError(t, err)
+	require.Equal(t, data, out.Materialize())
+
+	// the received messages keep their wire bytes
+	resp := &rawMessage{Message: dynamicpb.NewMessage(desc)}
+	require.NoError(t, rawCodec{}.Unmarshal(mem.BufferSlice{mem.SliceBuffer(data)}, resp))
+	require.Equal(t, data, resp.data)
+
+	js, txt, hexDump, err := formatResponses([]*rawMessage{resp}, false)
+	require.NoError(t, err)
+	require.JSONEq(t, `"1970-01-01T00:00:10.000000005Z"`, js)
+	require.Regexp(t, `seconds:\s+10`, txt)
+	require.Contains(t, hexDump, "10 05 08 0a")
+
+	// streamed messages come in the same formats
+	streamed, err := newStreamMessage(domain.GRPCMessageReceived, resp)
+	require.NoError(t, err)
+	require.Equal(t, js, streamed.Body)
+	require.Equal(t, txt, streamed.Text)
+	require.Equal(t, hexDump, streamed.Hex)
+
+	streamed, err = newStreamMessage(domain.GRPCMessageSent, timestamppb.New(time.Unix(10, 0)))
+	require.NoError(t, err)
+	require.Regexp(t, `seconds:\s+10`, streamed.Text)
+	require.Contains(t, streamed.Hex, "08 0a")
+
+	_, err = newRequestMessage(&domain.GRPCRequestSpec{BodyFormat: domain.GRPCBodyFormatBinary}, desc)
+	require.Error(t, err)
+}
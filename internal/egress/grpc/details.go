@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"encoding/json"
+
+	// registers google/rpc/error_details.proto, the standard details servers attach to the status of failed calls
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// errorDetails decodes the details of the status error to a json array, the types are looked up in
+// the given files and the types linked in the app, the standard error details among them. details
+// of unknown types are kept as their type url and base64 value.
+func errorDetails(files *protoregistry.Files, err error) string {
+	st, ok := status.FromError(err)
+	if !ok || len(st.Proto().GetDetails()) == 0 {
+		return ""
+	}
+
+	var resolver typeResolver
+	if files != nil {
+		resolver = append(resolver, dynamicpb.NewTypes(files))
+	}
+	resolver = append(resolver, protoregistry.GlobalTypes)
+
+	out := make([]json.RawMessage, 0, len(st.Proto().Details))
+	for _, detail := range st.Proto().Details {
+		data, err := (protojson.MarshalOptions{Resolver: resolver}).Marshal(detail)
+		if err != nil {
+			data, _ = json.Marshal(map[string]any{
+				"@type": detail.GetTypeUrl(),
+				"value": detail.GetValue(),
+			})
+		}
+		out = append(out, data)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// typeResolver looks the types up in each of the resolvers in turn.
+type typeResolver []interface {
+	protoregistry.MessageTypeResolver
+	protoregistry.ExtensionTypeResolver
+}
+
+func (r typeResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	for _, res := range r {
+		if mt, err := res.FindMessageByName(name); err == nil {
+			return mt, nil
+		}
+	}
+	return nil, protoregistry.NotFound
+}
+
+func (r typeResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	for _, res := range r {
+		if mt, err := res.FindMessageByURL(url); err == nil {
+			return mt, nil
+		}
+	}
+	return nil, protoregistry.NotFound
+}
+
+func (r typeResolver) FindExtensionByName(name protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	for _, res := range r {
+		if xt, err := res.FindExtensionByName(name); err == nil {
+			return xt, nil
+		}
+	}
+	return nil, protoregistry.NotFound
+}
+
+func (r typeResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	for _, res := range r {
+		if xt, err := res.FindExtensionByNumber(message, field); err == nil {
+			return xt, nil
+		}
+	}
+	return nil, protoregistry.NotFound
+}
@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestErrorDetails(t *testing.T) {
+	value, err := proto.Marshal(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "is required"}},
+	})
+	require.NoError(t, err)
+
+	st := status.FromProto(&spb.Status{
+		Code:    int32(codes.InvalidArgument),
+		Message: "invalid request",
+		Details: []*anypb.Any{
+			{TypeUrl: "type.googleapis.com/google.rpc.BadRequest", Value: value},
+			{TypeUrl: "type.googleapis.com/acme.Unknown", Value: []byte("abc")},
+		},
+	})
+
+	require.JSONEq(t, `[
+		{"@type": "type.googleapis.com/google.rpc.BadRequest", "fieldViolations": [{"field": "name", "description": "is required"}]},
+		{"@type": "type.googleapis.com/acme.Unknown", "value": "YWJj"}
+	]`, errorDetails(nil, st.Err()))
+
+	require.Empty(t, errorDetails(nil, status.Error(codes.NotFound, "not found")))
+	require.Empty(t, errorDetails(nil, nil))
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"sort"
@@ -17,9 +18,11 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	// registers the gzip compressor, it also lets the calls accept gzip compressed responses
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
@@ -27,6 +30,9 @@ import (
 
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/oauth2"
+	"github.com/chapar-rest/chapar/internal/prefs"
+	"github.com/chapar-rest/chapar/internal/proxy"
 	"github.com/chapar-rest/chapar/internal/safemap"
 	"github.com/chapar-rest/chapar/internal/state"
 	"github.com/chapar-rest/chapar/internal/util"
@@ -43,91 +49,310 @@ type Service struct {
 	environments *state.Environments
 	protoFiles   *state.ProtoFiles
 
-	protoFilesRegistry *safemap.Map[*protoregistry.Files]
+	protoFilesRegistry *safemap.Map[*registry]
+	pool               *pool
+
+	oauth2 *oauth2.Service
+}
+
+// registry is the proto files of a request and the server info they were loaded from.
+type registry struct {
+	files  *protoregistry.Files
+	source registrySource
+}
+
+// registrySource is where the proto files of a request come from, either the server reflection of
+// a server or the proto files on disk.
+type registrySource struct {
+	reflection connKey
+	protoFiles string
 }
 
-func NewService(requests *state.Requests, envs *state.Environments, protoFiles *state.ProtoFiles) *Service {
+func NewService(requests *state.Requests, envs *state.Environments, protoFiles *state.ProtoFiles, oauth2Service *oauth2.Service) *Service {
 	return &Service{
 		requests:           requests,
 		environments:       envs,
 		protoFiles:         protoFiles,
-		protoFilesRegistry: safemap.New[*protoregistry.Files](),
+		protoFilesRegistry: safemap.New[*registry](),
+		pool:               newPool(),
+		oauth2:             oauth2Service,
 	}
 }
 
-func (s *Service) Dial(req *domain.GRPCRequestSpec) (*grpc.ClientConn, error) {
+// SetOnConnectionStateChanged sets the function called when the state of the connection used by a request
+// changes, the state is empty for requests that are not using a grpc connection such as grpc-web calls.
+func (s *Service) SetOnConnectionStateChanged(f func(id, state string)) {
+	s.pool.onStateChanged = f
+}
+
+// ConnectionState returns the state of the connection the request used last.
+func (s *Service) ConnectionState(id string) string {
+	return s.pool.state(id)
+}
+
+// Reconnect closes the connection of the request, once its calls are done, and opens a new one.
+func (s *Service) Reconnect(id, activeEnvironmentID string) error {
+	spec, env, err := s.resolveRequest(id, activeEnvironmentID)
+	if err != nil {
+		return err
+	}
+
+	if spec.Settings.Protocol != "" && spec.Settings.Protocol != domain.GRPCProtocolNative {
+		return fmt.Errorf("%s calls are made over http and have no connection to reconnect", spec.Settings.Protocol)
+	}
+
+	s.pool.evict(newConnKey(spec, env))
+
+	c, err := s.connect(id, spec, env)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if pc, ok := c.(*pooledConn); ok {
+		pc.Connect()
+	}
+
+	return nil
+}
+
+// resolveRequest returns a clone of the grpc request of id with the variables and the values of
+// the active environment applied to it.
+func (s *Service) resolveRequest(id, activeEnvironmentID string) (*domain.GRPCRequestSpec, *domain.Environment, error) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return nil, nil, ErrRequestNotFound
+	}
+
+	req = req.Clone()
+	if req.Spec.GRPC == nil {
+		return nil, nil, fmt.Errorf("request with id %s is not a grpc request", id)
+	}
+
+	var activeEnvironment = s.getActiveEnvironment(activeEnvironmentID)
+	applyVariables(req.Spec.GRPC, activeEnvironment)
+	return req.Spec.GRPC, activeEnvironment, nil
+}
+
+// ResolveRequest returns a clone of the request of id with the metadata and auth of its collection merged in
+// and the variables and the values of the active environment applied.
+func (s *Service) ResolveRequest(id, activeEnvironmentID string) (*domain.Request, error) {
+	req, err := s.mergedRequest(id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyVariables(req.Spec.GRPC, s.getActiveEnvironment(activeEnvironmentID))
+	return req, nil
+}
+
+// mergedRequest returns a clone of the request of id with the metadata and auth of its collection merged in.
+func (s *Service) mergedRequest(id string) (*domain.Request, error) {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return nil, ErrRequestNotFound
+	}
+
+	req = req.Clone()
+	spec := req.Spec.GRPC
+	if spec == nil {
+		return nil, fmt.Errorf("request with id %s is not a grpc request", id)
+	}
+
+	// Merge collection headers (as metadata) and auth if request belongs to a collection
+	if req.CollectionID != "" {
+		collection := s.requests.GetCollection(req.CollectionID)
+		if collection != nil {
+			// Merge collection headers as metadata: collection headers as base, request metadata override
+			spec.Metadata = s.mergeMetadata(collection.Spec.Headers, spec.Metadata)
+
+			// Resolve auth: if request auth is inherit, use collection auth
+			if spec.Auth.Type == domain.AuthTypeInherit {
+				spec.Auth = collection.Spec.Auth
+			}
+		}
+	}
+
+	return req, nil
+}
+
+// applyVariables applies the global variables and the environment to the request.
+func applyVariables(spec *domain.GRPCRequestSpec, env *domain.Environment) {
+	vars := variables.GetVariables()
+	variables.ApplyToGRPCRequest(vars, spec)
+
+	if env != nil {
+		variables.ApplyToEnv(vars, &env.Spec)
+		env.ApplyToGRPCRequest(spec)
+	}
+}
+
+func (s *Service) Dial(req *domain.GRPCRequestSpec, env *domain.Environment) (*grpc.ClientConn, error) {
 	opts := []grpc.DialOption{
 		grpc.WithUserAgent(version.GetAgentName()),
 	}
 
+	// grpc only knows about the environment proxies, so dialing is done by us for all the proxy modes
+	dialer, err := proxy.NewDialer(domain.ResolveProxy(prefs.GetGlobalConfig().Spec.General.Proxy, env, req.Settings.Proxy))
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, grpc.WithNoProxy(), grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}))
+
+	target := req.ServerInfo.Address
+	if dialer.Proxied(target) && !strings.Contains(target, ":///") {
+		// let the proxy resolve the host name instead of resolving it locally
+		target = "passthrough:///" + target
+	}
+
 	if !req.Settings.Insecure {
-		var tlsCfg tls.Config
-		tlsCfg.InsecureSkipVerify = req.Settings.Insecure
+		tlsCfg, err := tlsConfig(req.Settings)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
 
-		if req.Settings.ClientCertFile != "" {
-			certFile, err := os.ReadFile(req.Settings.ClientCertFile)
-			if err != nil {
-				return nil, err
-			}
+	// the override is the :authority of the calls, tls verifies the server name against it too
+	if req.Settings.NameOverride != "" {
+		opts = append(opts, grpc.WithAuthority(req.Settings.NameOverride))
+	}
 
-			keyFile, err := os.ReadFile(req.Settings.ClientCertFile)
-			if err != nil {
-				return nil, err
-			}
+	if req.Settings.KeepaliveTimeSeconds > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                time.Duration(req.Settings.KeepaliveTimeSeconds) * time.Second,
+			Timeout:             time.Duration(req.Settings.KeepaliveTimeoutSeconds) * time.Second,
+			PermitWithoutStream: req.Settings.KeepalivePermitWithoutStream,
+		}))
+	}
 
-			cert, err := tls.X509KeyPair(certFile, keyFile)
-			if err != nil {
-				return nil, err
-			}
-			tlsCfg.Certificates = []tls.Certificate{cert}
+	return grpc.NewClient(target, opts...)
+}
+
+// tlsConfig builds the tls config of the connection from the certificates of the settings.
+func tlsConfig(settings domain.GRPCSettings) (*tls.Config, error) {
+	var (
+		tlsCfg tls.Config
+		err    error
+	)
+
+	if settings.ClientCertFile != "" {
+		certFile, err := os.ReadFile(settings.ClientCertFile)
+		if err != nil {
+			return nil, err
 		}
 
-		var err error
-		tlsCfg.RootCAs, err = x509.SystemCertPool()
+		keyFile, err := os.ReadFile(settings.ClientKeyFile)
 		if err != nil {
-			tlsCfg.RootCAs = x509.NewCertPool()
+			return nil, err
 		}
-		if req.Settings.RootCertFile != "" {
-			rootFile, err := os.ReadFile(req.Settings.RootCertFile)
-			if err != nil {
-				return nil, err
-			}
 
-			tlsCfg.RootCAs.AppendCertsFromPEM(rootFile)
+		cert, err := tls.X509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
 		}
-		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tlsCfg)))
-	} else {
-		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		tlsCfg.Certificates = []tls.Certificate{cert}
 	}
 
-	return grpc.NewClient(req.ServerInfo.Address, opts...)
-}
+	tlsCfg.ServerName = settings.NameOverride
 
-func (s *Service) GetRequestStruct(id, environmentID string) (string, error) {
-	req := s.requests.GetRequest(id)
-	if req == nil {
-		return "", ErrRequestNotFound
+	tlsCfg.RootCAs, err = x509.SystemCertPool()
+	if err != nil {
+		tlsCfg.RootCAs = x509.NewCertPool()
 	}
+	if settings.RootCertFile != "" {
+		rootFile, err := os.ReadFile(settings.RootCertFile)
+		if err != nil {
+			return nil, err
+		}
 
-	method := req.Spec.GRPC.LasSelectedMethod
-	if method == "" {
-		return "", errors.New("no method selected")
+		tlsCfg.RootCAs.AppendCertsFromPEM(rootFile)
 	}
 
-	// get the method descriptor
-	md, err := s.getMethodDesc(id, environmentID, method)
+	return &tlsCfg, nil
+}
+
+// conn is a connection the calls are made on, either a native grpc connection or
+// an http client for the grpc-web and connect protocols.
+type conn interface {
+	grpc.ClientConnInterface
+	Close() error
+}
+
+// connect returns the connection of the call for the protocol selected in the request settings,
+// the connections and http clients come from the pool and are shared with the other requests to the same server.
+func (s *Service) connect(id string, req *domain.GRPCRequestSpec, env *domain.Environment) (conn, error) {
+	switch req.Settings.Protocol {
+	case "", domain.GRPCProtocolNative:
+		return s.pool.acquire(id, newConnKey(req, env), func() (*grpc.ClientConn, error) {
+			return s.Dial(req, env)
+		})
+	case domain.GRPCProtocolWeb, domain.GRPCProtocolWebText, domain.GRPCProtocolConnect, domain.GRPCProtocolConnectProto:
+		s.pool.forget(id)
+		return s.pool.acquireHTTP(httpConnKey{connKey: newConnKey(req, env), protocol: req.Settings.Protocol}, func() (*httpConn, error) {
+			return newHTTPConn(req, env)
+		})
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q", req.Settings.Protocol)
+	}
+}
+
+func (s *Service) GetRequestStruct(id, environmentID string) (string, error) {
+	input, err := s.GetRequestDescriptor(id, environmentID)
 	if err != nil {
 		return "", err
 	}
 
-	jsonBytes, err := json.MarshalIndent(GenerateExampleJSON(md.Input()), "", "  ")
+	jsonBytes, err := json.MarshalIndent(GenerateExampleJSON(input), "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal to JSON: %w", err)
 	}
 
+	// the example is given in the body format of the request
+	if req := s.requests.GetRequest(id); req != nil && req.Spec.GRPC != nil && req.Spec.GRPC.BodyFormat == domain.GRPCBodyFormatText {
+		return ConvertBody(input, string(jsonBytes), domain.GRPCBodyFormatJSON, domain.GRPCBodyFormatText)
+	}
+
 	return string(jsonBytes), nil
 }
 
+// ConvertRequestBody converts the body of the request between the json and text formats, using the
+// input message of its selected method.
+func (s *Service) ConvertRequestBody(id, environmentID, body, from, to string) (string, error) {
+	input, err := s.GetRequestDescriptor(id, environmentID)
+	if err != nil {
+		return "", err
+	}
+
+	return ConvertBody(input, body, from, to)
+}
+
+// GetRequestDescriptor returns the descriptor of the input message of the selected method of the request.
+func (s *Service) GetRequestDescriptor(id, environmentID string) (protoreflect.MessageDescriptor, error) {
+	spec, env, err := s.resolveRequest(id, environmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	method := spec.LasSelectedMethod
+	if method == "" {
+		return nil, errors.New("no method selected")
+	}
+
+	// get the method descriptor
+	md, _, err := s.getMethodDesc(id, spec, env, method)
+	if err != nil {
+		return nil, err
+	}
+
+	return md.Input(), nil
+}
+
 func GenerateExampleJSON(messageDescriptor protoreflect.MessageDescriptor) map[string]interface{} {
 	out := make(map[string]interface{})
 
@@ -205,67 +430,52 @@ func GenerateExampleJSON(messageDescriptor protoreflect.MessageDescriptor) map[s
 	return out
 }
 
-func (s *Service) SendRequest(id, activeEnvironmentID string) (*egress.Response, error) {
-	req := s.requests.GetRequest(id)
-	if req == nil {
-		return nil, ErrRequestNotFound
-	}
-
-	clonedReq := req.Clone()
-	spec := clonedReq.Spec.GRPC
-	if spec == nil {
-		return nil, nil
-	}
-
-	// Merge collection headers (as metadata) and auth if request belongs to a collection
-	if clonedReq.CollectionID != "" {
-		collection := s.requests.GetCollection(clonedReq.CollectionID)
-		if collection != nil {
-			// Merge collection headers as metadata: collection headers as base, request metadata override
-			spec.Metadata = s.mergeMetadata(collection.Spec.Headers, spec.Metadata)
+// call is a method invocation of a request, the request is already merged with its collection and
+// the variables and environment values are applied to it.
+type call struct {
+	spec   *domain.GRPCRequestSpec
+	env    *domain.Environment
+	conn   conn
+	method string
+	md     protoreflect.MethodDescriptor
+	// files are the proto files of the request, used to decode the error details
+	files *protoregistry.Files
+}
 
-			// Resolve auth: if request auth is inherit, use collection auth
-			if spec.Auth.Type == domain.AuthTypeInherit {
-				spec.Auth = collection.Spec.Auth
-			}
+// prepareCall dials the server of the request and resolves its method, the returned context carries the
+// request metadata and timeout and has to be cancelled once the call is done.
+func (s *Service) prepareCall(ctx context.Context, id, activeEnvironmentID string) (context.Context, context.CancelFunc, *call, error) {
+	// the request of a pre request script is merged with its collection already
+	clonedReq := egress.ScriptRequest(ctx, id)
+	if clonedReq == nil {
+		var err error
+		if clonedReq, err = s.mergedRequest(id); err != nil {
+			return nil, nil, nil, err
 		}
 	}
+	spec := clonedReq.Spec.GRPC
 
+	// the variables a pre request script left in the request are applied as well
 	var activeEnvironment = s.getActiveEnvironment(activeEnvironmentID)
-
-	vars := variables.GetVariables()
-	variables.ApplyToGRPCRequest(vars, spec)
-
-	if activeEnvironment != nil {
-		variables.ApplyToEnv(vars, &activeEnvironment.Spec)
-		activeEnvironment.ApplyToGRPCRequest(spec)
-	}
+	applyVariables(spec, activeEnvironment)
 
 	method := spec.LasSelectedMethod
 	if method == "" {
-		return nil, errors.New("no method selected")
-	}
-
-	rawJSON := []byte(spec.Body)
-
-	conn, err := s.Dial(spec)
-	if err != nil {
-		return nil, err
+		return nil, nil, nil, errors.New("no method selected")
 	}
 
 	// get the method descriptor
-	md, err := s.getMethodDesc(id, activeEnvironmentID, method)
+	md, files, err := s.getMethodDesc(id, spec, activeEnvironment, method)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
-	// create the message
-	request := dynamicpb.NewMessage(md.Input())
-	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(rawJSON, request); err != nil {
-		return nil, err
+	conn, err := s.connect(id, spec, activeEnvironment)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	ctx := metadata.NewOutgoingContext(context.Background(), metadata.New(nil))
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(nil))
 	for _, item := range spec.Metadata {
 		if !item.Enable {
 			continue
@@ -273,11 +483,19 @@ func (s *Service) SendRequest(id, activeEnvironmentID string) (*egress.Response,
 		ctx = metadata.AppendToOutgoingContext(ctx, item.Key, item.Value)
 	}
 
-	if authHeaders := s.prepareAuth(spec); authHeaders != nil {
-		ctx = metadata.NewOutgoingContext(ctx, *authHeaders)
+	authHeaders, err := s.prepareAuth(ctx, spec, activeEnvironment)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, err
 	}
 
-	var respHeaders, respTrailers metadata.MD
+	if authHeaders != nil {
+		for k, values := range *authHeaders {
+			for _, v := range values {
+				ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+			}
+		}
+	}
 
 	// Set a timeout for the request if not specified, default to 2 hours to have a long enough timeout
 	timeOut := 2 * time.Hour
@@ -286,38 +504,108 @@ func (s *Service) SendRequest(id, activeEnvironmentID string) (*egress.Response,
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, timeOut)
+	return ctx, cancel, &call{
+		spec:   spec,
+		env:    activeEnvironment,
+		conn:   conn,
+		method: method,
+		md:     md,
+		files:  files,
+	}, nil
+}
+
+// callOptions returns the options of the call from the settings of the request.
+func (c *call) callOptions() []grpc.CallOption {
+	settings := c.spec.Settings
+
+	// the codec keeps the wire bytes of the messages, so binary bodies are sent as they are
+	opts := []grpc.CallOption{grpc.ForceCodecV2(rawCodec{})}
+	if settings.Compression != "" {
+		opts = append(opts, grpc.UseCompressor(settings.Compression))
+	}
+
+	if settings.MaxSendMessageSizeMB > 0 {
+		opts = append(opts, grpc.MaxCallSendMsgSize(settings.MaxSendMessageSizeMB<<20))
+	}
+
+	if settings.MaxReceiveMessageSizeMB > 0 {
+		opts = append(opts, grpc.MaxCallRecvMsgSize(settings.MaxReceiveMessageSizeMB<<20))
+	}
+
+	if settings.WaitForReady {
+		opts = append(opts, grpc.WaitForReady(true))
+	}
+
+	return opts
+}
+
+func (s *Service) SendRequest(ctx context.Context, id, activeEnvironmentID string) (*egress.Response, error) {
+	ctx, cancel, c, err := s.prepareCall(ctx, id, activeEnvironmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the messages of client streams opened for the user are sent as they're written
+	if c.md.IsStreamingClient() && egress.ClientStream(ctx, id) {
+		return s.openClientStream(ctx, cancel, c)
+	}
+
+	// create the message
+	request, err := newRequestMessage(c.spec, c.md.Input())
+	if err != nil {
+		cancel()
+		_ = c.conn.Close()
+		return nil, err
+	}
+
+	// server streams are read as the messages arrive, the stream owns the call from here on
+	if c.md.IsStreamingServer() && !c.md.IsStreamingClient() {
+		return s.openServerStream(ctx, cancel, c, request)
+	}
 	defer cancel()
+	defer func() { _ = c.conn.Close() }()
+
+	var respHeaders, respTrailers metadata.MD
 
 	outgoingMetadata, _ := metadata.FromOutgoingContext(ctx)
 
-	callOpts := []grpc.CallOption{
+	callOpts := append(c.callOptions(),
 		grpc.Header(&respHeaders),
 		grpc.Trailer(&respTrailers),
-	}
+	)
 
 	var (
-		respErr error
-		respStr string
+		respErr  error
+		respMsgs []*rawMessage
 	)
 
 	start := time.Now()
-	if md.IsStreamingServer() {
-		respStr, respErr = s.invokeServerStream(ctx, conn, method, request, md, callOpts...)
+	if c.md.IsStreamingClient() {
+		// client and bidi streams opened by SendRequest carry the request body as their only message
+		respMsgs, respErr = s.invokeStream(ctx, c.conn, c.method, request, c.md, callOpts...)
 	} else {
-		respStr, respErr = s.invokeUnary(ctx, conn, method, request, md, callOpts...)
+		respMsgs, respErr = s.invokeUnary(ctx, c.conn, c.method, request, c.md, callOpts...)
 	}
 	elapsed := time.Since(start)
 
+	respStr, respText, respHex, err := formatResponses(respMsgs, c.md.IsStreamingClient())
+	if err != nil {
+		return nil, err
+	}
+
 	out := &egress.Response{
 		TimePassed:       elapsed,
 		ResponseMetadata: domain.MetadataToKeyValue(respHeaders),
 		RequestMetadata:  domain.MetadataToKeyValue(outgoingMetadata),
 		Trailers:         domain.MetadataToKeyValue(respTrailers),
 		Error:            respErr,
+		ErrorDetails:     errorDetails(c.files, respErr),
 		StatueCode:       int(status.Code(respErr)),
 		Status:           status.Code(respErr).String(),
 		Size:             len(respStr),
 		Body:             []byte(respStr),
+		ProtoText:        respText,
+		HexDump:          respHex,
 	}
 
 	if util.IsJSON(string(out.Body)) {
@@ -329,22 +617,19 @@ func (s *Service) SendRequest(id, activeEnvironmentID string) (*egress.Response,
 		}
 	}
 
-	if respErr != nil {
-		return out, respErr
-	}
-
-	return out, nil
+	// a failed call still returns its response along with the error, its status and details are shown and can be extracted
+	return out, respErr
 }
 
-func (s *Service) invokeServerStream(ctx context.Context, conn *grpc.ClientConn, method string, req proto.Message, md protoreflect.MethodDescriptor, opts ...grpc.CallOption) (string, error) {
+func (s *Service) invokeStream(ctx context.Context, conn grpc.ClientConnInterface, method string, req proto.Message, md protoreflect.MethodDescriptor, opts ...grpc.CallOption) ([]*rawMessage, error) {
 	if conn == nil {
-		return "", errors.New("no connection")
+		return nil, errors.New("no connection")
 	}
 
 	sd := &grpc.StreamDesc{
 		StreamName:    method,
-		ClientStreams: false,
-		ServerStreams: true,
+		ClientStreams: md.IsStreamingClient(),
+		ServerStreams: md.IsStreamingServer(),
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -352,63 +637,51 @@ func (s *Service) invokeServerStream(ctx context.Context, conn *grpc.ClientConn,
 
 	stream, err := conn.NewStream(ctx, sd, method, opts...)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if err := stream.SendMsg(req); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if err := stream.CloseSend(); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var out string
-	counter := 0
+	var out []*rawMessage
 	for {
-		resp := dynamicpb.NewMessage(md.Output())
+		resp := &rawMessage{Message: dynamicpb.NewMessage(md.Output())}
 		err := stream.RecvMsg(resp)
 		if err == io.EOF {
 			break
 		}
 
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
-		respJSON, err := (protojson.MarshalOptions{
-			Indent: "  ",
-		}).Marshal(resp)
-		if err != nil {
-			return "", err
-		}
+		out = append(out, resp)
 
-		// concat responses with a new line and message counter
-		out += fmt.Sprintf("// Message %d:\n%s\n\n", counter, string(respJSON))
-		counter++
+		// client streams end with their single response
+		if !md.IsStreamingServer() {
+			break
+		}
 	}
 
 	return out, nil
 }
 
-func (s *Service) invokeUnary(ctx context.Context, conn *grpc.ClientConn, method string, req proto.Message, md protoreflect.MethodDescriptor, opts ...grpc.CallOption) (string, error) {
+func (s *Service) invokeUnary(ctx context.Context, conn grpc.ClientConnInterface, method string, req proto.Message, md protoreflect.MethodDescriptor, opts ...grpc.CallOption) ([]*rawMessage, error) {
 	if conn == nil {
-		return "", errors.New("no connection")
+		return nil, errors.New("no connection")
 	}
 
-	resp := dynamicpb.NewMessage(md.Output())
+	resp := &rawMessage{Message: dynamicpb.NewMessage(md.Output())}
 	if err := conn.Invoke(ctx, method, req, resp, opts...); err != nil {
-		return "", err
-	}
-
-	respJSON, err := (protojson.MarshalOptions{
-		Indent: "  ",
-	}).Marshal(resp)
-	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(respJSON), nil
+	return []*rawMessage{resp}, nil
 }
 
 // mergeMetadata merges collection headers (as metadata) with request metadata
@@ -447,99 +720,141 @@ func (s *Service) mergeMetadata(collectionHeaders, requestMetadata []domain.KeyV
 	return merged
 }
 
-func (s *Service) prepareAuth(req *domain.GRPCRequestSpec) *metadata.MD {
+func (s *Service) prepareAuth(ctx context.Context, req *domain.GRPCRequestSpec, env *domain.Environment) (*metadata.MD, error) {
 	if req.Auth.Type == domain.AuthTypeNone {
-		return nil
+		return nil, nil
 	}
 
 	md := metadata.New(nil)
 	if req.Auth.Type == domain.AuthTypeToken {
 		md.Append("Authorization", fmt.Sprintf("Bearer %s", req.Auth.TokenAuth.Token))
-		return &md
+		return &md, nil
 	}
 
 	if req.Auth.Type == domain.AuthTypeBasic && req.Auth.BasicAuth != nil {
 		md.Append("Authorization", fmt.Sprintf("Basic %s:%s", req.Auth.BasicAuth.Username, req.Auth.BasicAuth.Password))
-		return &md
+		return &md, nil
 	}
 
 	if req.Auth.Type == domain.AuthTypeAPIKey {
 		md.Append(req.Auth.APIKeyAuth.Key, req.Auth.APIKeyAuth.Value)
-		return &md
+		return &md, nil
 	}
 
-	return nil
-}
-
-func (s *Service) getMethodDesc(id, envID, fullName string) (protoreflect.MethodDescriptor, error) {
-	registryFiles, exist := s.protoFilesRegistry.Get(id)
-	if !exist {
-		// reload the proto files we don't have them in registry
-		if _, err := s.GetServices(id, envID); err != nil {
-			return nil, err
+	if req.Auth.Type == domain.AuthTypeOAuth2 && req.Auth.OAuth2Auth != nil {
+		token, err := s.oauth2.Token(ctx, env, req.Auth.OAuth2Auth, req.Settings.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get oauth2 token: %w", err)
 		}
 
-		// get the proto files from the registry
-		registryFiles, _ = s.protoFilesRegistry.Get(id)
+		md.Append("Authorization", token.AuthorizationHeader())
+		return &md, nil
+	}
+
+	if req.Auth.Type == domain.AuthTypeAWSSigV4 || req.Auth.Type == domain.AuthTypeDigest {
+		return nil, fmt.Errorf("%s auth is only supported for http requests", req.Auth.Type)
+	}
+
+	return nil, nil
+}
+
+func (s *Service) getMethodDesc(id string, spec *domain.GRPCRequestSpec, env *domain.Environment, fullName string) (protoreflect.MethodDescriptor, *protoregistry.Files, error) {
+	registryFiles, err := s.registry(id, spec, env)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	name := strings.Replace(fullName[1:], "/", ".", 1)
 	desc, err := registryFiles.FindDescriptorByName(protoreflect.FullName(name))
 	if err != nil {
-		return nil, fmt.Errorf("app: failed to find descriptor: %v", err)
+		return nil, nil, fmt.Errorf("app: failed to find descriptor: %v", err)
 	}
 
 	methodDesc, ok := desc.(protoreflect.MethodDescriptor)
 	if !ok {
-		return nil, fmt.Errorf("app: descriptor was not a method: %T", desc)
+		return nil, nil, fmt.Errorf("app: descriptor was not a method: %T", desc)
 	}
 
-	return methodDesc, nil
+	return methodDesc, registryFiles, nil
 }
 
-func (s *Service) GetServices(id, activeEnvironmentID string) ([]domain.GRPCService, error) {
-	req := s.requests.GetRequest(id)
-	if req == nil {
-		return nil, ErrRequestNotFound
+// registry returns the proto files of the request, they are loaded again when the server info
+// changed since they were loaded. server reflection results are shared by the requests to the same server.
+func (s *Service) registry(id string, spec *domain.GRPCRequestSpec, env *domain.Environment) (*protoregistry.Files, error) {
+	source := newRegistrySource(spec, env)
+	if r, ok := s.protoFilesRegistry.Get(id); ok && r.source == source {
+		return r.files, nil
 	}
 
-	req = req.Clone()
+	if spec.ServerInfo.ServerReflection {
+		if files, ok := s.pool.registry(source.reflection); ok {
+			s.protoFilesRegistry.Set(id, &registry{files: files, source: source})
+			return files, nil
+		}
+	}
 
-	var activeEnvironment = s.getActiveEnvironment(activeEnvironmentID)
-	vars := variables.GetVariables()
-	variables.ApplyToGRPCRequest(vars, req.Spec.GRPC)
+	// reload the proto files as we don't have them in registry
+	if _, err := s.loadServices(id, spec, env); err != nil {
+		return nil, err
+	}
+
+	r, _ := s.protoFilesRegistry.Get(id)
+	return r.files, nil
+}
 
-	if activeEnvironment != nil {
-		variables.ApplyToEnv(vars, &activeEnvironment.Spec)
-		activeEnvironment.ApplyToGRPCRequest(req.Spec.GRPC)
+func newRegistrySource(spec *domain.GRPCRequestSpec, env *domain.Environment) registrySource {
+	if spec.ServerInfo.ServerReflection {
+		return registrySource{reflection: newConnKey(spec, env)}
 	}
+	return registrySource{protoFiles: strings.Join(spec.ServerInfo.ProtoFiles, "\n")}
+}
 
-	conn, err := s.Dial(req.Spec.GRPC)
+// GetServices loads the services of the request from the server reflection or the proto files,
+// the cached proto files of the server are replaced with the loaded ones.
+func (s *Service) GetServices(id, activeEnvironmentID string) ([]domain.GRPCService, error) {
+	spec, env, err := s.resolveRequest(id, activeEnvironmentID)
 	if err != nil {
 		return nil, err
 	}
 
-	if req.Spec.GRPC.ServerInfo.ServerReflection {
-		protoRegistryFiles, err := ProtoFilesFromReflectionAPI(context.Background(), conn)
+	return s.loadServices(id, spec, env)
+}
+
+func (s *Service) loadServices(id string, spec *domain.GRPCRequestSpec, env *domain.Environment) ([]domain.GRPCService, error) {
+	source := newRegistrySource(spec, env)
+
+	if spec.ServerInfo.ServerReflection {
+		// reflection is a native grpc service whatever the protocol of the calls is
+		native := *spec
+		native.Settings.Protocol = ""
+
+		c, err := s.connect(id, &native, env)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = c.Close() }()
+
+		protoRegistryFiles, err := ProtoFilesFromReflectionAPI(context.Background(), c.(*pooledConn).ClientConn)
 		if err != nil {
 			return nil, err
 		}
 
-		s.protoFilesRegistry.Set(id, protoRegistryFiles)
+		s.pool.setRegistry(source.reflection, protoRegistryFiles)
+		s.protoFilesRegistry.Set(id, &registry{files: protoRegistryFiles, source: source})
 
 		return s.parseRegistryFiles(protoRegistryFiles)
-	} else if len(req.Spec.GRPC.ServerInfo.ProtoFiles) > 0 {
+	} else if len(spec.ServerInfo.ProtoFiles) > 0 {
 		protoFiles, err := s.protoFiles.LoadProtoFiles()
 		if err != nil {
 			return nil, err
 		}
 
-		protoRegistryFiles, err := ProtoFilesFromDisk(GetImportPaths(protoFiles, req.Spec.GRPC.ServerInfo.ProtoFiles))
+		protoRegistryFiles, err := ProtoFilesFromSources(protoFiles, spec.ServerInfo.ProtoFiles)
 		if err != nil {
 			return nil, err
 		}
 
-		s.protoFilesRegistry.Set(id, protoRegistryFiles)
+		s.protoFilesRegistry.Set(id, &registry{files: protoRegistryFiles, source: source})
 		return s.parseRegistryFiles(protoRegistryFiles)
 	}
 
@@ -563,12 +878,20 @@ func GetImportPaths(protoFiles []*domain.ProtoFile, files []string) ([]string, [
 	importPaths := make([]string, 0, len(protoFiles)+len(files))
 	fileNames := make([]string, 0, len(protoFiles)+len(files))
 	for _, file := range files {
+		if domain.IsDescriptorSetFile(file) {
+			continue
+		}
+
 		// extract the directory path from the file path
 		importPaths = append(importPaths, filepath.Dir(file))
 		fileNames = append(fileNames, filepath.Base(file))
 	}
 
 	for _, protoFile := range protoFiles {
+		if protoFile.Spec.IsDescriptorSet {
+			continue
+		}
+
 		if protoFile.Spec.IsImportPath {
 			importPaths = append(importPaths, protoFile.Spec.Path)
 		} else {
@@ -580,6 +903,24 @@ func GetImportPaths(protoFiles []*domain.ProtoFile, files []string) ([]string, [
 	return importPaths, fileNames
 }
 
+// GetDescriptorSets returns the descriptor set files among files and the proto files of the workspace.
+func GetDescriptorSets(protoFiles []*domain.ProtoFile, files []string) []string {
+	out := make([]string, 0)
+	for _, file := range files {
+		if domain.IsDescriptorSetFile(file) {
+			out = append(out, file)
+		}
+	}
+
+	for _, protoFile := range protoFiles {
+		if protoFile.Spec.IsDescriptorSet {
+			out = append(out, protoFile.Spec.Path)
+		}
+	}
+
+	return out
+}
+
 // isReflectionService returns true for the built-in gRPC reflection service(s),
 // which should be hidden from the user's method list and collections.
 func isReflectionService(svc protoreflect.ServiceDescriptor) bool {
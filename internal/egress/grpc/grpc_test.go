@@ -0,0 +1,30 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestCallOptions(t *testing.T) {
+	c := &call{spec: &domain.GRPCRequestSpec{}}
+	require.Equal(t, []grpc.CallOption{grpc.ForceCodecV2(rawCodec{})}, c.callOptions())
+
+	c.spec.Settings = domain.GRPCSettings{
+		Compression:             domain.GRPCCompressionGzip,
+		MaxSendMessageSizeMB:    8,
+		MaxReceiveMessageSizeMB: 16,
+		WaitForReady:            true,
+	}
+
+	require.Equal(t, []grpc.CallOption{
+		grpc.ForceCodecV2(rawCodec{}),
+		grpc.UseCompressor(domain.GRPCCompressionGzip),
+		grpc.MaxCallSendMsgSize(8 << 20),
+		grpc.MaxCallRecvMsgSize(16 << 20),
+		grpc.WaitForReady(true),
+	}, c.callOptions())
+}
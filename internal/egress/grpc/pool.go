@@ -0,0 +1,306 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/prefs"
+)
+
+const (
+	// idleConnTimeout is how long a connection without calls is kept open.
+	idleConnTimeout = 5 * time.Minute
+	// evictInterval is how often the idle connections are looked for.
+	evictInterval = time.Minute
+)
+
+// connKey identifies the connections that can be shared between the calls of the requests,
+// requests with the same server and tls settings use the same connection.
+type connKey struct {
+	address      string
+	insecure     bool
+	nameOverride string
+
+	rootCertFile   string
+	clientCertFile string
+	clientKeyFile  string
+
+	keepaliveTime                time.Duration
+	keepaliveTimeout             time.Duration
+	keepalivePermitWithoutStream bool
+
+	proxy domain.ProxyConfig
+}
+
+func newConnKey(req *domain.GRPCRequestSpec, env *domain.Environment) connKey {
+	return connKey{
+		address:        req.ServerInfo.Address,
+		insecure:       req.Settings.Insecure,
+		nameOverride:   req.Settings.NameOverride,
+		rootCertFile:   req.Settings.RootCertFile,
+		clientCertFile: req.Settings.ClientCertFile,
+		clientKeyFile:  req.Settings.ClientKeyFile,
+
+		keepaliveTime:                time.Duration(req.Settings.KeepaliveTimeSeconds) * time.Second,
+		keepaliveTimeout:             time.Duration(req.Settings.KeepaliveTimeoutSeconds) * time.Second,
+		keepalivePermitWithoutStream: req.Settings.KeepalivePermitWithoutStream,
+
+		proxy: domain.ResolveProxy(prefs.GetGlobalConfig().Spec.General.Proxy, env, req.Settings.Proxy),
+	}
+}
+
+// httpConnKey identifies the http clients of the grpc-web and connect calls, they are shared by the
+// requests with the same server, settings and protocol.
+type httpConnKey struct {
+	connKey
+	protocol string
+}
+
+// pool keeps the grpc connections open between the calls, the connections without calls are
+// closed once they are idle for idleConnTimeout.
+type pool struct {
+	mx    sync.Mutex
+	conns map[connKey]*pooledConn
+	// httpConns are the clients of the grpc-web and connect calls, so their connections are reused too
+	httpConns map[httpConnKey]*pooledHTTPConn
+	// requests maps the requests to the connection of their last call, to report its state
+	requests map[string]connKey
+	// registries are the proto files loaded by server reflection, per server
+	registries map[connKey]*protoregistry.Files
+
+	onStateChanged func(id, state string)
+}
+
+// pooledConn is a connection of the pool, Close gives it back to the pool instead of closing it.
+type pooledConn struct {
+	*grpc.ClientConn
+
+	pool     *pool
+	key      connKey
+	refs     int
+	lastUsed time.Time
+	// evicted connections are closed once their last call is done
+	evicted bool
+}
+
+// pooledHTTPConn is a http client of the pool, its idle connections are closed by its transport.
+type pooledHTTPConn struct {
+	*httpConn
+	lastUsed time.Time
+}
+
+func newPool() *pool {
+	p := &pool{
+		conns:      make(map[connKey]*pooledConn),
+		httpConns:  make(map[httpConnKey]*pooledHTTPConn),
+		requests:   make(map[string]connKey),
+		registries: make(map[connKey]*protoregistry.Files),
+	}
+
+	go func() {
+		for range time.Tick(evictInterval) {
+			p.evictIdle()
+		}
+	}()
+
+	return p
+}
+
+// acquire returns the connection for key, it's dialed when the pool has none. the connection has
+// to be closed once the call is done.
+func (p *pool) acquire(id string, key connKey, dial func() (*grpc.ClientConn, error)) (*pooledConn, error) {
+	p.mx.Lock()
+	pc, ok := p.conns[key]
+	if !ok {
+		cc, err := dial()
+		if err != nil {
+			p.mx.Unlock()
+			return nil, err
+		}
+
+		pc = &pooledConn{ClientConn: cc, pool: p, key: key}
+		p.conns[key] = pc
+		go p.watch(pc)
+	}
+
+	pc.refs++
+	pc.lastUsed = time.Now()
+	switched := p.requests[id] != key
+	p.requests[id] = key
+	p.mx.Unlock()
+
+	// the request may have moved to another server, its state is the one of the new connection
+	if switched {
+		p.notify(id, pc.GetState())
+	}
+
+	return pc, nil
+}
+
+// acquireHTTP returns the http client for key, it's created when the pool has none.
+func (p *pool) acquireHTTP(key httpConnKey, newConn func() (*httpConn, error)) (*httpConn, error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	pc, ok := p.httpConns[key]
+	if !ok {
+		c, err := newConn()
+		if err != nil {
+			return nil, err
+		}
+
+		pc = &pooledHTTPConn{httpConn: c}
+		p.httpConns[key] = pc
+	}
+
+	pc.lastUsed = time.Now()
+	return pc.httpConn, nil
+}
+
+// Close gives the connection back to the pool.
+func (pc *pooledConn) Close() error {
+	p := pc.pool
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	pc.refs--
+	pc.lastUsed = time.Now()
+	if pc.evicted && pc.refs == 0 {
+		return pc.ClientConn.Close()
+	}
+	return nil
+}
+
+// evict removes the connection of key from the pool, it's closed once its calls are done.
+func (p *pool) evict(key connKey) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	pc, ok := p.conns[key]
+	if !ok {
+		return
+	}
+
+	delete(p.conns, key)
+	pc.evicted = true
+	if pc.refs == 0 {
+		_ = pc.ClientConn.Close()
+	}
+}
+
+func (p *pool) evictIdle() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	for key, pc := range p.conns {
+		if pc.refs == 0 && time.Since(pc.lastUsed) > idleConnTimeout {
+			delete(p.conns, key)
+			_ = pc.ClientConn.Close()
+		}
+	}
+
+	// calls still running on a dropped client are not affected, only its idle connections are closed
+	for key, pc := range p.httpConns {
+		if time.Since(pc.lastUsed) > idleConnTimeout {
+			delete(p.httpConns, key)
+			pc.closeIdle()
+		}
+	}
+}
+
+// watch reports the state changes of the connection to the requests using it, until it's closed.
+func (p *pool) watch(pc *pooledConn) {
+	for state := pc.GetState(); ; state = pc.GetState() {
+		p.mx.Lock()
+		// a connection replaced by a reconnect doesn't speak for the requests anymore
+		current, ok := p.conns[pc.key]
+		active := (ok && current == pc) || (!ok && state == connectivity.Shutdown)
+		var ids []string
+		if active {
+			for id, key := range p.requests {
+				if key == pc.key {
+					ids = append(ids, id)
+				}
+			}
+		}
+		p.mx.Unlock()
+
+		for _, id := range ids {
+			p.notify(id, state)
+		}
+
+		if state == connectivity.Shutdown {
+			return
+		}
+		pc.WaitForStateChange(context.Background(), state)
+	}
+}
+
+func (p *pool) notify(id string, state connectivity.State) {
+	if p.onStateChanged != nil {
+		p.onStateChanged(id, connState(state))
+	}
+}
+
+// state returns the state of the connection the request used last.
+func (p *pool) state(id string) string {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	key, ok := p.requests[id]
+	if !ok {
+		return domain.GRPCConnIdle
+	}
+
+	pc, ok := p.conns[key]
+	if !ok {
+		return domain.GRPCConnIdle
+	}
+
+	return connState(pc.GetState())
+}
+
+// forget stops reporting the state of a connection to the request, it's used when the request
+// is not using the pool anymore.
+func (p *pool) forget(id string) {
+	p.mx.Lock()
+	_, ok := p.requests[id]
+	delete(p.requests, id)
+	p.mx.Unlock()
+
+	if ok && p.onStateChanged != nil {
+		p.onStateChanged(id, "")
+	}
+}
+
+func (p *pool) registry(key connKey) (*protoregistry.Files, bool) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	files, ok := p.registries[key]
+	return files, ok
+}
+
+func (p *pool) setRegistry(key connKey, files *protoregistry.Files) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.registries[key] = files
+}
+
+func connState(state connectivity.State) string {
+	switch state {
+	case connectivity.Connecting:
+		return domain.GRPCConnConnecting
+	case connectivity.Ready:
+		return domain.GRPCConnReady
+	case connectivity.TransientFailure:
+		return domain.GRPCConnFailure
+	default:
+		return domain.GRPCConnIdle
+	}
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestPoolReusesConnections(t *testing.T) {
+	p := &pool{
+		conns:    make(map[connKey]*pooledConn),
+		requests: make(map[string]connKey),
+	}
+
+	dials := 0
+	dial := func() (*grpc.ClientConn, error) {
+		dials++
+		return grpc.NewClient("passthrough:///localhost:1", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	key := connKey{address: "localhost:1", insecure: true}
+	first, err := p.acquire("a", key, dial)
+	require.NoError(t, err)
+	second, err := p.acquire("b", key, dial)
+	require.NoError(t, err)
+	require.Same(t, first, second)
+	require.Equal(t, 1, dials)
+
+	other, err := p.acquire("c", connKey{address: "localhost:2", insecure: true}, dial)
+	require.NoError(t, err)
+	require.NotSame(t, first, other)
+	require.Equal(t, 2, dials)
+	require.NoError(t, other.Close())
+
+	// an evicted connection stays open until its last call is done
+	require.NoError(t, first.Close())
+	p.evict(key)
+	require.NotEqual(t, connectivity.Shutdown, second.GetState())
+	require.NoError(t, second.Close())
+	require.Equal(t, connectivity.Shutdown, second.GetState())
+
+	third, err := p.acquire("a", key, dial)
+	require.NoError(t, err)
+	require.NotSame(t, first, third)
+	require.NoError(t, third.Close())
+}
+
+func TestPoolReusesHTTPConns(t *testing.T) {
+	p := &pool{httpConns: make(map[httpConnKey]*pooledHTTPConn)}
+
+	created := 0
+	newConn := func() (*httpConn, error) {
+		created++
+		return &httpConn{}, nil
+	}
+
+	key := httpConnKey{connKey: connKey{address: "localhost:1"}, protocol: domain.GRPCProtocolWeb}
+	first, err := p.acquireHTTP(key, newConn)
+	require.NoError(t, err)
+	second, err := p.acquireHTTP(key, newConn)
+	require.NoError(t, err)
+	require.Same(t, first, second)
+
+	// the same server over another protocol has its own client
+	key.protocol = domain.GRPCProtocolConnect
+	other, err := p.acquireHTTP(key, newConn)
+	require.NoError(t, err)
+	require.NotSame(t, first, other)
+	require.Equal(t, 2, created)
+}
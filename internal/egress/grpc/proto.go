@@ -3,15 +3,20 @@ package grpc
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
 
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/desc/protoparse"
 	"github.com/jhump/protoreflect/grpcreflect"
 	"google.golang.org/grpc"
 	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
 )
 
 func ProtoFilesFromReflectionAPI(ctx context.Context, conn *grpc.ClientConn) (*protoregistry.Files, error) {
@@ -47,6 +52,60 @@ func ProtoFilesFromDisk(importPaths, filenames []string) (*protoregistry.Files,
 		return nil, errors.New("app: no *.proto files found")
 	}
 
+	fdset, err := parseProtoFiles(importPaths, filenames)
+	if err != nil {
+		return nil, err
+	}
+
+	return protodesc.NewFiles(fdset)
+}
+
+// ProtoFilesFromDescriptorSets loads the compiled FileDescriptorSets, such as the output of
+// protoc --descriptor_set_out or buf build.
+func ProtoFilesFromDescriptorSets(paths ...string) (*protoregistry.Files, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("app: no descriptor set files found")
+	}
+
+	fdset, err := readDescriptorSets(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFiles(fdset)
+}
+
+// ProtoFilesFromSources loads the proto sources and the descriptor sets of files, along with the
+// proto files and import paths of the workspace, into a single registry.
+func ProtoFilesFromSources(protoFiles []*domain.ProtoFile, files []string) (*protoregistry.Files, error) {
+	importPaths, filenames := GetImportPaths(protoFiles, files)
+	descriptorSets := GetDescriptorSets(protoFiles, files)
+
+	if len(filenames) == 0 && len(descriptorSets) == 0 {
+		return nil, errors.New("app: no *.proto or descriptor set files found")
+	}
+
+	fdset := &descriptorpb.FileDescriptorSet{}
+	if len(filenames) > 0 {
+		parsed, err := parseProtoFiles(importPaths, filenames)
+		if err != nil {
+			return nil, err
+		}
+		fdset.File = append(fdset.File, parsed.File...)
+	}
+
+	if len(descriptorSets) > 0 {
+		compiled, err := readDescriptorSets(descriptorSets)
+		if err != nil {
+			return nil, err
+		}
+		fdset.File = append(fdset.File, compiled.File...)
+	}
+
+	return newFiles(fdset)
+}
+
+func parseProtoFiles(importPaths, filenames []string) (*descriptorpb.FileDescriptorSet, error) {
 	f, err := protoparse.ResolveFilenames(importPaths, filenames...)
 	if err != nil {
 		return nil, err
@@ -69,7 +128,80 @@ func ProtoFilesFromDisk(importPaths, filenames []string) (*protoregistry.Files,
 		fdset.File = append(fdset.File, walkFileDescriptors(seen, fd)...)
 	}
 
-	return protodesc.NewFiles(fdset)
+	return fdset, nil
+}
+
+func readDescriptorSets(paths []string) (*descriptorpb.FileDescriptorSet, error) {
+	fdset := &descriptorpb.FileDescriptorSet{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		set, err := parseDescriptorSet(data)
+		if err != nil {
+			return nil, fmt.Errorf("app: failed to read descriptor set %s: %w", path, err)
+		}
+		fdset.File = append(fdset.File, set.File...)
+	}
+
+	return fdset, nil
+}
+
+// ProtoFilesFromDescriptorSet loads the content of a compiled FileDescriptorSet.
+func ProtoFilesFromDescriptorSet(data []byte) (*protoregistry.Files, error) {
+	fdset, err := parseDescriptorSet(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFiles(fdset)
+}
+
+func parseDescriptorSet(data []byte) (*descriptorpb.FileDescriptorSet, error) {
+	fdset := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, fdset); err != nil {
+		return nil, err
+	}
+
+	if len(fdset.File) == 0 {
+		return nil, errors.New("no files in descriptor set")
+	}
+
+	return fdset, nil
+}
+
+// newFiles builds the registry of the descriptor set, files that are in the set more than once are
+// kept once. descriptor sets built without --include_imports miss the well-known types, they are
+// taken from the ones linked in the app.
+func newFiles(fdset *descriptorpb.FileDescriptorSet) (*protoregistry.Files, error) {
+	out := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]struct{})
+	for _, fd := range fdset.File {
+		if _, ok := seen[fd.GetName()]; ok {
+			continue
+		}
+		seen[fd.GetName()] = struct{}{}
+		out.File = append(out.File, fd)
+	}
+
+	for i := 0; i < len(out.File); i++ {
+		for _, dep := range out.File[i].GetDependency() {
+			if _, ok := seen[dep]; ok {
+				continue
+			}
+
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(dep)
+			if err != nil {
+				return nil, fmt.Errorf("app: %s imports %s which is not in the descriptor set", out.File[i].GetName(), dep)
+			}
+			seen[dep] = struct{}{}
+			out.File = append(out.File, protodesc.ToFileDescriptorProto(fd))
+		}
+	}
+
+	return protodesc.NewFiles(out)
 }
 
 func walkFileDescriptors(seen map[string]struct{}, fd *desc.FileDescriptor) []*descriptorpb.FileDescriptorProto {
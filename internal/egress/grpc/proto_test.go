@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const echoProto = `syntax = "proto3";
+
+package test;
+
+import "google/protobuf/empty.proto";
+
+service Echo {
+  rpc Echo(google.protobuf.Empty) returns (google.protobuf.Empty);
+  rpc Stream(google.protobuf.Empty) returns (stream google.protobuf.Empty);
+}
+`
+
+func TestProtoFilesFromDescriptorSets(t *testing.T) {
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(map[string]string{"echo.proto": echoProto}),
+	}
+	fds, err := parser.ParseFiles("echo.proto")
+	require.NoError(t, err)
+
+	// like protoc --descriptor_set_out without --include_imports, the well-known types are not in the set
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{fds[0].AsFileDescriptorProto()},
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "echo.protoset")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	files, err := ProtoFilesFromDescriptorSets(path)
+	require.NoError(t, err)
+
+	services, err := (&Service{}).parseRegistryFiles(files)
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	require.Equal(t, "Echo", services[0].Name)
+	require.Equal(t, []domain.GRPCMethod{
+		{FullName: "/test.Echo/Echo", Name: "Echo"},
+		{FullName: "/test.Echo/Stream", Name: "Stream", IsStreamingServer: true},
+	}, services[0].Methods)
+
+	// descriptor sets of the workspace are loaded along with the files of the request
+	protoFile := domain.NewProtoFile("echo.protoset")
+	protoFile.Spec.Path = path
+	protoFile.Spec.IsDescriptorSet = true
+
+	files, err = ProtoFilesFromSources([]*domain.ProtoFile{protoFile}, nil)
+	require.NoError(t, err)
+	_, err = files.FindDescriptorByName("test.Echo")
+	require.NoError(t, err)
+
+	_, err = ProtoFilesFromDescriptorSet([]byte("not a descriptor set"))
+	require.Error(t, err)
+}
@@ -0,0 +1,273 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/variables"
+)
+
+// Stream is an open streaming call, the received messages are delivered on Messages until the call ends.
+// On client and bidi streams the messages are sent one by one with Send until CloseSend is called.
+type Stream struct {
+	stream grpc.ClientStream
+	conn   conn
+	md     protoreflect.MethodDescriptor
+	files  *protoregistry.Files
+	env    *domain.Environment
+	cancel context.CancelFunc
+
+	// the messages sent are in the body format of the request
+	bodyFormat string
+	bodyFile   string
+
+	requestMetadata metadata.MD
+	start           time.Time
+
+	messages chan domain.GRPCStreamMessage
+
+	// sendMx serializes the writes, grpc streams do not allow concurrent SendMsg calls
+	sendMx sync.Mutex
+
+	mx     sync.Mutex
+	result *egress.Response
+}
+
+// openClientStream starts the call of a client or bidi streaming method, the request body is not sent,
+// the stream stays open until it's half closed and the server ends the call or ctx is cancelled.
+func (s *Service) openClientStream(ctx context.Context, cancel context.CancelFunc, c *call) (*egress.Response, error) {
+	st, err := newStream(ctx, cancel, c)
+	if err != nil {
+		return nil, err
+	}
+
+	go st.recvLoop()
+
+	return &egress.Response{
+		RequestMetadata: domain.MetadataToKeyValue(st.requestMetadata),
+		StatueCode:      int(codes.OK),
+		Status:          codes.OK.String(),
+		MessageStream:   st,
+	}, nil
+}
+
+// openServerStream sends the request as the only message of a server streaming call, the returned
+// response carries the stream so the messages are read as they arrive.
+func (s *Service) openServerStream(ctx context.Context, cancel context.CancelFunc, c *call, req proto.Message) (*egress.Response, error) {
+	st, err := newStream(ctx, cancel, c)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := newStreamMessage(domain.GRPCMessageSent, req)
+	if err == nil {
+		err = st.stream.SendMsg(req)
+	}
+
+	// io.EOF means the server already ended the call, its status is returned by the receiving side
+	if err == nil || errors.Is(err, io.EOF) {
+		err = st.stream.CloseSend()
+	}
+
+	if err != nil {
+		st.cancel()
+		_ = st.conn.Close()
+		return nil, err
+	}
+
+	st.messages <- msg
+	go st.recvLoop()
+
+	return &egress.Response{
+		RequestMetadata: domain.MetadataToKeyValue(st.requestMetadata),
+		StatueCode:      int(codes.OK),
+		Status:          codes.OK.String(),
+		MessageStream:   st,
+	}, nil
+}
+
+// newStream starts the call, the stream takes over ctx and the connection of the call.
+func newStream(ctx context.Context, cancel context.CancelFunc, c *call) (*Stream, error) {
+	sd := &grpc.StreamDesc{
+		StreamName:    c.method,
+		ClientStreams: c.md.IsStreamingClient(),
+		ServerStreams: c.md.IsStreamingServer(),
+	}
+
+	stream, err := c.conn.NewStream(ctx, sd, c.method, c.callOptions()...)
+	if err != nil {
+		cancel()
+		_ = c.conn.Close()
+		return nil, err
+	}
+
+	requestMetadata, _ := metadata.FromOutgoingContext(ctx)
+
+	return &Stream{
+		stream:          stream,
+		conn:            c.conn,
+		md:              c.md,
+		files:           c.files,
+		env:             c.env,
+		cancel:          cancel,
+		bodyFormat:      c.spec.BodyFormat,
+		bodyFile:        c.spec.BodyFile,
+		requestMetadata: requestMetadata,
+		start:           time.Now(),
+		messages:        make(chan domain.GRPCStreamMessage, 64),
+	}, nil
+}
+
+// Messages returns the messages received from the server, server streams start with the request they
+// were opened with, it's closed once the call ends.
+func (st *Stream) Messages() <-chan domain.GRPCStreamMessage {
+	return st.messages
+}
+
+// Result returns the status, metadata and trailers of the call, it should be called after Messages is closed.
+func (st *Stream) Result() *egress.Response {
+	st.mx.Lock()
+	defer st.mx.Unlock()
+	return st.result
+}
+
+func (st *Stream) recvLoop() {
+	defer close(st.messages)
+	defer func() { _ = st.conn.Close() }()
+	defer st.cancel()
+
+	var (
+		err  error
+		size int
+		// last is the last message received, it's the body the post request of the stream checks
+		last domain.GRPCStreamMessage
+	)
+
+	for {
+		// the raw message keeps the wire bytes for the hex view
+		resp := &rawMessage{Message: dynamicpb.NewMessage(st.md.Output())}
+		if err = st.stream.RecvMsg(resp); err != nil {
+			break
+		}
+
+		var msg domain.GRPCStreamMessage
+		if msg, err = newStreamMessage(domain.GRPCMessageReceived, resp); err != nil {
+			break
+		}
+
+		size += msg.Size
+		last = msg
+		st.messages <- msg
+
+		// client streams end with their single response
+		if !st.md.IsStreamingServer() {
+			break
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+
+	// both are available once RecvMsg failed or the single response of a client stream is received
+	header, _ := st.stream.Header()
+	trailer := st.stream.Trailer()
+
+	st.mx.Lock()
+	defer st.mx.Unlock()
+	st.result = &egress.Response{
+		TimePassed:       time.Since(st.start),
+		ResponseMetadata: domain.MetadataToKeyValue(header),
+		RequestMetadata:  domain.MetadataToKeyValue(st.requestMetadata),
+		Trailers:         domain.MetadataToKeyValue(trailer),
+		Error:            err,
+		ErrorDetails:     errorDetails(st.files, err),
+		StatueCode:       int(status.Code(err)),
+		Status:           status.Code(err).String(),
+		Size:             size,
+	}
+	if last.Body != "" {
+		st.result.SetStreamBody(last.Body)
+	}
+}
+
+// Send sends the given message on the stream in the body format of the request, environment values and
+// variables are applied to it first. binary streams send the body file again.
+func (st *Stream) Send(body string) (domain.GRPCStreamMessage, error) {
+	spec := &domain.GRPCRequestSpec{Body: body, BodyFormat: st.bodyFormat, BodyFile: st.bodyFile}
+	variables.ApplyToGRPCRequest(nil, spec)
+	st.env.ApplyToGRPCRequest(spec)
+
+	req, err := newRequestMessage(spec, st.md.Input())
+	if err != nil {
+		return domain.GRPCStreamMessage{}, err
+	}
+
+	// the message is stamped before it's written so it's never shown after the response it caused
+	msg, err := newStreamMessage(domain.GRPCMessageSent, req)
+	if err != nil {
+		return domain.GRPCStreamMessage{}, err
+	}
+
+	st.sendMx.Lock()
+	defer st.sendMx.Unlock()
+
+	if err := st.stream.SendMsg(req); err != nil {
+		// the actual status of the call is returned by the receiving side
+		if errors.Is(err, io.EOF) {
+			return domain.GRPCStreamMessage{}, errors.New("the stream is already closed")
+		}
+		return domain.GRPCStreamMessage{}, err
+	}
+
+	return msg, nil
+}
+
+// CloseSend half closes the stream, the server is told no more messages are coming while the
+// responses are still received.
+func (st *Stream) CloseSend() error {
+	st.sendMx.Lock()
+	defer st.sendMx.Unlock()
+	return st.stream.CloseSend()
+}
+
+// Close cancels the call.
+func (st *Stream) Close() {
+	st.cancel()
+}
+
+// newStreamMessage returns the message in the same formats as the responses of unary calls.
+func newStreamMessage(direction string, msg proto.Message) (domain.GRPCStreamMessage, error) {
+	raw, ok := msg.(*rawMessage)
+	if !ok {
+		raw = &rawMessage{Message: msg}
+	}
+
+	body, text, hexDump, err := formatMessage(raw)
+	if err != nil {
+		return domain.GRPCStreamMessage{}, err
+	}
+
+	return domain.GRPCStreamMessage{
+		Direction: direction,
+		Body:      body,
+		Text:      text,
+		Hex:       hexDump,
+		Size:      proto.Size(raw.Message),
+		Time:      time.Now(),
+	}, nil
+}
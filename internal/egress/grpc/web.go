@@ -0,0 +1,871 @@
+package grpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/prefs"
+	"github.com/chapar-rest/chapar/internal/proxy"
+	"github.com/chapar-rest/chapar/version"
+)
+
+const (
+	// flagCompressed marks a compressed message, no compression is asked for so it's never expected.
+	flagCompressed = 0x01
+	// flagConnectEndStream marks the last message of a connect stream, it carries the status and trailers as json.
+	flagConnectEndStream = 0x02
+	// flagWebTrailers marks the trailers frame of a grpc-web response.
+	flagWebTrailers = 0x80
+
+	// defaultMaxRecvMsgSize is the largest message received when the request sets no limit, the same as grpc-go.
+	defaultMaxRecvMsgSize = 4 << 20
+)
+
+// httpConn makes the calls of the grpc-web and connect protocols over http, the messages are still
+// built from the method descriptors so the calls look the same as the native grpc ones.
+type httpConn struct {
+	protocol string
+	baseURL  string
+	client   *http.Client
+	// authority overrides the host header of the calls
+	authority string
+
+	// h2c is used for the bidi streams of plain text connect calls, they need http2 which net/http
+	// only negotiates over tls.
+	h2c *http.Client
+}
+
+func newHTTPConn(req *domain.GRPCRequestSpec, env *domain.Environment) (*httpConn, error) {
+	dialer, err := proxy.NewDialer(domain.ResolveProxy(prefs.GetGlobalConfig().Spec.General.Proxy, env, req.Settings.Proxy))
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy:             dialer.ProxyFunc(),
+		ForceAttemptHTTP2: true,
+		IdleConnTimeout:   idleConnTimeout,
+	}
+
+	c := &httpConn{
+		protocol:  req.Settings.Protocol,
+		baseURL:   baseURL(req.ServerInfo.Address, req.Settings.Insecure),
+		client:    &http.Client{Transport: transport},
+		authority: req.Settings.NameOverride,
+	}
+
+	if !req.Settings.Insecure {
+		transport.TLSClientConfig, err = tlsConfig(req.Settings)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		c.h2c = &http.Client{
+			Transport: &http2.Transport{
+				AllowHTTP:       true,
+				IdleConnTimeout: idleConnTimeout,
+				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+					return dialer.DialContext(ctx, network, addr)
+				},
+			},
+		}
+	}
+
+	return c, nil
+}
+
+// baseURL returns the url the method paths are appended to, the address may already have a scheme
+// when the server is behind a path, e.g. https://example.com/api.
+func baseURL(address string, insecure bool) string {
+	address = strings.TrimSuffix(address, "/")
+	if strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://") {
+		return address
+	}
+
+	if insecure {
+		return "http://" + address
+	}
+	return "https://" + address
+}
+
+func (c *httpConn) isConnect() bool {
+	return c.protocol == domain.GRPCProtocolConnect || c.protocol == domain.GRPCProtocolConnectProto
+}
+
+func (c *httpConn) contentType(streaming bool) string {
+	switch c.protocol {
+	case domain.GRPCProtocolWebText:
+		return "application/grpc-web-text+proto"
+	case domain.GRPCProtocolConnect:
+		if streaming {
+			return "application/connect+json"
+		}
+		return "application/json"
+	case domain.GRPCProtocolConnectProto:
+		if streaming {
+			return "application/connect+proto"
+		}
+		return "application/proto"
+	default:
+		return "application/grpc-web+proto"
+	}
+}
+
+func (c *httpConn) marshal(m any) ([]byte, error) {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto message", m)
+	}
+
+	if c.protocol == domain.GRPCProtocolConnect {
+		return protojson.Marshal(msg)
+	}
+
+	// the wire bytes of raw messages are sent as they are
+	if raw, ok := msg.(*rawMessage); ok && raw.data != nil {
+		return raw.data, nil
+	}
+	return proto.Marshal(msg)
+}
+
+func (c *httpConn) unmarshal(data []byte, m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto message", m)
+	}
+
+	if c.protocol == domain.GRPCProtocolConnect {
+		return (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, msg)
+	}
+
+	if raw, ok := msg.(*rawMessage); ok {
+		raw.data = data
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+// envelope frames the message with its flags and length, grpc-web-text frames are base64 encoded one by one.
+func (c *httpConn) envelope(flags byte, data []byte) []byte {
+	frame := make([]byte, 5+len(data))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(data)))
+	copy(frame[5:], data)
+
+	if c.protocol == domain.GRPCProtocolWebText {
+		return []byte(base64.StdEncoding.EncodeToString(frame))
+	}
+	return frame
+}
+
+// newRequest builds the http request of the call, the outgoing metadata of ctx are sent as headers.
+func (c *httpConn) newRequest(ctx context.Context, method string, body io.Reader, streaming bool) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+method, body)
+	if err != nil {
+		return nil, err
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	for key, values := range md {
+		for _, v := range values {
+			if strings.HasSuffix(key, "-bin") {
+				v = base64.RawStdEncoding.EncodeToString([]byte(v))
+			}
+			req.Header.Add(key, v)
+		}
+	}
+
+	if c.authority != "" {
+		req.Host = c.authority
+	}
+
+	req.Header.Set("Content-Type", c.contentType(streaming))
+	req.Header.Set("User-Agent", version.GetAgentName())
+
+	var timeout time.Duration
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		timeout = max(time.Until(deadline), time.Millisecond)
+	}
+
+	if c.isConnect() {
+		if !streaming {
+			req.Header.Set("Connect-Protocol-Version", "1")
+		}
+		if hasDeadline {
+			req.Header.Set("Connect-Timeout-Ms", strconv.FormatInt(timeout.Milliseconds(), 10))
+		}
+		return req, nil
+	}
+
+	req.Header.Set("Accept", c.contentType(streaming))
+	req.Header.Set("X-Grpc-Web", "1")
+	req.Header.Set("X-User-Agent", version.GetAgentName())
+	if hasDeadline {
+		req.Header.Set("Grpc-Timeout", encodeTimeout(timeout))
+	}
+
+	return req, nil
+}
+
+// encodeTimeout encodes the timeout the way grpc-timeout expects it, at most 8 digits and a unit.
+func encodeTimeout(t time.Duration) string {
+	if ms := t.Milliseconds(); ms < 100_000_000 {
+		return strconv.FormatInt(ms, 10) + "m"
+	}
+	return strconv.FormatInt(int64(t.Seconds()), 10) + "S"
+}
+
+// Invoke makes a unary call, connect sends unary messages as plain http bodies while grpc-web
+// frames them the same way streams do.
+func (c *httpConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if c.isConnect() {
+		return c.invokeConnect(ctx, method, args, reply, opts...)
+	}
+
+	st, err := c.NewStream(ctx, &grpc.StreamDesc{StreamName: method}, method, opts...)
+	if err != nil {
+		return err
+	}
+
+	if err := st.SendMsg(args); err != nil {
+		return err
+	}
+
+	if err := st.CloseSend(); err != nil {
+		return err
+	}
+
+	return st.RecvMsg(reply)
+}
+
+func (c *httpConn) invokeConnect(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	data, err := c.marshal(args)
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest(ctx, method, bytes.NewReader(data), false)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return transportError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	header, trailer := splitConnectTrailers(resp.Header)
+	setCallMetadata(opts, header, trailer)
+
+	maxSize := maxRecvMsgSize(opts)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return transportError(ctx, err)
+	}
+
+	if len(body) > maxSize {
+		return status.Errorf(codes.ResourceExhausted, "grpc: received message larger than max (%d)", maxSize)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var ce connectError
+		if err := json.Unmarshal(body, &ce); err == nil && ce.Code != "" {
+			return ce.status().Err()
+		}
+		return httpStatusError(resp)
+	}
+
+	if err := c.unmarshal(body, reply); err != nil {
+		return status.Errorf(codes.Internal, "failed to unmarshal the response: %v", err)
+	}
+
+	return nil
+}
+
+func (c *httpConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if desc.ClientStreams && !c.isConnect() {
+		return nil, status.Error(codes.Unimplemented, "grpc-web does not support client streaming calls")
+	}
+
+	st := &httpStream{
+		ctx:     ctx,
+		conn:    c,
+		desc:    desc,
+		method:  method,
+		opts:    opts,
+		ready:   make(chan struct{}),
+		maxSize: maxRecvMsgSize(opts),
+	}
+
+	// client streams write the messages to the request body as they are sent
+	if desc.ClientStreams {
+		client := c.client
+		if desc.ServerStreams && c.h2c != nil {
+			client = c.h2c
+		}
+
+		pr, pw := io.Pipe()
+		st.pw = pw
+		if err := st.start(client, pr); err != nil {
+			return nil, err
+		}
+	}
+
+	return st, nil
+}
+
+// Close does nothing, the client is shared by the calls to the same server and its idle connections
+// are closed by the transport.
+func (c *httpConn) Close() error {
+	return nil
+}
+
+func (c *httpConn) closeIdle() {
+	c.client.CloseIdleConnections()
+	if c.h2c != nil {
+		c.h2c.CloseIdleConnections()
+	}
+}
+
+// httpStream is a streaming call of the grpc-web or connect protocols, calls without client streaming
+// buffer their single message and send the request once the stream is half closed.
+type httpStream struct {
+	ctx    context.Context
+	conn   *httpConn
+	desc   *grpc.StreamDesc
+	method string
+	opts   []grpc.CallOption
+
+	pw      *io.PipeWriter
+	buf     bytes.Buffer
+	started bool
+
+	// ready is closed once the response headers arrived or the request failed
+	ready   chan struct{}
+	resp    *http.Response
+	respErr error
+
+	received bool
+	body     io.Reader
+	messages int
+	// maxSize is the largest message the stream accepts
+	maxSize int
+
+	finished bool
+	trailer  metadata.MD
+	err      error
+}
+
+func (st *httpStream) start(client *http.Client, body io.Reader) error {
+	req, err := st.conn.newRequest(st.ctx, st.method, body, true)
+	if err != nil {
+		return err
+	}
+
+	st.started = true
+	go func() {
+		st.resp, st.respErr = client.Do(req)
+		close(st.ready)
+	}()
+
+	return nil
+}
+
+func (st *httpStream) SendMsg(m any) error {
+	data, err := st.conn.marshal(m)
+	if err != nil {
+		return err
+	}
+
+	frame := st.conn.envelope(0, data)
+	if st.pw == nil {
+		st.buf.Write(frame)
+		return nil
+	}
+
+	// the request is over, its status is returned by RecvMsg
+	if _, err := st.pw.Write(frame); err != nil {
+		return io.EOF
+	}
+
+	return nil
+}
+
+func (st *httpStream) CloseSend() error {
+	if st.pw != nil {
+		return st.pw.Close()
+	}
+
+	if st.started {
+		return nil
+	}
+
+	return st.start(st.conn.client, bytes.NewReader(st.buf.Bytes()))
+}
+
+func (st *httpStream) Header() (metadata.MD, error) {
+	select {
+	case <-st.ready:
+	case <-st.ctx.Done():
+		return nil, status.FromContextError(st.ctx.Err()).Err()
+	}
+
+	if st.respErr != nil {
+		return nil, transportError(st.ctx, st.respErr)
+	}
+
+	return metadataFromHeader(st.resp.Header), nil
+}
+
+func (st *httpStream) Trailer() metadata.MD {
+	return st.trailer
+}
+
+func (st *httpStream) Context() context.Context {
+	return st.ctx
+}
+
+func (st *httpStream) RecvMsg(m any) error {
+	if !st.received {
+		st.received = true
+		st.readResponse()
+	}
+
+	data, ok := st.next()
+	if !ok {
+		if st.err == nil && !st.desc.ServerStreams && st.messages == 0 {
+			return status.Error(codes.Internal, "the server sent no response message")
+		}
+		if st.err == nil {
+			return io.EOF
+		}
+		return st.err
+	}
+
+	if err := st.conn.unmarshal(data, m); err != nil {
+		st.finish(nil, status.Errorf(codes.Internal, "failed to unmarshal the response: %v", err))
+		return st.err
+	}
+	st.messages++
+
+	// calls with a single response end with it, the status comes right after the message
+	if !st.desc.ServerStreams {
+		if _, ok := st.next(); ok {
+			st.finish(nil, status.Error(codes.Internal, "the server sent more than one response message"))
+		}
+		return st.err
+	}
+
+	return nil
+}
+
+// readResponse waits for the response headers, responses that are not successful end the call right away.
+func (st *httpStream) readResponse() {
+	select {
+	case <-st.ready:
+	case <-st.ctx.Done():
+		st.finish(nil, status.FromContextError(st.ctx.Err()).Err())
+		return
+	}
+
+	if st.respErr != nil {
+		st.finish(nil, transportError(st.ctx, st.respErr))
+		return
+	}
+
+	header := metadataFromHeader(st.resp.Header)
+	if st.resp.StatusCode != http.StatusOK {
+		if s, ok := statusFromMetadata(header); ok && !st.conn.isConnect() {
+			st.finish(nil, s.Err())
+			return
+		}
+		st.finish(nil, httpStatusError(st.resp))
+		return
+	}
+
+	st.body = st.resp.Body
+	if st.conn.protocol == domain.GRPCProtocolWebText {
+		st.body = &base64Reader{r: bufio.NewReader(st.resp.Body)}
+	}
+
+	// a trailers-only response carries the status in the headers and has no messages
+	if !st.conn.isConnect() {
+		if _, ok := statusFromMetadata(header); ok {
+			st.finishWeb(header)
+		}
+	}
+}
+
+// next reads the next message of the response, it returns false once the call ended.
+func (st *httpStream) next() ([]byte, bool) {
+	if st.finished {
+		return nil, false
+	}
+
+	flags, data, err := readEnvelope(st.body, st.maxSize)
+	switch {
+	case errors.Is(err, io.EOF):
+		st.finishAtEOF()
+	case status.Code(err) == codes.ResourceExhausted:
+		st.finish(nil, err)
+	case err != nil:
+		st.finish(nil, transportError(st.ctx, err))
+	case flags&flagCompressed != 0:
+		st.finish(nil, status.Error(codes.Internal, "compressed messages are not supported"))
+	case st.conn.isConnect() && flags&flagConnectEndStream != 0:
+		st.finishConnect(data)
+	case !st.conn.isConnect() && flags&flagWebTrailers != 0:
+		st.finishWeb(parseWebTrailers(data))
+	default:
+		return data, true
+	}
+
+	return nil, false
+}
+
+// finishAtEOF ends a call whose body ended without the status, grpc-web servers behind http2 may
+// still send it as http trailers.
+func (st *httpStream) finishAtEOF() {
+	if !st.conn.isConnect() {
+		trailer := metadataFromHeader(st.resp.Trailer)
+		if _, ok := statusFromMetadata(trailer); ok {
+			st.finishWeb(trailer)
+			return
+		}
+	}
+
+	st.finish(nil, status.Error(codes.Internal, "the server closed the stream without sending the status"))
+}
+
+func (st *httpStream) finishWeb(trailer metadata.MD) {
+	s, ok := statusFromMetadata(trailer)
+	if !ok {
+		s = status.New(codes.Internal, "the trailers of the call carry no status")
+	}
+
+	trailer.Delete("grpc-status")
+	trailer.Delete("grpc-message")
+	trailer.Delete("grpc-status-details-bin")
+	st.finish(trailer, s.Err())
+}
+
+func (st *httpStream) finishConnect(data []byte) {
+	var end struct {
+		Error    *connectError       `json:"error"`
+		Metadata map[string][]string `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &end); err != nil {
+		st.finish(nil, status.Errorf(codes.Internal, "malformed end of stream message: %v", err))
+		return
+	}
+
+	trailer := metadata.MD{}
+	for key, values := range end.Metadata {
+		key = strings.ToLower(key)
+		for _, v := range values {
+			trailer.Append(key, decodeMetadataValue(key, v))
+		}
+	}
+
+	var err error
+	if end.Error != nil {
+		err = end.Error.status().Err()
+	}
+	st.finish(trailer, err)
+}
+
+func (st *httpStream) finish(trailer metadata.MD, err error) {
+	st.finished = true
+	st.trailer = trailer
+	st.err = err
+
+	// unblocks the senders of client streams
+	if st.pw != nil {
+		_ = st.pw.CloseWithError(io.EOF)
+	}
+
+	// the call may end on cancellation before the response arrived
+	var header metadata.MD
+	select {
+	case <-st.ready:
+		if st.resp != nil {
+			header = metadataFromHeader(st.resp.Header)
+			_ = st.resp.Body.Close()
+		}
+	default:
+	}
+
+	setCallMetadata(st.opts, header, trailer)
+}
+
+// setCallMetadata fills the header and trailer call options the same way a native call does.
+func setCallMetadata(opts []grpc.CallOption, header, trailer metadata.MD) {
+	for _, o := range opts {
+		switch o := o.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = header
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = trailer
+		}
+	}
+}
+
+// maxRecvMsgSize returns the largest message a call accepts from its options.
+func maxRecvMsgSize(opts []grpc.CallOption) int {
+	size := defaultMaxRecvMsgSize
+	for _, o := range opts {
+		if o, ok := o.(grpc.MaxRecvMsgSizeCallOption); ok {
+			size = o.MaxRecvMsgSize
+		}
+	}
+	return size
+}
+
+// readEnvelope reads the next length prefixed message, messages larger than maxSize are refused
+// before they are allocated.
+func readEnvelope(r io.Reader, maxSize int) (byte, []byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if uint64(size) > uint64(maxSize) {
+		return 0, nil, status.Errorf(codes.ResourceExhausted, "grpc: received message larger than max (%d vs. %d)", size, maxSize)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+
+	return prefix[0], data, nil
+}
+
+// base64Reader decodes a grpc-web-text body, each frame is encoded on its own so padding may show up
+// in the middle of the body, the groups of four characters are decoded one by one.
+type base64Reader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func (b *base64Reader) Read(p []byte) (int, error) {
+	for len(b.buf) == 0 {
+		var group [4]byte
+		for n := 0; n < len(group); {
+			c, err := b.r.ReadByte()
+			if err != nil {
+				if errors.Is(err, io.EOF) && n > 0 {
+					err = io.ErrUnexpectedEOF
+				}
+				return 0, err
+			}
+
+			if c == '\r' || c == '\n' {
+				continue
+			}
+			group[n] = c
+			n++
+		}
+
+		var out [3]byte
+		n, err := base64.StdEncoding.Decode(out[:], group[:])
+		if err != nil {
+			return 0, err
+		}
+		b.buf = append(b.buf[:0], out[:n]...)
+	}
+
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+// parseWebTrailers parses the trailers frame of grpc-web, it's formatted as http/1 header lines.
+func parseWebTrailers(data []byte) metadata.MD {
+	md := metadata.MD{}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimRight(line, "\r"), ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		md.Append(key, decodeMetadataValue(key, strings.TrimSpace(value)))
+	}
+	return md
+}
+
+func metadataFromHeader(h http.Header) metadata.MD {
+	md := metadata.MD{}
+	for k, values := range h {
+		key := strings.ToLower(k)
+		for _, v := range values {
+			md.Append(key, decodeMetadataValue(key, v))
+		}
+	}
+	return md
+}
+
+// splitConnectTrailers splits the headers of a connect unary response, trailers are sent as headers
+// prefixed with Trailer-.
+func splitConnectTrailers(h http.Header) (metadata.MD, metadata.MD) {
+	header, trailer := metadata.MD{}, metadata.MD{}
+	for key, values := range metadataFromHeader(h) {
+		if name, ok := strings.CutPrefix(key, "trailer-"); ok {
+			trailer.Append(name, values...)
+			continue
+		}
+		header.Append(key, values...)
+	}
+	return header, trailer
+}
+
+// decodeMetadataValue decodes the base64 value of binary metadata, servers may send it with or without padding.
+func decodeMetadataValue(key, value string) string {
+	if !strings.HasSuffix(key, "-bin") {
+		return value
+	}
+
+	if b, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "=")); err == nil {
+		return string(b)
+	}
+	return value
+}
+
+// statusFromMetadata reads the status of a grpc-web call from its trailers, ok is false when
+// they carry no status.
+func statusFromMetadata(md metadata.MD) (*status.Status, bool) {
+	values := md.Get("grpc-status")
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	code, err := strconv.Atoi(values[0])
+	if err != nil {
+		return status.Newf(codes.Internal, "malformed grpc-status %q", values[0]), true
+	}
+
+	if details := md.Get("grpc-status-details-bin"); len(details) > 0 {
+		p := &spb.Status{}
+		if err := proto.Unmarshal([]byte(details[0]), p); err == nil && p.Code == int32(code) {
+			return status.FromProto(p), true
+		}
+	}
+
+	var msg string
+	if values := md.Get("grpc-message"); len(values) > 0 {
+		// the message is percent encoded
+		if msg, err = url.PathUnescape(values[0]); err != nil {
+			msg = values[0]
+		}
+	}
+
+	return status.New(codes.Code(code), msg), true
+}
+
+// connectCodes are the names connect uses for the status codes, indexed by the code.
+var connectCodes = []string{
+	"ok",
+	"canceled",
+	"unknown",
+	"invalid_argument",
+	"deadline_exceeded",
+	"not_found",
+	"already_exists",
+	"permission_denied",
+	"resource_exhausted",
+	"failed_precondition",
+	"aborted",
+	"out_of_range",
+	"unimplemented",
+	"internal",
+	"unavailable",
+	"data_loss",
+	"unauthenticated",
+}
+
+// connectError is the json error of connect calls, the details are base64 encoded proto messages.
+type connectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details []struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"details"`
+}
+
+func (e *connectError) status() *status.Status {
+	code := codes.Unknown
+	for i, name := range connectCodes {
+		if i > 0 && name == e.Code {
+			code = codes.Code(i)
+		}
+	}
+
+	p := &spb.Status{Code: int32(code), Message: e.Message}
+	for _, d := range e.Details {
+		value, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(d.Value, "="))
+		if err != nil {
+			continue
+		}
+		p.Details = append(p.Details, &anypb.Any{TypeUrl: "type.googleapis.com/" + d.Type, Value: value})
+	}
+
+	return status.FromProto(p)
+}
+
+// httpStatusError maps the http status of a failed response to a grpc status.
+func httpStatusError(resp *http.Response) error {
+	code := codes.Unknown
+	switch resp.StatusCode {
+	case http.StatusBadRequest:
+		code = codes.Internal
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		code = codes.Unavailable
+	}
+
+	return status.Errorf(code, "unexpected http status %s", resp.Status)
+}
+
+func transportError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
@@ -0,0 +1,149 @@
+package grpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestHTTPConnGRPCWebText(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/test.Echo/Echo", r.URL.Path)
+		require.Equal(t, "application/grpc-web-text+proto", r.Header.Get("Content-Type"))
+		require.Equal(t, "value", r.Header.Get("x-key"))
+
+		body, err := io.ReadAll(&base64Reader{r: bufio.NewReader(r.Body)})
+		require.NoError(t, err)
+		_, data, err := readEnvelope(strings.NewReader(string(body)), defaultMaxRecvMsgSize)
+		require.NoError(t, err)
+
+		req := &wrapperspb.StringValue{}
+		require.NoError(t, proto.Unmarshal(data, req))
+
+		resp, err := proto.Marshal(wrapperspb.String("echo " + req.Value))
+		require.NoError(t, err)
+
+		c := &httpConn{protocol: domain.GRPCProtocolWebText}
+		w.Header().Set("Content-Type", "application/grpc-web-text+proto")
+		w.Header().Set("x-header", "h")
+		_, _ = w.Write(c.envelope(0, resp))
+		_, _ = w.Write(c.envelope(flagWebTrailers, []byte("grpc-status: 0\r\nx-trailer: t\r\n")))
+	}))
+	defer srv.Close()
+
+	c := &httpConn{protocol: domain.GRPCProtocolWebText, baseURL: srv.URL, client: srv.Client()}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-key", "value")
+	var header, trailer metadata.MD
+	reply := &wrapperspb.StringValue{}
+	err := c.Invoke(ctx, "/test.Echo/Echo", wrapperspb.String("hello"), reply, grpc.Header(&header), grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	require.Equal(t, "echo hello", reply.Value)
+	require.Equal(t, []string{"h"}, header.Get("x-header"))
+	require.Equal(t, []string{"t"}, trailer.Get("x-trailer"))
+	require.Empty(t, trailer.Get("grpc-status"))
+}
+
+func TestHTTPConnGRPCWebTrailersOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("grpc-status", "5")
+		w.Header().Set("grpc-message", "not%20found")
+	}))
+	defer srv.Close()
+
+	c := &httpConn{protocol: domain.GRPCProtocolWeb, baseURL: srv.URL, client: srv.Client()}
+	err := c.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String("hello"), &wrapperspb.StringValue{})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Equal(t, "not found", status.Convert(err).Message())
+}
+
+func TestHTTPConnConnectError(t *testing.T) {
+	info, err := proto.Marshal(wrapperspb.String("NO_USER"))
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, "1", r.Header.Get("Connect-Protocol-Version"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Trailer-X-Trailer", "t")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":"invalid_argument","message":"bad name","details":[{"type":"google.protobuf.StringValue","value":"` +
+			base64.RawStdEncoding.EncodeToString(info) + `"}]}`))
+	}))
+	defer srv.Close()
+
+	c := &httpConn{protocol: domain.GRPCProtocolConnect, baseURL: srv.URL, client: srv.Client()}
+
+	var trailer metadata.MD
+	err = c.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String("hello"), &wrapperspb.StringValue{}, grpc.Trailer(&trailer))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, "bad name", status.Convert(err).Message())
+	require.Len(t, status.Convert(err).Details(), 1)
+	require.Equal(t, []string{"t"}, trailer.Get("x-trailer"))
+}
+
+func TestHTTPConnMaxRecvMsgSize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		// only the prefix is sent, the length alone must be refused
+		_, _ = w.Write([]byte{0, 0xff, 0xff, 0xff, 0xff})
+	}))
+	defer srv.Close()
+
+	c := &httpConn{protocol: domain.GRPCProtocolWeb, baseURL: srv.URL, client: srv.Client()}
+	err := c.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String("hello"), &wrapperspb.StringValue{})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	err = c.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String("hello"), &wrapperspb.StringValue{}, grpc.MaxCallRecvMsgSize(1))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Contains(t, status.Convert(err).Message(), "vs. 1")
+}
+
+func TestHTTPConnConnectServerStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/connect+json", r.Header.Get("Content-Type"))
+
+		c := &httpConn{protocol: domain.GRPCProtocolConnect}
+		w.Header().Set("Content-Type", "application/connect+json")
+		_, _ = w.Write(c.envelope(0, []byte(`"one"`)))
+		_, _ = w.Write(c.envelope(0, []byte(`"two"`)))
+		_, _ = w.Write(c.envelope(flagConnectEndStream, []byte(`{"metadata":{"x-trailer":["t"]}}`)))
+	}))
+	defer srv.Close()
+
+	c := &httpConn{protocol: domain.GRPCProtocolConnect, baseURL: srv.URL, client: srv.Client()}
+
+	st, err := c.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, "/test.Echo/Stream")
+	require.NoError(t, err)
+	require.NoError(t, st.SendMsg(wrapperspb.String("hello")))
+	require.NoError(t, st.CloseSend())
+
+	var got []string
+	for {
+		msg := &wrapperspb.StringValue{}
+		if err := st.RecvMsg(msg); err != nil {
+			require.ErrorIs(t, err, io.EOF)
+			break
+		}
+		got = append(got, msg.Value)
+	}
+
+	require.Equal(t, []string{"one", "two"}, got)
+	require.Equal(t, []string{"t"}, st.Trailer().Get("x-trailer"))
+}
@@ -2,8 +2,10 @@ package egress
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/chapar-rest/chapar/internal/domain"
@@ -11,7 +13,9 @@ import (
 	"github.com/chapar-rest/chapar/internal/logger"
 	"github.com/chapar-rest/chapar/internal/prefs"
 	"github.com/chapar-rest/chapar/internal/scripting"
+	"github.com/chapar-rest/chapar/internal/sse"
 	"github.com/chapar-rest/chapar/internal/state"
+	"github.com/chapar-rest/chapar/internal/util"
 	"github.com/chapar-rest/chapar/ui/notifications"
 	"golang.org/x/sync/errgroup"
 )
@@ -22,6 +26,7 @@ type Response struct {
 	ResponseHeaders map[string]string
 	RequestHeaders  map[string]string
 	Cookies         []*http.Cookie
+	Timing          domain.Timing
 
 	// grpc
 	RequestMetadata  []domain.KeyValue
@@ -29,6 +34,8 @@ type Response struct {
 	Trailers         []domain.KeyValue
 	Size             int
 	Error            error
+	// ErrorDetails are the details of the status error as a json array.
+	ErrorDetails string
 
 	StatueCode int
 	Status     string
@@ -37,10 +44,114 @@ type Response struct {
 	TimePassed time.Duration
 	IsJSON     bool
 	JSON       string
+	// ProtoText and HexDump are the grpc response in the proto text format and its hex dumped wire bytes.
+	ProtoText string
+	HexDump   string
+
+	// EventStream is set instead of Body for text/event-stream responses, the caller reads the
+	// events as they arrive and has to close it when it's done.
+	EventStream *sse.Stream
+
+	// MessageStream is set instead of Body for grpc server streaming calls and the client streams opened
+	// with WithClientStream, the caller reads the messages as they arrive until the call ends.
+	MessageStream MessageStream
+
+	// SubscriptionStream is set instead of Body for graphql subscriptions, the caller reads the
+	// payloads as they arrive until the subscription ends.
+	SubscriptionStream SubscriptionStream
+
+	// Tests are the results of the tests of the post request script, if any.
+	Tests []domain.TestResult
+}
+
+// MessageStream is a grpc streaming call whose messages are read as they are sent and received.
+type MessageStream interface {
+	// Messages returns the messages of the call, it's closed once the call ends.
+	Messages() <-chan domain.GRPCStreamMessage
+	// Result returns the status, metadata and trailers of the call with the last message received as its body,
+	// it's nil until Messages is closed.
+	Result() *Response
+	// Close cancels the call.
+	Close()
+}
+
+// SubscriptionStream is a running graphql subscription.
+type SubscriptionStream interface {
+	// Messages returns the payloads sent by the server, it's closed once the subscription ends.
+	Messages() <-chan domain.GraphQLSubscriptionMessage
+	// Err returns the reason the subscription ended, it's nil when the server completed it or it was closed.
+	// it should be called after Messages is closed.
+	Err() error
+	// Close stops the subscription.
+	Close()
+}
+
+// ErrCancelled is returned by Send when the request context was cancelled before a response was received.
+var ErrCancelled = errors.New("request cancelled")
+
+type lastEventIDKey struct{}
+
+// WithLastEventID returns a copy of ctx which makes the request resume an event stream from the given event id.
+func WithLastEventID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, lastEventIDKey{}, id)
+}
+
+// LastEventID returns the event id set by WithLastEventID, if any.
+func LastEventID(ctx context.Context) string {
+	id, _ := ctx.Value(lastEventIDKey{}).(string)
+	return id
+}
+
+type clientStreamKey struct{}
+
+// WithClientStream returns a copy of ctx which makes the grpc sender leave the client or bidi stream of the
+// request with the given id open for the messages of the user, instead of sending the request body as its
+// only message.
+func WithClientStream(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, clientStreamKey{}, requestID)
+}
+
+// ClientStream reports whether the client stream of the request with the given id is to be left open,
+// see WithClientStream.
+func ClientStream(ctx context.Context, requestID string) bool {
+	id, ok := ctx.Value(clientStreamKey{}).(string)
+	return ok && id == requestID
+}
+
+type scriptRequestKey struct{}
+
+// scriptRequest is the request as a pre request script left it.
+type scriptRequest struct {
+	requestID string
+	req       *domain.Request
+}
+
+// WithScriptRequest returns a copy of ctx which makes the sender send req, the resolved request of the given id
+// as a pre request script left it.
+func WithScriptRequest(ctx context.Context, requestID string, req *domain.Request) context.Context {
+	return context.WithValue(ctx, scriptRequestKey{}, scriptRequest{requestID: requestID, req: req})
+}
+
+// ScriptRequest returns a copy of the request set by WithScriptRequest for the request with the given id, senders
+// send it as it is in place of resolving the stored request. nil means there is no such request.
+func ScriptRequest(ctx context.Context, requestID string) *domain.Request {
+	sr, ok := ctx.Value(scriptRequestKey{}).(scriptRequest)
+	if !ok || sr.requestID != requestID {
+		return nil
+	}
+	return sr.req.Clone()
 }
 
 type Sender interface {
-	SendRequest(requestID, activeEnvironmentID string) (*Response, error)
+	SendRequest(ctx context.Context, requestID, activeEnvironmentID string) (*Response, error)
+}
+
+// Resolver is implemented by the senders which resolve the request before sending it, pre request scripts
+// get the resolved request so they see what is going to be sent.
+type Resolver interface {
+	// ResolveRequest returns a clone of the request with what it inherits from its collection merged in
+	// and the variables and the values of the active environment applied.
+	ResolveRequest(requestID, activeEnvironmentID string) (*domain.Request, error)
 }
 
 type Service struct {
@@ -69,61 +180,165 @@ func (s *Service) SetExecutor(executor scripting.Executor) {
 	s.scriptExecutor = executor
 }
 
-func (s *Service) Send(id, activeEnvironmentID string) (any, error) {
+func (s *Service) Send(ctx context.Context, id, activeEnvironmentID string) (any, error) {
 	req := s.requests.GetRequest(id)
 	if req == nil {
 		return nil, fmt.Errorf("request with id %s not found", id)
 	}
 
-	if err := s.preRequest(req, activeEnvironmentID); err != nil {
+	activeEnvironment, err := s.environment(activeEnvironmentID)
+	if err != nil {
 		return nil, err
 	}
 
+	ctx, err = s.preRequest(ctx, req, activeEnvironment)
+	if err != nil {
+		return nil, cancelledOr(ctx, err)
+	}
+
 	var res *Response
-	var err error
 
 	sender, ok := s.senders[req.MetaData.Type]
 	if !ok {
 		return nil, fmt.Errorf("unknown request type: %s", req.MetaData.Type)
 	}
 
-	res, err = sender.SendRequest(req.MetaData.ID, activeEnvironmentID)
-	if err != nil {
-		return nil, err
+	res, err = sender.SendRequest(ctx, req.MetaData.ID, activeEnvironmentID)
+	if err != nil && (res == nil || ctx.Err() != nil) {
+		return nil, cancelledOr(ctx, err)
 	}
 
-	var activeEnvironment *domain.Environment
-	// Get environment if provided
-	if activeEnvironmentID != "" {
-		activeEnvironment = s.environments.GetEnvironment(activeEnvironmentID)
-		if activeEnvironment == nil {
-			return nil, fmt.Errorf("environment with id %s not found", activeEnvironmentID)
-		}
+	// the post request of streams runs once they end, see FinishStream
+	if res.hasStream() {
+		return res, err
 	}
 
-	if err := s.postRequest(req, res, activeEnvironment); err != nil {
-		return nil, err
+	// failed grpc calls come with their response, the post request still runs so their error details can be extracted
+	if err := s.postRequest(ctx, req, res, activeEnvironment); err != nil {
+		return nil, cancelledOr(ctx, err)
 	}
 
 	return res, err
 }
 
-func (s *Service) preRequest(req *domain.Request, activeEnvironmentID string) error {
+// FinishStream runs the post request of a request whose response is a stream, Send leaves it to the caller as
+// only the caller knows when the stream ends. res is the response of the ended stream with the last message
+// received as its body, the results of the tests of the post request script are set on it.
+func (s *Service) FinishStream(ctx context.Context, id, activeEnvironmentID string, res *Response) error {
+	req := s.requests.GetRequest(id)
+	if req == nil {
+		return fmt.Errorf("request with id %s not found", id)
+	}
+
+	activeEnvironment, err := s.environment(activeEnvironmentID)
+	if err != nil {
+		return err
+	}
+
+	// streams are mostly ended by the user cancelling the request, the post request runs all the same
+	return s.postRequest(context.WithoutCancel(ctx), req, res, activeEnvironment)
+}
+
+// environment returns the environment of id, nil when id is empty.
+func (s *Service) environment(id string) (*domain.Environment, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	env := s.environments.GetEnvironment(id)
+	if env == nil {
+		return nil, fmt.Errorf("environment with id %s not found", id)
+	}
+	return env, nil
+}
+
+// cancelledOr returns ErrCancelled if the given context has been cancelled, otherwise it returns err as is.
+func cancelledOr(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ErrCancelled
+	}
+	return err
+}
+
+// preRequest runs the pre request of req, the returned context carries the changes a script made to the request.
+func (s *Service) preRequest(ctx context.Context, req *domain.Request, env *domain.Environment) (context.Context, error) {
 	preReq := req.Spec.GetPreRequest()
 	if !domain.DoablePreRequest(preReq) {
-		return nil
+		return ctx, nil
+	}
+
+	var envID string
+	if env != nil {
+		envID = env.ID()
+	}
+
+	if domain.IsScriptType(preReq.Type) {
+		resolved, err := s.resolveRequest(req, envID)
+		if err != nil {
+			return ctx, err
+		}
+
+		result, err := s.executeScript(ctx, preReq.Type, preReq.Script, resolved, nil, env)
+		if err != nil || result == nil || result.Req == nil {
+			return ctx, err
+		}
+
+		scripting.ApplyRequestData(result.Req, resolved)
+		return WithScriptRequest(ctx, req.MetaData.ID, resolved), nil
 	}
 
-	// for now we only support trigger request
 	if preReq.TriggerRequest == nil {
-		return nil
+		return ctx, nil
 	}
 
-	_, err := s.Send(preReq.TriggerRequest.RequestID, activeEnvironmentID)
-	return err
+	// the triggered request is not the stream being resumed
+	res, err := s.Send(WithLastEventID(ctx, ""), preReq.TriggerRequest.RequestID, envID)
+	if r, ok := res.(*Response); ok {
+		// nobody is going to read the events or messages of a pre request
+		r.closeStreams()
+	}
+	return ctx, err
 }
 
-func (s *Service) postRequest(req *domain.Request, res *Response, env *domain.Environment) error {
+// resolveRequest returns the request the way its sender is going to send it, a clone of req when the sender
+// doesn't resolve requests.
+func (s *Service) resolveRequest(req *domain.Request, envID string) (*domain.Request, error) {
+	resolver, ok := s.senders[req.MetaData.Type].(Resolver)
+	if !ok {
+		return req.Clone(), nil
+	}
+	return resolver.ResolveRequest(req.MetaData.ID, envID)
+}
+
+// hasStream reports whether the response is a stream which is read by the caller.
+func (r *Response) hasStream() bool {
+	return r != nil && (r.EventStream != nil || r.MessageStream != nil || r.SubscriptionStream != nil)
+}
+
+// SetStreamBody sets the last message of an ended stream as the body of the response, it's what the post
+// request of the stream checks.
+func (r *Response) SetStreamBody(body string) {
+	r.Body = []byte(body)
+	r.IsJSON = util.IsJSON(body)
+	if r.IsJSON {
+		r.JSON = body
+	}
+}
+
+// closeStreams closes the streams of the response, if any.
+func (r *Response) closeStreams() {
+	if r.EventStream != nil {
+		_ = r.EventStream.Close()
+	}
+	if r.MessageStream != nil {
+		r.MessageStream.Close()
+	}
+	if r.SubscriptionStream != nil {
+		r.SubscriptionStream.Close()
+	}
+}
+
+func (s *Service) postRequest(ctx context.Context, req *domain.Request, res *Response, env *domain.Environment) error {
 	postReq := req.Spec.GetPostRequest()
 	if !domain.DoablePostRequest(postReq) {
 		return nil
@@ -136,9 +351,14 @@ func (s *Service) postRequest(req *domain.Request, res *Response, env *domain.En
 
 	// if any script is provided, execute it
 	if postReq.Script != "" {
-		if err := s.executeScript(postReq.Script, req, res, env); err != nil {
+		result, err := s.executeScript(ctx, postReq.Type, postReq.Script, req, scriptResponse(req, res), env)
+		if err != nil {
 			return err
 		}
+
+		if result != nil {
+			res.Tests = result.Tests
+		}
 	}
 
 	if err := s.handlePostRequestSetEnv(postReq, res, env); err != nil {
@@ -148,6 +368,34 @@ func (s *Service) postRequest(req *domain.Request, res *Response, env *domain.En
 	return nil
 }
 
+// scriptResponse returns the response as scripts see it, grpc calls have their status code and metadata
+// in place of the http ones.
+func scriptResponse(req *domain.Request, res *Response) *scripting.ResponseData {
+	if req.MetaData.Type != domain.RequestTypeGRPC {
+		return &scripting.ResponseData{
+			StatusCode: res.StatusCode,
+			Headers:    res.ResponseHeaders,
+			Body:       res.JSON,
+		}
+	}
+
+	headers := make(map[string]string, len(res.ResponseMetadata))
+	for _, kv := range res.ResponseMetadata {
+		headers[kv.Key] = kv.Value
+	}
+
+	body := res.JSON
+	if body == "" {
+		body = string(res.Body)
+	}
+
+	return &scripting.ResponseData{
+		StatusCode: res.StatueCode,
+		Headers:    headers,
+		Body:       body,
+	}
+}
+
 func (s *Service) extactVariables(settings []domain.Variable, response *Response, env *domain.Environment) error {
 	if settings == nil || response == nil || env == nil {
 		return nil
@@ -215,6 +463,22 @@ func (s *Service) extactVariables(settings []domain.Variable, response *Response
 					}
 				}
 			}
+		case domain.VariableFromErrorDetails:
+			if response.ErrorDetails == "" {
+				return nil
+			}
+
+			data, err := jsonpath.Get(response.ErrorDetails, v.JsonPath)
+			if err != nil {
+				return err
+			}
+
+			if result, ok := data.(string); ok {
+				env.SetKey(v.TargetEnvVariable, result)
+				if err := s.environments.UpdateEnvironment(env, state.SourceGRPCService, false); err != nil {
+					return err
+				}
+			}
 		}
 
 		return nil
@@ -254,30 +518,34 @@ func (s *Service) handlePostRequestSetEnv(postReq domain.PostRequest, res *Respo
 		return s.handlePostRequestFromMetaData(postReq, res, env)
 	case domain.PostRequestSetFromResponseTrailers:
 		return s.handlePostRequestFromTrailers(postReq, res, env)
+	case domain.PostRequestSetFromResponseErrorDetails:
+		return s.handlePostRequestFromErrorDetails(postReq, res, env)
 	}
 	return nil
 }
 
-func (s *Service) executeScript(script string, request *domain.Request, resp *Response, env *domain.Environment) error {
+// executeScript runs the script and applies the environment variables it set, resp is nil for pre request scripts.
+func (s *Service) executeScript(ctx context.Context, scriptType, script string, request *domain.Request, resp *scripting.ResponseData, env *domain.Environment) (*scripting.ExecResult, error) {
 	if !prefs.GetGlobalConfig().Spec.Scripting.Enabled || s.scriptExecutor == nil {
 		logger.Warn("Scripting is disabled, cannot execute script")
 		notifications.Send("Scripting is disabled, cannot execute script", notifications.NotificationTypeError, time.Second*3)
-		return nil
+		return nil, nil
+	}
+
+	// the executor is picked by the scripting language of the settings
+	if !strings.EqualFold(scriptType, s.scriptExecutor.Name()) {
+		return nil, fmt.Errorf("the script is %s but the scripting language is %s, change it in Settings->Scripting", scriptType, s.scriptExecutor.Name())
 	}
 
 	params := &scripting.ExecParams{
 		Env: env,
 		Req: scripting.RequestDataFromDomain(request),
-		Res: &scripting.ResponseData{
-			StatusCode: resp.StatusCode,
-			Headers:    resp.ResponseHeaders,
-			Body:       resp.JSON,
-		},
+		Res: resp,
 	}
 
-	result, err := s.scriptExecutor.Execute(context.Background(), script, params)
+	result, err := s.scriptExecutor.Execute(ctx, script, params)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if env != nil {
@@ -291,7 +559,7 @@ func (s *Service) executeScript(script string, request *domain.Request, resp *Re
 
 		if changed {
 			if err := s.environments.UpdateEnvironment(env, state.SourceRestService, false); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	} else if len(result.SetEnvironments) > 0 {
@@ -303,7 +571,7 @@ func (s *Service) executeScript(script string, request *domain.Request, resp *Re
 		logger.Print(pt)
 	}
 
-	return nil
+	return result, nil
 }
 
 func (s *Service) handlePostRequestFromBody(r domain.PostRequest, response *Response, env *domain.Environment) error {
@@ -402,3 +670,23 @@ func (s *Service) handlePostRequestFromTrailers(r domain.PostRequest, res *Respo
 
 	return nil
 }
+
+func (s *Service) handlePostRequestFromErrorDetails(r domain.PostRequest, res *Response, env *domain.Environment) error {
+	if r.PostRequestSet.From != domain.PostRequestSetFromResponseErrorDetails || res.ErrorDetails == "" {
+		return nil
+	}
+
+	data, err := jsonpath.Get(res.ErrorDetails, r.PostRequestSet.FromKey)
+	if err != nil {
+		return err
+	}
+
+	if result, ok := data.(string); ok {
+		if env != nil {
+			env.SetKey(r.PostRequestSet.Target, result)
+			return s.environments.UpdateEnvironment(env, state.SourceGRPCService, false)
+		}
+	}
+
+	return nil
+}
@@ -2,7 +2,8 @@ package rest
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -15,9 +16,15 @@ import (
 
 	"golang.org/x/net/http2"
 
+	"github.com/chapar-rest/chapar/internal/digest"
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/logger"
+	"github.com/chapar-rest/chapar/internal/oauth2"
 	"github.com/chapar-rest/chapar/internal/prefs"
+	"github.com/chapar-rest/chapar/internal/proxy"
+	"github.com/chapar-rest/chapar/internal/sigv4"
+	"github.com/chapar-rest/chapar/internal/sse"
 	"github.com/chapar-rest/chapar/internal/state"
 	"github.com/chapar-rest/chapar/internal/util"
 	"github.com/chapar-rest/chapar/internal/variables"
@@ -27,16 +34,62 @@ import (
 type Service struct {
 	requests     *state.Requests
 	environments *state.Environments
+	workspaces   *state.Workspaces
+
+	oauth2 *oauth2.Service
 }
 
-func New(requests *state.Requests, environments *state.Environments) *Service {
+func New(requests *state.Requests, environments *state.Environments, workspaces *state.Workspaces, oauth2Service *oauth2.Service) *Service {
 	return &Service{
 		requests:     requests,
 		environments: environments,
+		workspaces:   workspaces,
+		oauth2:       oauth2Service,
+	}
+}
+
+func (s *Service) SendRequest(ctx context.Context, requestID, activeEnvironmentID string) (*egress.Response, error) {
+	// the request of a pre request script is merged with its collection already
+	r := egress.ScriptRequest(ctx, requestID)
+	if r == nil {
+		var err error
+		if r, err = s.mergedRequest(requestID); err != nil {
+			return nil, err
+		}
+	}
+
+	activeEnvironment, err := s.environment(activeEnvironmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.sendRequest(ctx, r.Spec.HTTP, activeEnvironment)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ResolveRequest returns a clone of the request with the headers and auth of its collection merged in and the
+// variables and the values of the active environment applied.
+func (s *Service) ResolveRequest(requestID, activeEnvironmentID string) (*domain.Request, error) {
+	r, err := s.mergedRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	activeEnvironment, err := s.environment(activeEnvironmentID)
+	if err != nil {
+		return nil, err
 	}
+
+	applyVariables(r.Spec.HTTP, activeEnvironment)
+	return r, nil
 }
 
-func (s *Service) SendRequest(requestID, activeEnvironmentID string) (*egress.Response, error) {
+// mergedRequest returns a clone of the request with the headers and auth of its collection merged in.
+func (s *Service) mergedRequest(requestID string) (*domain.Request, error) {
 	req := s.requests.GetRequest(requestID)
 	if req == nil {
 		return nil, fmt.Errorf("request with id %s not found", requestID)
@@ -44,9 +97,12 @@ func (s *Service) SendRequest(requestID, activeEnvironmentID string) (*egress.Re
 
 	// clone the request to make sure we do not modify the original request
 	r := req.Clone()
+	if r.Spec.HTTP == nil {
+		return nil, fmt.Errorf("request with id %s is not a http request", requestID)
+	}
 
 	// Merge collection headers and auth if request belongs to a collection
-	if r.CollectionID != "" && r.Spec.HTTP != nil {
+	if r.CollectionID != "" {
 		collection := s.requests.GetCollection(r.CollectionID)
 		if collection != nil {
 			// Merge headers: collection headers as base, request headers override
@@ -59,38 +115,30 @@ func (s *Service) SendRequest(requestID, activeEnvironmentID string) (*egress.Re
 		}
 	}
 
-	var activeEnvironment *domain.Environment
-	// Get environment if provided
-	if activeEnvironmentID != "" {
-		activeEnvironment = s.environments.GetEnvironment(activeEnvironmentID)
-		if activeEnvironment == nil {
-			return nil, fmt.Errorf("environment with id %s not found", activeEnvironmentID)
-		}
-	}
+	return r, nil
+}
 
-	response, err := s.sendRequest(r.Spec.HTTP, activeEnvironment)
-	if err != nil {
-		return nil, err
+// environment returns the environment of id, nil when id is empty.
+func (s *Service) environment(id string) (*domain.Environment, error) {
+	if id == "" {
+		return nil, nil
 	}
 
-	return response, nil
+	env := s.environments.GetEnvironment(id)
+	if env == nil {
+		return nil, fmt.Errorf("environment with id %s not found", id)
+	}
+	return env, nil
 }
 
-func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment) (*egress.Response, error) {
+func (s *Service) sendRequest(ctx context.Context, req *domain.HTTPRequestSpec, e *domain.Environment) (*egress.Response, error) {
 	// prepare request
 	// - apply environment
-	// - apply variables
+	// - apply variables, the ones a pre request script left in the request are applied as well
 	// - apply authentication (if any) is not already applied to the headers
+	applyVariables(req, e)
 
-	vars := variables.GetVariables()
-	variables.ApplyToHTTPRequest(vars, req)
-
-	if e != nil {
-		variables.ApplyToEnv(vars, &e.Spec)
-		e.ApplyToHTTPRequest(req)
-	}
-
-	httpReq, err := http.NewRequest(req.Method, req.URL, nil)
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +152,11 @@ func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment
 		httpReq.Header.Add(h.Key, h.Value)
 	}
 
+	// resume the event stream from where it was left
+	if id := egress.LastEventID(ctx); id != "" {
+		httpReq.Header.Set("Last-Event-ID", id)
+	}
+
 	// apply path params as single brace
 	for _, p := range req.Request.PathParams {
 		if !p.Enable {
@@ -145,6 +198,14 @@ func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment
 				httpReq.Header.Add(req.Request.Auth.APIKeyAuth.Key, req.Request.Auth.APIKeyAuth.Value)
 			}
 		}
+
+		if req.Request.Auth.Type == domain.AuthTypeOAuth2 && req.Request.Auth.OAuth2Auth != nil {
+			token, err := s.oauth2.Token(ctx, e, req.Request.Auth.OAuth2Auth, req.Request.Proxy)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get oauth2 token: %w", err)
+			}
+			httpReq.Header.Set("Authorization", token.AuthorizationHeader())
+		}
 	}
 
 	// send request
@@ -157,15 +218,40 @@ func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment
 
 	globalConfig := prefs.GetGlobalConfig()
 
+	tlsConfig, err := egress.TLSConfig(s.workspaces.GetActiveWorkspace(), httpReq.URL, globalConfig.Spec.General.VaidateTLSCertificates)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyDialer, err := proxy.NewDialer(domain.ResolveProxy(globalConfig.Spec.General.Proxy, e, req.Request.Proxy))
+	if err != nil {
+		return nil, err
+	}
+
 	// send request
 	start := time.Now()
 
+	// the timeout is not set on the client as it would also cut off event streams, instead it covers
+	// the request until the body is read, or until the headers arrive for event streams.
+	timeout := time.Duration(globalConfig.Spec.General.RequestTimeoutSec) * time.Second
+	reqCtx, cancel := context.WithCancelCause(httpReq.Context())
+	streaming := false
+	defer func() {
+		if !streaming {
+			cancel(nil)
+		}
+	}()
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() { cancel(errTimeout) })
+		defer timer.Stop()
+	}
+
 	client := &http.Client{
-		Timeout: time.Duration(globalConfig.Spec.General.RequestTimeoutSec) * time.Second,
 		Transport: &http.Transport{
 			MaxIdleConns:           10,
 			MaxResponseHeaderBytes: int64(globalConfig.Spec.General.ResponseSizeMb * 1024 * 1024),
-			TLSClientConfig:        &tls.Config{InsecureSkipVerify: !globalConfig.Spec.General.VaidateTLSCertificates},
+			TLSClientConfig:        tlsConfig,
+			Proxy:                  proxyDialer.ProxyFunc(),
 		},
 	}
 	if !globalConfig.Spec.General.FollowRedirects {
@@ -178,7 +264,20 @@ func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment
 		client.Transport = &http2.Transport{
 			AllowHTTP:        true,
 			MaxReadFrameSize: uint32(globalConfig.Spec.General.ResponseSizeMb * 1024 * 1024),
-			TLSClientConfig:  &tls.Config{InsecureSkipVerify: !globalConfig.Spec.General.VaidateTLSCertificates},
+			TLSClientConfig:  tlsConfig,
+			DialTLSContext:   proxyDialer.DialTLSContext,
+		}
+	}
+
+	// digest auth needs the challenge from the server, so it's answered by the transport
+	if req.Request.Auth.Type == domain.AuthTypeDigest && req.Request.Auth.DigestAuth != nil {
+		client.Transport = &digest.Transport{
+			Username: req.Request.Auth.DigestAuth.Username,
+			Password: req.Request.Auth.DigestAuth.Password,
+			Base:     client.Transport,
+			OnChallenge: func(r *http.Request, c *digest.Challenge) {
+				logger.Info(fmt.Sprintf("%s %s: got digest challenge (realm=%q, algorithm=%s, qop=%s), retrying with credentials", r.Method, r.URL, c.Realm, c.Algorithm, strings.Join(c.Qop, ",")))
+			},
 		}
 	}
 
@@ -190,19 +289,34 @@ func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment
 		httpReq.Header.Add("User-Agent", version.GetAgentName())
 	}
 
-	res, err := client.Do(httpReq)
+	// aws signature covers the final headers and body, so it has to be the last thing before sending
+	if req.Request.Auth.Type == domain.AuthTypeAWSSigV4 && req.Request.Auth.AWSSigV4Auth != nil {
+		if err := sigv4.Sign(httpReq, req.Request.Auth.AWSSigV4Auth, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
+
+	trace := egress.NewTimingTrace(start)
+	res, err := client.Do(httpReq.WithContext(trace.WithContext(reqCtx)))
 	if err != nil {
-		return nil, err
+		return nil, timeoutOr(reqCtx, timeout, err)
 	}
 
+	if sse.IsEventStream(res.Header.Get("Content-Type")) {
+		streaming = true
+		return s.eventStreamResponse(res, httpReq, trace), nil
+	}
+	defer func() { _ = res.Body.Close() }()
+
 	// read body
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return nil, timeoutOr(reqCtx, timeout, err)
 	}
 
 	// measure time
-	elapsed := time.Since(start)
+	end := time.Now()
+	elapsed := end.Sub(start)
 
 	// handle response
 	response := &egress.Response{
@@ -212,6 +326,7 @@ func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment
 		Cookies:         res.Cookies(),
 		Body:            body,
 		TimePassed:      elapsed,
+		Timing:          trace.Timing(end),
 		IsJSON:          false,
 	}
 
@@ -236,6 +351,41 @@ func (s *Service) sendRequest(req *domain.HTTPRequestSpec, e *domain.Environment
 	return response, nil
 }
 
+// eventStreamResponse returns the response of a text/event-stream, the body is read by the caller through the event stream.
+// the request context is cancelled when the caller cancels the context it passed to SendRequest.
+func (s *Service) eventStreamResponse(res *http.Response, httpReq *http.Request, trace *egress.TimingTrace) *egress.Response {
+	timing := trace.Timing(time.Now())
+	response := &egress.Response{
+		StatusCode:      res.StatusCode,
+		ResponseHeaders: map[string]string{},
+		RequestHeaders:  map[string]string{},
+		Cookies:         res.Cookies(),
+		TimePassed:      timing.Total,
+		Timing:          timing,
+		EventStream:     sse.NewStream(res.Body),
+	}
+
+	for k, v := range res.Header {
+		response.ResponseHeaders[k] = strings.Join(v, ", ")
+	}
+
+	for k, v := range httpReq.Header {
+		response.RequestHeaders[k] = strings.Join(v, ", ")
+	}
+
+	return response
+}
+
+var errTimeout = errors.New("request timeout")
+
+// timeoutOr returns a timeout error if the request was cut off by the timeout, otherwise it returns err as is.
+func timeoutOr(ctx context.Context, timeout time.Duration, err error) error {
+	if errors.Is(context.Cause(ctx), errTimeout) {
+		return fmt.Errorf("request timed out after %s: %w", timeout, errTimeout)
+	}
+	return err
+}
+
 func (s *Service) applyBody(req *domain.HTTPRequestSpec, httpReq *http.Request) error {
 	// apply body
 	switch req.Request.Body.Type {
@@ -348,3 +498,14 @@ func (s *Service) applyBody(req *domain.HTTPRequestSpec, httpReq *http.Request)
 
 	return nil
 }
+
+// applyVariables applies the global variables and the environment to the request.
+func applyVariables(req *domain.HTTPRequestSpec, e *domain.Environment) {
+	vars := variables.GetVariables()
+	variables.ApplyToHTTPRequest(vars, req)
+
+	if e != nil {
+		variables.ApplyToEnv(vars, &e.Spec)
+		e.ApplyToHTTPRequest(req)
+	}
+}
@@ -0,0 +1,120 @@
+package egress
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// TimingTrace records the timing of an http request through httptrace.
+// when the request is sent more than once, e.g. on redirects or a digest challenge, the last attempt is recorded.
+type TimingTrace struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+// NewTimingTrace returns a trace for a request which started at start.
+func NewTimingTrace(start time.Time) *TimingTrace {
+	return &TimingTrace{start: start}
+}
+
+// WithContext returns a copy of ctx which reports to the trace.
+func (t *TimingTrace) WithContext(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.record(func() {
+				t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+				t.connectStart, t.connectDone = time.Time{}, time.Time{}
+				t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+				t.gotConn, t.wroteRequest, t.firstByte = time.Time{}, time.Time{}, time.Time{}
+				t.reused = false
+			})
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { t.record(func() { t.dnsStart = time.Now() }) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.record(func() { t.dnsDone = time.Now() }) },
+		ConnectStart: func(string, string) {
+			t.record(func() {
+				// with happy eyeballs there can be more than one attempt, the first one counts as the start
+				if t.connectStart.IsZero() {
+					t.connectStart = time.Now()
+				}
+			})
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				t.record(func() { t.connectDone = time.Now() })
+			}
+		},
+		TLSHandshakeStart: func() { t.record(func() { t.tlsStart = time.Now() }) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.record(func() { t.tlsDone = time.Now() }) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.record(func() {
+				t.gotConn = time.Now()
+				t.reused = info.Reused
+			})
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.record(func() { t.wroteRequest = time.Now() }) },
+		GotFirstResponseByte: func() { t.record(func() { t.firstByte = time.Now() }) },
+	})
+}
+
+func (t *TimingTrace) record(f func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f()
+}
+
+// Timing returns the breakdown of the request, end is when the response body was read completely.
+func (t *TimingTrace) Timing(end time.Time) domain.Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := domain.Timing{
+		Total:            end.Sub(t.start),
+		ConnectionReused: t.reused,
+	}
+
+	addPhase := func(name string, from, to time.Time) {
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return
+		}
+
+		out.Phases = append(out.Phases, domain.TimingPhase{
+			Name:     name,
+			Start:    from.Sub(t.start),
+			Duration: to.Sub(from),
+		})
+	}
+
+	addPhase(domain.TimingPhaseDNSLookup, t.dnsStart, t.dnsDone)
+	addPhase(domain.TimingPhaseTCPConnect, t.connectStart, t.connectDone)
+	addPhase(domain.TimingPhaseTLSHandshake, t.tlsStart, t.tlsDone)
+
+	waitingFrom := t.wroteRequest
+	if waitingFrom.IsZero() {
+		waitingFrom = t.gotConn
+	}
+	addPhase(domain.TimingPhaseWaiting, waitingFrom, t.firstByte)
+	addPhase(domain.TimingPhaseContentTransfer, t.firstByte, end)
+
+	if !t.firstByte.IsZero() {
+		out.TimeToFirstByte = t.firstByte.Sub(t.start)
+	}
+
+	return out
+}
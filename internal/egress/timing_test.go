@@ -0,0 +1,60 @@
+package egress
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestTimingTrace(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+
+	send := func() domain.Timing {
+		start := time.Now()
+		trace := NewTimingTrace(start)
+
+		req, err := http.NewRequestWithContext(trace.WithContext(context.Background()), http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		_, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		return trace.Timing(time.Now())
+	}
+
+	first := send()
+	assert.False(t, first.ConnectionReused)
+	assert.NotZero(t, first.Duration(domain.TimingPhaseTCPConnect))
+	assert.NotZero(t, first.Duration(domain.TimingPhaseTLSHandshake))
+	assert.NotZero(t, first.TimeToFirstByte)
+	assert.LessOrEqual(t, first.TimeToFirstByte, first.Total)
+
+	var names []string
+	for _, p := range first.Phases {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{domain.TimingPhaseTCPConnect, domain.TimingPhaseTLSHandshake, domain.TimingPhaseWaiting, domain.TimingPhaseContentTransfer}, names)
+
+	second := send()
+	assert.True(t, second.ConnectionReused)
+	assert.Zero(t, second.Duration(domain.TimingPhaseTCPConnect))
+	assert.Zero(t, second.Duration(domain.TimingPhaseTLSHandshake))
+}
@@ -0,0 +1,65 @@
+package egress
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// TLSConfig returns the tls config for the given url, applying the client certificate and CA bundle
+// of the workspace entry matching the url host, if any.
+func TLSConfig(workspace *domain.Workspace, u *url.URL, validateCertificates bool) (*tls.Config, error) {
+	cfg := &tls.Config{InsecureSkipVerify: !validateCertificates}
+	if workspace == nil || u == nil {
+		return cfg, nil
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" || u.Scheme == "ws" {
+			port = "80"
+		}
+	}
+
+	cert := workspace.Spec.FindCertificate(u.Hostname(), port)
+	if cert == nil {
+		return cfg, nil
+	}
+
+	if cert.CACertFile != "" {
+		pem, err := os.ReadFile(cert.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca certificate: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cert.CACertFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if cert.ClientCertFile != "" || cert.ClientKeyFile != "" {
+		if cert.ClientCertFile == "" || cert.ClientKeyFile == "" {
+			return nil, errors.New("client certificate and client key must be set together")
+		}
+
+		pair, err := tls.LoadX509KeyPair(cert.ClientCertFile, cert.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+
+	return cfg, nil
+}
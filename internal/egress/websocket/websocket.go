@@ -0,0 +1,316 @@
+package websocket
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/oauth2"
+	"github.com/chapar-rest/chapar/internal/prefs"
+	"github.com/chapar-rest/chapar/internal/proxy"
+	"github.com/chapar-rest/chapar/internal/state"
+	"github.com/chapar-rest/chapar/internal/variables"
+	"github.com/chapar-rest/chapar/internal/websocket"
+)
+
+// closeTimeout is how long we wait for the server to answer our close frame before dropping the connection.
+const closeTimeout = 5 * time.Second
+
+type Service struct {
+	requests     *state.Requests
+	environments *state.Environments
+	workspaces   *state.Workspaces
+
+	oauth2 *oauth2.Service
+}
+
+func New(requests *state.Requests, environments *state.Environments, workspaces *state.Workspaces, oauth2Service *oauth2.Service) *Service {
+	return &Service{
+		requests:     requests,
+		environments: environments,
+		workspaces:   workspaces,
+		oauth2:       oauth2Service,
+	}
+}
+
+// Connection is an open websocket connection, the received frames are delivered on Frames
+// until the connection is closed.
+type Connection struct {
+	conn *websocket.Conn
+	env  *domain.Environment
+
+	Subprotocol     string
+	ResponseHeaders []domain.KeyValue
+
+	frames chan domain.WebSocketFrame
+
+	mx      sync.Mutex
+	err     error
+	closing bool
+}
+
+// Connect opens the websocket connection of the given request, the connection stays open until Close is called
+// or the server closes it.
+func (s *Service) Connect(ctx context.Context, requestID, activeEnvironmentID string) (*Connection, error) {
+	req := s.requests.GetRequest(requestID)
+	if req == nil {
+		return nil, fmt.Errorf("request with id %s not found", requestID)
+	}
+
+	if req.Spec.WebSocket == nil {
+		return nil, fmt.Errorf("request with id %s is not a websocket request", requestID)
+	}
+
+	// clone the request to make sure we do not modify the original request
+	r := req.Clone()
+
+	// Merge collection headers and auth if request belongs to a collection
+	if r.CollectionID != "" {
+		collection := s.requests.GetCollection(r.CollectionID)
+		if collection != nil {
+			r.Spec.WebSocket.Headers = domain.MergeHeaders(collection.Spec.Headers, r.Spec.WebSocket.Headers)
+
+			if r.Spec.WebSocket.Auth.Type == domain.AuthTypeInherit {
+				r.Spec.WebSocket.Auth = collection.Spec.Auth
+			}
+		}
+	}
+
+	var activeEnvironment *domain.Environment
+	if activeEnvironmentID != "" {
+		activeEnvironment = s.environments.GetEnvironment(activeEnvironmentID)
+		if activeEnvironment == nil {
+			return nil, fmt.Errorf("environment with id %s not found", activeEnvironmentID)
+		}
+	}
+
+	return s.connect(ctx, r.Spec.WebSocket, activeEnvironment)
+}
+
+func (s *Service) connect(ctx context.Context, req *domain.WebSocketRequestSpec, e *domain.Environment) (*Connection, error) {
+	vars := variables.GetVariables()
+	variables.ApplyToWebSocketRequest(vars, req)
+
+	if e != nil {
+		variables.ApplyToEnv(vars, &e.Spec)
+		e.ApplyToWebSocketRequest(req)
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	header := make(http.Header)
+	for _, h := range req.Headers {
+		if !h.Enable {
+			continue
+		}
+		header.Add(h.Key, h.Value)
+	}
+
+	if err := s.applyAuth(ctx, header, req.Auth, e, req.Proxy); err != nil {
+		return nil, err
+	}
+
+	globalConfig := prefs.GetGlobalConfig()
+
+	// the tls config is looked up by the http url the handshake is sent to
+	tlsURL := *u
+	if tlsURL.Scheme == "wss" {
+		tlsURL.Scheme = "https"
+	}
+
+	tlsConfig, err := egress.TLSConfig(s.workspaces.GetActiveWorkspace(), &tlsURL, globalConfig.Spec.General.VaidateTLSCertificates)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyDialer, err := proxy.NewDialer(domain.ResolveProxy(globalConfig.Spec.General.Proxy, e, req.Proxy))
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &websocket.Dialer{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           proxyDialer.ProxyFunc(),
+		},
+		MaxMessageSize: int64(globalConfig.Spec.General.ResponseSizeMb * 1024 * 1024),
+	}
+
+	if timeout := time.Duration(globalConfig.Spec.General.RequestTimeoutSec) * time.Second; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn, res, err := dialer.Dial(ctx, u.String(), header, req.Subprotocols)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Connection{
+		conn:        conn,
+		env:         e,
+		Subprotocol: conn.Subprotocol(),
+		frames:      make(chan domain.WebSocketFrame, 64),
+	}
+
+	for k, v := range res.Header {
+		c.ResponseHeaders = append(c.ResponseHeaders, domain.KeyValue{Key: k, Value: strings.Join(v, ", ")})
+	}
+
+	go c.readLoop()
+
+	return c, nil
+}
+
+func (s *Service) applyAuth(ctx context.Context, header http.Header, auth domain.Auth, e *domain.Environment, requestProxy *domain.ProxyConfig) error {
+	switch auth.Type {
+	case domain.AuthTypeToken:
+		if auth.TokenAuth != nil && auth.TokenAuth.Token != "" {
+			header.Set("Authorization", "Bearer "+auth.TokenAuth.Token)
+		}
+	case domain.AuthTypeBasic:
+		if auth.BasicAuth != nil && auth.BasicAuth.Username != "" && auth.BasicAuth.Password != "" {
+			credentials := base64.StdEncoding.EncodeToString([]byte(auth.BasicAuth.Username + ":" + auth.BasicAuth.Password))
+			header.Set("Authorization", "Basic "+credentials)
+		}
+	case domain.AuthTypeAPIKey:
+		if auth.APIKeyAuth != nil && auth.APIKeyAuth.Key != "" && auth.APIKeyAuth.Value != "" {
+			header.Set(auth.APIKeyAuth.Key, auth.APIKeyAuth.Value)
+		}
+	case domain.AuthTypeOAuth2:
+		if auth.OAuth2Auth != nil {
+			token, err := s.oauth2.Token(ctx, e, auth.OAuth2Auth, requestProxy)
+			if err != nil {
+				return fmt.Errorf("failed to get oauth2 token: %w", err)
+			}
+			header.Set("Authorization", token.AuthorizationHeader())
+		}
+	case domain.AuthTypeAWSSigV4, domain.AuthTypeDigest:
+		return fmt.Errorf("%s auth is not supported for websocket requests", auth.Type)
+	}
+
+	return nil
+}
+
+// Frames returns the frames received from the server, it's closed once the connection is closed.
+func (c *Connection) Frames() <-chan domain.WebSocketFrame {
+	return c.frames
+}
+
+// Err returns the reason the connection was closed, it's nil when it was closed normally.
+// it should be called after Frames is closed.
+func (c *Connection) Err() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.err
+}
+
+func (c *Connection) readLoop() {
+	defer close(c.frames)
+
+	for {
+		msg, err := c.conn.ReadMessage()
+
+		var closeErr *websocket.CloseError
+		if msg.Opcode == websocket.OpClose && errors.As(err, &closeErr) {
+			msg.Data = []byte(strings.TrimSpace(fmt.Sprintf("%d %s", closeErr.Code, closeErr.Reason)))
+		}
+
+		if msg.Opcode != 0 || len(msg.Data) > 0 {
+			c.frames <- newFrame(domain.WebSocketDirectionReceived, msg.Opcode, msg.Data)
+
+			// pings are answered by the connection itself
+			if msg.Opcode == websocket.OpPing && err == nil {
+				c.frames <- newFrame(domain.WebSocketDirectionSent, websocket.OpPong, msg.Data)
+			}
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if errors.As(err, &closeErr) && (closeErr.Code == websocket.CloseNormal || closeErr.Code == websocket.CloseNoStatus || closeErr.Code == websocket.CloseGoingAway) {
+			err = nil
+		}
+
+		c.mx.Lock()
+		// the connection is dropped if the server doesn't answer our close frame, it's not an error for the user
+		if !c.closing {
+			c.err = err
+		}
+		c.mx.Unlock()
+
+		_ = c.conn.CloseNow()
+		return
+	}
+}
+
+// Send sends the given message template, environment values and variables are applied to it first.
+func (c *Connection) Send(msg domain.WebSocketMessage) (domain.WebSocketFrame, error) {
+	spec := &domain.WebSocketRequestSpec{Messages: []domain.WebSocketMessage{msg}}
+	variables.ApplyToWebSocketRequest(nil, spec)
+	c.env.ApplyToWebSocketRequest(spec)
+	msg = spec.Messages[0]
+
+	opcode := websocket.OpText
+	data := []byte(msg.Data)
+	if msg.Type == domain.WebSocketMessageTypeBinary {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(msg.Data))
+		if err != nil {
+			return domain.WebSocketFrame{}, fmt.Errorf("binary message must be base64 encoded: %w", err)
+		}
+		opcode, data = websocket.OpBinary, decoded
+	}
+
+	if err := c.conn.WriteMessage(opcode, data); err != nil {
+		return domain.WebSocketFrame{}, err
+	}
+
+	return newFrame(domain.WebSocketDirectionSent, opcode, data), nil
+}
+
+// Ping sends a ping frame, the pong is delivered on Frames.
+func (c *Connection) Ping() (domain.WebSocketFrame, error) {
+	if err := c.conn.WriteMessage(websocket.OpPing, nil); err != nil {
+		return domain.WebSocketFrame{}, err
+	}
+
+	return newFrame(domain.WebSocketDirectionSent, websocket.OpPing, nil), nil
+}
+
+// Close starts the closing handshake, the connection is dropped if the server does not answer in time.
+func (c *Connection) Close() (domain.WebSocketFrame, error) {
+	c.mx.Lock()
+	c.closing = true
+	c.mx.Unlock()
+
+	time.AfterFunc(closeTimeout, func() { _ = c.conn.CloseNow() })
+
+	if err := c.conn.Close(websocket.CloseNormal, ""); err != nil {
+		_ = c.conn.CloseNow()
+		return domain.WebSocketFrame{}, err
+	}
+
+	return newFrame(domain.WebSocketDirectionSent, websocket.OpClose, nil), nil
+}
+
+func newFrame(direction string, opcode websocket.Opcode, data []byte) domain.WebSocketFrame {
+	return domain.WebSocketFrame{
+		Direction: direction,
+		Type:      opcode.String(),
+		Data:      data,
+		Time:      time.Now(),
+	}
+}
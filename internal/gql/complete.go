@@ -0,0 +1,430 @@
+package gql
+
+import (
+	"strings"
+)
+
+// kinds of the suggestions
+const (
+	SuggestionField     = "field"
+	SuggestionArgument  = "argument"
+	SuggestionValue     = "value"
+	SuggestionType      = "type"
+	SuggestionKeyword   = "keyword"
+	SuggestionDirective = "directive"
+	SuggestionVariable  = "variable"
+	SuggestionFragment  = "fragment"
+)
+
+// Suggestion is a completion item, Insert replaces the word being typed.
+type Suggestion struct {
+	Label       string
+	Detail      string
+	Kind        string
+	Insert      string
+	Description string
+}
+
+type frameKind int
+
+const (
+	frameSelection frameKind = iota
+	frameArguments
+	frameObject
+	frameList
+	frameVariables
+)
+
+// frame is a nesting level of the query at the caret.
+type frame struct {
+	kind frameKind
+
+	// typ is the type whose fields are selected in selection frames
+	typ *Type
+	// inputs are the arguments or the input object fields that may be given
+	inputs []*InputValue
+	// given are the inputs already written
+	given map[string]bool
+
+	// valueType is the type of the value being written, the items of the list in list frames
+	valueType   *TypeRef
+	expectValue bool
+}
+
+// completer walks the tokens before the caret and keeps the state needed to suggest the next word.
+type completer struct {
+	schema *Schema
+	stack  []*frame
+
+	prev token
+	// pendingArgs are the arguments of the last field or directive, they're used if "(" follows
+	pendingArgs []*InputValue
+	// pendingType is the type of the selection set that "{" would open
+	pendingType *Type
+	// rootType is the type of the operation being defined at the top level
+	rootType *Type
+
+	variables []string
+	fragments []string
+}
+
+// Complete suggests the words that may be written at the byte offset of the query, start is the offset
+// of the word the suggestions replace.
+func Complete(s *Schema, src string, offset int) (int, []Suggestion) {
+	if s == nil || offset < 0 || offset > len(src) {
+		return offset, nil
+	}
+
+	tokens, err := lex(src[:offset])
+	if err != nil {
+		// the caret is in a string or after an invalid character
+		return offset, nil
+	}
+
+	last := 0
+	if len(tokens) > 0 {
+		last = tokens[len(tokens)-1].end
+	}
+	if gap := src[last:offset]; strings.Contains(gap[strings.LastIndexAny(gap, "\r\n")+1:], "#") {
+		// the caret is in a comment
+		return offset, nil
+	}
+
+	start, prefix := offset, ""
+	if n := len(tokens); n > 0 && tokens[n-1].end == offset && tokens[n-1].kind == tokenName {
+		start, prefix = tokens[n-1].start, tokens[n-1].value
+		tokens = tokens[:n-1]
+	}
+
+	c := &completer{schema: s, fragments: fragmentNames(src)}
+	for _, tok := range tokens {
+		c.feed(tok)
+		c.prev = tok
+	}
+
+	var out []Suggestion
+	for _, sg := range c.suggestions() {
+		if strings.HasPrefix(strings.ToLower(sg.Label), strings.ToLower(prefix)) && sg.Label != prefix {
+			out = append(out, sg)
+		}
+	}
+	return start, out
+}
+
+func (c *completer) top() *frame {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	return c.stack[len(c.stack)-1]
+}
+
+func (c *completer) push(f *frame) {
+	c.stack = append(c.stack, f)
+}
+
+// pop closes the frame, the value it held is complete for the parent.
+func (c *completer) pop() {
+	if len(c.stack) == 0 {
+		return
+	}
+
+	c.stack = c.stack[:len(c.stack)-1]
+	if f := c.top(); f != nil && f.kind != frameList {
+		f.expectValue = false
+	}
+	c.pendingArgs = nil
+}
+
+func (c *completer) prevIs(kind tokenKind, value string) bool {
+	return c.prev.kind == kind && c.prev.value == value
+}
+
+func (c *completer) feed(tok token) {
+	f := c.top()
+
+	// directives may follow most tokens, their name sets the arguments "(" would open
+	if c.prevIs(tokenPunct, "@") && tok.kind == tokenName {
+		if d := c.schema.Directive(tok.value); d != nil {
+			c.pendingArgs = d.Args
+		} else {
+			c.pendingArgs = nil
+		}
+		return
+	}
+
+	if f == nil {
+		c.feedTopLevel(tok)
+		return
+	}
+
+	switch f.kind {
+	case frameSelection:
+		c.feedSelection(f, tok)
+	case frameVariables:
+		c.feedVariables(f, tok)
+	default:
+		c.feedInputs(f, tok)
+	}
+}
+
+func (c *completer) feedTopLevel(tok token) {
+	definitionStart := c.prev.kind == tokenEOF || c.prevIs(tokenPunct, "}")
+
+	switch {
+	case tok.kind == tokenName && definitionStart:
+		c.variables = nil
+		c.rootType = nil
+		if tok.value != "fragment" {
+			c.rootType = c.schema.RootType(tok.value)
+		}
+	case tok.kind == tokenName && c.prevIs(tokenName, "on"):
+		c.rootType = c.schema.Type(tok.value)
+	case tok.kind == tokenPunct && tok.value == "(":
+		c.push(&frame{kind: frameVariables})
+	case tok.kind == tokenPunct && tok.value == "{":
+		if definitionStart {
+			// an anonymous query
+			c.variables = nil
+			c.rootType = c.schema.RootType("query")
+		}
+		c.push(&frame{kind: frameSelection, typ: c.rootType})
+	}
+}
+
+func (c *completer) feedVariables(f *frame, tok token) {
+	switch {
+	case tok.kind == tokenPunct && tok.value == ")":
+		c.pop()
+	case tok.kind == tokenName && c.prevIs(tokenPunct, "$"):
+		c.variables = append(c.variables, tok.value)
+	case tok.kind == tokenPunct && tok.value == ":":
+		f.expectValue = false
+	}
+}
+
+func (c *completer) feedSelection(f *frame, tok token) {
+	switch tok.kind {
+	case tokenName:
+		switch {
+		case c.prev.kind == tokenSpread && tok.value == "on":
+		case c.prev.kind == tokenSpread:
+			// a fragment spread
+			c.pendingArgs, c.pendingType = nil, nil
+		case c.prevIs(tokenName, "on"):
+			c.pendingType = c.schema.Type(tok.value)
+			c.pendingArgs = nil
+		default:
+			fd := f.typ.Field(tok.value)
+			c.pendingArgs, c.pendingType = nil, nil
+			if fd != nil {
+				c.pendingArgs = fd.Args
+				c.pendingType = c.schema.Type(fd.Type.NamedType())
+			}
+		}
+	case tokenSpread:
+		// an inline fragment without condition keeps the type
+		c.pendingType = f.typ
+	case tokenPunct:
+		switch tok.value {
+		case "(":
+			c.push(&frame{kind: frameArguments, inputs: c.pendingArgs, given: make(map[string]bool)})
+		case "{":
+			c.push(&frame{kind: frameSelection, typ: c.pendingType})
+			c.pendingArgs, c.pendingType = nil, nil
+		case "}":
+			c.pop()
+		}
+	}
+}
+
+// feedInputs handles the arguments, the input objects and the lists.
+func (c *completer) feedInputs(f *frame, tok token) {
+	if !f.expectValue && f.kind != frameList {
+		switch {
+		case tok.kind == tokenName:
+			f.given[tok.value] = true
+			f.valueType = nil
+			if v := findInputValue(f.inputs, tok.value); v != nil {
+				f.valueType = v.Type
+			}
+		case tok.kind == tokenPunct && tok.value == ":":
+			f.expectValue = true
+		case tok.kind == tokenPunct && (tok.value == ")" || tok.value == "}"):
+			c.pop()
+		}
+		return
+	}
+
+	switch {
+	case tok.kind == tokenPunct && tok.value == "$":
+		// the variable name follows
+	case tok.kind == tokenPunct && tok.value == "[":
+		var item *TypeRef
+		if t := f.valueType.Nullable(); t != nil && t.Kind == KindList {
+			item = t.OfType
+		}
+		c.push(&frame{kind: frameList, valueType: item})
+	case tok.kind == tokenPunct && tok.value == "{":
+		var inputs []*InputValue
+		if t := c.schema.Type(f.valueType.NamedType()); t != nil {
+			inputs = t.InputFields
+		}
+		c.push(&frame{kind: frameObject, inputs: inputs, given: make(map[string]bool)})
+	case tok.kind == tokenPunct && (tok.value == "]" || tok.value == ")" || tok.value == "}"):
+		c.pop()
+	default:
+		if f.kind != frameList {
+			f.expectValue = false
+		}
+	}
+}
+
+func (c *completer) suggestions() []Suggestion {
+	if c.prevIs(tokenPunct, "@") {
+		return c.directiveSuggestions()
+	}
+
+	f := c.top()
+	if f == nil {
+		if c.prevIs(tokenName, "on") {
+			return c.typeSuggestions(func(t *Type) bool { return t.IsComposite() })
+		}
+		if c.prev.kind == tokenEOF || c.prevIs(tokenPunct, "}") {
+			return keywordSuggestions("query", "mutation", "subscription", "fragment")
+		}
+		return nil
+	}
+
+	switch f.kind {
+	case frameSelection:
+		switch {
+		case c.prev.kind == tokenSpread:
+			out := keywordSuggestions("on")
+			for _, name := range c.fragments {
+				out = append(out, Suggestion{Label: name, Kind: SuggestionFragment, Insert: name})
+			}
+			return out
+		case c.prevIs(tokenName, "on"):
+			return c.typeSuggestions(func(t *Type) bool { return t.IsComposite() && c.schema.Overlaps(f.typ, t) })
+		}
+		return fieldSuggestions(f.typ)
+	case frameVariables:
+		if c.prevIs(tokenPunct, ":") || c.prevIs(tokenPunct, "[") {
+			return c.typeSuggestions(func(t *Type) bool { return t.IsInput() })
+		}
+		return nil
+	}
+
+	if c.prevIs(tokenPunct, "$") {
+		out := make([]Suggestion, 0, len(c.variables))
+		for _, name := range c.variables {
+			out = append(out, Suggestion{Label: name, Kind: SuggestionVariable, Insert: name})
+		}
+		return out
+	}
+
+	if f.expectValue || f.kind == frameList {
+		return c.valueSuggestions(f.valueType)
+	}
+
+	var out []Suggestion
+	for _, v := range f.inputs {
+		if f.given[v.Name] {
+			continue
+		}
+		out = append(out, Suggestion{
+			Label:       v.Name,
+			Detail:      v.Type.String(),
+			Kind:        SuggestionArgument,
+			Insert:      v.Name + ": ",
+			Description: v.Description,
+		})
+	}
+	return out
+}
+
+func (c *completer) valueSuggestions(typ *TypeRef) []Suggestion {
+	for typ != nil && (typ.Kind == KindNonNull || typ.Kind == KindList) {
+		typ = typ.OfType
+	}
+
+	t := c.schema.Type(typ.NamedType())
+	if t == nil {
+		return nil
+	}
+
+	switch {
+	case t.Kind == KindEnum:
+		out := make([]Suggestion, 0, len(t.EnumValues))
+		for _, v := range t.EnumValues {
+			out = append(out, Suggestion{Label: v.Name, Detail: t.Name, Kind: SuggestionValue, Insert: v.Name, Description: v.Description})
+		}
+		return out
+	case t.Name == "Boolean":
+		return []Suggestion{
+			{Label: "true", Detail: t.Name, Kind: SuggestionValue, Insert: "true"},
+			{Label: "false", Detail: t.Name, Kind: SuggestionValue, Insert: "false"},
+		}
+	}
+	return nil
+}
+
+func (c *completer) typeSuggestions(filter func(t *Type) bool) []Suggestion {
+	var out []Suggestion
+	for _, name := range c.schema.TypeNames() {
+		t := c.schema.Types[name]
+		if filter(t) {
+			out = append(out, Suggestion{Label: name, Detail: strings.ToLower(t.Kind), Kind: SuggestionType, Insert: name, Description: t.Description})
+		}
+	}
+	return out
+}
+
+func (c *completer) directiveSuggestions() []Suggestion {
+	out := make([]Suggestion, 0, len(c.schema.Directives))
+	for _, d := range c.schema.Directives {
+		out = append(out, Suggestion{Label: d.Name, Kind: SuggestionDirective, Insert: d.Name, Description: d.Description})
+	}
+	return out
+}
+
+func fieldSuggestions(t *Type) []Suggestion {
+	if t == nil {
+		return nil
+	}
+
+	out := make([]Suggestion, 0, len(t.Fields)+1)
+	for _, f := range t.Fields {
+		desc := f.Description
+		if f.IsDeprecated {
+			desc = strings.TrimSpace("Deprecated: " + f.DeprecationReason + "\n" + desc)
+		}
+		out = append(out, Suggestion{Label: f.Name, Detail: f.Type.String(), Kind: SuggestionField, Insert: f.Name, Description: desc})
+	}
+
+	if t.IsComposite() {
+		out = append(out, Suggestion{Label: typenameField.Name, Detail: typenameField.Type.String(), Kind: SuggestionField, Insert: typenameField.Name, Description: typenameField.Description})
+	}
+	return out
+}
+
+func keywordSuggestions(words ...string) []Suggestion {
+	out := make([]Suggestion, 0, len(words))
+	for _, w := range words {
+		out = append(out, Suggestion{Label: w, Kind: SuggestionKeyword, Insert: w})
+	}
+	return out
+}
+
+// fragmentNames returns the names of the fragments defined in the source, even when it doesn't parse.
+func fragmentNames(src string) []string {
+	tokens, _ := lex(src)
+
+	var names []string
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].kind == tokenName && tokens[i].value == "fragment" && tokens[i+1].kind == tokenName && tokens[i+1].value != "on" {
+			names = append(names, tokens[i+1].value)
+		}
+	}
+	return names
+}
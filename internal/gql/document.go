@@ -0,0 +1,273 @@
+package gql
+
+// Document is a parsed query document, the operations and fragments keep the order they are written in.
+type Document struct {
+	Operations []*Operation
+	Fragments  []*Fragment
+}
+
+// Operation is a query, mutation or subscription, Name is empty for anonymous operations.
+type Operation struct {
+	Type                string
+	Name                string
+	VariableDefinitions []*VariableDefinition
+	Directives          []*AppliedDirective
+	SelectionSet        []Selection
+	// Pos is the byte offset of the operation in the source
+	Pos int
+}
+
+type VariableDefinition struct {
+	Name         string
+	Type         *TypeRef
+	DefaultValue *Value
+	Pos          int
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*AppliedDirective
+	SelectionSet  []Selection
+	Pos           int
+}
+
+// Selection is a FieldSelection, a FragmentSpread or an InlineFragment.
+type Selection interface {
+	selection()
+}
+
+type FieldSelection struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*AppliedDirective
+	SelectionSet []Selection
+	Pos          int
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*AppliedDirective
+	Pos        int
+}
+
+type InlineFragment struct {
+	// TypeCondition is empty when the fragment has no condition
+	TypeCondition string
+	Directives    []*AppliedDirective
+	SelectionSet  []Selection
+	Pos           int
+}
+
+func (*FieldSelection) selection() {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+type Argument struct {
+	Name  string
+	Value *Value
+	Pos   int
+}
+
+type AppliedDirective struct {
+	Name      string
+	Arguments []*Argument
+	Pos       int
+}
+
+type ValueKind int
+
+const (
+	ValueVariable ValueKind = iota
+	ValueInt
+	ValueFloat
+	ValueString
+	ValueBoolean
+	ValueNull
+	ValueEnum
+	ValueList
+	ValueObject
+)
+
+// Value is a literal or a variable, Raw is the literal as it's written and the name for variables.
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []*Value
+	Fields []*ObjectField
+	Pos    int
+}
+
+type ObjectField struct {
+	Name  string
+	Value *Value
+	Pos   int
+}
+
+// OperationNames returns the names of the named operations of the document.
+func (d *Document) OperationNames() []string {
+	var names []string
+	for _, op := range d.Operations {
+		if op.Name != "" {
+			names = append(names, op.Name)
+		}
+	}
+	return names
+}
+
+// Operation returns the operation with the given name, an empty name selects the only operation of the
+// document. it returns nil when there's no such operation.
+func (d *Document) Operation(name string) *Operation {
+	if name == "" {
+		if len(d.Operations) == 1 {
+			return d.Operations[0]
+		}
+		return nil
+	}
+
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op
+		}
+	}
+	return nil
+}
+
+// Fragment returns the fragment with the given name, or nil.
+func (d *Document) Fragment(name string) *Fragment {
+	for _, f := range d.Fragments {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// ParseQuery parses a query document, it returns the first syntax error.
+func ParseQuery(src string) (*Document, *Error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	if err := p.parse(func() { p.parseDocument(doc) }); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (p *parser) parseDocument(doc *Document) {
+	if p.eof() {
+		p.fail(len(p.src), "the document has no operations")
+	}
+
+	for !p.eof() {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenPunct && tok.value == "{":
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", SelectionSet: p.parseSelectionSet(), Pos: tok.start})
+		case tok.kind == tokenName && (tok.value == "query" || tok.value == "mutation" || tok.value == "subscription"):
+			doc.Operations = append(doc.Operations, p.parseOperation())
+		case tok.kind == tokenName && tok.value == "fragment":
+			doc.Fragments = append(doc.Fragments, p.parseFragment())
+		default:
+			p.fail(tok.start, "expected an operation or a fragment, found %s", describe(tok))
+		}
+	}
+}
+
+func (p *parser) parseOperation() *Operation {
+	tok := p.next()
+	op := &Operation{Type: tok.value, Pos: tok.start}
+	if p.peek().kind == tokenName {
+		op.Name = p.next().value
+	}
+
+	if p.peekPunct("(") {
+		open := p.next()
+		for !p.skipPunct(")") {
+			if p.eof() {
+				p.fail(open.start, "unterminated variable definitions")
+			}
+
+			dollar := p.expectPunct("$")
+			def := &VariableDefinition{Name: p.expectName().value, Pos: dollar.start}
+			p.expectPunct(":")
+			def.Type = p.parseTypeRef()
+			if p.skipPunct("=") {
+				def.DefaultValue = p.parseValue(true)
+			}
+			p.parseDirectives(true)
+			op.VariableDefinitions = append(op.VariableDefinitions, def)
+		}
+	}
+
+	op.Directives = p.parseDirectives(false)
+	op.SelectionSet = p.parseSelectionSet()
+	return op
+}
+
+func (p *parser) parseFragment() *Fragment {
+	tok := p.next()
+	name := p.expectName()
+	if name.value == "on" {
+		p.fail(name.start, "unexpected \"on\", expected the name of the fragment")
+	}
+
+	frag := &Fragment{Name: name.value, Pos: tok.start}
+	p.expectKeyword("on")
+	frag.TypeCondition = p.expectName().value
+	frag.Directives = p.parseDirectives(false)
+	frag.SelectionSet = p.parseSelectionSet()
+	return frag
+}
+
+func (p *parser) parseSelectionSet() []Selection {
+	open := p.expectPunct("{")
+
+	var out []Selection
+	for !p.skipPunct("}") {
+		if p.eof() {
+			p.fail(open.start, "unterminated selection set")
+		}
+		out = append(out, p.parseSelection())
+	}
+
+	if len(out) == 0 {
+		p.fail(open.start, "expected at least one field in the selection set")
+	}
+	return out
+}
+
+func (p *parser) parseSelection() Selection {
+	if p.peek().kind == tokenSpread {
+		spread := p.next()
+		if p.peek().kind == tokenName && p.peek().value != "on" {
+			return &FragmentSpread{Name: p.next().value, Directives: p.parseDirectives(false), Pos: spread.start}
+		}
+
+		frag := &InlineFragment{Pos: spread.start}
+		if p.skipKeyword("on") {
+			frag.TypeCondition = p.expectName().value
+		}
+		frag.Directives = p.parseDirectives(false)
+		frag.SelectionSet = p.parseSelectionSet()
+		return frag
+	}
+
+	name := p.expectName()
+	f := &FieldSelection{Name: name.value, Pos: name.start}
+	if p.skipPunct(":") {
+		f.Alias = f.Name
+		f.Name = p.expectName().value
+	}
+
+	f.Arguments = p.parseArguments(false)
+	f.Directives = p.parseDirectives(false)
+	if p.peekPunct("{") {
+		f.SelectionSet = p.parseSelectionSet()
+	}
+	return f
+}
@@ -0,0 +1,202 @@
+package gql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSDL = `
+"""
+The root of the queries.
+"""
+type Query {
+  "Returns a user by its id."
+  user(id: ID!): User
+  users(first: Int = 10, role: Role, filter: UserFilter): [User!]!
+  node(id: ID!): Node
+}
+
+type Mutation {
+  createUser(input: UserFilter!): User
+}
+
+interface Node {
+  id: ID!
+}
+
+type User implements Node {
+  id: ID!
+  name: String
+  role: Role
+  friends(first: Int): [User]
+  age: Int @deprecated(reason: "Use birthday.")
+}
+
+enum Role {
+  ADMIN
+  MEMBER
+}
+
+input UserFilter {
+  name: String!
+  roles: [Role!]
+}
+
+extend type Query {
+  me: User
+}
+`
+
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	s, err := ParseSDL(testSDL)
+	require.NoError(t, err)
+	return s
+}
+
+func TestParseSDL(t *testing.T) {
+	s := testSchema(t)
+
+	require.Equal(t, "Query", s.QueryType)
+	require.Equal(t, "Mutation", s.MutationType)
+	require.Empty(t, s.SubscriptionType)
+
+	query := s.Type("Query")
+	require.Equal(t, "The root of the queries.", query.Description)
+	require.NotNil(t, query.Field("me"))
+
+	users := query.Field("users")
+	require.Equal(t, "[User!]!", users.Type.String())
+	require.Equal(t, KindObject, users.Type.OfType.OfType.OfType.Kind)
+	require.Equal(t, "10", findInputValue(users.Args, "first").DefaultValue)
+
+	age := s.Type("User").Field("age")
+	require.True(t, age.IsDeprecated)
+	require.Equal(t, "Use birthday.", age.DeprecationReason)
+
+	require.Equal(t, []string{"User"}, s.Type("Node").PossibleTypes)
+	require.NotNil(t, s.Type("String"))
+	require.NotNil(t, s.Directive("include"))
+
+	_, err := ParseSDL("type Query { user: }")
+	require.Error(t, err)
+}
+
+func TestParseIntrospection(t *testing.T) {
+	data, err := json.Marshal(map[string]any{
+		"data": map[string]any{
+			"__schema": map[string]any{
+				"queryType": map[string]any{"name": "Query"},
+				"types": []any{
+					map[string]any{
+						"kind": "OBJECT",
+						"name": "Query",
+						"fields": []any{
+							map[string]any{
+								"name": "hello",
+								"args": []any{
+									map[string]any{"name": "name", "type": map[string]any{"kind": "SCALAR", "name": "String"}, "defaultValue": `"world"`},
+								},
+								"type": map[string]any{"kind": "NON_NULL", "name": nil, "ofType": map[string]any{"kind": "SCALAR", "name": "String"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	s, err := ParseSchemaFile(data)
+	require.NoError(t, err)
+
+	hello := s.Type("Query").Field("hello")
+	require.Equal(t, "String!", hello.Type.String())
+	require.Equal(t, `"world"`, hello.Args[0].DefaultValue)
+	require.NotNil(t, s.Type("Boolean"))
+
+	_, err = ParseIntrospection([]byte(`{"errors":[{"message":"introspection is disabled"}]}`))
+	require.EqualError(t, err, "introspection failed: introspection is disabled")
+}
+
+func TestValidate(t *testing.T) {
+	s := testSchema(t)
+
+	require.Empty(t, Validate(s, `
+query Users($role: Role) {
+  users(role: $role, filter: {name: "a", roles: [ADMIN]}) { id ...UserFields }
+  node(id: 1) { ... on User { name } }
+  __typename
+}
+
+fragment UserFields on User { name friends(first: 2) { id } }
+`))
+
+	tests := []struct {
+		name  string
+		query string
+		err   string
+	}{
+		{"syntax", `{ user(id: 1) { id }`, "1:1: unterminated selection set"},
+		{"unknown field", `{ me { nmae } }`, `1:8: cannot query field "nmae" on type "User", did you mean "name"?`},
+		{"missing argument", `{ user { id } }`, `1:3: field "user" argument "id" of type "ID!" is required, but it was not provided`},
+		{"unknown argument", `{ user(id: 1, ids: 2) { id } }`, `1:15: unknown argument "ids" on field "user", did you mean "id"?`},
+		{"missing subfields", `{ me }`, `1:3: field "me" of type "User" must have a selection of subfields`},
+		{"leaf subfields", `{ me { name { id } } }`, `1:8: field "name" must not have a selection since type "String" has no subfields`},
+		{"enum value", `{ users(role: OWNER) { id } }`, `1:15: value "OWNER" does not exist in "Role" enum`},
+		{"scalar value", `{ users(first: "ten") { id } }`, `1:16: Int cannot represent the value "ten"`},
+		{"input field", `{ users(filter: {roles: []}) { id } }`, `1:17: field "UserFilter.name" of required type "String!" was not provided`},
+		{"undefined variable", `{ user(id: $id) { id } }`, `1:12: variable "$id" is not defined`},
+		{"unknown fragment", `{ me { ...Missing } }`, `1:8: unknown fragment "Missing"`},
+		{"subscription", `subscription { me { id } }`, `1:1: the schema does not support subscription operations`},
+		{"anonymous", "{ me { id } }\nquery A { me { id } }", `1:1: this anonymous operation must be the only defined operation`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate(s, tt.query)
+			require.NotEmpty(t, errs)
+			require.Equal(t, tt.err, errs[0].Error())
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := testSchema(t)
+
+	labels := func(query string) []string {
+		offset := len(query)
+		start, suggestions := Complete(s, query+" }", offset)
+		require.LessOrEqual(t, start, offset)
+
+		out := make([]string, 0, len(suggestions))
+		for _, sg := range suggestions {
+			out = append(out, sg.Label)
+		}
+		return out
+	}
+
+	require.Equal(t, []string{"user", "users"}, labels(`{ us`))
+	require.Equal(t, []string{"id", "name", "role", "friends", "age", "__typename"}, labels(`query { me { `))
+	require.Equal(t, []string{"first", "role", "filter"}, labels(`{ users(`))
+	require.Equal(t, []string{"role", "filter"}, labels(`{ users(first: 1, `))
+	require.Equal(t, []string{"ADMIN", "MEMBER"}, labels(`{ users(role: `))
+	require.Equal(t, []string{"MEMBER"}, labels(`{ users(role: M`))
+	require.Equal(t, []string{"name", "roles"}, labels(`{ users(filter: {`))
+	require.Equal(t, []string{"ADMIN", "MEMBER"}, labels(`{ users(filter: {name: "a", roles: [`))
+	require.Equal(t, []string{"id", "name"}, labels(`{ users(filter: {name: "a"}) { id ...on User { friends { `)[:2])
+	require.Equal(t, []string{"Node", "User"}, labels(`{ node(id: 1) { ... on `))
+	require.Equal(t, []string{"role"}, labels(`query Q($role: Role) { users(role: $`))
+	require.Equal(t, []string{"include", "skip"}, labels(`{ me @`))
+	require.Equal(t, []string{"Boolean", "Float", "ID", "Int", "Role", "String", "UserFilter"}, labels(`query Q($a: `))
+	require.Empty(t, labels(`{ user(id: "us`))
+	require.Empty(t, labels("{ # us"))
+
+	start, suggestions := Complete(s, `{ users(fil`, 11)
+	require.Equal(t, 8, start)
+	require.Equal(t, "filter: ", suggestions[0].Insert)
+	require.Equal(t, "UserFilter", suggestions[0].Detail)
+}
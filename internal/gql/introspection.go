@@ -0,0 +1,281 @@
+package gql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IntrospectionQuery asks the server for its schema.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+type introspectionResponse struct {
+	Data   *introspectionData `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type introspectionData struct {
+	Schema *introspectionSchema `json:"__schema"`
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionName        `json:"queryType"`
+	MutationType     *introspectionName        `json:"mutationType"`
+	SubscriptionType *introspectionName        `json:"subscriptionType"`
+	Types            []*introspectionType      `json:"types"`
+	Directives       []*introspectionDirective `json:"directives"`
+}
+
+type introspectionName struct {
+	Name string `json:"name"`
+}
+
+type introspectionType struct {
+	Kind          string                    `json:"kind"`
+	Name          string                    `json:"name"`
+	Description   string                    `json:"description"`
+	Fields        []*introspectionField     `json:"fields"`
+	InputFields   []*introspectionInput     `json:"inputFields"`
+	Interfaces    []*TypeRef                `json:"interfaces"`
+	EnumValues    []*introspectionEnumValue `json:"enumValues"`
+	PossibleTypes []*TypeRef                `json:"possibleTypes"`
+}
+
+type introspectionField struct {
+	Name              string                `json:"name"`
+	Description       string                `json:"description"`
+	Args              []*introspectionInput `json:"args"`
+	Type              *TypeRef              `json:"type"`
+	IsDeprecated      bool                  `json:"isDeprecated"`
+	DeprecationReason string                `json:"deprecationReason"`
+}
+
+type introspectionInput struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Type         *TypeRef `json:"type"`
+	DefaultValue *string  `json:"defaultValue"`
+}
+
+type introspectionEnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason"`
+}
+
+type introspectionDirective struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Locations   []string              `json:"locations"`
+	Args        []*introspectionInput `json:"args"`
+}
+
+func (r *TypeRef) UnmarshalJSON(data []byte) error {
+	var ref struct {
+		Kind   string   `json:"kind"`
+		Name   *string  `json:"name"`
+		OfType *TypeRef `json:"ofType"`
+	}
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return err
+	}
+
+	r.Kind = ref.Kind
+	if ref.Name != nil {
+		r.Name = *ref.Name
+	}
+	r.OfType = ref.OfType
+	return nil
+}
+
+// ParseIntrospection reads the schema from the result of the introspection query, the result may be
+// the whole response or only its data.
+func ParseIntrospection(data []byte) (*Schema, error) {
+	var resp introspectionResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("invalid introspection result: %w", err)
+	}
+
+	if resp.Data == nil {
+		var d introspectionData
+		if err := json.Unmarshal(data, &d); err == nil && d.Schema != nil {
+			resp.Data = &d
+		}
+	}
+
+	if resp.Data == nil || resp.Data.Schema == nil {
+		if len(resp.Errors) > 0 {
+			msgs := make([]string, 0, len(resp.Errors))
+			for _, e := range resp.Errors {
+				msgs = append(msgs, e.Message)
+			}
+			return nil, fmt.Errorf("introspection failed: %s", strings.Join(msgs, ", "))
+		}
+		return nil, errors.New("introspection result has no schema")
+	}
+
+	in := resp.Data.Schema
+	s := &Schema{Types: make(map[string]*Type, len(in.Types))}
+	if in.QueryType != nil {
+		s.QueryType = in.QueryType.Name
+	}
+	if in.MutationType != nil {
+		s.MutationType = in.MutationType.Name
+	}
+	if in.SubscriptionType != nil {
+		s.SubscriptionType = in.SubscriptionType.Name
+	}
+
+	for _, it := range in.Types {
+		t := &Type{
+			Kind:        it.Kind,
+			Name:        it.Name,
+			Description: it.Description,
+			InputFields: inputValues(it.InputFields),
+		}
+
+		for _, f := range it.Fields {
+			t.Fields = append(t.Fields, &Field{
+				Name:              f.Name,
+				Description:       f.Description,
+				Args:              inputValues(f.Args),
+				Type:              f.Type,
+				IsDeprecated:      f.IsDeprecated,
+				DeprecationReason: f.DeprecationReason,
+			})
+		}
+
+		for _, ref := range it.Interfaces {
+			t.Interfaces = append(t.Interfaces, ref.NamedType())
+		}
+
+		for _, ref := range it.PossibleTypes {
+			t.PossibleTypes = append(t.PossibleTypes, ref.NamedType())
+		}
+
+		for _, v := range it.EnumValues {
+			t.EnumValues = append(t.EnumValues, &EnumValue{
+				Name:              v.Name,
+				Description:       v.Description,
+				IsDeprecated:      v.IsDeprecated,
+				DeprecationReason: v.DeprecationReason,
+			})
+		}
+
+		s.Types[t.Name] = t
+	}
+
+	for _, d := range in.Directives {
+		s.Directives = append(s.Directives, &Directive{
+			Name:        d.Name,
+			Description: d.Description,
+			Locations:   d.Locations,
+			Args:        inputValues(d.Args),
+		})
+	}
+
+	s.complete()
+	return s, nil
+}
+
+func inputValues(in []*introspectionInput) []*InputValue {
+	var out []*InputValue
+	for _, v := range in {
+		iv := &InputValue{Name: v.Name, Description: v.Description, Type: v.Type}
+		if v.DefaultValue != nil {
+			iv.DefaultValue = *v.DefaultValue
+		}
+		out = append(out, iv)
+	}
+	return out
+}
+
+// ParseSchemaFile reads a schema file, it's either a SDL document or the json result of an introspection query.
+func ParseSchemaFile(data []byte) (*Schema, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return ParseIntrospection(trimmed)
+	}
+	return ParseSDL(string(data))
+}
@@ -0,0 +1,273 @@
+// Package gql parses graphql schemas and queries, it validates the queries against a schema and
+// suggests completions while they are written.
+package gql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenBlockString
+	tokenSpread
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	// start and end are the byte offsets of the token in the source
+	start, end int
+}
+
+// Error is a syntax or validation error of a document, Line and Column start at 1.
+type Error struct {
+	Message string
+	Offset  int
+	Line    int
+	Column  int
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+func newError(src string, offset int, format string, args ...any) *Error {
+	line, col := position(src, offset)
+	return &Error{Message: fmt.Sprintf(format, args...), Offset: offset, Line: line, Column: col}
+}
+
+// position returns the line and column of the byte offset, columns count runes.
+func position(src string, offset int) (int, int) {
+	offset = min(offset, len(src))
+	before := src[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+	return line, col
+}
+
+// lex splits the source into tokens, commas and comments are ignored like white space. the tokens read
+// before an error are returned with it.
+func lex(src string) ([]token, *Error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == 0xEF && strings.HasPrefix(src[i:], "\uFEFF"):
+			i += 3
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case c == '.':
+			if !strings.HasPrefix(src[i:], "...") {
+				return tokens, newError(src, i, "unexpected %q, did you mean \"...\"?", ".")
+			}
+			tokens = append(tokens, token{kind: tokenSpread, value: "...", start: i, end: i + 3})
+			i += 3
+		case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+			tokens = append(tokens, token{kind: tokenPunct, value: string(c), start: i, end: i + 1})
+			i++
+		case isNameStart(c):
+			start := i
+			for i < len(src) && isNameContinue(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, value: src[start:i], start: start, end: i})
+		case c == '-' || isDigit(c):
+			tok, err := lexNumber(src, i)
+			if err != nil {
+				return tokens, err
+			}
+			tokens = append(tokens, tok)
+			i = tok.end
+		case c == '"':
+			tok, err := lexString(src, i)
+			if err != nil {
+				return tokens, err
+			}
+			tokens = append(tokens, tok)
+			i = tok.end
+		default:
+			r, _ := utf8.DecodeRuneInString(src[i:])
+			return tokens, newError(src, i, "unexpected character %q", r)
+		}
+	}
+
+	return tokens, nil
+}
+
+func lexNumber(src string, start int) (token, *Error) {
+	i := start
+	if src[i] == '-' {
+		i++
+	}
+
+	digits := func() int {
+		n := 0
+		for i < len(src) && isDigit(src[i]) {
+			i++
+			n++
+		}
+		return n
+	}
+
+	if digits() == 0 {
+		return token{}, newError(src, start, "invalid number, expected a digit after %q", src[start:i])
+	}
+
+	kind := tokenInt
+	if i < len(src) && src[i] == '.' {
+		i++
+		kind = tokenFloat
+		if digits() == 0 {
+			return token{}, newError(src, start, "invalid number %q", src[start:i])
+		}
+	}
+
+	if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		i++
+		kind = tokenFloat
+		if i < len(src) && (src[i] == '+' || src[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return token{}, newError(src, start, "invalid number %q", src[start:i])
+		}
+	}
+
+	if i < len(src) && (isNameStart(src[i]) || src[i] == '.') {
+		return token{}, newError(src, i, "invalid number, unexpected %q", src[i])
+	}
+
+	return token{kind: kind, value: src[start:i], start: start, end: i}, nil
+}
+
+func lexString(src string, start int) (token, *Error) {
+	if strings.HasPrefix(src[start:], `"""`) {
+		end := start + 3
+		for {
+			idx := strings.Index(src[end:], `"""`)
+			if idx < 0 {
+				return token{}, newError(src, start, "unterminated block string")
+			}
+			end += idx
+			// escaped triple quotes are part of the string
+			if end > 0 && src[end-1] == '\\' {
+				end += 3
+				continue
+			}
+			break
+		}
+
+		raw := strings.ReplaceAll(src[start+3:end], `\"""`, `"""`)
+		return token{kind: tokenBlockString, value: blockStringValue(raw), start: start, end: end + 3}, nil
+	}
+
+	var b strings.Builder
+	i := start + 1
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '"':
+			return token{kind: tokenString, value: b.String(), start: start, end: i + 1}, nil
+		case c == '\n' || c == '\r':
+			return token{}, newError(src, start, "unterminated string")
+		case c == '\\':
+			if i+1 >= len(src) {
+				return token{}, newError(src, start, "unterminated string")
+			}
+			esc := src[i+1]
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+6 > len(src) {
+					return token{}, newError(src, i, "invalid unicode escape")
+				}
+				var r rune
+				if _, err := fmt.Sscanf(src[i+2:i+6], "%04x", &r); err != nil {
+					return token{}, newError(src, i, "invalid unicode escape %q", src[i:i+6])
+				}
+				b.WriteRune(r)
+				i += 4
+			default:
+				return token{}, newError(src, i, "invalid escape sequence \\%c", esc)
+			}
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return token{}, newError(src, start, "unterminated string")
+}
+
+// blockStringValue removes the common indentation and the blank first and last lines of a block string.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
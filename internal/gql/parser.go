@@ -0,0 +1,231 @@
+package gql
+
+// parser reads the tokens of a document, the errors are raised with panics and recovered by parse so the
+// grammar functions stay short.
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+type parseError struct {
+	err *Error
+}
+
+func newParser(src string) (*parser, *Error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{src: src, tokens: tokens}, nil
+}
+
+// parse runs fn and returns the syntax error it raised, if any.
+func (p *parser) parse(fn func()) (err *Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pe, ok := r.(parseError)
+			if !ok {
+				panic(r)
+			}
+			err = pe.err
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+func (p *parser) fail(offset int, format string, args ...any) {
+	panic(parseError{err: newError(p.src, offset, format, args...)})
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenEOF, start: len(p.src), end: len(p.src)}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) eof() bool {
+	return p.peek().kind == tokenEOF
+}
+
+// peekPunct reports whether the next token is the punctuator.
+func (p *parser) peekPunct(value string) bool {
+	tok := p.peek()
+	return tok.kind == tokenPunct && tok.value == value
+}
+
+// skipPunct consumes the next token when it's the punctuator.
+func (p *parser) skipPunct(value string) bool {
+	if p.peekPunct(value) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// peekKeyword reports whether the next token is the name.
+func (p *parser) peekKeyword(value string) bool {
+	tok := p.peek()
+	return tok.kind == tokenName && tok.value == value
+}
+
+func (p *parser) skipKeyword(value string) bool {
+	if p.peekKeyword(value) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectPunct(value string) token {
+	tok := p.next()
+	if tok.kind != tokenPunct || tok.value != value {
+		p.fail(tok.start, "expected %q, found %s", value, describe(tok))
+	}
+	return tok
+}
+
+func (p *parser) expectKeyword(value string) token {
+	tok := p.next()
+	if tok.kind != tokenName || tok.value != value {
+		p.fail(tok.start, "expected %q, found %s", value, describe(tok))
+	}
+	return tok
+}
+
+func (p *parser) expectName() token {
+	tok := p.next()
+	if tok.kind != tokenName {
+		p.fail(tok.start, "expected a name, found %s", describe(tok))
+	}
+	return tok
+}
+
+// parseTypeRef reads a type reference like [String!]!, the kinds of the named types are resolved later.
+func (p *parser) parseTypeRef() *TypeRef {
+	var ref *TypeRef
+	if p.skipPunct("[") {
+		ref = &TypeRef{Kind: KindList, OfType: p.parseTypeRef()}
+		p.expectPunct("]")
+	} else {
+		ref = namedRef(p.expectName().value)
+	}
+
+	if p.skipPunct("!") {
+		ref = &TypeRef{Kind: KindNonNull, OfType: ref}
+	}
+	return ref
+}
+
+// parseValue reads a value, constant values may not contain variables.
+func (p *parser) parseValue(constant bool) *Value {
+	tok := p.next()
+	v := &Value{Raw: p.src[tok.start:tok.end], Pos: tok.start}
+
+	switch tok.kind {
+	case tokenInt:
+		v.Kind = ValueInt
+	case tokenFloat:
+		v.Kind = ValueFloat
+	case tokenString, tokenBlockString:
+		v.Kind = ValueString
+	case tokenName:
+		switch tok.value {
+		case "true", "false":
+			v.Kind = ValueBoolean
+		case "null":
+			v.Kind = ValueNull
+		default:
+			v.Kind = ValueEnum
+		}
+	case tokenPunct:
+		switch tok.value {
+		case "$":
+			if constant {
+				p.fail(tok.start, "unexpected variable in a constant value")
+			}
+			v.Kind = ValueVariable
+			v.Raw = p.expectName().value
+		case "[":
+			v.Kind = ValueList
+			for !p.skipPunct("]") {
+				if p.eof() {
+					p.fail(tok.start, "unterminated list")
+				}
+				v.List = append(v.List, p.parseValue(constant))
+			}
+		case "{":
+			v.Kind = ValueObject
+			for !p.skipPunct("}") {
+				if p.eof() {
+					p.fail(tok.start, "unterminated object")
+				}
+				name := p.expectName()
+				p.expectPunct(":")
+				v.Fields = append(v.Fields, &ObjectField{Name: name.value, Value: p.parseValue(constant), Pos: name.start})
+			}
+		default:
+			p.fail(tok.start, "expected a value, found %s", describe(tok))
+		}
+	default:
+		p.fail(tok.start, "expected a value, found %s", describe(tok))
+	}
+
+	return v
+}
+
+// parseArguments reads the optional arguments in parentheses.
+func (p *parser) parseArguments(constant bool) []*Argument {
+	if !p.peekPunct("(") {
+		return nil
+	}
+
+	open := p.next()
+	var args []*Argument
+	for !p.skipPunct(")") {
+		if p.eof() {
+			p.fail(open.start, "unterminated arguments")
+		}
+		name := p.expectName()
+		p.expectPunct(":")
+		args = append(args, &Argument{Name: name.value, Value: p.parseValue(constant), Pos: name.start})
+	}
+
+	if len(args) == 0 {
+		p.fail(open.start, "expected at least one argument")
+	}
+	return args
+}
+
+// parseDirectives reads the directives applied to a definition.
+func (p *parser) parseDirectives(constant bool) []*AppliedDirective {
+	var out []*AppliedDirective
+	for p.peekPunct("@") {
+		at := p.next()
+		name := p.expectName()
+		out = append(out, &AppliedDirective{Name: name.value, Arguments: p.parseArguments(constant), Pos: at.start})
+	}
+	return out
+}
+
+func describe(tok token) string {
+	switch tok.kind {
+	case tokenEOF:
+		return "end of document"
+	case tokenString, tokenBlockString:
+		return "a string"
+	default:
+		return "\"" + tok.value + "\""
+	}
+}
@@ -0,0 +1,346 @@
+package gql
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// kinds of the schema types, as named by the introspection
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+	KindList        = "LIST"
+	KindNonNull     = "NON_NULL"
+)
+
+// Schema is the type system of a graphql server.
+type Schema struct {
+	QueryType        string
+	MutationType     string
+	SubscriptionType string
+
+	Types      map[string]*Type
+	Directives []*Directive
+}
+
+type Type struct {
+	Kind        string
+	Name        string
+	Description string
+
+	Fields        []*Field
+	Interfaces    []string
+	PossibleTypes []string
+	EnumValues    []*EnumValue
+	InputFields   []*InputValue
+}
+
+type Field struct {
+	Name        string
+	Description string
+	Args        []*InputValue
+	Type        *TypeRef
+
+	IsDeprecated      bool
+	DeprecationReason string
+}
+
+type InputValue struct {
+	Name         string
+	Description  string
+	Type         *TypeRef
+	DefaultValue string
+}
+
+type EnumValue struct {
+	Name        string
+	Description string
+
+	IsDeprecated      bool
+	DeprecationReason string
+}
+
+type Directive struct {
+	Name        string
+	Description string
+	Locations   []string
+	Args        []*InputValue
+}
+
+// TypeRef is a reference to a named type, possibly wrapped in lists and non null modifiers.
+type TypeRef struct {
+	Kind   string
+	Name   string
+	OfType *TypeRef
+}
+
+func (t *TypeRef) String() string {
+	if t == nil {
+		return ""
+	}
+
+	switch t.Kind {
+	case KindNonNull:
+		return t.OfType.String() + "!"
+	case KindList:
+		return "[" + t.OfType.String() + "]"
+	default:
+		return t.Name
+	}
+}
+
+// NamedType returns the name of the type once the list and non null modifiers are removed.
+func (t *TypeRef) NamedType() string {
+	for t != nil && t.OfType != nil {
+		t = t.OfType
+	}
+
+	if t == nil {
+		return ""
+	}
+	return t.Name
+}
+
+// IsNonNull reports whether the value is required.
+func (t *TypeRef) IsNonNull() bool {
+	return t != nil && t.Kind == KindNonNull
+}
+
+// Nullable returns the type without its non null modifier.
+func (t *TypeRef) Nullable() *TypeRef {
+	if t.IsNonNull() {
+		return t.OfType
+	}
+	return t
+}
+
+func namedRef(name string) *TypeRef {
+	return &TypeRef{Name: name}
+}
+
+var builtinScalars = []struct{ name, description string }{
+	{"Int", "The `Int` scalar type represents non-fractional signed whole numeric values between -(2^31) and 2^31 - 1."},
+	{"Float", "The `Float` scalar type represents signed double-precision fractional values as specified by IEEE 754."},
+	{"String", "The `String` scalar type represents textual data, represented as UTF-8 character sequences."},
+	{"Boolean", "The `Boolean` scalar type represents `true` or `false`."},
+	{"ID", "The `ID` scalar type represents a unique identifier, it's serialized as a string."},
+}
+
+var builtinDirectives = []*Directive{
+	{
+		Name:        "include",
+		Description: "Directs the executor to include this field or fragment only when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*InputValue{{Name: "if", Description: "Included when true.", Type: &TypeRef{Kind: KindNonNull, OfType: namedRef("Boolean")}}},
+	},
+	{
+		Name:        "skip",
+		Description: "Directs the executor to skip this field or fragment when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*InputValue{{Name: "if", Description: "Skipped when true.", Type: &TypeRef{Kind: KindNonNull, OfType: namedRef("Boolean")}}},
+	},
+}
+
+// complete adds the built-in scalars and directives missing from the schema and resolves the kinds of the
+// named type references, SDL documents leave them out.
+func (s *Schema) complete() {
+	if s.Types == nil {
+		s.Types = make(map[string]*Type)
+	}
+
+	for _, sc := range builtinScalars {
+		if _, ok := s.Types[sc.name]; !ok {
+			s.Types[sc.name] = &Type{Kind: KindScalar, Name: sc.name, Description: sc.description}
+		}
+	}
+
+	for _, d := range builtinDirectives {
+		if s.Directive(d.Name) == nil {
+			s.Directives = append(s.Directives, d)
+		}
+	}
+
+	if s.QueryType == "" {
+		if _, ok := s.Types["Query"]; ok {
+			s.QueryType = "Query"
+		}
+	}
+	if s.MutationType == "" {
+		if _, ok := s.Types["Mutation"]; ok {
+			s.MutationType = "Mutation"
+		}
+	}
+	if s.SubscriptionType == "" {
+		if _, ok := s.Types["Subscription"]; ok {
+			s.SubscriptionType = "Subscription"
+		}
+	}
+
+	resolve := func(ref *TypeRef) {
+		for ; ref != nil; ref = ref.OfType {
+			if ref.OfType == nil && ref.Kind == "" {
+				if t, ok := s.Types[ref.Name]; ok {
+					ref.Kind = t.Kind
+				}
+			}
+		}
+	}
+
+	for _, t := range s.Types {
+		for _, f := range t.Fields {
+			resolve(f.Type)
+			for _, a := range f.Args {
+				resolve(a.Type)
+			}
+		}
+		for _, f := range t.InputFields {
+			resolve(f.Type)
+		}
+	}
+
+	// the implementations of the interfaces are only listed on the objects in SDL documents
+	for _, t := range s.Types {
+		for _, name := range t.Interfaces {
+			if iface, ok := s.Types[name]; ok && iface.Kind == KindInterface && !slices.Contains(iface.PossibleTypes, t.Name) {
+				iface.PossibleTypes = append(iface.PossibleTypes, t.Name)
+			}
+		}
+	}
+}
+
+// Type returns the type with the given name, or nil.
+func (s *Schema) Type(name string) *Type {
+	if s == nil {
+		return nil
+	}
+	return s.Types[name]
+}
+
+// RootType returns the root type of the operation, query, mutation or subscription.
+func (s *Schema) RootType(operation string) *Type {
+	switch operation {
+	case "mutation":
+		return s.Type(s.MutationType)
+	case "subscription":
+		return s.Type(s.SubscriptionType)
+	default:
+		return s.Type(s.QueryType)
+	}
+}
+
+// Directive returns the directive with the given name, or nil.
+func (s *Schema) Directive(name string) *Directive {
+	for _, d := range s.Directives {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// TypeNames returns the names of the types sorted, the introspection types are left out.
+func (s *Schema) TypeNames() []string {
+	names := make([]string, 0, len(s.Types))
+	for name := range s.Types {
+		if !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Field returns the field with the given name, the __typename meta field is available on all composite types.
+func (t *Type) Field(name string) *Field {
+	if t == nil {
+		return nil
+	}
+
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+
+	if name == "__typename" && t.IsComposite() {
+		return typenameField
+	}
+	return nil
+}
+
+// InputField returns the field of the input object with the given name, or nil.
+func (t *Type) InputField(name string) *InputValue {
+	if t == nil {
+		return nil
+	}
+	return findInputValue(t.InputFields, name)
+}
+
+// EnumValue returns the value of the enum with the given name, or nil.
+func (t *Type) EnumValue(name string) *EnumValue {
+	if t == nil {
+		return nil
+	}
+
+	for _, v := range t.EnumValues {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// IsComposite reports whether the type has fields to select.
+func (t *Type) IsComposite() bool {
+	return t != nil && (t.Kind == KindObject || t.Kind == KindInterface || t.Kind == KindUnion)
+}
+
+// IsInput reports whether values of the type can be given as arguments and variables.
+func (t *Type) IsInput() bool {
+	return t != nil && (t.Kind == KindScalar || t.Kind == KindEnum || t.Kind == KindInputObject)
+}
+
+// Overlaps reports whether the fields of other may be selected on t, it's used for the fragments.
+func (s *Schema) Overlaps(t, other *Type) bool {
+	if t == nil || other == nil {
+		return true
+	}
+
+	if t.Name == other.Name {
+		return true
+	}
+
+	possible := func(t *Type) []string {
+		if t.Kind == KindObject {
+			return []string{t.Name}
+		}
+		return t.PossibleTypes
+	}
+
+	for _, a := range possible(t) {
+		if slices.Contains(possible(other), a) {
+			return true
+		}
+	}
+	return false
+}
+
+var typenameField = &Field{
+	Name:        "__typename",
+	Description: "The name of the object type.",
+	Type:        &TypeRef{Kind: KindNonNull, OfType: &TypeRef{Kind: KindScalar, Name: "String"}},
+}
+
+func findInputValue(values []*InputValue, name string) *InputValue {
+	for _, v := range values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package gql
+
+import "fmt"
+
+// ParseSDL parses a schema written in the graphql schema definition language, type extensions are merged
+// into the types they extend.
+func ParseSDL(src string) (*Schema, error) {
+	p, perr := newParser(src)
+	if perr != nil {
+		return nil, perr
+	}
+
+	s := &Schema{Types: make(map[string]*Type)}
+	var extensions []*Type
+	if perr := p.parse(func() {
+		for !p.eof() {
+			if ext := p.parseDefinition(s); ext != nil {
+				extensions = append(extensions, ext)
+			}
+		}
+	}); perr != nil {
+		return nil, perr
+	}
+
+	for _, ext := range extensions {
+		t, ok := s.Types[ext.Name]
+		if !ok {
+			return nil, fmt.Errorf("cannot extend type %q, it's not defined", ext.Name)
+		}
+		t.Fields = append(t.Fields, ext.Fields...)
+		t.Interfaces = append(t.Interfaces, ext.Interfaces...)
+		t.PossibleTypes = append(t.PossibleTypes, ext.PossibleTypes...)
+		t.EnumValues = append(t.EnumValues, ext.EnumValues...)
+		t.InputFields = append(t.InputFields, ext.InputFields...)
+	}
+
+	s.complete()
+	return s, nil
+}
+
+// parseDefinition reads a definition into the schema, the extensions are returned to be merged once
+// the whole document is read.
+func (p *parser) parseDefinition(s *Schema) *Type {
+	description := p.parseDescription()
+	extend := p.skipKeyword("extend")
+
+	tok := p.expectName()
+	if tok.value == "schema" {
+		p.parseSchemaDefinition(s)
+		return nil
+	}
+
+	if tok.value == "directive" {
+		if extend {
+			p.fail(tok.start, "directives cannot be extended")
+		}
+		d := &Directive{Description: description}
+		p.expectPunct("@")
+		d.Name = p.expectName().value
+		d.Args = p.parseInputValues("(", ")")
+		p.skipKeyword("repeatable")
+		p.expectKeyword("on")
+		p.skipPunct("|")
+		for {
+			d.Locations = append(d.Locations, p.expectName().value)
+			if !p.skipPunct("|") {
+				break
+			}
+		}
+		s.Directives = append(s.Directives, d)
+		return nil
+	}
+
+	t := &Type{Name: p.expectName().value, Description: description}
+	switch tok.value {
+	case "scalar":
+		t.Kind = KindScalar
+		p.parseDirectives(true)
+	case "type", "interface":
+		t.Kind = KindObject
+		if tok.value == "interface" {
+			t.Kind = KindInterface
+		}
+		if p.skipKeyword("implements") {
+			p.skipPunct("&")
+			for {
+				t.Interfaces = append(t.Interfaces, p.expectName().value)
+				if !p.skipPunct("&") {
+					break
+				}
+			}
+		}
+		p.parseDirectives(true)
+		t.Fields = p.parseFieldDefinitions()
+	case "union":
+		t.Kind = KindUnion
+		p.parseDirectives(true)
+		if p.skipPunct("=") {
+			p.skipPunct("|")
+			for {
+				t.PossibleTypes = append(t.PossibleTypes, p.expectName().value)
+				if !p.skipPunct("|") {
+					break
+				}
+			}
+		}
+	case "enum":
+		t.Kind = KindEnum
+		p.parseDirectives(true)
+		if p.peekPunct("{") {
+			open := p.next()
+			for !p.skipPunct("}") {
+				if p.eof() {
+					p.fail(open.start, "unterminated enum values")
+				}
+				v := &EnumValue{Description: p.parseDescription(), Name: p.expectName().value}
+				v.IsDeprecated, v.DeprecationReason = deprecation(p.parseDirectives(true))
+				t.EnumValues = append(t.EnumValues, v)
+			}
+		}
+	case "input":
+		t.Kind = KindInputObject
+		p.parseDirectives(true)
+		t.InputFields = p.parseInputValues("{", "}")
+	default:
+		p.fail(tok.start, "unexpected %s, expected a definition", describe(tok))
+	}
+
+	if extend {
+		return t
+	}
+
+	if _, ok := s.Types[t.Name]; ok {
+		p.fail(tok.start, "type %q is defined more than once", t.Name)
+	}
+	s.Types[t.Name] = t
+	return nil
+}
+
+func (p *parser) parseSchemaDefinition(s *Schema) {
+	p.parseDirectives(true)
+	if !p.peekPunct("{") {
+		return
+	}
+
+	open := p.next()
+	for !p.skipPunct("}") {
+		if p.eof() {
+			p.fail(open.start, "unterminated schema definition")
+		}
+		op := p.expectName()
+		p.expectPunct(":")
+		name := p.expectName().value
+		switch op.value {
+		case "query":
+			s.QueryType = name
+		case "mutation":
+			s.MutationType = name
+		case "subscription":
+			s.SubscriptionType = name
+		default:
+			p.fail(op.start, "unknown operation type %q", op.value)
+		}
+	}
+}
+
+func (p *parser) parseFieldDefinitions() []*Field {
+	if !p.peekPunct("{") {
+		return nil
+	}
+
+	open := p.next()
+	var fields []*Field
+	for !p.skipPunct("}") {
+		if p.eof() {
+			p.fail(open.start, "unterminated field definitions")
+		}
+
+		f := &Field{Description: p.parseDescription(), Name: p.expectName().value}
+		f.Args = p.parseInputValues("(", ")")
+		p.expectPunct(":")
+		f.Type = p.parseTypeRef()
+		f.IsDeprecated, f.DeprecationReason = deprecation(p.parseDirectives(true))
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// parseInputValues reads the arguments or the input fields between the open and close punctuators.
+func (p *parser) parseInputValues(openPunct, closePunct string) []*InputValue {
+	if !p.peekPunct(openPunct) {
+		return nil
+	}
+
+	open := p.next()
+	var values []*InputValue
+	for !p.skipPunct(closePunct) {
+		if p.eof() {
+			p.fail(open.start, "unterminated input values")
+		}
+
+		v := &InputValue{Description: p.parseDescription(), Name: p.expectName().value}
+		p.expectPunct(":")
+		v.Type = p.parseTypeRef()
+		if p.skipPunct("=") {
+			start := p.peek().start
+			p.parseValue(true)
+			v.DefaultValue = p.src[start:p.tokens[p.pos-1].end]
+		}
+		p.parseDirectives(true)
+		values = append(values, v)
+	}
+	return values
+}
+
+func (p *parser) parseDescription() string {
+	if tok := p.peek(); tok.kind == tokenString || tok.kind == tokenBlockString {
+		p.pos++
+		return tok.value
+	}
+	return ""
+}
+
+// deprecation reads the @deprecated directive, the reason defaults to the one of the specification.
+func deprecation(directives []*AppliedDirective) (bool, string) {
+	for _, d := range directives {
+		if d.Name != "deprecated" {
+			continue
+		}
+
+		reason := "No longer supported"
+		for _, arg := range d.Arguments {
+			if arg.Name == "reason" && arg.Value.Kind == ValueString {
+				if tokens, err := lex(arg.Value.Raw); err == nil && len(tokens) == 1 {
+					reason = tokens[0].value
+				}
+			}
+		}
+		return true, reason
+	}
+	return false, ""
+}
@@ -0,0 +1,380 @@
+package gql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type validator struct {
+	schema *Schema
+	src    string
+	doc    *Document
+	errs   []*Error
+
+	// variables are the variables of the operation being validated, nil in fragments since they may be
+	// spread in any operation
+	variables map[string]*VariableDefinition
+}
+
+// Validate checks the query against the schema, it returns the syntax error or the validation errors
+// sorted by their position. with a nil schema only the syntax is checked.
+func Validate(s *Schema, src string) []*Error {
+	doc, err := ParseQuery(src)
+	if err != nil {
+		return []*Error{err}
+	}
+
+	if s == nil {
+		return nil
+	}
+
+	v := &validator{schema: s, src: src, doc: doc}
+	v.validateDocument()
+	sort.SliceStable(v.errs, func(i, j int) bool { return v.errs[i].Offset < v.errs[j].Offset })
+	return v.errs
+}
+
+func (v *validator) report(offset int, format string, args ...any) {
+	v.errs = append(v.errs, newError(v.src, offset, format, args...))
+}
+
+func (v *validator) validateDocument() {
+	names := make(map[string]bool)
+	for _, op := range v.doc.Operations {
+		if op.Name == "" && len(v.doc.Operations) > 1 {
+			v.report(op.Pos, "this anonymous operation must be the only defined operation")
+		}
+		if op.Name != "" {
+			if names[op.Name] {
+				v.report(op.Pos, "there can be only one operation named %q", op.Name)
+			}
+			names[op.Name] = true
+		}
+		v.validateOperation(op)
+	}
+
+	fragments := make(map[string]bool)
+	for _, frag := range v.doc.Fragments {
+		if fragments[frag.Name] {
+			v.report(frag.Pos, "there can be only one fragment named %q", frag.Name)
+		}
+		fragments[frag.Name] = true
+
+		t := v.schema.Type(frag.TypeCondition)
+		switch {
+		case t == nil:
+			v.report(frag.Pos, "unknown type %q", frag.TypeCondition)
+		case !t.IsComposite():
+			v.report(frag.Pos, "fragment %q cannot condition on non composite type %q", frag.Name, frag.TypeCondition)
+		default:
+			v.variables = nil
+			v.validateDirectives(frag.Directives)
+			v.validateSelectionSet(t, frag.SelectionSet)
+		}
+	}
+}
+
+func (v *validator) validateOperation(op *Operation) {
+	root := v.schema.RootType(op.Type)
+	if root == nil {
+		v.report(op.Pos, "the schema does not support %s operations", op.Type)
+		return
+	}
+
+	v.variables = make(map[string]*VariableDefinition)
+	for _, def := range op.VariableDefinitions {
+		if _, ok := v.variables[def.Name]; ok {
+			v.report(def.Pos, "there can be only one variable named \"$%s\"", def.Name)
+		}
+		v.variables[def.Name] = def
+
+		t := v.schema.Type(def.Type.NamedType())
+		switch {
+		case t == nil:
+			v.report(def.Pos, "unknown type %q", def.Type.NamedType())
+		case !t.IsInput():
+			v.report(def.Pos, "variable \"$%s\" cannot be of non-input type %q", def.Name, def.Type)
+		case def.DefaultValue != nil:
+			v.validateValue(def.DefaultValue, v.resolve(def.Type))
+		}
+	}
+
+	v.validateDirectives(op.Directives)
+	v.validateSelectionSet(root, op.SelectionSet)
+}
+
+func (v *validator) validateSelectionSet(t *Type, selections []Selection) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *FieldSelection:
+			v.validateField(t, sel)
+		case *FragmentSpread:
+			v.validateDirectives(sel.Directives)
+			frag := v.doc.Fragment(sel.Name)
+			if frag == nil {
+				v.report(sel.Pos, "unknown fragment %q", sel.Name)
+				continue
+			}
+			if cond := v.schema.Type(frag.TypeCondition); !v.schema.Overlaps(t, cond) {
+				v.report(sel.Pos, "fragment %q cannot be spread here as objects of type %q can never be of type %q", sel.Name, t.Name, frag.TypeCondition)
+			}
+		case *InlineFragment:
+			v.validateDirectives(sel.Directives)
+			cond := t
+			if sel.TypeCondition != "" {
+				cond = v.schema.Type(sel.TypeCondition)
+				switch {
+				case cond == nil:
+					v.report(sel.Pos, "unknown type %q", sel.TypeCondition)
+					continue
+				case !cond.IsComposite():
+					v.report(sel.Pos, "fragment cannot condition on non composite type %q", sel.TypeCondition)
+					continue
+				case !v.schema.Overlaps(t, cond):
+					v.report(sel.Pos, "fragment cannot be spread here as objects of type %q can never be of type %q", t.Name, cond.Name)
+				}
+			}
+			v.validateSelectionSet(cond, sel.SelectionSet)
+		}
+	}
+}
+
+func (v *validator) validateField(parent *Type, sel *FieldSelection) {
+	// the introspection fields of the root type are not part of the schema types
+	if (sel.Name == "__schema" || sel.Name == "__type") && parent.Name == v.schema.QueryType {
+		return
+	}
+
+	f := parent.Field(sel.Name)
+	if f == nil {
+		v.report(sel.Pos, "cannot query field %q on type %q%s", sel.Name, parent.Name, suggest(sel.Name, fieldNames(parent)))
+		return
+	}
+
+	v.validateArguments(fmt.Sprintf("field %q", sel.Name), f.Args, sel.Arguments, sel.Pos)
+	v.validateDirectives(sel.Directives)
+
+	t := v.schema.Type(f.Type.NamedType())
+	if t == nil {
+		return
+	}
+
+	switch {
+	case t.IsComposite() && len(sel.SelectionSet) == 0:
+		v.report(sel.Pos, "field %q of type %q must have a selection of subfields", sel.Name, f.Type)
+	case !t.IsComposite() && len(sel.SelectionSet) > 0:
+		v.report(sel.Pos, "field %q must not have a selection since type %q has no subfields", sel.Name, f.Type)
+	case t.IsComposite():
+		v.validateSelectionSet(t, sel.SelectionSet)
+	}
+}
+
+func (v *validator) validateDirectives(directives []*AppliedDirective) {
+	for _, d := range directives {
+		def := v.schema.Directive(d.Name)
+		if def == nil {
+			v.report(d.Pos, "unknown directive \"@%s\"", d.Name)
+			continue
+		}
+		v.validateArguments(fmt.Sprintf("directive \"@%s\"", d.Name), def.Args, d.Arguments, d.Pos)
+	}
+}
+
+// validateArguments checks the given arguments against the definitions, owner names the field or the
+// directive in the messages.
+func (v *validator) validateArguments(owner string, defs []*InputValue, args []*Argument, pos int) {
+	given := make(map[string]bool, len(args))
+	for _, arg := range args {
+		if given[arg.Name] {
+			v.report(arg.Pos, "there can be only one argument named %q", arg.Name)
+		}
+		given[arg.Name] = true
+
+		def := findInputValue(defs, arg.Name)
+		if def == nil {
+			v.report(arg.Pos, "unknown argument %q on %s%s", arg.Name, owner, suggest(arg.Name, inputNames(defs)))
+			continue
+		}
+		v.validateValue(arg.Value, v.resolve(def.Type))
+	}
+
+	for _, def := range defs {
+		if def.Type.IsNonNull() && def.DefaultValue == "" && !given[def.Name] {
+			v.report(pos, "%s argument %q of type %q is required, but it was not provided", owner, def.Name, def.Type)
+		}
+	}
+}
+
+// validateValue checks the literal against the expected type, variables are only checked to be defined.
+func (v *validator) validateValue(val *Value, typ *TypeRef) {
+	if val.Kind == ValueVariable {
+		if v.variables != nil {
+			if _, ok := v.variables[val.Raw]; !ok {
+				v.report(val.Pos, "variable \"$%s\" is not defined", val.Raw)
+			}
+		}
+		return
+	}
+
+	if typ == nil {
+		return
+	}
+
+	if val.Kind == ValueNull {
+		if typ.IsNonNull() {
+			v.report(val.Pos, "expected value of type %q, found null", typ)
+		}
+		return
+	}
+
+	nullable := typ.Nullable()
+	if nullable.Kind == KindList {
+		if val.Kind != ValueList {
+			// a single value is accepted as a list of one item
+			v.validateValue(val, nullable.OfType)
+			return
+		}
+		for _, item := range val.List {
+			v.validateValue(item, nullable.OfType)
+		}
+		return
+	}
+
+	t := v.schema.Type(nullable.Name)
+	if t == nil {
+		return
+	}
+
+	if val.Kind == ValueList {
+		v.report(val.Pos, "expected value of type %q, found a list", typ)
+		return
+	}
+
+	switch t.Kind {
+	case KindEnum:
+		if val.Kind != ValueEnum {
+			v.report(val.Pos, "enum %q cannot represent non-enum value %s", t.Name, val.Raw)
+		} else if t.EnumValue(val.Raw) == nil {
+			v.report(val.Pos, "value %q does not exist in %q enum%s", val.Raw, t.Name, suggest(val.Raw, enumNames(t)))
+		}
+	case KindInputObject:
+		if val.Kind != ValueObject {
+			v.report(val.Pos, "expected value of type %q, found %s", typ, val.Raw)
+			return
+		}
+
+		given := make(map[string]bool, len(val.Fields))
+		for _, f := range val.Fields {
+			given[f.Name] = true
+			def := t.InputField(f.Name)
+			if def == nil {
+				v.report(f.Pos, "field %q is not defined by type %q%s", f.Name, t.Name, suggest(f.Name, inputNames(t.InputFields)))
+				continue
+			}
+			v.validateValue(f.Value, v.resolve(def.Type))
+		}
+
+		for _, def := range t.InputFields {
+			if def.Type.IsNonNull() && def.DefaultValue == "" && !given[def.Name] {
+				v.report(val.Pos, "field \"%s.%s\" of required type %q was not provided", t.Name, def.Name, def.Type)
+			}
+		}
+	case KindScalar:
+		if !scalarAccepts(t.Name, val.Kind) {
+			v.report(val.Pos, "%s cannot represent the value %s", t.Name, val.Raw)
+		}
+	}
+}
+
+// resolve fills the kinds of the named types of a reference written in the query.
+func (v *validator) resolve(ref *TypeRef) *TypeRef {
+	for r := ref; r != nil; r = r.OfType {
+		if r.OfType == nil && r.Kind == "" {
+			if t := v.schema.Type(r.Name); t != nil {
+				r.Kind = t.Kind
+			}
+		}
+	}
+	return ref
+}
+
+// scalarAccepts reports whether a literal of the kind is valid for the built-in scalar, custom scalars
+// accept any literal.
+func scalarAccepts(name string, kind ValueKind) bool {
+	switch name {
+	case "Int":
+		return kind == ValueInt
+	case "Float":
+		return kind == ValueInt || kind == ValueFloat
+	case "String":
+		return kind == ValueString
+	case "Boolean":
+		return kind == ValueBoolean
+	case "ID":
+		return kind == ValueString || kind == ValueInt
+	default:
+		return kind != ValueObject
+	}
+}
+
+func fieldNames(t *Type) []string {
+	names := make([]string, 0, len(t.Fields))
+	for _, f := range t.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func inputNames(values []*InputValue) []string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func enumNames(t *Type) []string {
+	names := make([]string, 0, len(t.EnumValues))
+	for _, v := range t.EnumValues {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+// suggest returns a "did you mean" hint with the closest candidate, or an empty string when none is close.
+func suggest(name string, candidates []string) string {
+	best, bestDist := "", len(name)/2+1
+	for _, c := range candidates {
+		if d := distance(strings.ToLower(name), strings.ToLower(c)); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// distance is the levenshtein distance of the two strings.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -20,19 +20,40 @@ import (
 	"github.com/chapar-rest/chapar/internal/repository"
 )
 
-// ImportProtoFile imports a proto file and creates a collection with gRPC requests
+// ImportProtoFile imports a proto file and creates a collection with gRPC requests,
+// compiled FileDescriptorSets are detected by the extension of the file path.
 func ImportProtoFile(data []byte, repo repository.RepositoryV2, filePath ...string) error {
+	var registryFiles *protoregistry.Files
+	if len(filePath) > 0 && domain.IsDescriptorSetFile(filePath[0]) {
+		files, err := grpc.ProtoFilesFromDescriptorSet(data)
+		if err != nil {
+			return fmt.Errorf("error parsing descriptor set: %w", err)
+		}
+		registryFiles = files
+	} else {
+		files, err := parseProtoFile(data)
+		if err != nil {
+			return err
+		}
+		registryFiles = files
+	}
+
+	return createCollectionFromRegistry(registryFiles, repo, filePath)
+}
+
+// parseProtoFile parses the proto source into protoregistry.Files
+func parseProtoFile(data []byte) (*protoregistry.Files, error) {
 	// Write the data to a temporary file for parsing
 	tempFile, err := os.CreateTemp("", "proto_import_*.proto")
 	if err != nil {
-		return fmt.Errorf("error creating temporary file: %w", err)
+		return nil, fmt.Errorf("error creating temporary file: %w", err)
 	}
 	defer func() { _ = os.Remove(tempFile.Name()) }()
 	defer func() { _ = tempFile.Close() }()
 
 	// Write the proto content to the temporary file
 	if _, err := tempFile.Write(data); err != nil {
-		return fmt.Errorf("error writing to temporary file: %w", err)
+		return nil, fmt.Errorf("error writing to temporary file: %w", err)
 	}
 	_ = tempFile.Close()
 
@@ -43,19 +64,24 @@ func ImportProtoFile(data []byte, repo repository.RepositoryV2, filePath ...stri
 
 	descriptors, err := parser.ParseFiles(filepath.Base(tempFile.Name()))
 	if err != nil {
-		return fmt.Errorf("error parsing proto file: %w", err)
+		return nil, fmt.Errorf("error parsing proto file: %w", err)
 	}
 
 	if len(descriptors) == 0 {
-		return fmt.Errorf("no descriptors found in proto file")
+		return nil, fmt.Errorf("no descriptors found in proto file")
 	}
 
 	// Convert to protoregistry.Files format
 	registryFiles, err := convertToRegistryFiles(descriptors)
 	if err != nil {
-		return fmt.Errorf("error converting to registry files: %w", err)
+		return nil, fmt.Errorf("error converting to registry files: %w", err)
 	}
 
+	return registryFiles, nil
+}
+
+// createCollectionFromRegistry creates a collection with a gRPC request per method of the services in registryFiles
+func createCollectionFromRegistry(registryFiles *protoregistry.Files, repo repository.RepositoryV2, filePath []string) error {
 	// Parse services from registry files
 	services := parseServicesFromRegistry(registryFiles)
 
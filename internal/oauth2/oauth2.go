@@ -0,0 +1,538 @@
+package oauth2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/logger"
+	"github.com/chapar-rest/chapar/internal/prefs"
+	"github.com/chapar-rest/chapar/internal/proxy"
+	"github.com/chapar-rest/chapar/internal/safemap"
+	"github.com/chapar-rest/chapar/internal/state"
+	"github.com/chapar-rest/chapar/internal/util"
+)
+
+const (
+	// expiryDelta is how early a token is considered expired, so it gets refreshed before the server rejects it.
+	expiryDelta = 30 * time.Second
+
+	// authorizationTimeout is how long we wait for the user to complete the authorization code flow in the browser.
+	authorizationTimeout = 5 * time.Minute
+
+	defaultRedirectURL = "http://127.0.0.1:0/callback"
+)
+
+var ErrInvalidConfig = errors.New("invalid oauth2 config")
+
+// Error is an error response returned by the authorization server as per RFC 6749 section 5.2.
+type Error struct {
+	Code        string
+	Description string
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("oauth2: %s", e.Code)
+	}
+	return fmt.Sprintf("oauth2: %s: %s", e.Code, e.Description)
+}
+
+type Token struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+// Valid reports whether the token has an access token which is not about to expire.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+
+	if t.Expiry.IsZero() {
+		return true
+	}
+
+	return time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// AuthorizationHeader returns the value to be used in the Authorization header.
+func (t *Token) AuthorizationHeader() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	return tokenType + " " + t.AccessToken
+}
+
+// Service acquires oauth2 tokens and caches them per environment, so requests sharing the same
+// config and environment reuse the token until it is about to expire.
+type Service struct {
+	tokens *safemap.Map[*Token]
+	// workspaces gives the client certificates of the token urls
+	workspaces *state.Workspaces
+
+	// locks serialize token acquisition per cache key, so concurrent requests with the same config do not
+	// start more than one flow while requests with other configs are not blocked by it.
+	locks   map[string]*keyLock
+	locksMu sync.Mutex
+
+	openURL func(url string) error
+}
+
+func NewService(workspaces *state.Workspaces) *Service {
+	return &Service{
+		tokens:     safemap.New[*Token](),
+		workspaces: workspaces,
+		locks:      make(map[string]*keyLock),
+		openURL:    util.OpenURL,
+	}
+}
+
+// Token returns a valid token for the given config, it uses the cached token if any, refreshes it
+// if it is about to expire and otherwise acquires a new one. the token server is reached through the
+// same proxy as the request, requestProxy is the proxy override of the request if any.
+func (s *Service) Token(ctx context.Context, env *domain.Environment, cfg *domain.OAuth2Auth, requestProxy *domain.ProxyConfig) (*Token, error) {
+	if cfg == nil || cfg.TokenURL == "" {
+		return nil, fmt.Errorf("%w: token url is required", ErrInvalidConfig)
+	}
+
+	key := cacheKey(env, cfg)
+	unlock := s.lock(key)
+	defer unlock()
+
+	cached, ok := s.tokens.Get(key)
+	if ok && cached.Valid() {
+		return cached, nil
+	}
+
+	client, err := s.httpClient(env, cfg, requestProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && cached.RefreshToken != "" {
+		token, err := s.refresh(ctx, client, cfg, cached.RefreshToken)
+		if err == nil {
+			s.tokens.Set(key, token)
+			return token, nil
+		}
+
+		logger.Warn(fmt.Sprintf("failed to refresh oauth2 token, acquiring a new one: %v", err))
+	}
+
+	token, err := s.acquire(ctx, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s.tokens.Set(key, token)
+	return token, nil
+}
+
+// Clear removes all cached tokens.
+func (s *Service) Clear() {
+	for _, k := range s.tokens.Keys() {
+		s.tokens.Delete(k)
+	}
+}
+
+// keyLock is the lock of a cache key, refs counts the requests holding or waiting for it.
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks the given cache key and returns the function unlocking it, the lock is dropped once no
+// request holds or waits for it so edited configs don't leave their locks behind.
+func (s *Service) lock(key string) func() {
+	s.locksMu.Lock()
+	l, ok := s.locks[key]
+	if !ok {
+		l = &keyLock{}
+		s.locks[key] = l
+	}
+	l.refs++
+	s.locksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		s.locksMu.Lock()
+		defer s.locksMu.Unlock()
+		l.refs--
+		if l.refs == 0 {
+			delete(s.locks, key)
+		}
+	}
+}
+
+// cacheKey returns the key of the token of the config, it covers the secrets as well so fixing a wrong
+// secret or password acquires a new token. the fields are hashed to keep the secrets out of the key.
+func cacheKey(env *domain.Environment, cfg *domain.OAuth2Auth) string {
+	envID := ""
+	if env != nil {
+		envID = env.MetaData.ID
+	}
+
+	fields := []string{
+		envID, cfg.GrantType, cfg.AuthURL, cfg.TokenURL, cfg.ClientID, cfg.ClientSecret,
+		cfg.Scopes, cfg.Audience, cfg.Username, cfg.Password, cfg.RedirectURL,
+	}
+	hash := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// httpClient returns the client of the token requests, it goes through the proxy of the request and
+// uses the client certificate of the workspace for the token url.
+func (s *Service) httpClient(env *domain.Environment, cfg *domain.OAuth2Auth, requestProxy *domain.ProxyConfig) (*http.Client, error) {
+	tokenURL, err := url.Parse(cfg.TokenURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid token url: %v", ErrInvalidConfig, err)
+	}
+
+	var workspace *domain.Workspace
+	if s.workspaces != nil {
+		workspace = s.workspaces.GetActiveWorkspace()
+	}
+
+	globalConfig := prefs.GetGlobalConfig()
+	tlsConfig, err := egress.TLSConfig(workspace, tokenURL, globalConfig.Spec.General.VaidateTLSCertificates)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyDialer, err := proxy.NewDialer(domain.ResolveProxy(globalConfig.Spec.General.Proxy, env, requestProxy))
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout: time.Duration(globalConfig.Spec.General.RequestTimeoutSec) * time.Second,
+		Transport: &http.Transport{
+			Proxy:           proxyDialer.ProxyFunc(),
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+func (s *Service) acquire(ctx context.Context, client *http.Client, cfg *domain.OAuth2Auth) (*Token, error) {
+	switch cfg.GrantType {
+	case domain.OAuth2GrantTypeClientCredentials, "":
+		form := url.Values{"grant_type": {domain.OAuth2GrantTypeClientCredentials}}
+		return s.tokenRequest(ctx, client, cfg, form)
+	case domain.OAuth2GrantTypePassword:
+		form := url.Values{
+			"grant_type": {domain.OAuth2GrantTypePassword},
+			"username":   {cfg.Username},
+			"password":   {cfg.Password},
+		}
+		return s.tokenRequest(ctx, client, cfg, form)
+	case domain.OAuth2GrantTypeAuthorizationCode:
+		return s.authorizationCode(ctx, client, cfg)
+	default:
+		return nil, fmt.Errorf("%w: unsupported grant type %s", ErrInvalidConfig, cfg.GrantType)
+	}
+}
+
+func (s *Service) refresh(ctx context.Context, client *http.Client, cfg *domain.OAuth2Auth, refreshToken string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+
+	token, err := s.tokenRequest(ctx, client, cfg, form)
+	if err != nil {
+		return nil, err
+	}
+
+	// servers may not rotate the refresh token, keep the old one in that case
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+
+	return token, nil
+}
+
+// authorizationCode runs the authorization code flow with PKCE, it listens on the loopback redirect url,
+// opens the authorization url in the browser and exchanges the received code for a token.
+func (s *Service) authorizationCode(ctx context.Context, client *http.Client, cfg *domain.OAuth2Auth) (*Token, error) {
+	if cfg.AuthURL == "" {
+		return nil, fmt.Errorf("%w: auth url is required for authorization code grant", ErrInvalidConfig)
+	}
+
+	redirect := cfg.RedirectURL
+	if redirect == "" {
+		redirect = defaultRedirectURL
+	}
+
+	redirectURL, err := url.Parse(redirect)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect url: %w", err)
+	}
+
+	if !isLoopback(redirectURL.Hostname()) {
+		return nil, fmt.Errorf("%w: redirect url must be a loopback address, got %s", ErrInvalidConfig, redirectURL.Host)
+	}
+
+	listener, err := net.Listen("tcp", redirectURL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on redirect url: %w", err)
+	}
+	defer listener.Close()
+
+	// the port may have been chosen by the os, keep the host as configured since it has to match the registered one
+	if addr, ok := listener.Addr().(*net.TCPAddr); ok {
+		redirectURL.Host = net.JoinHostPort(redirectURL.Hostname(), strconv.Itoa(addr.Port))
+	}
+	if redirectURL.Path == "" {
+		redirectURL.Path = "/"
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirectURL.Path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		res := result{code: q.Get("code")}
+		switch {
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("authorization failed: %s %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("state") != state:
+			res.err = errors.New("authorization failed: state mismatch")
+		case res.code == "":
+			res.err = errors.New("authorization failed: no code received")
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if res.err != nil {
+			_, _ = fmt.Fprintf(w, "<html><body><h3>%s</h3></body></html>", html.EscapeString(res.err.Error()))
+		} else {
+			_, _ = io.WriteString(w, "<html><body><h3>Authorization completed, you can close this window and return to Chapar.</h3></body></html>")
+		}
+
+		select {
+		case results <- res:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	authURL, err := url.Parse(cfg.AuthURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth url: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", cfg.ClientID)
+	q.Set("redirect_uri", redirectURL.String())
+	q.Set("state", state)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	if cfg.Scopes != "" {
+		q.Set("scope", cfg.Scopes)
+	}
+	if cfg.Audience != "" {
+		q.Set("audience", cfg.Audience)
+	}
+	authURL.RawQuery = q.Encode()
+
+	logger.Info(fmt.Sprintf("opening browser for oauth2 authorization: %s", authURL.String()))
+	if err := s.openURL(authURL.String()); err != nil {
+		logger.Warn(fmt.Sprintf("failed to open browser, open the url manually: %v", err))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, authorizationTimeout)
+	defer cancel()
+
+	var res result
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for oauth2 authorization: %w", ctx.Err())
+	}
+
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	form := url.Values{
+		"grant_type":    {domain.OAuth2GrantTypeAuthorizationCode},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURL.String()},
+		"code_verifier": {verifier},
+	}
+
+	return s.tokenRequest(ctx, client, cfg, form)
+}
+
+func (s *Service) tokenRequest(ctx context.Context, client *http.Client, cfg *domain.OAuth2Auth, form url.Values) (*Token, error) {
+	if cfg.Scopes != "" && form.Get("grant_type") != domain.OAuth2GrantTypeAuthorizationCode {
+		form.Set("scope", cfg.Scopes)
+	}
+
+	if cfg.Audience != "" {
+		form.Set("audience", cfg.Audience)
+	}
+
+	// public clients only send their id, confidential clients authenticate with basic auth as per RFC 6749 section 2.3.1
+	if cfg.ClientSecret == "" {
+		form.Set("client_id", cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request oauth2 token: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read oauth2 token response: %w", err)
+	}
+
+	token, err := parseTokenResponse(res.Header.Get("Content-Type"), body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var oauthErr *Error
+		if errors.As(err, &oauthErr) {
+			return nil, oauthErr
+		}
+		return nil, fmt.Errorf("oauth2 token request failed with status %d: %s", res.StatusCode, string(body))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if token.AccessToken == "" {
+		return nil, errors.New("oauth2 token response does not contain an access token")
+	}
+
+	return token, nil
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        any    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func parseTokenResponse(contentType string, body []byte) (*Token, error) {
+	var tr tokenResponse
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "text/plain" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse oauth2 token response: %w", err)
+		}
+
+		tr = tokenResponse{
+			AccessToken:      values.Get("access_token"),
+			TokenType:        values.Get("token_type"),
+			RefreshToken:     values.Get("refresh_token"),
+			ExpiresIn:        values.Get("expires_in"),
+			Error:            values.Get("error"),
+			ErrorDescription: values.Get("error_description"),
+		}
+	} else if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, fmt.Errorf("failed to parse oauth2 token response: %w", err)
+	}
+
+	if tr.Error != "" {
+		return nil, &Error{Code: tr.Error, Description: tr.ErrorDescription}
+	}
+
+	token := &Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+
+	// some servers send expires_in as a string
+	var expiresIn int64
+	switch v := tr.ExpiresIn.(type) {
+	case float64:
+		expiresIn = int64(v)
+	case string:
+		expiresIn, _ = strconv.ParseInt(v, 10, 64)
+	}
+
+	if expiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,232 @@
+package oauth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestClientCredentialsTokenIsCachedPerEnvironment(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+
+		id, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "client", id)
+		assert.Equal(t, "secret", secret)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "token-1",
+			"token_type":   "bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &domain.OAuth2Auth{
+		GrantType:    domain.OAuth2GrantTypeClientCredentials,
+		TokenURL:     srv.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       "read write",
+	}
+
+	s := NewService(nil)
+	token, err := s.Token(context.Background(), nil, cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", token.AuthorizationHeader())
+
+	_, err = s.Token(context.Background(), nil, cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	env := &domain.Environment{MetaData: domain.MetaData{ID: "env-1"}}
+	_, err = s.Token(context.Background(), env, cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestTokenCacheKeyAndClear(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 3600})
+	}))
+	defer srv.Close()
+
+	cfg := &domain.OAuth2Auth{
+		GrantType:    domain.OAuth2GrantTypeClientCredentials,
+		TokenURL:     srv.URL,
+		ClientID:     "client",
+		ClientSecret: "wrong",
+	}
+
+	s := NewService(nil)
+	_, err := s.Token(context.Background(), nil, cfg, nil)
+	require.NoError(t, err)
+
+	// fixing the secret acquires a new token
+	fixed := *cfg
+	fixed.ClientSecret = "secret"
+	_, err = s.Token(context.Background(), nil, &fixed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	s.Clear()
+	_, err = s.Token(context.Background(), nil, &fixed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// a flow in progress for one config doesn't block the others
+	unlock := s.lock(cacheKey(nil, cfg))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := s.Token(context.Background(), nil, &fixed, nil)
+		assert.NoError(t, err)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("token request blocked by the flow of another config")
+	}
+
+	// the locks are dropped once released
+	unlock()
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	assert.Empty(t, s.locks)
+}
+
+func TestExpiredTokenIsRefreshed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.PostForm.Get("grant_type") == "refresh_token" {
+			assert.Equal(t, "refresh-1", r.PostForm.Get("refresh_token"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token-2", "expires_in": 3600})
+			return
+		}
+
+		// expires within the expiry delta, so the next call has to refresh it
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token-1", "refresh_token": "refresh-1", "expires_in": 1})
+	}))
+	defer srv.Close()
+
+	cfg := &domain.OAuth2Auth{
+		GrantType: domain.OAuth2GrantTypePassword,
+		TokenURL:  srv.URL,
+		ClientID:  "client",
+		Username:  "user",
+		Password:  "pass",
+	}
+
+	s := NewService(nil)
+	token, err := s.Token(context.Background(), nil, cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token.AccessToken)
+
+	token, err = s.Token(context.Background(), nil, cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token.AccessToken)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+}
+
+func TestAuthorizationCodeWithPKCE(t *testing.T) {
+	var challenge string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "authorization_code", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "the-code", r.PostForm.Get("code"))
+
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		assert.Equal(t, challenge, base64.RawURLEncoding.EncodeToString(sum[:]))
+
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		_, _ = w.Write([]byte("access_token=token-1&token_type=bearer"))
+	}))
+	defer srv.Close()
+
+	s := NewService(nil)
+	s.openURL = func(authURL string) error {
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+
+		q := u.Query()
+		assert.Equal(t, "S256", q.Get("code_challenge_method"))
+		challenge = q.Get("code_challenge")
+
+		redirect := q.Get("redirect_uri") + "?code=the-code&state=" + url.QueryEscape(q.Get("state"))
+		go func() {
+			res, err := http.Get(redirect)
+			if err == nil {
+				_ = res.Body.Close()
+			}
+		}()
+		return nil
+	}
+
+	token, err := s.Token(context.Background(), nil, &domain.OAuth2Auth{
+		GrantType: domain.OAuth2GrantTypeAuthorizationCode,
+		AuthURL:   "https://auth.example.com/authorize",
+		TokenURL:  srv.URL,
+		ClientID:  "client",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token.AccessToken)
+}
+
+func TestTokenErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"unknown client"}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewService(nil).Token(context.Background(), nil, &domain.OAuth2Auth{TokenURL: srv.URL, ClientID: "client"}, nil)
+	var oauthErr *Error
+	require.ErrorAs(t, err, &oauthErr)
+	assert.Equal(t, "invalid_client", oauthErr.Code)
+}
+
+func TestTokenRequestUsesProxy(t *testing.T) {
+	// the proxy answers the token request itself, the token url doesn't resolve
+	var proxied atomic.Int32
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		assert.Equal(t, "http://token.invalid/token", r.URL.String())
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 3600})
+	}))
+	defer proxySrv.Close()
+
+	cfg := &domain.OAuth2Auth{TokenURL: "http://token.invalid/token", ClientID: "client"}
+	requestProxy := &domain.ProxyConfig{Mode: domain.ProxyModeManual, URL: proxySrv.URL}
+
+	token, err := NewService(nil).Token(context.Background(), nil, cfg, requestProxy)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token.AccessToken)
+	assert.Equal(t, int32(1), proxied.Load())
+}
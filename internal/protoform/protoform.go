@@ -0,0 +1,613 @@
+// Package protoform is the model of the forms which edit proto messages, a form is generated from the
+// descriptor of a message and it's read from and written to the json of the message.
+package protoform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// the formats of the well-known types edited as text, they are checked and written the way json expects them.
+const (
+	FormatTimestamp = "timestamp"
+	FormatDuration  = "duration"
+	// FormatJSON is the json of the types that can hold any value, such as Struct and Any.
+	FormatJSON = "json"
+)
+
+// Form is the form of a message, the values are kept as they are edited and written as json by Body.
+type Form struct {
+	Root *Message
+	// Warning is set when the body could not be read into the form, editing the form replaces the body.
+	Warning string
+}
+
+// New builds the form of the message and fills it with the body, values the form can't show are dropped.
+func New(desc protoreflect.MessageDescriptor, body string) *Form {
+	f := &Form{}
+
+	var values map[string]any
+	if strings.TrimSpace(body) != "" {
+		dec := json.NewDecoder(strings.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&values); err != nil {
+			f.Warning = fmt.Sprintf("The body is not a valid JSON object (%s), editing the form replaces it.", err)
+		}
+	}
+
+	f.Root = newMessage(desc, values)
+	return f
+}
+
+// Body returns the message of the form as indented json.
+func (f *Form) Body() string {
+	v, _ := f.Root.JSON()
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Value is the value of a field, JSON returns its json value and whether it's set. values that are not
+// set are left out of the message.
+type Value interface {
+	JSON() (any, bool)
+}
+
+// Row is a field of a message, or a oneof with its selected field.
+type Row interface {
+	Value
+	// Key is the json name the value is written under.
+	Key() string
+}
+
+// Field is a field of a message which is not part of a oneof.
+type Field struct {
+	Desc  protoreflect.FieldDescriptor
+	Value Value
+}
+
+func (f *Field) Key() string {
+	return string(f.Desc.Name())
+}
+
+func (f *Field) JSON() (any, bool) {
+	return f.Value.JSON()
+}
+
+func newFieldValue(fd protoreflect.FieldDescriptor, v any) Value {
+	switch {
+	case fd.IsMap():
+		return newMap(fd, v)
+	case fd.IsList():
+		return newList(fd, v)
+	default:
+		return newItemValue(fd, v)
+	}
+}
+
+// newItemValue returns a single value of the field, for lists it's an item of the list.
+func newItemValue(fd protoreflect.FieldDescriptor, v any) Value {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return newBool(v)
+	case protoreflect.EnumKind:
+		return newEnum(fd.Enum(), v)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return newMessageValue(fd.Message(), v)
+	default:
+		return newScalar(fd.Kind(), v)
+	}
+}
+
+// newMessageValue returns the value of a message, well-known types are edited as their json form.
+func newMessageValue(md protoreflect.MessageDescriptor, v any) Value {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		s := newScalar(protoreflect.StringKind, v)
+		s.Format = FormatTimestamp
+		s.Placeholder = "2006-01-02 15:04:05 or RFC 3339"
+		return s
+	case "google.protobuf.Duration":
+		return newDuration(v)
+	case "google.protobuf.FieldMask":
+		s := newScalar(protoreflect.StringKind, v)
+		s.Placeholder = "paths, comma separated"
+		return s
+	case "google.protobuf.Struct", "google.protobuf.Value", "google.protobuf.ListValue", "google.protobuf.Any":
+		return newRaw(v)
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue", "google.protobuf.Int64Value",
+		"google.protobuf.UInt64Value", "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		// wrappers are written as the value they wrap
+		return newItemValue(md.Fields().ByName("value"), v)
+	default:
+		values, _ := v.(map[string]any)
+		return newMessage(md, values)
+	}
+}
+
+// Message is the fields of a message, they are created once they're asked for so recursive messages are
+// not built all the way down.
+type Message struct {
+	Desc protoreflect.MessageDescriptor
+
+	values map[string]any
+	rows   []Row
+	built  bool
+}
+
+func newMessage(desc protoreflect.MessageDescriptor, values map[string]any) *Message {
+	m := &Message{Desc: desc, values: values}
+	if len(values) > 0 {
+		m.build()
+	}
+	return m
+}
+
+// Built reports whether the fields of the message are created, messages read with values are built right away.
+func (m *Message) Built() bool {
+	return m.built
+}
+
+// Rows returns the fields of the message in the order of the proto file, a oneof takes the place of its
+// first field.
+func (m *Message) Rows() []Row {
+	m.build()
+	return m.rows
+}
+
+func (m *Message) build() {
+	if m.built {
+		return
+	}
+	m.built = true
+
+	seen := make(map[protoreflect.FullName]bool)
+	fields := m.Desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() {
+			if seen[od.FullName()] {
+				continue
+			}
+			seen[od.FullName()] = true
+			m.rows = append(m.rows, newOneof(od, m.values))
+			continue
+		}
+
+		m.rows = append(m.rows, &Field{Desc: fd, Value: newFieldValue(fd, lookupField(m.values, fd))})
+	}
+	m.values = nil
+}
+
+func (m *Message) JSON() (any, bool) {
+	out := make(jsonObject, 0)
+	if !m.built {
+		return out, false
+	}
+
+	for _, row := range m.rows {
+		if v, ok := row.JSON(); ok {
+			out = append(out, jsonField{key: row.Key(), value: v})
+		}
+	}
+	return out, len(out) > 0
+}
+
+// Oneof is a oneof of a message, only the value of its selected field is written.
+type Oneof struct {
+	Desc protoreflect.OneofDescriptor
+
+	values   map[int]Value
+	selected int
+}
+
+func newOneof(od protoreflect.OneofDescriptor, values map[string]any) *Oneof {
+	o := &Oneof{Desc: od, values: make(map[int]Value), selected: -1}
+	for i := 0; i < od.Fields().Len(); i++ {
+		fd := od.Fields().Get(i)
+		if v := lookupField(values, fd); v != nil {
+			o.selected = i
+			o.values[i] = newFieldValue(fd, v)
+			break
+		}
+	}
+	return o
+}
+
+// Selected returns the index of the selected field in the fields of the oneof, -1 when none is selected.
+func (o *Oneof) Selected() int {
+	return o.selected
+}
+
+// Select selects the field of index i and returns its value, the values of the fields selected before
+// are kept for when they're selected again. -1 selects none of the fields.
+func (o *Oneof) Select(i int) Value {
+	if i < 0 || i >= o.Desc.Fields().Len() {
+		o.selected = -1
+		return nil
+	}
+
+	o.selected = i
+	if _, ok := o.values[i]; !ok {
+		o.values[i] = newFieldValue(o.Desc.Fields().Get(i), nil)
+	}
+	return o.values[i]
+}
+
+// SelectedValue returns the value of the selected field, nil when none is selected.
+func (o *Oneof) SelectedValue() Value {
+	return o.values[o.selected]
+}
+
+func (o *Oneof) Key() string {
+	if o.selected < 0 {
+		return ""
+	}
+	return string(o.Desc.Fields().Get(o.selected).Name())
+}
+
+func (o *Oneof) JSON() (any, bool) {
+	value, ok := o.values[o.selected]
+	if !ok {
+		return nil, false
+	}
+
+	// the selected field is sent even with its default value, it's what sets the oneof
+	v, _ := value.JSON()
+	return v, true
+}
+
+// List is the items of a repeated field.
+type List struct {
+	Desc  protoreflect.FieldDescriptor
+	Items []Value
+}
+
+func newList(fd protoreflect.FieldDescriptor, v any) *List {
+	l := &List{Desc: fd}
+	values, _ := v.([]any)
+	for _, item := range values {
+		l.Items = append(l.Items, newItemValue(fd, item))
+	}
+	return l
+}
+
+// Add appends an empty item to the list and returns it.
+func (l *List) Add() Value {
+	item := newItemValue(l.Desc, nil)
+	l.Items = append(l.Items, item)
+	return item
+}
+
+// Remove removes the item of index i.
+func (l *List) Remove(i int) {
+	l.Items = append(l.Items[:i:i], l.Items[i+1:]...)
+}
+
+func (l *List) JSON() (any, bool) {
+	out := make([]any, 0, len(l.Items))
+	for _, item := range l.Items {
+		v, _ := item.JSON()
+		out = append(out, v)
+	}
+	return out, len(out) > 0
+}
+
+// Map is the entries of a map field.
+type Map struct {
+	Desc    protoreflect.FieldDescriptor
+	Entries []*MapEntry
+}
+
+type MapEntry struct {
+	Key   *Scalar
+	Value Value
+}
+
+func newMap(fd protoreflect.FieldDescriptor, v any) *Map {
+	m := &Map{Desc: fd}
+	values, _ := v.(map[string]any)
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		m.Entries = append(m.Entries, m.newEntry(k, values[k]))
+	}
+	return m
+}
+
+func (m *Map) newEntry(key string, v any) *MapEntry {
+	// map keys are always strings in json, bool keys are written as "true" and "false"
+	keyKind := m.Desc.MapKey().Kind()
+	if keyKind == protoreflect.BoolKind {
+		keyKind = protoreflect.StringKind
+	}
+
+	k := newScalar(keyKind, key)
+	k.Placeholder = "key"
+	return &MapEntry{Key: k, Value: newItemValue(m.Desc.MapValue(), v)}
+}
+
+// Add appends an empty entry to the map and returns it.
+func (m *Map) Add() *MapEntry {
+	entry := m.newEntry("", nil)
+	m.Entries = append(m.Entries, entry)
+	return entry
+}
+
+// Remove removes the entry of index i.
+func (m *Map) Remove(i int) {
+	m.Entries = append(m.Entries[:i:i], m.Entries[i+1:]...)
+}
+
+func (m *Map) JSON() (any, bool) {
+	out := make(jsonObject, 0, len(m.Entries))
+	for _, entry := range m.Entries {
+		v, _ := entry.Value.JSON()
+		out = append(out, jsonField{key: entry.Key.Text, value: v})
+	}
+	return out, len(out) > 0
+}
+
+// Scalar is a value edited as text, such as strings, bytes and numbers, along with the well-known types
+// of its Format.
+type Scalar struct {
+	Kind   protoreflect.Kind
+	Format string
+	Text   string
+	// Placeholder tells what the text is expected to be.
+	Placeholder string
+}
+
+func newScalar(kind protoreflect.Kind, v any) *Scalar {
+	return &Scalar{Kind: kind, Text: scalarText(v), Placeholder: kindPlaceholder(kind)}
+}
+
+// newDuration returns the editor of a duration, json durations are seconds with an s suffix and they're
+// shown the way they are typed, such as 1h30m.
+func newDuration(v any) *Scalar {
+	text := scalarText(v)
+	if d, err := time.ParseDuration(text); err == nil {
+		text = d.String()
+	}
+
+	return &Scalar{
+		Kind:        protoreflect.StringKind,
+		Format:      FormatDuration,
+		Text:        text,
+		Placeholder: "1h30m, 20s, 1.5s or 300ms",
+	}
+}
+
+func newRaw(v any) *Scalar {
+	text := ""
+	if v != nil {
+		data, _ := json.Marshal(v)
+		text = string(data)
+	}
+	return &Scalar{Kind: protoreflect.StringKind, Format: FormatJSON, Text: text, Placeholder: "JSON"}
+}
+
+// timestampLayouts are the formats accepted for timestamps, they are sent as RFC 3339 in UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func parseTimestamp(text string) (time.Time, bool) {
+	for _, l := range timestampLayouts {
+		if ts, err := time.Parse(l, text); err == nil {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Valid reports whether the text is a value of the kind or the format of the scalar, empty text is valid.
+func (s *Scalar) Valid() bool {
+	text := strings.TrimSpace(s.Text)
+	if text == "" {
+		return true
+	}
+
+	var err error
+	switch s.Format {
+	case FormatTimestamp:
+		_, ok := parseTimestamp(text)
+		return ok
+	case FormatDuration:
+		_, err = time.ParseDuration(text)
+		return err == nil
+	case FormatJSON:
+		return json.Valid([]byte(text))
+	}
+
+	switch s.Kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		_, err = strconv.ParseInt(text, 10, 32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		_, err = strconv.ParseInt(text, 10, 64)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		_, err = strconv.ParseUint(text, 10, 32)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		_, err = strconv.ParseUint(text, 10, 64)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		_, err = strconv.ParseFloat(text, 64)
+		return err == nil || text == "NaN" || text == "Infinity" || text == "-Infinity"
+	}
+	return err == nil
+}
+
+func (s *Scalar) JSON() (any, bool) {
+	text := strings.TrimSpace(s.Text)
+
+	switch s.Format {
+	case FormatTimestamp:
+		if ts, ok := parseTimestamp(text); ok {
+			return ts.UTC().Format(time.RFC3339Nano), true
+		}
+		return text, text != ""
+	case FormatDuration:
+		if dur, err := time.ParseDuration(text); err == nil {
+			return strconv.FormatFloat(dur.Seconds(), 'f', -1, 64) + "s", true
+		}
+		return text, text != ""
+	case FormatJSON:
+		if text == "" || !s.Valid() {
+			return nil, false
+		}
+		return json.RawMessage(text), true
+	}
+
+	switch s.Kind {
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return s.Text, s.Text != ""
+	}
+
+	if text == "" {
+		return json.Number("0"), false
+	}
+
+	// invalid numbers are sent as typed, the error of the server tells what's wrong with them
+	if !s.Valid() {
+		return text, true
+	}
+
+	// numbers json can't write, such as NaN, Infinity and +1, are sent as strings which protojson reads too
+	if _, err := json.Marshal(json.Number(text)); err != nil {
+		return text, true
+	}
+
+	return json.Number(text), true
+}
+
+type Bool struct {
+	Checked bool
+}
+
+func newBool(v any) *Bool {
+	b := &Bool{}
+	switch v := v.(type) {
+	case bool:
+		b.Checked = v
+	case string:
+		b.Checked = v == "true"
+	}
+	return b
+}
+
+func (b *Bool) JSON() (any, bool) {
+	return b.Checked, b.Checked
+}
+
+// Enum is the value of an enum field, Selected is the index of the value in the values of the enum.
+type Enum struct {
+	Desc     protoreflect.EnumDescriptor
+	Selected int
+}
+
+func newEnum(enum protoreflect.EnumDescriptor, v any) *Enum {
+	e := &Enum{Desc: enum}
+	for i := 0; i < enum.Values().Len(); i++ {
+		ev := enum.Values().Get(i)
+		switch v := v.(type) {
+		case string:
+			if v == string(ev.Name()) {
+				e.Selected = i
+			}
+		case json.Number:
+			if v.String() == strconv.Itoa(int(ev.Number())) {
+				e.Selected = i
+			}
+		}
+	}
+	return e
+}
+
+func (e *Enum) JSON() (any, bool) {
+	if e.Selected < 0 || e.Selected >= e.Desc.Values().Len() {
+		return nil, false
+	}
+
+	ev := e.Desc.Values().Get(e.Selected)
+	return string(ev.Name()), ev.Number() != 0
+}
+
+// jsonObject is a json object that keeps the order of its fields, the fields of the message are
+// written in the order of the proto file.
+type jsonObject []jsonField
+
+type jsonField struct {
+	key   string
+	value any
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// lookupField returns the value of the field in the json object, by its proto or json name.
+func lookupField(values map[string]any, fd protoreflect.FieldDescriptor) any {
+	if v, ok := values[string(fd.Name())]; ok {
+		return v
+	}
+	return values[fd.JSONName()]
+}
+
+func scalarText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func kindPlaceholder(kind protoreflect.Kind) string {
+	if kind == protoreflect.BytesKind {
+		return "bytes, base64"
+	}
+	return kind.String()
+}
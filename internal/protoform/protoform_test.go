@@ -0,0 +1,353 @@
+package protoform
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const testProto = `syntax = "proto3";
+
+package test;
+
+import "google/protobuf/timestamp.proto";
+import "google/protobuf/duration.proto";
+import "google/protobuf/struct.proto";
+import "google/protobuf/wrappers.proto";
+
+enum Status {
+  STATUS_UNKNOWN = 0;
+  STATUS_ACTIVE = 1;
+}
+
+message Address {
+  string street = 1;
+  Address next = 2;
+}
+
+message Request {
+  string name = 1;
+  bytes data = 2;
+  int32 count = 3;
+  int64 total = 4;
+  uint32 size = 5;
+  uint64 big = 6;
+  double ratio = 7;
+  float score = 8;
+  bool enabled = 9;
+  Status status = 10;
+  Address address = 11;
+  oneof target {
+    string email = 12;
+    Address location = 13;
+  }
+  map<string, int32> counts = 14;
+  map<bool, string> flags = 15;
+  repeated string tags = 16;
+  repeated Address addresses = 17;
+  google.protobuf.Timestamp created_at = 18;
+  google.protobuf.Duration timeout = 19;
+  google.protobuf.Struct meta = 20;
+  google.protobuf.StringValue nickname = 21;
+}
+`
+
+func testDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(map[string]string{"test.proto": testProto}),
+	}
+	fds, err := parser.ParseFiles("test.proto")
+	require.NoError(t, err)
+
+	desc := fds[0].UnwrapFile().Messages().ByName("Request")
+	require.NotNil(t, desc)
+	return desc
+}
+
+// roundTrip reads the body into a form and returns the message of the body the form writes.
+func roundTrip(t *testing.T, desc protoreflect.MessageDescriptor, body string) *dynamicpb.Message {
+	t.Helper()
+
+	f := New(desc, body)
+	require.Empty(t, f.Warning)
+
+	msg := dynamicpb.NewMessage(desc)
+	require.NoError(t, protojson.Unmarshal([]byte(f.Body()), msg), f.Body())
+	return msg
+}
+
+func TestFormRoundTrip(t *testing.T) {
+	desc := testDescriptor(t)
+
+	body := `{
+  "name": "chapar",
+  "data": "aGVsbG8=",
+  "count": -12,
+  "total": "9007199254740993",
+  "size": 7,
+  "big": 18446744073709551615,
+  "ratio": 0.25,
+  "score": "NaN",
+  "enabled": true,
+  "status": "STATUS_ACTIVE",
+  "address": {"street": "main", "next": {"street": "second"}},
+  "location": {"street": "third"},
+  "counts": {"b": 2, "a": 1},
+  "flags": {"true": "yes"},
+  "tags": ["x", "y"],
+  "addresses": [{"street": "first"}, {}],
+  "createdAt": "2024-05-01T10:20:30.5+02:00",
+  "timeout": "90s",
+  "meta": {"key": ["value", 1, null]},
+  "nickname": "chap"
+}`
+
+	want := dynamicpb.NewMessage(desc)
+	require.NoError(t, protojson.Unmarshal([]byte(body), want))
+
+	got := roundTrip(t, desc, body)
+	require.True(t, proto.Equal(want, got), "want %v, got %v", want, got)
+}
+
+func TestFormRoundTripEmpty(t *testing.T) {
+	desc := testDescriptor(t)
+
+	f := New(desc, "")
+	require.Empty(t, f.Warning)
+	require.False(t, f.Root.Built())
+	require.Equal(t, "{}", f.Body())
+
+	// defaults are left out of the body
+	f.Root.Rows()
+	require.Equal(t, "{}", f.Body())
+}
+
+func TestFormInvalidBody(t *testing.T) {
+	desc := testDescriptor(t)
+
+	f := New(desc, `{"name": `)
+	require.Contains(t, f.Warning, "The body is not a valid JSON object")
+	require.Equal(t, "{}", f.Body())
+
+	f = New(desc, `["name"]`)
+	require.NotEmpty(t, f.Warning)
+}
+
+func TestFormKeepsFieldOrder(t *testing.T) {
+	desc := testDescriptor(t)
+
+	f := New(desc, `{"enabled": true, "name": "a", "unknown": 1}`)
+	require.Equal(t, "{\n  \"name\": \"a\",\n  \"enabled\": true\n}", f.Body())
+}
+
+func rowByKey(t *testing.T, m *Message, key string) Row {
+	t.Helper()
+
+	for _, row := range m.Rows() {
+		if f, ok := row.(*Field); ok && f.Key() == key {
+			return row
+		}
+		if o, ok := row.(*Oneof); ok && string(o.Desc.Name()) == key {
+			return row
+		}
+	}
+	require.Failf(t, "row not found", "%s", key)
+	return nil
+}
+
+func fieldValue(t *testing.T, m *Message, key string) Value {
+	t.Helper()
+	return rowByKey(t, m, key).(*Field).Value
+}
+
+func TestMessageUnbuilt(t *testing.T) {
+	desc := testDescriptor(t)
+
+	f := New(desc, `{"name": "a"}`)
+	require.True(t, f.Root.Built())
+
+	address := fieldValue(t, f.Root, "address").(*Message)
+	require.False(t, address.Built())
+	v, ok := address.JSON()
+	require.False(t, ok)
+	require.Equal(t, jsonObject{}, v)
+
+	// building a recursive message only builds its own fields
+	next := fieldValue(t, address, "next").(*Message)
+	require.False(t, next.Built())
+
+	fieldValue(t, address, "street").(*Scalar).Text = "main"
+	require.JSONEq(t, `{"name": "a", "address": {"street": "main"}}`, f.Body())
+}
+
+func TestOneof(t *testing.T) {
+	desc := testDescriptor(t)
+
+	f := New(desc, `{"email": "a@b.c"}`)
+	oneof := rowByKey(t, f.Root, "target").(*Oneof)
+	require.Equal(t, 0, oneof.Selected())
+	require.Equal(t, "email", oneof.Key())
+
+	// the selected message is sent even when it's empty, it's what sets the oneof
+	location := oneof.Select(1).(*Message)
+	require.Equal(t, "location", oneof.Key())
+	require.JSONEq(t, `{"location": {}}`, f.Body())
+
+	location.Rows()
+	fieldValue(t, location, "street").(*Scalar).Text = "main"
+	require.JSONEq(t, `{"location": {"street": "main"}}`, f.Body())
+
+	// the values of the fields selected before are kept
+	require.Equal(t, "a@b.c", oneof.Select(0).(*Scalar).Text)
+	require.JSONEq(t, `{"email": "a@b.c"}`, f.Body())
+	require.Same(t, location, oneof.Select(1))
+
+	require.Nil(t, oneof.Select(-1))
+	require.Nil(t, oneof.SelectedValue())
+	require.Equal(t, "{}", f.Body())
+}
+
+func TestListAndMap(t *testing.T) {
+	desc := testDescriptor(t)
+
+	f := New(desc, `{"tags": ["a", "b", "c"], "counts": {"y": 2, "x": 1}, "flags": {"false": "no"}}`)
+
+	tags := fieldValue(t, f.Root, "tags").(*List)
+	require.Len(t, tags.Items, 3)
+	tags.Remove(1)
+	tags.Add().(*Scalar).Text = "d"
+
+	counts := fieldValue(t, f.Root, "counts").(*Map)
+	require.Equal(t, "x", counts.Entries[0].Key.Text)
+	counts.Remove(0)
+	entry := counts.Add()
+	entry.Key.Text = "z"
+	entry.Value.(*Scalar).Text = "3"
+
+	flags := fieldValue(t, f.Root, "flags").(*Map)
+	require.Equal(t, protoreflect.StringKind, flags.Entries[0].Key.Kind)
+
+	addresses := fieldValue(t, f.Root, "addresses").(*List)
+	require.IsType(t, &Message{}, addresses.Add())
+
+	require.JSONEq(t, `{
+		"counts": {"y": 2, "z": 3},
+		"flags": {"false": "no"},
+		"tags": ["a", "c", "d"],
+		"addresses": [{}]
+	}`, f.Body())
+
+	got := roundTrip(t, desc, f.Body())
+	require.Equal(t, 2, got.Get(desc.Fields().ByName("counts")).Map().Len())
+}
+
+func TestWellKnownTypes(t *testing.T) {
+	desc := testDescriptor(t)
+
+	f := New(desc, `{"createdAt": "2024-05-01T10:20:30Z", "timeout": "5400s", "meta": {"a": 1}, "nickname": "n"}`)
+
+	createdAt := fieldValue(t, f.Root, "created_at").(*Scalar)
+	require.Equal(t, FormatTimestamp, createdAt.Format)
+
+	timeout := fieldValue(t, f.Root, "timeout").(*Scalar)
+	require.Equal(t, FormatDuration, timeout.Format)
+	require.Equal(t, "1h30m0s", timeout.Text)
+
+	meta := fieldValue(t, f.Root, "meta").(*Scalar)
+	require.Equal(t, FormatJSON, meta.Format)
+	require.JSONEq(t, `{"a": 1}`, meta.Text)
+
+	nickname := fieldValue(t, f.Root, "nickname").(*Scalar)
+	require.Equal(t, "n", nickname.Text)
+
+	createdAt.Text = "2024-05-01 12:00"
+	timeout.Text = "1.5s"
+	meta.Text = `["x"]`
+	require.JSONEq(t, `{
+		"created_at": "2024-05-01T12:00:00Z",
+		"timeout": "1.5s",
+		"meta": ["x"],
+		"nickname": "n"
+	}`, f.Body())
+
+	createdAt.Text = "2024-05-01T12:00:00+02:00"
+	v, ok := createdAt.JSON()
+	require.True(t, ok)
+	require.Equal(t, "2024-05-01T10:00:00Z", v)
+
+	// invalid json is left out, invalid timestamps and durations are sent as typed
+	createdAt.Text = "yesterday"
+	timeout.Text = "soon"
+	meta.Text = `{"a":`
+	require.False(t, createdAt.Valid())
+	require.False(t, timeout.Valid())
+	require.False(t, meta.Valid())
+	require.JSONEq(t, `{"created_at": "yesterday", "timeout": "soon", "nickname": "n"}`, f.Body())
+}
+
+func TestScalarNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  protoreflect.Kind
+		text  string
+		valid bool
+		value any
+	}{
+		{name: "int32", kind: protoreflect.Int32Kind, text: "-2147483648", valid: true, value: json.Number("-2147483648")},
+		{name: "int32 overflow", kind: protoreflect.Int32Kind, text: "2147483648", value: "2147483648"},
+		{name: "int32 plus", kind: protoreflect.Int32Kind, text: "+1", valid: true, value: "+1"},
+		{name: "int64", kind: protoreflect.Int64Kind, text: " 9223372036854775807 ", valid: true, value: json.Number("9223372036854775807")},
+		{name: "int64 decimal", kind: protoreflect.Sint64Kind, text: "1.5", value: "1.5"},
+		{name: "uint32 negative", kind: protoreflect.Uint32Kind, text: "-1", value: "-1"},
+		{name: "uint64", kind: protoreflect.Fixed64Kind, text: "18446744073709551615", valid: true, value: json.Number("18446744073709551615")},
+		{name: "double", kind: protoreflect.DoubleKind, text: "1e-3", valid: true, value: json.Number("1e-3")},
+		{name: "float nan", kind: protoreflect.FloatKind, text: "NaN", valid: true, value: "NaN"},
+		{name: "double infinity", kind: protoreflect.DoubleKind, text: "-Infinity", valid: true, value: "-Infinity"},
+		{name: "double text", kind: protoreflect.DoubleKind, text: "abc", value: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Scalar{Kind: tt.kind, Text: tt.text}
+			require.Equal(t, tt.valid, s.Valid())
+
+			v, ok := s.JSON()
+			require.True(t, ok)
+			require.Equal(t, tt.value, v)
+		})
+	}
+
+	// unset numbers are left out, strings are sent untrimmed
+	v, ok := (&Scalar{Kind: protoreflect.Int32Kind, Text: " "}).JSON()
+	require.False(t, ok)
+	require.Equal(t, json.Number("0"), v)
+
+	v, ok = (&Scalar{Kind: protoreflect.StringKind, Text: " a "}).JSON()
+	require.True(t, ok)
+	require.Equal(t, " a ", v)
+}
+
+func TestBoolAndEnum(t *testing.T) {
+	desc := testDescriptor(t)
+
+	f := New(desc, `{"enabled": true, "status": 1}`)
+	enabled := fieldValue(t, f.Root, "enabled").(*Bool)
+	require.True(t, enabled.Checked)
+
+	status := fieldValue(t, f.Root, "status").(*Enum)
+	require.Equal(t, 1, status.Selected)
+	require.JSONEq(t, `{"enabled": true, "status": "STATUS_ACTIVE"}`, f.Body())
+
+	// false and the zero value of enums are the defaults, they are left out
+	enabled.Checked = false
+	status.Selected = 0
+	require.Equal(t, "{}", f.Body())
+}
@@ -0,0 +1,300 @@
+// Package proxy resolves the proxy settings into something the http transports and the grpc dialer can use.
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// Func returns the proxy function for the given config, to be used as http.Transport.Proxy.
+// it returns a nil func when requests should go out directly.
+func Func(cfg domain.ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	switch cfg.Mode {
+	case domain.ProxyModeNone:
+		return nil, nil
+	case domain.ProxyModeSystem, "":
+		return http.ProxyFromEnvironment, nil
+	case domain.ProxyModeManual:
+	default:
+		return nil, fmt.Errorf("unknown proxy mode %q", cfg.Mode)
+	}
+
+	if cfg.URL == "" {
+		return nil, nil
+	}
+
+	raw := cfg.URL
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+
+	if cfg.Username != "" {
+		proxyURL.User = url.UserPassword(cfg.Username, cfg.Password)
+	}
+
+	bypass := parseBypass(cfg.Bypass)
+	return func(req *http.Request) (*url.URL, error) {
+		if bypass.match(req.URL.Hostname(), req.URL.Port()) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// Dialer opens connections through the configured proxy, it's used where the
+// transport has no proxy support of its own such as http2 and grpc.
+type Dialer struct {
+	proxy   func(*http.Request) (*url.URL, error)
+	forward net.Dialer
+}
+
+func NewDialer(cfg domain.ProxyConfig) (*Dialer, error) {
+	f, err := Func(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dialer{proxy: f}, nil
+}
+
+// ProxyFunc returns the proxy function of the dialer, to be used as http.Transport.Proxy.
+func (d *Dialer) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	return d.proxy
+}
+
+// Proxied reports whether connections to addr go through a proxy.
+func (d *Dialer) Proxied(addr string) bool {
+	proxyURL, err := d.proxyFor(addr)
+	return err == nil && proxyURL != nil
+}
+
+// DialContext connects to addr, either directly or through the proxy.
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	proxyURL, err := d.proxyFor(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if proxyURL == nil {
+		return d.forward.DialContext(ctx, network, addr)
+	}
+
+	conn, err := d.forward.DialContext(ctx, "tcp", canonicalAddr(proxyURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy: %w", err)
+	}
+
+	// make sure the handshake respects the context deadline and cancellation
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		err = socks5Connect(conn, proxyURL.User, addr)
+	case "https":
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+		if err = tlsConn.HandshakeContext(ctx); err == nil {
+			conn = tlsConn
+			conn, err = httpConnect(conn, proxyURL.User, addr)
+		}
+	default:
+		conn, err = httpConnect(conn, proxyURL.User, addr)
+	}
+
+	if !stop() && err == nil {
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// DialTLSContext connects to addr and does the tls handshake, it matches the signature of http2.Transport.DialTLSContext.
+func (d *Dialer) DialTLSContext(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// http2 leaves the tls handshake to us, so it's reported to the trace here the same way net/http does
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.TLSHandshakeStart != nil {
+		trace.TLSHandshakeStart()
+	}
+
+	tlsConn := tls.Client(conn, cfg)
+	err = tlsConn.HandshakeContext(ctx)
+	if trace != nil && trace.TLSHandshakeDone != nil {
+		trace.TLSHandshakeDone(tlsConn.ConnectionState(), err)
+	}
+
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+func (d *Dialer) proxyFor(addr string) (*url.URL, error) {
+	if d.proxy == nil {
+		return nil, nil
+	}
+
+	// the proxy func expects a request, the scheme decides which of the environment proxies is used
+	return d.proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: addr}})
+}
+
+func canonicalAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+
+	switch u.Scheme {
+	case "https":
+		return net.JoinHostPort(u.Hostname(), "443")
+	case "socks5", "socks5h":
+		return net.JoinHostPort(u.Hostname(), "1080")
+	default:
+		return net.JoinHostPort(u.Hostname(), "80")
+	}
+}
+
+// httpConnect opens a tunnel to addr through the http proxy on conn, the returned conn also serves the
+// bytes the target sent right after the proxy response, as they may already be read with it.
+func httpConnect(conn net.Conn, user *url.Userinfo, addr string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+
+	if user != nil {
+		password, _ := user.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+
+	if err := req.Write(conn); err != nil {
+		return conn, fmt.Errorf("failed to write proxy connect request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		return conn, fmt.Errorf("failed to read proxy connect response: %w", err)
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return conn, fmt.Errorf("proxy connect failed: %s", res.Status)
+	}
+
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn reads what's left in r before reading from the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	if c.r.Buffered() > 0 {
+		return c.r.Read(p)
+	}
+	return c.Conn.Read(p)
+}
+
+type bypassList struct {
+	entries []string
+	nets    []*net.IPNet
+}
+
+func parseBypass(s string) bypassList {
+	var b bypassList
+	for _, e := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == ' ' || r == '\n' }) {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+
+		if _, n, err := net.ParseCIDR(e); err == nil {
+			b.nets = append(b.nets, n)
+			continue
+		}
+
+		b.entries = append(b.entries, e)
+	}
+	return b
+}
+
+func (b bypassList) match(host, port string) bool {
+	host = strings.ToLower(host)
+	if ip := net.ParseIP(host); ip != nil {
+		for _, n := range b.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+	}
+
+	for _, e := range b.entries {
+		if e == "*" {
+			return true
+		}
+
+		target := host
+		if strings.Contains(e, ":") && net.ParseIP(e) == nil {
+			target = net.JoinHostPort(host, port)
+		}
+
+		// .example.com matches example.com and all of its subdomains
+		if strings.HasPrefix(e, ".") {
+			if target == e[1:] || strings.HasSuffix(target, e) {
+				return true
+			}
+			continue
+		}
+
+		if ok, _ := path.Match(e, target); ok {
+			return true
+		}
+	}
+
+	return false
+}
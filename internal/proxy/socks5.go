@@ -0,0 +1,121 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// socks5Connect asks the socks5 server on conn to connect to addr, as described in RFC 1928.
+// username/password authentication (RFC 1929) is used when user is set.
+func socks5Connect(conn net.Conn, user *url.Userinfo, addr string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 0xffff {
+		return fmt.Errorf("invalid port %q", portStr)
+	}
+
+	method := byte(0x00) // no authentication
+	if user != nil {
+		method = 0x02 // username/password
+	}
+
+	if _, err := conn.Write([]byte{0x05, 0x01, method}); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("failed to read socks5 greeting: %w", err)
+	}
+
+	if reply[0] != 0x05 {
+		return fmt.Errorf("unexpected socks version %d", reply[0])
+	}
+
+	if reply[1] != method {
+		return errors.New("socks5 proxy does not accept the authentication method")
+	}
+
+	if method == 0x02 {
+		password, _ := user.Password()
+		if len(user.Username()) > 255 || len(password) > 255 {
+			return errors.New("socks5 username and password must be at most 255 bytes")
+		}
+
+		req := []byte{0x01, byte(len(user.Username()))}
+		req = append(req, user.Username()...)
+		req = append(req, byte(len(password)))
+		req = append(req, password...)
+		if _, err := conn.Write(req); err != nil {
+			return err
+		}
+
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return fmt.Errorf("failed to read socks5 auth response: %w", err)
+		}
+
+		if reply[1] != 0x00 {
+			return errors.New("socks5 authentication failed")
+		}
+	}
+
+	req := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, 0x01)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, 0x04)
+			req = append(req, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return errors.New("socks5 host name is too long")
+		}
+		req = append(req, 0x03, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("failed to read socks5 connect response: %w", err)
+	}
+
+	if header[1] != 0x00 {
+		return fmt.Errorf("socks5 connect failed with code %d", header[1])
+	}
+
+	// skip the bound address, it's not needed
+	var skip int
+	switch header[3] {
+	case 0x01:
+		skip = net.IPv4len
+	case 0x04:
+		skip = net.IPv6len
+	case 0x03:
+		l := make([]byte, 1)
+		if _, err := io.ReadFull(conn, l); err != nil {
+			return err
+		}
+		skip = int(l[0])
+	default:
+		return fmt.Errorf("unknown socks5 address type %d", header[3])
+	}
+
+	_, err = io.ReadFull(conn, make([]byte, skip+2))
+	return err
+}
@@ -0,0 +1,246 @@
+package scripting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const (
+	JavaScriptExecutorName = "JavaScript"
+
+	// javaScriptTimeout is how long a script can run, the same as the timeout of the python executor.
+	javaScriptTimeout = 10 * time.Second
+)
+
+var _ Executor = (*JavaScriptExecutor)(nil)
+
+// expectScript defines expect, its matchers throw an error which fails the test they're called in.
+const expectScript = `
+function expect(actual) {
+	function equal(a, b) {
+		if (a === b) {
+			return true;
+		}
+		if (typeof a !== "object" || typeof b !== "object" || a === null || b === null || Array.isArray(a) !== Array.isArray(b)) {
+			return false;
+		}
+		const keys = Object.keys(a);
+		if (keys.length !== Object.keys(b).length) {
+			return false;
+		}
+		return keys.every(function (k) { return Object.prototype.hasOwnProperty.call(b, k) && equal(a[k], b[k]); });
+	}
+
+	function show(v) {
+		return v === undefined ? "undefined" : JSON.stringify(v) || String(v);
+	}
+
+	function matchers(negate) {
+		function check(pass, message) {
+			if (pass === negate) {
+				throw new Error("expected " + show(actual) + (negate ? " not " : " ") + message);
+			}
+		}
+
+		return {
+			toBe: function (expected) { check(actual === expected, "to be " + show(expected)); },
+			toEqual: function (expected) { check(equal(actual, expected), "to equal " + show(expected)); },
+			toBeTruthy: function () { check(!!actual, "to be truthy"); },
+			toBeFalsy: function () { check(!actual, "to be falsy"); },
+			toBeNull: function () { check(actual === null, "to be null"); },
+			toBeDefined: function () { check(actual !== undefined, "to be defined"); },
+			toBeUndefined: function () { check(actual === undefined, "to be undefined"); },
+			toBeGreaterThan: function (n) { check(actual > n, "to be greater than " + show(n)); },
+			toBeLessThan: function (n) { check(actual < n, "to be less than " + show(n)); },
+			toContain: function (item) { check(actual != null && actual.indexOf(item) !== -1, "to contain " + show(item)); },
+			toHaveLength: function (n) { check(actual != null && actual.length === n, "to have length " + n); },
+			toHaveProperty: function (key) { check(actual != null && Object.prototype.hasOwnProperty.call(actual, key), "to have property " + show(key)); },
+			toMatch: function (pattern) { check(new RegExp(pattern).test(actual), "to match " + String(pattern)); },
+		};
+	}
+
+	const out = matchers(false);
+	out.not = matchers(true);
+	return out;
+}
+`
+
+// JavaScriptExecutor runs scripts in process on an embedded JavaScript engine, so it doesn't need docker or
+// a separate server. scripts get the same req, res, env and set_env as the python ones, along with test and
+// expect to check the response.
+type JavaScriptExecutor struct{}
+
+func NewJavaScriptExecutor() *JavaScriptExecutor {
+	return &JavaScriptExecutor{}
+}
+
+func (j *JavaScriptExecutor) Name() string {
+	return JavaScriptExecutorName
+}
+
+func (j *JavaScriptExecutor) Init(_ domain.ScriptingConfig) error {
+	return nil
+}
+
+func (j *JavaScriptExecutor) Shutdown() error {
+	return nil
+}
+
+func (j *JavaScriptExecutor) Execute(ctx context.Context, script string, params *ExecParams) (*ExecResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, javaScriptTimeout)
+	defer cancel()
+
+	// each script gets its own runtime, so nothing leaks from one script to another
+	vm := goja.New()
+	stop := context.AfterFunc(ctx, func() {
+		vm.Interrupt(ctx.Err())
+	})
+	defer stop()
+
+	result := &ExecResult{SetEnvironments: map[string]interface{}{}}
+
+	req, err := toJSValue(params.Req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := toJSValue(params.Res)
+	if err != nil {
+		return nil, err
+	}
+
+	env := params.Env.GetKeyValues()
+	if env == nil {
+		env = map[string]interface{}{}
+	}
+
+	printer := func(call goja.FunctionCall) goja.Value {
+		args := make([]string, 0, len(call.Arguments))
+		for _, arg := range call.Arguments {
+			args = append(args, formatJSValue(arg))
+		}
+		result.Prints = append(result.Prints, strings.Join(args, " "))
+		return goja.Undefined()
+	}
+
+	console := vm.NewObject()
+	for _, name := range []string{"log", "info", "warn", "error", "debug"} {
+		if err := console.Set(name, printer); err != nil {
+			return nil, err
+		}
+	}
+
+	// test runs fn and records whether it threw, a failed test doesn't stop the script
+	test := func(name string, fn goja.Callable) {
+		tr := domain.TestResult{Name: name, Passed: true}
+		if _, err := fn(goja.Undefined()); err != nil {
+			var interrupted *goja.InterruptedError
+			if errors.As(err, &interrupted) {
+				panic(interrupted)
+			}
+			tr.Passed = false
+			tr.Message = testFailure(err)
+		}
+		result.Tests = append(result.Tests, tr)
+	}
+
+	globals := map[string]any{
+		"test":    test,
+		"req":     req,
+		"res":     res,
+		"env":     env,
+		"print":   printer,
+		"console": console,
+		"set_env": func(key string, value goja.Value) {
+			v := formatJSValue(value)
+			env[key] = v
+			result.SetEnvironments[key] = v
+		},
+	}
+	for name, value := range globals {
+		if err := vm.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := vm.RunString(expectScript); err != nil {
+		return nil, err
+	}
+
+	if _, err := vm.RunString(script); err != nil {
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &interrupted) {
+			return nil, fmt.Errorf("script interrupted: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("script failed: %w", err)
+	}
+
+	// the script may have changed the request or replaced it altogether
+	if err := fromJSValue(vm.Get("req"), &result.Req); err != nil {
+		return nil, fmt.Errorf("invalid req: %w", err)
+	}
+
+	return result, nil
+}
+
+// toJSValue returns v as plain json values, so scripts see the same field names as the python ones.
+func toJSValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func fromJSValue(v goja.Value, out any) error {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil
+	}
+
+	data, err := json.Marshal(v.Export())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// testFailure returns the message of the error thrown by a test.
+func testFailure(err error) string {
+	var ex *goja.Exception
+	if !errors.As(err, &ex) {
+		return err.Error()
+	}
+
+	if obj, ok := ex.Value().(*goja.Object); ok {
+		if msg := obj.Get("message"); msg != nil && !goja.IsUndefined(msg) {
+			return msg.String()
+		}
+	}
+	return ex.Value().String()
+}
+
+// formatJSValue returns strings as they are and objects as json.
+func formatJSValue(v goja.Value) string {
+	if v == nil {
+		return "undefined"
+	}
+
+	if _, ok := v.(*goja.Object); ok {
+		if data, err := json.Marshal(v.Export()); err == nil {
+			return string(data)
+		}
+	}
+	return v.String()
+}
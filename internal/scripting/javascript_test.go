@@ -0,0 +1,83 @@
+package scripting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestJavaScriptExecutor(t *testing.T) {
+	env := &domain.Environment{Spec: domain.EnvSpec{Values: []domain.KeyValue{{Key: "host", Value: "example.com"}}}}
+	params := &ExecParams{
+		Env: env,
+		Req: &RequestData{Method: "GET", URL: "https://example.com", Headers: Values{"Accept": {"*/*"}}},
+		Res: &ResponseData{StatusCode: 200, Body: `{"token":"abc","user":{"id":7}}`},
+	}
+
+	script := `
+const body = JSON.parse(res.body);
+set_env("token", body.token);
+set_env("user", body.user);
+set_env("status", res.statusCode);
+req.headers["Authorization"] = "Bearer " + env.token;
+print("host is", env.host);
+console.log({ok: true});
+`
+	result, err := NewJavaScriptExecutor().Execute(context.Background(), script, params)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]interface{}{"token": "abc", "user": `{"id":7}`, "status": "200"}, result.SetEnvironments)
+	require.Equal(t, []string{"host is example.com", `{"ok":true}`}, result.Prints)
+	require.Equal(t, "Bearer abc", result.Req.Headers.Get("Authorization"))
+	require.Equal(t, "GET", result.Req.Method)
+}
+
+func TestJavaScriptExecutorErrors(t *testing.T) {
+	_, err := NewJavaScriptExecutor().Execute(context.Background(), "throw new Error('boom')", &ExecParams{})
+	require.ErrorContains(t, err, "boom")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = NewJavaScriptExecutor().Execute(ctx, "while (true) {}", &ExecParams{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestJavaScriptExecutorTests(t *testing.T) {
+	params := &ExecParams{
+		Res: &ResponseData{StatusCode: 404, Headers: map[string]string{"Content-Type": "application/json"}, Body: `{"items":[1,2],"user":{"id":7}}`},
+	}
+
+	script := `
+const body = JSON.parse(res.body);
+test("status is 200", function () { expect(res.statusCode).toBe(200); });
+test("has items", function () {
+	expect(body.items).toHaveLength(2);
+	expect(body.items).toContain(2);
+	expect(body.user).toEqual({id: 7});
+	expect(body).toHaveProperty("user");
+	expect(res.headers["Content-Type"]).toMatch("json");
+});
+test("not empty", () => expect(body.items).not.toHaveLength(0));
+test("no error", () => expect(body.error).not.toBeDefined());
+test("thrown", () => { throw "oops"; });
+`
+	result, err := NewJavaScriptExecutor().Execute(context.Background(), script, params)
+	require.NoError(t, err)
+	require.Equal(t, []domain.TestResult{
+		{Name: "status is 200", Message: "expected 404 to be 200"},
+		{Name: "has items", Passed: true},
+		{Name: "not empty", Passed: true},
+		{Name: "no error", Passed: true},
+		{Name: "thrown", Message: "oops"},
+	}, result.Tests)
+
+	// a test running out of time stops the script
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = NewJavaScriptExecutor().Execute(ctx, `test("loop", () => { while (true) {} });`, &ExecParams{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -143,7 +143,7 @@ func (p *PythonExecutor) Execute(ctx context.Context, script string, params *Exe
 	}
 
 	// Execute the script
-	result, err := p.executeScript("/execute", body)
+	result, err := p.executeScript(ctx, "/execute", body)
 	if err != nil {
 		return nil, err
 	}
@@ -158,8 +158,8 @@ func (p *PythonExecutor) Shutdown() error {
 	return nil
 }
 
-// executeScript sends a request to the Python server to execute a script
-func (p *PythonExecutor) executeScript(endpoint string, requestBody interface{}) (*ExecResult, error) {
+// executeScript sends a request to the Python server to execute a script, cancelling ctx stops waiting for it
+func (p *PythonExecutor) executeScript(ctx context.Context, endpoint string, requestBody interface{}) (*ExecResult, error) {
 	// Marshal the request body
 	data, err := json.Marshal(requestBody)
 	if err != nil {
@@ -168,7 +168,13 @@ func (p *PythonExecutor) executeScript(endpoint string, requestBody interface{})
 
 	url := p.serverURL() + endpoint
 	// Send the request to the Python server
-	resp, err := p.client.Post(url, "application/json", io.NopCloser(bytes.NewReader(data)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request to Python server: %w", err)
 	}
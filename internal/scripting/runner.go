@@ -3,8 +3,11 @@ package scripting
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/google/uuid"
+
 	"github.com/chapar-rest/chapar/internal/domain"
 )
 
@@ -17,8 +20,11 @@ type Executor interface {
 
 func GetExecutor(language string, cfg domain.ScriptingConfig) (Executor, error) {
 	n := strings.ToLower(language)
-	if n == "python" {
+	switch n {
+	case "python":
 		return NewPythonExecutor(cfg), nil
+	case "javascript":
+		return NewJavaScriptExecutor(), nil
 	}
 
 	return nil, fmt.Errorf("unknown scripting executor: %s", language)
@@ -26,8 +32,9 @@ func GetExecutor(language string, cfg domain.ScriptingConfig) (Executor, error)
 
 // RequestData represents the HTTP request data that can be modified by scripts
 type RequestData struct {
-	// Body is the request body
-	Body string `json:"body"`
+	// Body is the request body, in case of grpc it is the message in the body format of the request.
+	// nil leaves the body of the request as it is.
+	Body *string `json:"body,omitempty"`
 	// when grpc is used, this field in the grpc method name otherwise it is the http method
 	Method string `json:"method"`
 
@@ -35,12 +42,16 @@ type RequestData struct {
 	URL string `json:"url"`
 
 	// GRPC related fields
-	Metadata map[string]string `json:"metadata"`
+	Metadata Values `json:"metadata"`
 
 	// HTTP related fields
-	Headers     map[string]string `json:"headers"`
-	QueryParams map[string]string `json:"QueryParams"`
-	PathParams  map[string]string `json:"pathParams"`
+	Headers     Values `json:"headers"`
+	QueryParams Values `json:"QueryParams"`
+	PathParams  Values `json:"pathParams"`
+
+	// GraphQL related fields, nil leaves them as they are
+	Query     *string `json:"query,omitempty"`
+	Variables *string `json:"variables,omitempty"`
 }
 
 // ResponseData represents the HTTP response data that can be accessed by scripts
@@ -57,11 +68,16 @@ type ExecParams struct {
 }
 
 type ExecResult struct {
-	Req             *RequestData           `json:"-"`
+	// Req is the request as the script left it, nil when the executor doesn't return it
+	Req             *RequestData           `json:"requestData,omitempty"`
 	SetEnvironments map[string]interface{} `json:"set_environments"`
 	Prints          []string               `json:"prints"`
+	// Tests are the results of the test calls of the script, in the order they ran
+	Tests []domain.TestResult `json:"tests"`
 }
 
+// RequestDataFromDomain returns the request data scripts get for req, the disabled key values are left out
+// as they're not sent.
 func RequestDataFromDomain(req *domain.Request) *RequestData {
 	out := &RequestData{}
 
@@ -72,29 +88,140 @@ func RequestDataFromDomain(req *domain.Request) *RequestData {
 	if httpReq := req.Spec.GetHTTP(); httpReq != nil {
 		out.Method = httpReq.Method
 		out.URL = httpReq.URL
-		out.Headers = make(map[string]string)
-		for _, header := range httpReq.Request.Headers {
-			out.Headers[header.Key] = header.Value
+		out.Headers = enabledValues(httpReq.Request.Headers)
+		out.QueryParams = enabledValues(httpReq.Request.QueryParams)
+		out.PathParams = enabledValues(httpReq.Request.PathParams)
+		out.Body = ptr(httpReq.Request.Body.Data)
+	}
+
+	if grpcReq := req.Spec.GetGRPC(); grpcReq != nil {
+		out.Method = grpcReq.LasSelectedMethod
+		out.URL = grpcReq.ServerInfo.Address
+		out.Metadata = enabledValues(grpcReq.Metadata)
+		out.Body = ptr(grpcReq.Body)
+	}
+
+	if graphqlReq := req.Spec.GetGraphQL(); graphqlReq != nil {
+		out.Method = "POST"
+		out.URL = graphqlReq.URL
+		out.Headers = enabledValues(graphqlReq.Headers)
+		out.Query = ptr(graphqlReq.Query)
+		out.Variables = ptr(graphqlReq.Variables)
+	}
+
+	return out
+}
+
+// ApplyRequestData applies the request data returned by a pre request script to req, it's the reverse of
+// RequestDataFromDomain. nil maps and fields leave the values of the request as they are.
+func ApplyRequestData(data *RequestData, req *domain.Request) {
+	if data == nil || req == nil {
+		return
+	}
+
+	if httpReq := req.Spec.GetHTTP(); httpReq != nil {
+		if data.Method != "" {
+			httpReq.Method = data.Method
+		}
+		if data.URL != "" {
+			httpReq.URL = data.URL
 		}
-		out.QueryParams = make(map[string]string)
-		for _, param := range httpReq.Request.QueryParams {
-			out.QueryParams[param.Key] = param.Value
+		httpReq.Request.Headers = applyValues(httpReq.Request.Headers, data.Headers)
+		httpReq.Request.PathParams = applyValues(httpReq.Request.PathParams, data.PathParams)
+
+		// the query params are sent as part of the url, so the url follows them when the script changed them
+		queryParams := applyValues(httpReq.Request.QueryParams, data.QueryParams)
+		if !domain.CompareKeyValues(queryParams, httpReq.Request.QueryParams) {
+			httpReq.Request.QueryParams = queryParams
+			httpReq.URL, _, _ = strings.Cut(httpReq.URL, "?")
+			if query := domain.EncodeQueryParams(queryParams); query != "" {
+				httpReq.URL += "?" + query
+			}
 		}
-		out.PathParams = make(map[string]string)
-		for _, param := range httpReq.Request.PathParams {
-			out.PathParams[param.Key] = param.Value
+		if data.Body != nil {
+			httpReq.Request.Body.Data = *data.Body
 		}
-		out.Body = httpReq.Request.Body.Data
 	}
 
 	if grpcReq := req.Spec.GetGRPC(); grpcReq != nil {
-		out.Method = grpcReq.LasSelectedMethod
-		out.URL = grpcReq.ServerInfo.Address
-		out.Metadata = make(map[string]string)
-		for _, header := range grpcReq.Metadata {
-			out.Metadata[header.Key] = header.Value
+		if data.Method != "" {
+			grpcReq.LasSelectedMethod = data.Method
+		}
+		if data.URL != "" {
+			grpcReq.ServerInfo.Address = data.URL
+		}
+		grpcReq.Metadata = applyValues(grpcReq.Metadata, data.Metadata)
+		// binary bodies are read from their file, so the body only matters for the other formats
+		if data.Body != nil {
+			grpcReq.Body = *data.Body
+		}
+	}
+
+	if graphqlReq := req.Spec.GetGraphQL(); graphqlReq != nil {
+		if data.URL != "" {
+			graphqlReq.URL = data.URL
+		}
+		graphqlReq.Headers = applyValues(graphqlReq.Headers, data.Headers)
+		if data.Query != nil {
+			graphqlReq.Query = *data.Query
+		}
+		if data.Variables != nil {
+			graphqlReq.Variables = *data.Variables
 		}
 	}
+}
 
+// enabledValues returns the values of the enabled key values.
+func enabledValues(kvs []domain.KeyValue) Values {
+	out := make(Values, len(kvs))
+	for _, kv := range kvs {
+		if kv.Enable {
+			out[kv.Key] = append(out[kv.Key], kv.Value)
+		}
+	}
 	return out
 }
+
+// applyValues updates the enabled key values from the values of the script, the disabled ones are kept as
+// they are since scripts don't see them. the values of a key replace its entries in order, entries left
+// without a value are dropped and the values left over are appended as enabled.
+func applyValues(kvs []domain.KeyValue, values Values) []domain.KeyValue {
+	if values == nil {
+		return kvs
+	}
+
+	out := make([]domain.KeyValue, 0, len(kvs))
+	used := make(map[string]int, len(values))
+	for _, kv := range kvs {
+		if !kv.Enable {
+			out = append(out, kv)
+			continue
+		}
+
+		i := used[kv.Key]
+		if i >= len(values[kv.Key]) {
+			continue
+		}
+		kv.Value = values[kv.Key][i]
+		used[kv.Key] = i + 1
+		out = append(out, kv)
+	}
+
+	// the map has no order, so the added keys are sorted to keep the request stable
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range values[k][used[k]:] {
+			out = append(out, domain.KeyValue{ID: uuid.NewString(), Key: k, Value: v, Enable: true})
+		}
+	}
+	return out
+}
+
+func ptr(s string) *string {
+	return &s
+}
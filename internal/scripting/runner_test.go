@@ -0,0 +1,128 @@
+package scripting
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func TestApplyRequestData(t *testing.T) {
+	req := domain.NewHTTPRequest("test")
+	req.Spec.HTTP.URL = "https://example.com/users?page=1"
+	req.Spec.HTTP.Request.Headers = []domain.KeyValue{
+		{ID: "1", Key: "Accept", Value: "*/*", Enable: true},
+		{ID: "2", Key: "X-Debug", Value: "1", Enable: false},
+	}
+	req.Spec.HTTP.Request.QueryParams = []domain.KeyValue{{ID: "3", Key: "page", Value: "1", Enable: true}}
+
+	data := RequestDataFromDomain(req)
+	data.Method = "POST"
+	require.NotContains(t, data.Headers, "X-Debug")
+	data.Headers.Set("Accept", "application/json")
+	data.Headers.Set("X-Signature", "abc")
+	data.Headers.Set("X-Nonce", "n1")
+	data.QueryParams.Set("page", "2")
+	data.Body = ptr(`{"name":"test"}`)
+
+	ApplyRequestData(data, req)
+
+	http := req.Spec.HTTP
+	require.Equal(t, "POST", http.Method)
+	require.Equal(t, "https://example.com/users?page=2", http.URL)
+	require.Equal(t, `{"name":"test"}`, http.Request.Body.Data)
+
+	// the disabled header is kept as scripts don't see it
+	require.Len(t, http.Request.Headers, 4)
+	require.Equal(t, domain.KeyValue{ID: "1", Key: "Accept", Value: "application/json", Enable: true}, http.Request.Headers[0])
+	require.Equal(t, domain.KeyValue{ID: "2", Key: "X-Debug", Value: "1", Enable: false}, http.Request.Headers[1])
+	require.Equal(t, "X-Nonce", http.Request.Headers[2].Key)
+	require.Equal(t, "X-Signature", http.Request.Headers[3].Key)
+	require.True(t, http.Request.Headers[3].Enable)
+	require.NotEmpty(t, http.Request.Headers[3].ID)
+}
+
+func TestApplyRequestDataDuplicateKeys(t *testing.T) {
+	req := domain.NewHTTPRequest("test")
+	req.Spec.HTTP.Request.Headers = []domain.KeyValue{
+		{ID: "1", Key: "X-Tag", Value: "a", Enable: true},
+		{ID: "2", Key: "X-Tag", Value: "b", Enable: true},
+		{ID: "3", Key: "X-Tag", Value: "off", Enable: false},
+		{ID: "4", Key: "X-Other", Value: "1", Enable: true},
+		{ID: "5", Key: "X-Other", Value: "2", Enable: true},
+	}
+
+	data := RequestDataFromDomain(req)
+	require.Equal(t, Values{"X-Tag": {"a", "b"}, "X-Other": {"1", "2"}}, data.Headers)
+
+	// scripts get the keys with more than one value as arrays
+	encoded, err := json.Marshal(data.Headers)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"X-Tag":["a","b"],"X-Other":["1","2"]}`, string(encoded))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"X-Tag":["a","c","d"],"X-Other":"3"}`), &data.Headers))
+	ApplyRequestData(data, req)
+
+	require.Equal(t, []domain.KeyValue{
+		{ID: "1", Key: "X-Tag", Value: "a", Enable: true},
+		{ID: "2", Key: "X-Tag", Value: "c", Enable: true},
+		{ID: "3", Key: "X-Tag", Value: "off", Enable: false},
+		{ID: "4", Key: "X-Other", Value: "3", Enable: true},
+	}, req.Spec.HTTP.Request.Headers[:4])
+	require.Len(t, req.Spec.HTTP.Request.Headers, 5)
+	require.Equal(t, "d", req.Spec.HTTP.Request.Headers[4].Value)
+}
+
+func TestValuesUnmarshal(t *testing.T) {
+	var values Values
+	require.NoError(t, json.Unmarshal([]byte(`{"a":"x","b":12,"c":true,"d":null,"e":["1",2]}`), &values))
+	require.Equal(t, Values{"a": {"x"}, "b": {"12"}, "c": {"true"}, "d": {}, "e": {"1", "2"}}, values)
+
+	require.Error(t, json.Unmarshal([]byte(`{"a":{"b":"c"}}`), &values))
+
+	values = nil
+	require.NoError(t, json.Unmarshal([]byte(`null`), &values))
+	require.Nil(t, values)
+}
+
+func TestApplyRequestDataGRPCAndGraphQL(t *testing.T) {
+	grpcReq := domain.NewGRPCRequest("test")
+	grpcReq.Spec.GRPC.Body = `{"id":1}`
+	data := RequestDataFromDomain(grpcReq)
+	require.Equal(t, `{"id":1}`, *data.Body)
+	data.Body = ptr(`{"id":2}`)
+	ApplyRequestData(data, grpcReq)
+	require.Equal(t, `{"id":2}`, grpcReq.Spec.GRPC.Body)
+
+	graphqlReq := domain.NewGraphQLRequest("test")
+	graphqlReq.Spec.GraphQL.Query = "{ me { id } }"
+	data = RequestDataFromDomain(graphqlReq)
+	require.Equal(t, "{ me { id } }", *data.Query)
+	data.Variables = ptr(`{"id":1}`)
+	ApplyRequestData(data, graphqlReq)
+	require.Equal(t, "{ me { id } }", graphqlReq.Spec.GraphQL.Query)
+	require.Equal(t, `{"id":1}`, graphqlReq.Spec.GraphQL.Variables)
+}
+
+func TestApplyRequestDataUnchanged(t *testing.T) {
+	req := domain.NewHTTPRequest("test")
+	req.Spec.HTTP.URL = "https://example.com/{{path}}?q={{query}}"
+	req.Spec.HTTP.Request.QueryParams = []domain.KeyValue{{ID: "1", Key: "q", Value: "{{query}}", Enable: true}}
+	want := req.Clone().Spec
+
+	ApplyRequestData(RequestDataFromDomain(req), req)
+	require.True(t, domain.CompareHTTPRequestSpecs(want.HTTP, req.Spec.HTTP))
+
+	// scripts replacing req altogether keep what they left out
+	req.Spec.HTTP.Request.Body.Data = "body"
+	ApplyRequestData(&RequestData{Headers: Values{"Accept": {"*/*"}}}, req)
+	require.Equal(t, "https://example.com/{{path}}?q={{query}}", req.Spec.HTTP.URL)
+	require.Equal(t, "body", req.Spec.HTTP.Request.Body.Data)
+	require.Equal(t, want.HTTP.Method, req.Spec.HTTP.Method)
+	// the disabled content type of new requests is kept along with the added header
+	require.Len(t, req.Spec.HTTP.Request.Headers, 2)
+	require.False(t, req.Spec.HTTP.Request.Headers[0].Enable)
+	require.Equal(t, "Accept", req.Spec.HTTP.Request.Headers[1].Key)
+}
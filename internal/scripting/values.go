@@ -0,0 +1,94 @@
+package scripting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Values are the key values of a request by their key, keys used more than once keep all their values in
+// order. scripts see the keys with a single value as strings and the others as arrays of strings.
+type Values map[string][]string
+
+// Get returns the first value of key, if any.
+func (v Values) Get(key string) string {
+	if len(v[key]) == 0 {
+		return ""
+	}
+	return v[key][0]
+}
+
+// Set replaces the values of key with value.
+func (v Values) Set(key, value string) {
+	v[key] = []string{value}
+}
+
+func (v Values) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+
+	out := make(map[string]any, len(v))
+	for k, values := range v {
+		if len(values) == 1 {
+			out[k] = values[0]
+		} else {
+			out[k] = values
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON reads the values as scripts left them, numbers and booleans are taken as their text and
+// null drops the key.
+func (v *Values) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		return nil
+	}
+
+	out := make(Values, len(raw))
+	for k, value := range raw {
+		items := []json.RawMessage{value}
+		if bytes.HasPrefix(bytes.TrimSpace(value), []byte("[")) {
+			if err := json.Unmarshal(value, &items); err != nil {
+				return err
+			}
+		}
+
+		out[k] = make([]string, 0, len(items))
+		for _, item := range items {
+			s, ok, err := valueString(item)
+			if err != nil {
+				return err
+			}
+			if ok {
+				out[k] = append(out[k], s)
+			}
+		}
+	}
+
+	*v = out
+	return nil
+}
+
+// valueString returns the text of a single json value, false for null.
+func valueString(item json.RawMessage) (string, bool, error) {
+	var value any
+	if err := json.Unmarshal(item, &value); err != nil {
+		return "", false, err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return value, true, nil
+	case map[string]any, []any:
+		return "", false, fmt.Errorf("values have to be strings, got %s", item)
+	}
+	return string(bytes.TrimSpace(item)), true, nil
+}
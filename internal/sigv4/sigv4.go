@@ -0,0 +1,215 @@
+// Package sigv4 signs http requests with AWS Signature Version 4.
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const (
+	algorithm  = "AWS4-HMAC-SHA256"
+	timeFormat = "20060102T150405Z"
+	dateFormat = "20060102"
+
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+var ErrMissingCredentials = errors.New("aws access key id and secret access key are required")
+
+// headers that are either modified by proxies or added by the transport after signing.
+var ignoredHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+	"connection":      true,
+}
+
+// Sign adds the X-Amz-Date, X-Amz-Content-Sha256, X-Amz-Security-Token and Authorization headers to the request.
+// It has to be called after the body and all the other headers are set, as they are part of the signature.
+func Sign(req *http.Request, auth *domain.AWSSigV4Auth, now time.Time) error {
+	if auth == nil || auth.AccessKeyID == "" || auth.SecretAccessKey == "" {
+		return ErrMissingCredentials
+	}
+
+	payloadHash, err := hashPayload(req)
+	if err != nil {
+		return fmt.Errorf("failed to hash payload: %w", err)
+	}
+
+	now = now.UTC()
+	amzDate := now.Format(timeFormat)
+	date := now.Format(dateFormat)
+
+	req.Header.Del("Authorization")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if auth.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", auth.SessionToken)
+	}
+
+	// s3 requires the payload hash header, other services accept it but don't need it
+	if auth.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	canonicalHeaders, signedHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL, auth.Service != "s3"),
+		canonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, auth.Region, auth.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		algorithm,
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+auth.SecretAccessKey), date)
+	key = hmacSHA256(key, auth.Region)
+	key = hmacSHA256(key, auth.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, auth.AccessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+func hashPayload(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return emptyPayloadHash, nil
+	}
+
+	if req.GetBody == nil {
+		return "", errors.New("request body can not be read twice")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func canonicalURI(u *url.URL, doubleEncode bool) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		unescaped, err := url.PathUnescape(s)
+		if err != nil {
+			unescaped = s
+		}
+
+		s = escape(unescaped)
+		if doubleEncode {
+			s = escape(s)
+		}
+		segments[i] = s
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	pairs := make([]string, 0, len(query))
+	for k, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, escape(k)+"="+escape(v))
+		}
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaders(req *http.Request) (string, string) {
+	values := map[string][]string{}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values["host"] = []string{host}
+
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if ignoredHeaders[k] {
+			continue
+		}
+		values[k] = append(values[k], v...)
+	}
+
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, k := range names {
+		trimmed := make([]string, len(values[k]))
+		for i, v := range values[k] {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+
+		b.WriteString(k)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(trimmed, ","))
+		b.WriteByte('\n')
+	}
+
+	return b.String(), strings.Join(names, ";")
+}
+
+// escape implements the uri encoding described by aws, only the unreserved characters are left as is.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,88 @@
+package sigv4
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+// credentials and expected signatures are taken from the aws sigv4 test suite.
+var testAuth = &domain.AWSSigV4Auth{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	Region:          "us-east-1",
+	Service:         "service",
+}
+
+var testTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		signature string
+	}{
+		{
+			name:      "get vanilla",
+			url:       "https://example.amazonaws.com/",
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:      "query order",
+			url:       "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			require.NoError(t, err)
+			require.NoError(t, Sign(req, testAuth, testTime))
+
+			assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+			assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature="+tt.signature, req.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestSignWithBodyAndSessionToken(t *testing.T) {
+	body := []byte(`{"hello":"world"}`)
+	req, err := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/my key", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	auth := testAuth.Clone()
+	auth.Service = "s3"
+	auth.SessionToken = "session"
+	require.NoError(t, Sign(req, auth, testTime))
+
+	assert.Equal(t, hashHex(body), req.Header.Get("X-Amz-Content-Sha256"))
+	assert.Equal(t, "session", req.Header.Get("X-Amz-Security-Token"))
+	assert.Contains(t, req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,")
+
+	// the body must still be readable after signing
+	sent, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, sent)
+}
+
+func TestCanonicalURI(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/a b/c", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/a%2520b/c", canonicalURI(req.URL, true))
+	assert.Equal(t, "/a%20b/c", canonicalURI(req.URL, false))
+}
+
+func TestSignRequiresCredentials(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, Sign(req, &domain.AWSSigV4Auth{}, testTime), ErrMissingCredentials)
+}
@@ -0,0 +1,209 @@
+// Package sse reads server-sent events from a text/event-stream body as described in
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+const ContentType = "text/event-stream"
+
+// IsEventStream reports whether the given content type header is a text/event-stream.
+func IsEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == ContentType
+}
+
+// Reader parses events from a text/event-stream.
+type Reader struct {
+	r *bufio.Reader
+
+	lastEventID string
+	started     bool
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next blocks until the next event is dispatched, it returns io.EOF when the stream ends.
+// an event which is not terminated by a blank line before the end of the stream is discarded.
+func (r *Reader) Next() (domain.SSEEvent, error) {
+	var (
+		event   domain.SSEEvent
+		data    strings.Builder
+		hasData bool
+	)
+
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return domain.SSEEvent{}, err
+		}
+
+		if line == "" {
+			if !hasData {
+				// nothing to dispatch, the event type is reset as well
+				event = domain.SSEEvent{}
+				continue
+			}
+
+			event.ID = r.lastEventID
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			if event.Event == "" {
+				event.Event = "message"
+			}
+			event.ReceivedAt = time.Now()
+			return event, nil
+		}
+
+		// comment
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, found := strings.Cut(line, ":")
+		if found {
+			value = strings.TrimPrefix(value, " ")
+		}
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				r.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				event.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// LastEventID returns the last event id seen on the stream, to be sent as Last-Event-ID on reconnect.
+func (r *Reader) LastEventID() string {
+	return r.lastEventID
+}
+
+// readLine reads a line terminated by CRLF, LF or CR.
+func (r *Reader) readLine() (string, error) {
+	var buf bytes.Buffer
+	for {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch b {
+		case '\n':
+			return r.line(buf.Bytes()), nil
+		case '\r':
+			if next, err := r.r.Peek(1); err == nil && next[0] == '\n' {
+				_, _ = r.r.ReadByte()
+			}
+			return r.line(buf.Bytes()), nil
+		default:
+			buf.WriteByte(b)
+		}
+	}
+}
+
+func (r *Reader) line(b []byte) string {
+	// a byte order mark is allowed at the start of the stream
+	if !r.started {
+		r.started = true
+		b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	}
+	return string(b)
+}
+
+// Stream reads the events of a response body in the background.
+type Stream struct {
+	events chan domain.SSEEvent
+	done   chan struct{}
+	body   io.ReadCloser
+	reader *Reader
+
+	mu        sync.Mutex
+	err       error
+	closeOnce sync.Once
+}
+
+func NewStream(body io.ReadCloser) *Stream {
+	s := &Stream{
+		events: make(chan domain.SSEEvent),
+		done:   make(chan struct{}),
+		body:   body,
+		reader: NewReader(body),
+	}
+
+	go s.read()
+	return s
+}
+
+func (s *Stream) read() {
+	defer close(s.events)
+	defer func() { _ = s.body.Close() }()
+
+	for {
+		event, err := s.reader.Next()
+		if err != nil {
+			select {
+			case <-s.done:
+				// closed by the user, the read error is expected
+			default:
+				if !errors.Is(err, io.EOF) {
+					s.mu.Lock()
+					s.err = err
+					s.mu.Unlock()
+				}
+			}
+			return
+		}
+
+		select {
+		case s.events <- event:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Events returns the channel of events, it's closed when the stream ends or is closed.
+func (s *Stream) Events() <-chan domain.SSEEvent {
+	return s.events
+}
+
+// Err returns the error which ended the stream, it's nil if the server closed the stream or it was closed by Close.
+// it should be called after the events channel is closed.
+func (s *Stream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close stops reading the stream and closes the body.
+func (s *Stream) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.body.Close()
+	})
+	return err
+}
@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+)
+
+func readAll(t *testing.T, input string) ([]domain.SSEEvent, *Reader) {
+	t.Helper()
+
+	r := NewReader(strings.NewReader(input))
+	var out []domain.SSEEvent
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			return out, r
+		}
+		require.NoError(t, err)
+
+		ev.ReceivedAt = time.Time{}
+		out = append(out, ev)
+	}
+}
+
+func TestReader(t *testing.T) {
+	input := "\xef\xbb\xbf: this is a comment\n" +
+		"data: first\n" +
+		"data:second line\n" +
+		"\n" +
+		"id: 42\r\n" +
+		"event: update\r\n" +
+		"retry: 3000\r\n" +
+		"data: {\"a\":1}\r\n" +
+		"\r\n" +
+		"event: ignored\rretry: abc\r\r" +
+		"data\n" +
+		"\n" +
+		"data: not dispatched"
+
+	events, r := readAll(t, input)
+	assert.Equal(t, []domain.SSEEvent{
+		{Event: "message", Data: "first\nsecond line"},
+		{ID: "42", Event: "update", Data: `{"a":1}`, Retry: 3 * time.Second},
+		{ID: "42", Event: "message", Data: ""},
+	}, events)
+	assert.Equal(t, "42", r.LastEventID())
+}
+
+func TestIsEventStream(t *testing.T) {
+	assert.True(t, IsEventStream("text/event-stream"))
+	assert.True(t, IsEventStream("text/event-stream; charset=utf-8"))
+	assert.False(t, IsEventStream("application/json"))
+	assert.False(t, IsEventStream(""))
+}
+
+func TestStreamClose(t *testing.T) {
+	pr, pw := io.Pipe()
+	s := NewStream(pr)
+
+	go func() { _, _ = pw.Write([]byte("data: hello\n\n")) }()
+
+	ev := <-s.Events()
+	assert.Equal(t, "hello", ev.Data)
+
+	require.NoError(t, s.Close())
+	_, ok := <-s.Events()
+	assert.False(t, ok)
+	assert.NoError(t, s.Err())
+}
@@ -101,6 +101,12 @@ func (m *Workspaces) UpdateWorkspace(workspace *domain.Workspace, source Source,
 	}
 
 	m.workspaces.Set(workspace.MetaData.ID, workspace)
+
+	// keep the active workspace in sync, services read their workspace settings from it
+	if m.activeWorkspace != nil && m.activeWorkspace.ID() == workspace.ID() {
+		m.activeWorkspace = workspace
+	}
+
 	m.notifyWorkspaceChange(workspace, source, ActionUpdate)
 
 	return nil
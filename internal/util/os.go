@@ -3,7 +3,9 @@ package util
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 )
 
 func MakeDir(dir string) error {
@@ -23,3 +25,18 @@ func MakeDir(dir string) error {
 		return err
 	}
 }
+
+// OpenURL opens the given url in the user's default browser.
+func OpenURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	return cmd.Start()
+}
@@ -162,6 +162,10 @@ func ApplyToHTTPRequest(variables map[string]string, req *domain.HTTPRequestSpec
 				req.Request.Auth.APIKeyAuth.Value = strings.ReplaceAll(req.Request.Auth.APIKeyAuth.Value, "{{"+k+"}}", v)
 			}
 		}
+
+		req.Request.Auth.OAuth2Auth.ApplyVariable(k, v)
+		req.Request.Auth.AWSSigV4Auth.ApplyVariable(k, v)
+		req.Request.Auth.DigestAuth.ApplyVariable(k, v)
 	}
 }
 
@@ -202,6 +206,40 @@ func ApplyToGraphQLRequest(variables map[string]string, req *domain.GraphQLReque
 	}
 }
 
+// ApplyToWebSocketRequest apply variables to the request and its message templates
+func ApplyToWebSocketRequest(variables map[string]string, req *domain.WebSocketRequestSpec) {
+	if variables == nil {
+		variables = GetVariables()
+	}
+
+	if req == nil {
+		return
+	}
+
+	// to through all the variables and replace them in the request
+	for k, v := range variables {
+		if strings.Contains(req.URL, "{{"+k+"}}") {
+			req.URL = strings.ReplaceAll(req.URL, "{{"+k+"}}", v)
+		}
+
+		for i, kv := range req.Headers {
+			if strings.Contains(kv.Value, "{{"+k+"}}") {
+				req.Headers[i].Value = strings.ReplaceAll(kv.Value, "{{"+k+"}}", v)
+			}
+		}
+
+		for i, m := range req.Messages {
+			if m.Type == domain.WebSocketMessageTypeText && strings.Contains(m.Data, "{{"+k+"}}") {
+				req.Messages[i].Data = strings.ReplaceAll(m.Data, "{{"+k+"}}", v)
+			}
+		}
+	}
+
+	if req.Auth != (domain.Auth{}) {
+		ApplyToAuth(variables, &req.Auth)
+	}
+}
+
 func ApplyToAuth(variables map[string]string, auth *domain.Auth) {
 	if variables == nil {
 		variables = GetVariables()
@@ -237,5 +275,9 @@ func ApplyToAuth(variables map[string]string, auth *domain.Auth) {
 				auth.APIKeyAuth.Value = strings.ReplaceAll(auth.APIKeyAuth.Value, "{{"+k+"}}", v)
 			}
 		}
+
+		auth.OAuth2Auth.ApplyVariable(k, v)
+		auth.AWSSigV4Auth.ApplyVariable(k, v)
+		auth.DigestAuth.ApplyVariable(k, v)
 	}
 }
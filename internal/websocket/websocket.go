@@ -0,0 +1,431 @@
+// Package websocket is a small RFC 6455 client, it keeps control frames visible to the caller
+// so ping and pong frames can be shown along with the data frames.
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type Opcode byte
+
+const (
+	OpContinuation Opcode = 0x0
+	OpText         Opcode = 0x1
+	OpBinary       Opcode = 0x2
+	OpClose        Opcode = 0x8
+	OpPing         Opcode = 0x9
+	OpPong         Opcode = 0xA
+)
+
+func (o Opcode) String() string {
+	switch o {
+	case OpContinuation:
+		return "continuation"
+	case OpText:
+		return "text"
+	case OpBinary:
+		return "binary"
+	case OpClose:
+		return "close"
+	case OpPing:
+		return "ping"
+	case OpPong:
+		return "pong"
+	default:
+		return fmt.Sprintf("opcode(%d)", o)
+	}
+}
+
+func (o Opcode) isControl() bool {
+	return o >= OpClose
+}
+
+// close codes from RFC 6455 section 7.4.1
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseNoStatus        = 1005
+	CloseAbnormal        = 1006
+	CloseInvalidPayload  = 1007
+	CloseMessageTooLarge = 1009
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	ErrBadHandshake    = errors.New("websocket: bad handshake")
+	ErrClosed          = errors.New("websocket: connection closed")
+	ErrMessageTooLarge = errors.New("websocket: message too large")
+)
+
+// CloseError is returned by ReadMessage when the peer closed the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: closed with code %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: closed with code %d: %s", e.Code, e.Reason)
+}
+
+// Message is a complete data message or a control frame.
+type Message struct {
+	Opcode Opcode
+	Data   []byte
+}
+
+// Dialer opens websocket connections, the handshake is sent with the given http transport so
+// its tls and proxy settings apply to the connection.
+type Dialer struct {
+	Transport *http.Transport
+
+	// MaxMessageSize limits the size of received messages, zero means unlimited.
+	MaxMessageSize int64
+}
+
+// Dial connects to the ws or wss url, it returns the handshake response along with the connection,
+// the response is returned on a bad handshake as well when the server replied.
+func (d *Dialer) Dial(ctx context.Context, rawURL string, header http.Header, subprotocols []string) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(subprotocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(subprotocols, ", "))
+	}
+
+	transport := d.Transport
+	if transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if res.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.EqualFold(res.Header.Get("Upgrade"), "websocket") ||
+		res.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		_ = res.Body.Close()
+		return nil, res, fmt.Errorf("%w: %s", ErrBadHandshake, res.Status)
+	}
+
+	rwc, ok := res.Body.(io.ReadWriteCloser)
+	if !ok {
+		_ = res.Body.Close()
+		return nil, res, fmt.Errorf("%w: connection is not writable", ErrBadHandshake)
+	}
+
+	conn := newConn(rwc, false)
+	conn.maxMessageSize = d.MaxMessageSize
+	conn.subprotocol = res.Header.Get("Sec-WebSocket-Protocol")
+	return conn, res, nil
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Conn is a websocket connection, ReadMessage must be called from a single goroutine while
+// the write methods can be called concurrently.
+type Conn struct {
+	rwc      io.ReadWriteCloser
+	r        *bufio.Reader
+	isServer bool
+
+	maxMessageSize int64
+	subprotocol    string
+
+	// partial is the data message being read while fragmented is set, it's kept on the connection
+	// as control frames between its fragments are returned on their own
+	partial    Message
+	fragmented bool
+
+	writeMu sync.Mutex
+	// closeSent is set once a close frame is written, nothing can be written after it
+	closeSent bool
+}
+
+func newConn(rwc io.ReadWriteCloser, isServer bool) *Conn {
+	return &Conn{
+		rwc:      rwc,
+		r:        bufio.NewReader(rwc),
+		isServer: isServer,
+	}
+}
+
+// Subprotocol returns the subprotocol selected by the server, if any.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// ReadMessage returns the next data message or control frame, fragmented messages are joined.
+// control frames sent between the fragments of a message are returned before the message.
+// pings are answered automatically and a close frame from the peer is echoed, in which case
+// the close frame is returned first and a *CloseError on the next call.
+func (c *Conn) ReadMessage() (Message, error) {
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return Message{}, err
+		}
+
+		switch {
+		case opcode == OpPing:
+			if err := c.WriteMessage(OpPong, payload); err != nil && !errors.Is(err, ErrClosed) {
+				return Message{}, err
+			}
+			return Message{Opcode: opcode, Data: payload}, nil
+		case opcode == OpPong:
+			return Message{Opcode: opcode, Data: payload}, nil
+		case opcode == OpClose:
+			closeErr := parseClose(payload)
+			// echo the close frame, it's ignored if we started the closing handshake
+			_ = c.writeClose(closeErr.Code, "")
+			_ = c.rwc.Close()
+			return Message{Opcode: opcode, Data: payload}, closeErr
+		case opcode == OpContinuation:
+			if !c.fragmented {
+				return Message{}, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		case opcode == OpText || opcode == OpBinary:
+			if c.fragmented {
+				return Message{}, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			c.partial = Message{Opcode: opcode}
+			c.fragmented = true
+		default:
+			return Message{}, c.fail(CloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode))
+		}
+
+		c.partial.Data = append(c.partial.Data, payload...)
+		if c.maxMessageSize > 0 && int64(len(c.partial.Data)) > c.maxMessageSize {
+			_ = c.fail(CloseMessageTooLarge, "")
+			return Message{}, ErrMessageTooLarge
+		}
+
+		if fin {
+			message := c.partial
+			c.partial = Message{}
+			c.fragmented = false
+			if message.Opcode == OpText && !utf8.Valid(message.Data) {
+				return Message{}, c.fail(CloseInvalidPayload, "invalid utf-8 in text message")
+			}
+			return message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, opcode Opcode, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(c.r, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0]&0x80 != 0
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits are set")
+	}
+	opcode = Opcode(header[0] & 0x0f)
+
+	masked := header[1]&0x80 != 0
+	if masked != c.isServer {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid frame masking")
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if opcode.isControl() && (length > 125 || !fin) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+
+	if c.maxMessageSize > 0 && length > uint64(c.maxMessageSize) {
+		_ = c.fail(CloseMessageTooLarge, "")
+		return false, 0, nil, ErrMessageTooLarge
+	}
+
+	var maskKey [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.r, maskKey[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		maskBytes(maskKey, payload)
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage writes a single frame with the given opcode.
+func (c *Conn) WriteMessage(opcode Opcode, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return ErrClosed
+	}
+
+	return c.writeFrame(opcode, data)
+}
+
+func (c *Conn) writeFrame(opcode Opcode, data []byte) error {
+	frame := make([]byte, 0, len(data)+14)
+	frame = append(frame, 0x80|byte(opcode))
+
+	maskBit := byte(0)
+	if !c.isServer {
+		maskBit = 0x80
+	}
+
+	switch length := len(data); {
+	case length <= 125:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+
+	payloadStart := len(frame)
+	if !c.isServer {
+		var maskKey [4]byte
+		if _, err := rand.Read(maskKey[:]); err != nil {
+			return err
+		}
+		frame = append(frame, maskKey[:]...)
+		payloadStart += 4
+		frame = append(frame, data...)
+		maskBytes(maskKey, frame[payloadStart:])
+	} else {
+		frame = append(frame, data...)
+	}
+
+	_, err := c.rwc.Write(frame)
+	return err
+}
+
+// Close starts the closing handshake, the connection is closed once the peer echoes the close
+// frame which is seen by ReadMessage.
+func (c *Conn) Close(code int, reason string) error {
+	return c.writeClose(code, reason)
+}
+
+func (c *Conn) writeClose(code int, reason string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return nil
+	}
+	c.closeSent = true
+
+	var payload []byte
+	if code != CloseNoStatus {
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+	}
+
+	return c.writeFrame(OpClose, payload)
+}
+
+// CloseNow closes the underlying connection without the closing handshake.
+func (c *Conn) CloseNow() error {
+	return c.rwc.Close()
+}
+
+// fail closes the connection because of a protocol violation by the peer.
+func (c *Conn) fail(code int, reason string) error {
+	_ = c.writeClose(code, reason)
+	_ = c.rwc.Close()
+	return &CloseError{Code: code, Reason: reason}
+}
+
+func parseClose(payload []byte) *CloseError {
+	if len(payload) < 2 {
+		return &CloseError{Code: CloseNoStatus}
+	}
+
+	return &CloseError{
+		Code:   int(binary.BigEndian.Uint16(payload)),
+		Reason: string(payload[2:]),
+	}
+}
+
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i%4]
+	}
+}
@@ -0,0 +1,152 @@
+package websocket
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoServer upgrades the connection and echoes every data message back, it sends a ping first
+// and a fragmented text message when it receives "fragment".
+func echoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "chat, superchat", r.Header.Get("Sec-WebSocket-Protocol"))
+
+		hj, ok := w.(http.Hijacker)
+		require.True(t, ok)
+
+		netConn, brw, err := hj.Hijack()
+		require.NoError(t, err)
+
+		_, _ = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\n" +
+			"Connection: Upgrade\r\n" +
+			"Sec-WebSocket-Protocol: chat\r\n" +
+			"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = brw.Flush()
+
+		conn := newConn(netConn, true)
+		_ = conn.WriteMessage(OpPing, []byte("hi"))
+
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			switch msg.Opcode {
+			case OpText, OpBinary:
+				if string(msg.Data) == "fragment" {
+					conn.writeMu.Lock()
+					_, _ = netConn.Write([]byte{0x01, 3, 'a', 'b', 'c'})
+					_, _ = netConn.Write([]byte{0x80, 3, 'd', 'e', 'f'})
+					conn.writeMu.Unlock()
+					continue
+				}
+				_ = conn.WriteMessage(msg.Opcode, msg.Data)
+			}
+		}
+	}))
+}
+
+func TestDial(t *testing.T) {
+	srv := echoServer(t)
+	defer srv.Close()
+
+	d := &Dialer{}
+	header := http.Header{"Authorization": []string{"Bearer token"}}
+	conn, res, err := d.Dial(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http"), header, []string{"chat", "superchat"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	assert.Equal(t, "chat", conn.Subprotocol())
+
+	msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, Message{Opcode: OpPing, Data: []byte("hi")}, msg)
+
+	// the ping was answered with a pong while reading it, the echo server ignores it
+	require.NoError(t, conn.WriteMessage(OpText, []byte("hello")))
+	msg, err = conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, Message{Opcode: OpText, Data: []byte("hello")}, msg)
+
+	large := strings.Repeat("x", 70000)
+	require.NoError(t, conn.WriteMessage(OpBinary, []byte(large)))
+	msg, err = conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, OpBinary, msg.Opcode)
+	assert.Equal(t, large, string(msg.Data))
+
+	require.NoError(t, conn.WriteMessage(OpText, []byte("fragment")))
+	msg, err = conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, Message{Opcode: OpText, Data: []byte("abcdef")}, msg)
+
+	require.NoError(t, conn.Close(CloseNormal, "bye"))
+	msg, err = conn.ReadMessage()
+	assert.Equal(t, OpClose, msg.Opcode)
+
+	var closeErr *CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, CloseNormal, closeErr.Code)
+
+	assert.ErrorIs(t, conn.WriteMessage(OpText, []byte("late")), ErrClosed)
+}
+
+func TestReadMessageControlBetweenFragments(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() { _ = client.Close() }()
+
+	// drain the pong sent for the ping
+	go func() { _, _ = io.Copy(io.Discard, server) }()
+	go func() {
+		// a fragmented text message with a ping and a pong between its fragments
+		_, _ = server.Write([]byte{0x01, 3, 'a', 'b', 'c'})
+		_, _ = server.Write([]byte{0x89, 2, 'h', 'i'})
+		_, _ = server.Write([]byte{0x8A, 0})
+		_, _ = server.Write([]byte{0x00, 3, 'd', 'e', 'f'})
+		_, _ = server.Write([]byte{0x80, 3, 'g', 'h', 'i'})
+	}()
+
+	conn := newConn(client, false)
+
+	msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, Message{Opcode: OpPing, Data: []byte("hi")}, msg)
+
+	msg, err = conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, OpPong, msg.Opcode)
+
+	msg, err = conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, Message{Opcode: OpText, Data: []byte("abcdefghi")}, msg)
+}
+
+func TestDialBadHandshake(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	d := &Dialer{}
+	_, res, err := d.Dial(context.Background(), srv.URL, nil, nil)
+	assert.ErrorIs(t, err, ErrBadHandshake)
+	require.NotNil(t, res)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestAcceptKey(t *testing.T) {
+	// example from RFC 6455 section 1.3
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
@@ -67,7 +67,15 @@ func NewApp(w *app.Window) (*App, error) {
 	prefs.AddGlobalConfigChangeListener(func(old, updated domain.GlobalConfig) {
 		if old.Spec.Scripting.Changed(updated.Spec.Scripting) {
 			if updated.Spec.Scripting.Enabled {
-				go initExecutor()
+				// the executor of the previous language is not used anymore
+				previous := base.Executor
+				languageChanged := old.Spec.Scripting.Enabled && old.Spec.Scripting.Language != updated.Spec.Scripting.Language
+				go func() {
+					if languageChanged {
+						stopScripting(previous)
+					}
+					initExecutor()
+				}()
 			} else if old.Spec.Scripting.Enabled && !updated.Spec.Scripting.Enabled {
 				go stopScripting(base.Executor)
 			}
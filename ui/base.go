@@ -14,6 +14,8 @@ import (
 	"github.com/chapar-rest/chapar/internal/egress/graphql"
 	"github.com/chapar-rest/chapar/internal/egress/grpc"
 	"github.com/chapar-rest/chapar/internal/egress/rest"
+	"github.com/chapar-rest/chapar/internal/egress/websocket"
+	"github.com/chapar-rest/chapar/internal/oauth2"
 	"github.com/chapar-rest/chapar/internal/prefs"
 	"github.com/chapar-rest/chapar/internal/repository"
 	"github.com/chapar-rest/chapar/internal/scripting"
@@ -50,8 +52,17 @@ type Base struct {
 	// keeping it for backward compatibility.
 	GrpcDiscorvery *grpc.Service
 
+	// GraphQLSchemas loads and caches the schemas of the graphql requests.
+	GraphQLSchemas *graphql.Service
+
 	EgressService *egress.Service
 
+	// WebSocketService keeps the websocket connections open, they don't go through the egress service.
+	WebSocketService *websocket.Service
+
+	// OAuth2Service caches the oauth2 tokens of all the services.
+	OAuth2Service *oauth2.Service
+
 	// scripting executor
 	Executor scripting.Executor
 }
@@ -84,10 +95,12 @@ func NewBase(w *app.Window, navi *navigator.Navigator) (*Base, error) {
 	}
 
 	// init services
-	grpcService := grpc.NewService(requestsState, environmentsState, protoFilesState)
-	restService := rest.New(requestsState, environmentsState)
-	graphqlService := graphql.New(requestsState, environmentsState)
+	oauth2Service := oauth2.NewService(workspacesState)
+	grpcService := grpc.NewService(requestsState, environmentsState, protoFilesState, oauth2Service)
+	restService := rest.New(requestsState, environmentsState, workspacesState, oauth2Service)
+	graphqlService := graphql.New(requestsState, environmentsState, workspacesState, oauth2Service)
 	egressService := egress.New(requestsState, environmentsState, restService, grpcService, graphqlService, nil)
+	websocketService := websocket.New(requestsState, environmentsState, workspacesState, oauth2Service)
 
 	modal := modallayer.NewModal()
 
@@ -106,7 +119,10 @@ func NewBase(w *app.Window, navi *navigator.Navigator) (*Base, error) {
 		GrpcDiscorvery:    grpcService,
 		RestService:       restService,
 		GraphQLService:    graphqlService,
+		GraphQLSchemas:    graphqlService,
 		EgressService:     egressService,
+		WebSocketService:  websocketService,
+		OAuth2Service:     oauth2Service,
 		Executor:          nil, // scripting executor will be set later,
 	}, nil
 }
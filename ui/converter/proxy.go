@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"gioui.org/text"
+	"gioui.org/unit"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// ProxyModeInherit is the mode shown for environments and requests which don't override the proxy.
+const ProxyModeInherit = "inherit"
+
+// ProxySettingItems returns the setting items to edit the given proxy config, when inherit is true
+// an extra option is added to fall back to the parent config and a nil cfg selects it.
+func ProxySettingItems(cfg *domain.ProxyConfig, inherit bool) []*widgets.SettingItem {
+	mode := ProxyModeInherit
+	values := domain.ProxyConfig{}
+	if cfg != nil {
+		values = *cfg
+		mode = cfg.Mode
+		if mode == "" {
+			mode = domain.ProxyModeSystem
+		}
+	}
+
+	var options []*widgets.DropDownOption
+	if inherit {
+		options = append(options, widgets.NewDropDownOption("Inherit").WithIdentifier(ProxyModeInherit).WithValue(ProxyModeInherit))
+	}
+	options = append(options,
+		widgets.NewDropDownOption("System").WithIdentifier(domain.ProxyModeSystem).WithValue(domain.ProxyModeSystem),
+		widgets.NewDropDownOption("No proxy").WithIdentifier(domain.ProxyModeNone).WithValue(domain.ProxyModeNone),
+		widgets.NewDropDownOption("Manual").WithIdentifier(domain.ProxyModeManual).WithValue(domain.ProxyModeManual),
+	)
+
+	manual := func(values map[string]any) bool {
+		return values["proxyMode"] == domain.ProxyModeManual
+	}
+
+	return []*widgets.SettingItem{
+		widgets.NewHeaderItem("Proxy"),
+		widgets.NewDropDownItem("Proxy", "proxyMode", "System uses the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.", mode, options...),
+		widgets.NewTextItem("Proxy URL", "proxyURL", "http, https or socks5 proxy e.g. socks5://127.0.0.1:1080", values.URL).MinWidth(unit.Dp(400)).TextAlignment(text.Start).SetVisibleWhen(manual),
+		widgets.NewTextItem("Username", "proxyUsername", "Username to authenticate to the proxy", values.Username).MinWidth(unit.Dp(400)).TextAlignment(text.Start).SetVisibleWhen(manual),
+		widgets.NewTextItem("Password", "proxyPassword", "Password to authenticate to the proxy", values.Password).MinWidth(unit.Dp(400)).TextAlignment(text.Start).SetVisibleWhen(manual),
+		widgets.NewTextItem("Bypass", "proxyBypass", "Comma separated hosts to reach directly e.g. localhost, *.internal.com, 10.0.0.0/8", values.Bypass).MinWidth(unit.Dp(400)).TextAlignment(text.Start).SetVisibleWhen(manual),
+	}
+}
+
+// ProxyFromSettingValues reads the proxy config back from the values of the items returned by ProxySettingItems.
+// it returns nil when the inherit option is selected.
+func ProxyFromSettingValues(values map[string]any) *domain.ProxyConfig {
+	mode, _ := values["proxyMode"].(string)
+	if mode == ProxyModeInherit || mode == "" {
+		return nil
+	}
+
+	cfg := &domain.ProxyConfig{Mode: mode}
+	cfg.URL, _ = values["proxyURL"].(string)
+	cfg.Username, _ = values["proxyUsername"].(string)
+	cfg.Password, _ = values["proxyPassword"].(string)
+	cfg.Bypass, _ = values["proxyBypass"].(string)
+	return cfg
+}
@@ -75,3 +75,45 @@ func (e *Explorer) ChoseFile(onResult func(r Result), extensions ...string) {
 		onResult(Result{Data: data, FilePath: filePath, Error: nil})
 	}(onResult)
 }
+
+// SaveFile asks the user where to save a file with the given name and writes data to it.
+func (e *Explorer) SaveFile(name string, data []byte, onResult func(r Result)) {
+	go func(onResult func(r Result)) {
+		defer func(e *Explorer) {
+			e.w.Invalidate()
+		}(e)
+
+		file, err := e.expl.CreateFile(name)
+		if err != nil {
+			if errors.Is(err, explorer.ErrUserDecline) {
+				onResult(Result{Error: err, Declined: true})
+				return
+			}
+
+			err = fmt.Errorf("failed creating file: %w", err)
+			onResult(Result{Error: err})
+			return
+		}
+
+		filePath := ""
+		// get file path if possible
+		if f, ok := file.(*os.File); ok {
+			filePath = f.Name()
+		}
+
+		if _, err := file.Write(data); err != nil {
+			_ = file.Close()
+			err = fmt.Errorf("failed writing file: %w", err)
+			onResult(Result{Error: err, FilePath: filePath})
+			return
+		}
+
+		if err := file.Close(); err != nil {
+			err = fmt.Errorf("failed closing file: %w", err)
+			onResult(Result{Error: err, FilePath: filePath})
+			return
+		}
+
+		onResult(Result{Data: data, FilePath: filePath})
+	}(onResult)
+}
@@ -20,9 +20,14 @@ type container struct {
 	SaveButton  widget.Clickable
 	Prompt      *widgets.Prompt
 	DataChanged bool
+
+	// proxy override of the environment, shown instead of the items when toggled
+	Proxy       *widgets.Settings
+	ProxyButton widget.Clickable
+	showProxy   bool
 }
 
-func newContainer(id, name string, items []domain.KeyValue) *container {
+func newContainer(id, name string, items []domain.KeyValue, proxy *domain.ProxyConfig) *container {
 	search := widgets.NewTextField("", "Search items")
 	search.SetIcon(widgets.SearchIcon, widgets.IconPositionEnd)
 
@@ -33,6 +38,7 @@ func newContainer(id, name string, items []domain.KeyValue) *container {
 		SearchBox:  search,
 		SaveButton: widget.Clickable{},
 		Prompt:     widgets.NewPrompt("Save", "", widgets.ModalTypeWarn),
+		Proxy:      widgets.NewSettings(converter.ProxySettingItems(proxy, true)),
 	}
 	c.Prompt.WithoutRememberBool()
 	return c
@@ -42,11 +48,19 @@ func (c *container) SetItems(items []domain.KeyValue) {
 	c.Items.SetItems(converter.WidgetItemsFromKeyValue(items))
 }
 
+func (c *container) SetProxy(proxy *domain.ProxyConfig) {
+	c.Proxy = widgets.NewSettings(converter.ProxySettingItems(proxy, true))
+}
+
 func (c *container) SetTitle(title string) {
 	c.Title.SetText(title)
 }
 
 func (c *container) Layout(gtx layout.Context, theme *chapartheme.Theme, selectedID string) layout.Dimensions {
+	if c.ProxyButton.Clicked(gtx) {
+		c.showProxy = !c.showProxy
+	}
+
 	return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -73,13 +87,32 @@ func (c *container) Layout(gtx layout.Context, theme *chapartheme.Theme, selecte
 							)
 						}),
 						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-							gtx.Constraints.Max.X = gtx.Dp(200)
-							return c.SearchBox.Layout(gtx, theme)
+							return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									text := "Proxy"
+									if c.showProxy {
+										text = "Values"
+									}
+									btn := widgets.Button(theme, &c.ProxyButton, widgets.SettingsIcon, widgets.IconPositionStart, text)
+									return btn.Layout(gtx, theme)
+								}),
+								layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									if c.showProxy {
+										return layout.Dimensions{}
+									}
+									gtx.Constraints.Max.X = gtx.Dp(200)
+									return c.SearchBox.Layout(gtx, theme)
+								}),
+							)
 						}),
 					)
 				})
 			}),
 			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				if c.showProxy {
+					return c.Proxy.Layout(gtx, theme)
+				}
 				return c.Items.WithAddLayout(gtx, "", "Disabled items have no effect on your requests", theme)
 			}),
 		)
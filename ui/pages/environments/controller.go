@@ -185,7 +185,37 @@ func (c *Controller) OnItemsChanged(id string, items []domain.KeyValue) {
 		return
 	}
 
-	c.view.SetTabDirty(id, !domain.CompareKeyValues(env.Spec.Values, envFromFile.Spec.Values))
+	c.view.SetTabDirty(id, isDirty(env, envFromFile))
+}
+
+func (c *Controller) OnProxyChanged(id string, proxy *domain.ProxyConfig) {
+	env := c.state.GetEnvironment(id)
+	if env == nil {
+		return
+	}
+
+	if domain.CompareProxyConfig(env.Spec.Proxy, proxy) {
+		return
+	}
+
+	env.Spec.Proxy = proxy
+	if err := c.state.UpdateEnvironment(env, state.SourceController, true); err != nil {
+		c.view.showError(fmt.Errorf("failed to update environment, %w", err))
+		return
+	}
+
+	envFromFile, err := c.state.GetPersistedEnvironment(id)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to get environment from file %w", err))
+		return
+	}
+
+	c.view.SetTabDirty(id, isDirty(env, envFromFile))
+}
+
+func isDirty(env, envFromFile *domain.Environment) bool {
+	return !domain.CompareKeyValues(env.Spec.Values, envFromFile.Spec.Values) ||
+		!domain.CompareProxyConfig(env.Spec.Proxy, envFromFile.Spec.Proxy)
 }
 
 func (c *Controller) OnSave(id string) {
@@ -209,7 +239,7 @@ func (c *Controller) OnTabClose(id string) {
 	}
 
 	// if data is not changed close the tab
-	if domain.CompareKeyValues(env.Spec.Values, envFromFile.Spec.Values) {
+	if !isDirty(env, envFromFile) {
 		c.view.CloseTab(id)
 		return
 	}
@@ -35,6 +35,7 @@ type EnvironmentController interface {
 	OnTreeViewMenuClicked(id, action string)
 	OnTabSelected(id string)
 	OnItemsChanged(id string, items []domain.KeyValue)
+	OnProxyChanged(id string, proxy *domain.ProxyConfig)
 	OnSave(id string)
 	OnTabClose(id string)
 }
@@ -245,7 +246,7 @@ func (v *View) OpenContainer(env *domain.Environment) {
 		return
 	}
 
-	ct := newContainer(env.MetaData.ID, env.MetaData.Name, env.Spec.Values)
+	ct := newContainer(env.MetaData.ID, env.MetaData.Name, env.Spec.Values, env.Spec.Proxy)
 
 	v.containers.Set(env.MetaData.ID, ct)
 
@@ -255,6 +256,7 @@ func (v *View) OpenContainer(env *domain.Environment) {
 func (v *View) ReloadContainerData(env *domain.Environment) {
 	if ct, ok := v.containers.Get(env.MetaData.ID); ok {
 		ct.SetItems(env.Spec.Values)
+		ct.SetProxy(env.Spec.Proxy)
 	}
 }
 
@@ -374,6 +376,9 @@ func (v *View) containerHolder(gtx layout.Context, theme *chapartheme.Theme) lay
 					if ct.Items.Changed() && v.controller != nil {
 						v.controller.OnItemsChanged(selectedTab.Identifier, converter.KeyValueFromWidgetItems(ct.Items.Items))
 					}
+					if ct.Proxy.Changed() && v.controller != nil {
+						v.controller.OnProxyChanged(selectedTab.Identifier, converter.ProxyFromSettingValues(ct.Proxy.GetValues()))
+					}
 					return dims
 				}
 			}
@@ -94,6 +94,7 @@ func (c *Controller) OnAdd() {
 		}
 
 		proto.Spec.Path = result.FilePath
+		proto.Spec.IsDescriptorSet = domain.IsDescriptorSetFile(result.FilePath)
 		proto.Spec.Package = pInfo.Package
 		proto.Spec.Services = pInfo.Services
 
@@ -105,7 +106,7 @@ func (c *Controller) OnAdd() {
 
 		c.state.AddProtoFile(proto)
 		c.view.AddItem(proto)
-	}, "proto")
+	}, append([]string{"proto"}, domain.DescriptorSetExtensions...)...)
 }
 
 type info struct {
@@ -119,7 +120,31 @@ func (c *Controller) getProtoInfo(path, filename string) (*info, error) {
 		return nil, err
 	}
 
-	pInfo, err := grpc.ProtoFilesFromDisk(grpc.GetImportPaths(protoFiles, []string{filepath.Join(path, filename)}))
+	file := filepath.Join(path, filename)
+	if domain.IsDescriptorSetFile(file) {
+		pInfo, err := grpc.ProtoFilesFromDescriptorSets(file)
+		if err != nil {
+			return nil, err
+		}
+
+		// a descriptor set holds its dependencies too, only the files declaring services are listed
+		out := &info{}
+		pInfo.RangeFiles(func(f protoreflect.FileDescriptor) bool {
+			if f.Services().Len() == 0 {
+				return true
+			}
+
+			out.Package = string(f.Package())
+			for i := 0; i < f.Services().Len(); i++ {
+				out.Services = append(out.Services, string(f.Services().Get(i).FullName()))
+			}
+			return true
+		})
+
+		return out, nil
+	}
+
+	pInfo, err := grpc.ProtoFilesFromDisk(grpc.GetImportPaths(protoFiles, []string{file}))
 	if err != nil {
 		return nil, err
 	}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/explorer"
 	"github.com/chapar-rest/chapar/ui/keys"
 	"github.com/chapar-rest/chapar/ui/pages/requests/component"
 	"github.com/chapar-rest/chapar/ui/widgets"
@@ -30,6 +31,9 @@ type Collection struct {
 	Headers *component.Headers
 	Auth    *component.Auth
 
+	// GraphQLSchemaFile is the schema of the graphql requests of the collection which have none
+	GraphQLSchemaFile *widgets.FileSelector
+
 	notesEditor widget.Editor
 
 	split widgets.SplitView
@@ -66,7 +70,7 @@ func (c *Collection) SetOnSave(f func(id string)) {
 	c.onSave = f
 }
 
-func New(collection *domain.Collection, theme *chapartheme.Theme) *Collection {
+func New(collection *domain.Collection, theme *chapartheme.Theme, explorer *explorer.Explorer) *Collection {
 	c := &Collection{
 		collection: collection,
 		Title:      widgets.NewEditableLabel(collection.MetaData.Name),
@@ -76,9 +80,11 @@ func New(collection *domain.Collection, theme *chapartheme.Theme) *Collection {
 			{Title: "Notes"},
 			{Title: "Auth"},
 			{Title: "Headers"},
+			{Title: "GraphQL"},
 		}, nil),
-		Headers: component.NewHeaders(collection.Spec.Headers),
-		Auth:    component.NewAuth(collection.Spec.Auth, theme),
+		Headers:           component.NewHeaders(collection.Spec.Headers),
+		Auth:              component.NewAuth(collection.Spec.Auth, theme),
+		GraphQLSchemaFile: widgets.NewFileSelector(collection.Spec.GraphQLSchemaFile, explorer, "graphql", "graphqls", "gql", "json"),
 		split: widgets.SplitView{
 			Resize: giox.Resize{
 				Ratio: 0.6, // 60% left, 40% right
@@ -93,6 +99,9 @@ func New(collection *domain.Collection, theme *chapartheme.Theme) *Collection {
 		widgets.NewDropDownOption("Basic").WithValue(domain.AuthTypeBasic),
 		widgets.NewDropDownOption("Token").WithValue(domain.AuthTypeToken),
 		widgets.NewDropDownOption("API Key").WithValue(domain.AuthTypeAPIKey),
+		widgets.NewDropDownOption("Digest").WithValue(domain.AuthTypeDigest),
+		widgets.NewDropDownOption("OAuth 2.0").WithValue(domain.AuthTypeOAuth2),
+		widgets.NewDropDownOption("AWS Signature").WithValue(domain.AuthTypeAWSSigV4),
 	)
 	c.Auth.SetAuth(collection.Spec.Auth)
 
@@ -111,6 +120,10 @@ func (c *Collection) SetTitle(title string) {
 	c.Title.SetText(title)
 }
 
+func (c *Collection) SetOnClearOAuth2Token(f func()) {
+	c.Auth.SetOnClearToken(f)
+}
+
 func (c *Collection) setupHooks() {
 	c.Headers.SetOnChange(func(headers []domain.KeyValue) {
 		c.collection.Spec.Headers = headers
@@ -125,6 +138,34 @@ func (c *Collection) setupHooks() {
 			c.onDataChanged(c.collection.MetaData.ID, c.collection)
 		}
 	})
+
+	c.GraphQLSchemaFile.SetOnChanged(func(filePath string) {
+		c.collection.Spec.GraphQLSchemaFile = filePath
+		if c.onDataChanged != nil {
+			c.onDataChanged(c.collection.MetaData.ID, c.collection)
+		}
+	})
+}
+
+func (c *Collection) graphQLLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Label(theme.Material(), unit.Sp(14), "Schema file")
+			label.Color = theme.TextColor
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return c.GraphQLSchemaFile.Layout(gtx, theme)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Label(theme.Material(), unit.Sp(12), "The SDL or introspection JSON schema of the GraphQL requests without a schema of their own.")
+			label.Color = theme.TextColor
+			label.Color.A = 0xa0
+			return label.Layout(gtx)
+		}),
+	)
 }
 
 // getAuthTypeDisplay returns a human-readable auth type string
@@ -139,6 +180,12 @@ func (c *Collection) getAuthTypeDisplay() string {
 		return "Token"
 	case domain.AuthTypeAPIKey:
 		return "API Key"
+	case domain.AuthTypeDigest:
+		return "Digest"
+	case domain.AuthTypeOAuth2:
+		return "OAuth 2.0"
+	case domain.AuthTypeAWSSigV4:
+		return "AWS Signature"
 	default:
 		if authType == "" {
 			return "None"
@@ -250,6 +297,8 @@ func (c *Collection) Layout(gtx layout.Context, theme *chapartheme.Theme) layout
 										return c.Headers.Layout(gtx, theme)
 									case "Auth":
 										return c.Auth.Layout(gtx, theme)
+									case "GraphQL":
+										return c.graphQLLayout(gtx, theme)
 									default:
 										return layout.Dimensions{}
 									}
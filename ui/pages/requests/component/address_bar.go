@@ -21,9 +21,13 @@ type AddressBar struct {
 
 	sendClickable widget.Clickable
 
+	// loading is true while a request is in flight, the send button turns into a cancel button.
+	loading bool
+
 	onURLChanged    func(url string)
 	onMethodChanged func(method string)
 	onSubmit        func()
+	onCancel        func()
 }
 
 func NewAddressBar(address, method string) *AddressBar {
@@ -66,6 +70,14 @@ func (a *AddressBar) SetOnSubmit(onSubmit func()) {
 	a.onSubmit = onSubmit
 }
 
+func (a *AddressBar) SetOnCancel(onCancel func()) {
+	a.onCancel = onCancel
+}
+
+func (a *AddressBar) SetLoading(loading bool) {
+	a.loading = loading
+}
+
 func (a *AddressBar) SetURL(url string) {
 	a.url.SetText(url)
 }
@@ -74,7 +86,7 @@ func (a *AddressBar) Layout(gtx layout.Context, theme *chapartheme.Theme) layout
 	if a.url.Changed() && a.onURLChanged != nil {
 		a.onURLChanged(a.url.Text())
 	}
-	if a.url.Submitted() && a.onSubmit != nil {
+	if a.url.Submitted() && !a.loading && a.onSubmit != nil {
 		a.onSubmit()
 	}
 	if a.methodDropDown.Changed() && a.onMethodChanged != nil {
@@ -120,12 +132,23 @@ func (a *AddressBar) Layout(gtx layout.Context, theme *chapartheme.Theme) layout
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			if a.sendClickable.Clicked(gtx) {
-				if a.onSubmit != nil {
+				if a.loading {
+					if a.onCancel != nil {
+						a.onCancel()
+					}
+				} else if a.onSubmit != nil {
 					a.onSubmit()
 				}
 			}
 
 			gtx.Constraints.Min.X = gtx.Dp(80)
+			if a.loading {
+				btn := material.Button(theme.Material(), &a.sendClickable, "Cancel")
+				btn.Background = theme.DeleteButtonBgColor
+				btn.Color = theme.ButtonTextColor
+				return btn.Layout(gtx)
+			}
+
 			btn := material.Button(theme.Material(), &a.sendClickable, "Send")
 			btn.Background = theme.ActionButtonBgColor
 			btn.Color = theme.ButtonTextColor
@@ -5,6 +5,7 @@ import (
 
 	"gioui.org/layout"
 	"gioui.org/unit"
+	"gioui.org/widget"
 	"gioui.org/widget/material"
 
 	"github.com/chapar-rest/chapar/internal/domain"
@@ -20,10 +21,18 @@ type Auth struct {
 	TokenForm  *Form
 	BasicForm  *Form
 	APIKeyForm *Form
+	DigestForm *Form
+
+	OAuth2GrantDropDown *widgets.DropDown
+	OAuth2Forms         map[string]*Form
+	clearTokenButton    widget.Clickable
+
+	AWSSigV4Form *Form
 
 	collectionAuth *domain.Auth // Auth from collection for inheritance
 
-	onChange func(auth domain.Auth)
+	onChange     func(auth domain.Auth)
+	onClearToken func()
 }
 
 func NewAuth(auth domain.Auth, theme *chapartheme.Theme) *Auth {
@@ -34,6 +43,9 @@ func NewAuth(auth domain.Auth, theme *chapartheme.Theme) *Auth {
 			widgets.NewDropDownOption("Basic").WithValue(domain.AuthTypeBasic),
 			widgets.NewDropDownOption("Token").WithValue(domain.AuthTypeToken),
 			widgets.NewDropDownOption("API Key").WithValue(domain.AuthTypeAPIKey),
+			widgets.NewDropDownOption("Digest").WithValue(domain.AuthTypeDigest),
+			widgets.NewDropDownOption("OAuth 2.0").WithValue(domain.AuthTypeOAuth2),
+			widgets.NewDropDownOption("AWS Signature").WithValue(domain.AuthTypeAWSSigV4),
 			widgets.NewDropDownOption("Inherit from Collection").WithValue(domain.AuthTypeInherit),
 		),
 
@@ -48,10 +60,58 @@ func NewAuth(auth domain.Auth, theme *chapartheme.Theme) *Auth {
 			{Label: "Key", Value: ""},
 			{Label: "Value", Value: ""},
 		}),
+		DigestForm: NewForm([]*Field{
+			{Label: "Username", Value: ""},
+			{Label: "Password", Value: ""},
+		}),
+
+		OAuth2GrantDropDown: widgets.NewDropDown(
+			widgets.NewDropDownOption("Client Credentials").WithValue(domain.OAuth2GrantTypeClientCredentials),
+			widgets.NewDropDownOption("Password").WithValue(domain.OAuth2GrantTypePassword),
+			widgets.NewDropDownOption("Authorization Code (PKCE)").WithValue(domain.OAuth2GrantTypeAuthorizationCode),
+		),
+		OAuth2Forms: map[string]*Form{
+			domain.OAuth2GrantTypeClientCredentials: NewForm([]*Field{
+				{Label: "Token URL"},
+				{Label: "Client ID"},
+				{Label: "Client Secret"},
+				{Label: "Scopes"},
+				{Label: "Audience"},
+			}),
+			domain.OAuth2GrantTypePassword: NewForm([]*Field{
+				{Label: "Token URL"},
+				{Label: "Client ID"},
+				{Label: "Client Secret"},
+				{Label: "Username"},
+				{Label: "Password"},
+				{Label: "Scopes"},
+				{Label: "Audience"},
+			}),
+			domain.OAuth2GrantTypeAuthorizationCode: NewForm([]*Field{
+				{Label: "Auth URL"},
+				{Label: "Token URL"},
+				{Label: "Client ID"},
+				{Label: "Client Secret"},
+				{Label: "Redirect URL"},
+				{Label: "Scopes"},
+				{Label: "Audience"},
+			}),
+		},
+
+		AWSSigV4Form: NewForm([]*Field{
+			{Label: "Access Key ID"},
+			{Label: "Secret Access Key"},
+			{Label: "Session Token"},
+			{Label: "Region"},
+			{Label: "Service"},
+		}),
 	}
 
 	a.DropDown.SetSelectedByValue(auth.Type)
 	a.DropDown.MaxWidth = unit.Dp(150)
+	a.OAuth2GrantDropDown.MaxWidth = unit.Dp(220)
+	a.setOAuth2Values(auth.OAuth2Auth)
+	a.setAWSSigV4Values(auth.AWSSigV4Auth)
 
 	if auth.BasicAuth != nil {
 		a.BasicForm.SetValues(map[string]string{
@@ -73,9 +133,21 @@ func NewAuth(auth domain.Auth, theme *chapartheme.Theme) *Auth {
 		})
 	}
 
+	if auth.DigestAuth != nil {
+		a.DigestForm.SetValues(map[string]string{
+			"Username": auth.DigestAuth.Username,
+			"Password": auth.DigestAuth.Password,
+		})
+	}
+
 	return a
 }
 
+// SetOnClearToken sets the function called to drop the cached oauth2 tokens, the button is hidden until it's set.
+func (a *Auth) SetOnClearToken(f func()) {
+	a.onClearToken = f
+}
+
 func (a *Auth) SetOnChange(f func(auth domain.Auth)) {
 	a.onChange = f
 
@@ -107,6 +179,102 @@ func (a *Auth) SetOnChange(f func(auth domain.Auth)) {
 		a.auth.APIKeyAuth.Value = values["Value"]
 		a.onChange(a.auth)
 	})
+
+	a.DigestForm.SetOnChange(func(values map[string]string) {
+		if a.auth.DigestAuth == nil {
+			a.auth.DigestAuth = &domain.DigestAuth{}
+		}
+
+		a.auth.DigestAuth.Username = values["Username"]
+		a.auth.DigestAuth.Password = values["Password"]
+		a.onChange(a.auth)
+	})
+
+	a.AWSSigV4Form.SetOnChange(func(values map[string]string) {
+		if a.auth.AWSSigV4Auth == nil {
+			a.auth.AWSSigV4Auth = &domain.AWSSigV4Auth{}
+		}
+
+		a.auth.AWSSigV4Auth.AccessKeyID = values["Access Key ID"]
+		a.auth.AWSSigV4Auth.SecretAccessKey = values["Secret Access Key"]
+		a.auth.AWSSigV4Auth.SessionToken = values["Session Token"]
+		a.auth.AWSSigV4Auth.Region = values["Region"]
+		a.auth.AWSSigV4Auth.Service = values["Service"]
+		a.onChange(a.auth)
+	})
+
+	for grantType, form := range a.OAuth2Forms {
+		grantType, form := grantType, form
+		form.SetOnChange(func(values map[string]string) {
+			if a.auth.OAuth2Auth == nil {
+				a.auth.OAuth2Auth = &domain.OAuth2Auth{}
+			}
+
+			a.auth.OAuth2Auth.GrantType = grantType
+			setOAuth2Field(a.auth.OAuth2Auth, values)
+
+			// keep the other grant forms in sync so switching the grant type keeps the shared values
+			for gt, f := range a.OAuth2Forms {
+				if gt != grantType {
+					f.SetValues(oauth2FieldValues(a.auth.OAuth2Auth))
+				}
+			}
+
+			a.onChange(a.auth)
+		})
+	}
+}
+
+func (a *Auth) setOAuth2Values(auth *domain.OAuth2Auth) {
+	if auth == nil {
+		a.OAuth2GrantDropDown.SetSelectedByValue(domain.OAuth2GrantTypeClientCredentials)
+		return
+	}
+
+	grantType := auth.GrantType
+	if grantType == "" {
+		grantType = domain.OAuth2GrantTypeClientCredentials
+	}
+
+	a.OAuth2GrantDropDown.SetSelectedByValue(grantType)
+	for _, f := range a.OAuth2Forms {
+		f.SetValues(oauth2FieldValues(auth))
+	}
+}
+
+func oauth2FieldValues(auth *domain.OAuth2Auth) map[string]string {
+	return map[string]string{
+		"Auth URL":      auth.AuthURL,
+		"Token URL":     auth.TokenURL,
+		"Client ID":     auth.ClientID,
+		"Client Secret": auth.ClientSecret,
+		"Username":      auth.Username,
+		"Password":      auth.Password,
+		"Redirect URL":  auth.RedirectURL,
+		"Scopes":        auth.Scopes,
+		"Audience":      auth.Audience,
+	}
+}
+
+// setOAuth2Field sets the values of the given form, values which are not part of the form are left untouched.
+func setOAuth2Field(auth *domain.OAuth2Auth, values map[string]string) {
+	fields := map[string]*string{
+		"Auth URL":      &auth.AuthURL,
+		"Token URL":     &auth.TokenURL,
+		"Client ID":     &auth.ClientID,
+		"Client Secret": &auth.ClientSecret,
+		"Username":      &auth.Username,
+		"Password":      &auth.Password,
+		"Redirect URL":  &auth.RedirectURL,
+		"Scopes":        &auth.Scopes,
+		"Audience":      &auth.Audience,
+	}
+
+	for label, v := range values {
+		if f, ok := fields[label]; ok {
+			*f = v
+		}
+	}
 }
 
 func (a *Auth) SetAuth(auth domain.Auth) {
@@ -132,6 +300,30 @@ func (a *Auth) SetAuth(auth domain.Auth) {
 			"Value": auth.APIKeyAuth.Value,
 		})
 	}
+
+	if auth.DigestAuth != nil {
+		a.DigestForm.SetValues(map[string]string{
+			"Username": auth.DigestAuth.Username,
+			"Password": auth.DigestAuth.Password,
+		})
+	}
+
+	a.setOAuth2Values(auth.OAuth2Auth)
+	a.setAWSSigV4Values(auth.AWSSigV4Auth)
+}
+
+func (a *Auth) setAWSSigV4Values(auth *domain.AWSSigV4Auth) {
+	if auth == nil {
+		return
+	}
+
+	a.AWSSigV4Form.SetValues(map[string]string{
+		"Access Key ID":     auth.AccessKeyID,
+		"Secret Access Key": auth.SecretAccessKey,
+		"Session Token":     auth.SessionToken,
+		"Region":            auth.Region,
+		"Service":           auth.Service,
+	})
 }
 
 // SetCollectionAuth sets the auth configuration from the collection for inheritance
@@ -147,6 +339,17 @@ func (a *Auth) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimen
 		}
 	}
 
+	if a.OAuth2GrantDropDown.Changed() {
+		if a.auth.OAuth2Auth == nil {
+			a.auth.OAuth2Auth = &domain.OAuth2Auth{}
+		}
+
+		a.auth.OAuth2Auth.GrantType = a.OAuth2GrantDropDown.GetSelected().Value
+		if a.onChange != nil {
+			a.onChange(a.auth)
+		}
+	}
+
 	inset := layout.Inset{Top: unit.Dp(15), Right: unit.Dp(10)}
 	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		selectedAuthType := a.DropDown.GetSelected().Value
@@ -191,6 +394,12 @@ func (a *Auth) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimen
 					return a.BasicForm.Layout(gtx, theme)
 				case "API Key":
 					return a.APIKeyForm.Layout(gtx, theme)
+				case "Digest":
+					return a.DigestForm.Layout(gtx, theme)
+				case "OAuth 2.0":
+					return a.layoutOAuth2(gtx, theme)
+				case "AWS Signature":
+					return a.AWSSigV4Form.Layout(gtx, theme)
 				default:
 					return layout.Dimensions{}
 				}
@@ -199,6 +408,37 @@ func (a *Auth) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimen
 	})
 }
 
+func (a *Auth) layoutOAuth2(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	form, ok := a.OAuth2Forms[a.OAuth2GrantDropDown.GetSelected().Value]
+	if !ok {
+		return layout.Dimensions{}
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return a.OAuth2GrantDropDown.Layout(gtx, theme)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return form.Layout(gtx, theme)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.onClearToken == nil {
+				return layout.Dimensions{}
+			}
+
+			if a.clearTokenButton.Clicked(gtx) {
+				a.onClearToken()
+			}
+
+			return layout.Inset{Top: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := widgets.Button(theme, &a.clearTokenButton, widgets.DeleteIcon, widgets.IconPositionStart, "Clear cached tokens")
+				return btn.Layout(gtx, theme)
+			})
+		}),
+	)
+}
+
 func (a *Auth) getAuthTypeDisplay(authType string) string {
 	switch authType {
 	case domain.AuthTypeBasic:
@@ -207,6 +447,12 @@ func (a *Auth) getAuthTypeDisplay(authType string) string {
 		return "Token Auth"
 	case domain.AuthTypeAPIKey:
 		return "API Key Auth"
+	case domain.AuthTypeDigest:
+		return "Digest Auth"
+	case domain.AuthTypeOAuth2:
+		return "OAuth 2.0"
+	case domain.AuthTypeAWSSigV4:
+		return "AWS Signature"
 	case domain.AuthTypeNone:
 		return "None"
 	default:
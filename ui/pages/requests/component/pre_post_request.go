@@ -155,6 +155,16 @@ func (p *PrePostRequest) SetOnDropDownChanged(f func(selected string)) {
 
 func (p *PrePostRequest) SetSelectedDropDown(selected string) {
 	p.dropDown.SetSelectedByValue(selected)
+	p.updateScriptLanguage(selected)
+}
+
+// updateScriptLanguage highlights the script as the language of the selected type.
+func (p *PrePostRequest) updateScriptLanguage(selected string) {
+	if selected == domain.PrePostTypeJavaScript {
+		p.script.SetLanguage(codeeditor.CodeLanguageJavaScript)
+	} else {
+		p.script.SetLanguage(codeeditor.CodeLanguagePython)
+	}
 }
 
 func (p *PrePostRequest) SetCode(code string) {
@@ -185,8 +195,11 @@ func (p *PrePostRequest) SetOnPostRequestSetChanged(f func(statusCode int, item,
 }
 
 func (p *PrePostRequest) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
-	if p.dropDown.Changed() && p.onDropDownChanged != nil {
-		p.onDropDownChanged(p.dropDown.GetSelected().Value)
+	if p.dropDown.Changed() {
+		p.updateScriptLanguage(p.dropDown.GetSelected().Value)
+		if p.onDropDownChanged != nil {
+			p.onDropDownChanged(p.dropDown.GetSelected().Value)
+		}
 	}
 
 	inset := layout.Inset{Top: unit.Dp(15), Right: unit.Dp(10)}
@@ -291,7 +304,7 @@ func (p *PrePostRequest) SetEnvForm(gtx layout.Context, theme *chapartheme.Theme
 			return topButtonInset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				label := "Key"
 				hint := "e.g. name"
-				if p.setEnvForm.fromDropDown.GetSelected().Value == domain.PostRequestSetFromResponseBody {
+				if from := p.setEnvForm.fromDropDown.GetSelected().Value; from == domain.PostRequestSetFromResponseBody || from == domain.PostRequestSetFromResponseErrorDetails {
 					label = "JSON Path"
 					hint = "e.g. $.data[0].name"
 				}
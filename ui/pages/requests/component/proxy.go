@@ -0,0 +1,39 @@
+package component
+
+import (
+	"gioui.org/layout"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/converter"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Proxy edits the proxy override of a request, a nil config means the environment or global proxy is used.
+type Proxy struct {
+	settings *widgets.Settings
+
+	onChange func(proxy *domain.ProxyConfig)
+}
+
+func NewProxy(proxy *domain.ProxyConfig) *Proxy {
+	return &Proxy{
+		settings: widgets.NewSettings(converter.ProxySettingItems(proxy, true)),
+	}
+}
+
+func (p *Proxy) SetProxy(proxy *domain.ProxyConfig) {
+	p.settings = widgets.NewSettings(converter.ProxySettingItems(proxy, true))
+}
+
+func (p *Proxy) SetOnChange(f func(proxy *domain.ProxyConfig)) {
+	p.onChange = f
+}
+
+func (p *Proxy) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if p.settings.Changed() && p.onChange != nil {
+		p.onChange(converter.ProxyFromSettingValues(p.settings.GetValues()))
+	}
+
+	return p.settings.Layout(gtx, theme)
+}
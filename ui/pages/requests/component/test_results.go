@@ -0,0 +1,112 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+)
+
+// TestResults shows the results of the tests of the post request script.
+type TestResults struct {
+	tests []domain.TestResult
+	list  widget.List
+}
+
+func NewTestResults() *TestResults {
+	return &TestResults{
+		list: widget.List{
+			List: layout.List{Axis: layout.Vertical},
+		},
+	}
+}
+
+func (t *TestResults) SetTests(tests []domain.TestResult) {
+	t.tests = tests
+}
+
+func (t *TestResults) summary() string {
+	passed := 0
+	for _, test := range t.tests {
+		if test.Passed {
+			passed++
+		}
+	}
+	return fmt.Sprintf("%d passed, %d failed", passed, len(t.tests)-passed)
+}
+
+// String returns the results as text, used to copy them.
+func (t *TestResults) String() string {
+	var b strings.Builder
+	b.WriteString(t.summary() + "\n")
+	for _, test := range t.tests {
+		status := "PASS"
+		if !test.Passed {
+			status = "FAIL"
+		}
+
+		b.WriteString(fmt.Sprintf("%s %s\n", status, test.Name))
+		if test.Message != "" {
+			b.WriteString(fmt.Sprintf("    %s\n", test.Message))
+		}
+	}
+	return b.String()
+}
+
+func (t *TestResults) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if len(t.tests) == 0 {
+		return Message(gtx, MessageTypeInfo, theme, "Results of test calls in the post request script are shown here")
+	}
+
+	return layout.Inset{Top: unit.Dp(10), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return material.List(theme.Material(), &t.list).Layout(gtx, len(t.tests)+1, func(gtx layout.Context, i int) layout.Dimensions {
+			if i == 0 {
+				return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, material.Label(theme.Material(), theme.TextSize, t.summary()).Layout)
+			}
+			return t.testLayout(gtx, theme, t.tests[i-1])
+		})
+	})
+}
+
+func (t *TestResults) testLayout(gtx layout.Context, theme *chapartheme.Theme, test domain.TestResult) layout.Dimensions {
+	status, statusColor := "PASS", chapartheme.LightGreen
+	if !test.Passed {
+		status, statusColor = "FAIL", theme.ErrorColor
+	}
+
+	return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						gtx.Constraints.Min.X = gtx.Dp(50)
+						lb := material.Label(theme.Material(), theme.TextSize, status)
+						lb.Font.Weight = font.Bold
+						lb.Color = statusColor
+						return lb.Layout(gtx)
+					}),
+					layout.Flexed(1, material.Label(theme.Material(), theme.TextSize, test.Name).Layout),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if test.Message == "" {
+					return layout.Dimensions{}
+				}
+
+				return layout.Inset{Left: unit.Dp(50), Top: unit.Dp(2)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					lb := material.Label(theme.Material(), unit.Sp(12), test.Message)
+					lb.Font.Typeface = theme.Face
+					lb.Color = theme.TextColor
+					return lb.Layout(gtx)
+				})
+			}),
+		)
+	})
+}
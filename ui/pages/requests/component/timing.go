@@ -0,0 +1,125 @@
+package component
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+)
+
+var timingPhaseColors = map[string]color.NRGBA{
+	domain.TimingPhaseDNSLookup:       chapartheme.LightPurple,
+	domain.TimingPhaseTCPConnect:      chapartheme.LightYellow,
+	domain.TimingPhaseTLSHandshake:    chapartheme.LightRed,
+	domain.TimingPhaseWaiting:         chapartheme.LightGreen,
+	domain.TimingPhaseContentTransfer: chapartheme.LightBlue,
+}
+
+// Timing shows the phases of an http request as a waterfall.
+type Timing struct {
+	timing domain.Timing
+	list   widget.List
+}
+
+func NewTiming() *Timing {
+	return &Timing{
+		list: widget.List{
+			List: layout.List{Axis: layout.Vertical},
+		},
+	}
+}
+
+func (t *Timing) SetTiming(timing domain.Timing) {
+	t.timing = timing
+}
+
+// String returns the timing as text, used to copy it.
+func (t *Timing) String() string {
+	out := fmt.Sprintf("Total: %s\nTime to first byte: %s\nConnection reused: %t\n", roundDuration(t.timing.Total), roundDuration(t.timing.TimeToFirstByte), t.timing.ConnectionReused)
+	for _, p := range t.timing.Phases {
+		out += fmt.Sprintf("%s: %s (at %s)\n", p.Name, roundDuration(p.Duration), roundDuration(p.Start))
+	}
+	return out
+}
+
+func (t *Timing) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if t.timing.Total == 0 {
+		return Message(gtx, MessageTypeInfo, theme, "No timing available")
+	}
+
+	reused := "No"
+	if t.timing.ConnectionReused {
+		reused = "Yes"
+	}
+
+	summary := []string{
+		fmt.Sprintf("Total: %s", roundDuration(t.timing.Total)),
+		fmt.Sprintf("Time to first byte: %s", roundDuration(t.timing.TimeToFirstByte)),
+		fmt.Sprintf("Connection reused: %s", reused),
+	}
+
+	return layout.Inset{Top: unit.Dp(10), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return material.List(theme.Material(), &t.list).Layout(gtx, len(summary)+len(t.timing.Phases)+1, func(gtx layout.Context, i int) layout.Dimensions {
+			switch {
+			case i < len(summary):
+				return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, material.Label(theme.Material(), theme.TextSize, summary[i]).Layout)
+			case i == len(summary):
+				return layout.Spacer{Height: unit.Dp(10)}.Layout(gtx)
+			default:
+				return t.phaseLayout(gtx, theme, t.timing.Phases[i-len(summary)-1])
+			}
+		})
+	})
+}
+
+func (t *Timing) phaseLayout(gtx layout.Context, theme *chapartheme.Theme, phase domain.TimingPhase) layout.Dimensions {
+	return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Dp(140)
+				gtx.Constraints.Max.X = gtx.Constraints.Min.X
+				return material.Label(theme.Material(), theme.TextSize, phase.Name).Layout(gtx)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				width := gtx.Constraints.Max.X
+				height := gtx.Dp(14)
+
+				total := float64(t.timing.Total)
+				x0 := int(float64(width) * float64(phase.Start) / total)
+				x1 := int(float64(width) * float64(phase.Start+phase.Duration) / total)
+				// keep very short phases visible
+				if x1-x0 < gtx.Dp(2) {
+					x1 = x0 + gtx.Dp(2)
+				}
+				if x1 > width {
+					x1 = width
+				}
+
+				paint.FillShape(gtx.Ops, theme.SeparatorColor, clip.Rect{Max: image.Pt(width, height)}.Op())
+				paint.FillShape(gtx.Ops, timingPhaseColors[phase.Name], clip.Rect{Min: image.Pt(x0, 0), Max: image.Pt(x1, height)}.Op())
+				return layout.Dimensions{Size: image.Pt(width, height)}
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Dp(90)
+				return layout.E.Layout(gtx, material.Label(theme.Material(), theme.TextSize, roundDuration(phase.Duration).String()).Layout)
+			}),
+		)
+	})
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d > time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(time.Microsecond)
+}
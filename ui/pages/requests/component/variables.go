@@ -128,6 +128,7 @@ func (f *Variables) addItem(item *Variable) {
 			widgets.NewDropDownOption("Body").WithIdentifier("body").WithValue("body"),
 			widgets.NewDropDownOption("Meta").WithIdentifier("metadata").WithValue("metadata"),
 			widgets.NewDropDownOption("Trailers").WithIdentifier(domain.VariableFromTrailers.String()).WithValue(domain.VariableFromTrailers.String()),
+			widgets.NewDropDownOption("Error details").WithIdentifier(domain.VariableFromErrorDetails.String()).WithValue(domain.VariableFromErrorDetails.String()),
 		)
 	}
 
@@ -207,7 +208,7 @@ func (f *Variables) itemLayouts(gtx layout.Context, theme *chapartheme.Theme, it
 	}
 
 	itemType := item.fromDropDown.GetSelected().Identifier
-	if itemType == string(domain.VariableFromBody) {
+	if itemType == string(domain.VariableFromBody) || itemType == string(domain.VariableFromErrorDetails) {
 		items = append(items, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 			return layout.Inset{Left: unit.Dp(4), Right: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				editor := material.Editor(theme.Material(), item.jsonPathCodeEditor, "e.g. $.data[0].name")
@@ -475,6 +476,11 @@ func (f *Variables) handleGrpcPreview(item *Variable) {
 		pre = domain.FindKeyValue(f.responseDetail.GRPC.ResponseMetadata, item.SourceKey)
 	case domain.VariableFromTrailers:
 		pre = domain.FindKeyValue(f.responseDetail.GRPC.Trailers, item.SourceKey)
+	case domain.VariableFromErrorDetails:
+		pre, err = jsonpath.Get(f.responseDetail.GRPC.ErrorDetails, item.JsonPath)
+		if err != nil {
+			return
+		}
 	}
 
 	if result, ok := pre.(string); ok {
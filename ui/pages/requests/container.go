@@ -2,8 +2,10 @@ package requests
 
 import (
 	"gioui.org/layout"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/gql"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
 	"github.com/chapar-rest/chapar/ui/widgets"
 )
@@ -24,6 +26,7 @@ type Container interface {
 	ShowPrompt(title, content, modalType string, onSubmit func(selectedOption string, remember bool), options ...widgets.Option)
 	HidePrompt()
 	SetTitle(title string)
+	SetOnClearOAuth2Token(f func())
 }
 
 type GrpcContainer interface {
@@ -33,6 +36,8 @@ type GrpcContainer interface {
 	SetMethodsLoading(loading bool)
 	SetResponseLoading(loading bool)
 	SetOnInvoke(f func(id string))
+	SetOnCancel(f func(id string))
+	SetCancelled()
 	SetResponse(response domain.GRPCResponseDetail)
 	GetResponse() *domain.GRPCResponseDetail
 	SetOnLoadRequestExample(f func(id string))
@@ -47,6 +52,17 @@ type GrpcContainer interface {
 	SetPostRequestSetPreview(preview string)
 	SetOnRequestTabChange(f func(id, tab string))
 	SetOnCreateCollectionFromMethods(f func())
+	SetOnStreamSend(f func(id, body string))
+	SetOnHalfClose(f func(id string))
+	SetOnSaveMessages(f func(id string, messages []domain.GRPCStreamMessage))
+	SetOnReconnect(f func(id string))
+	SetOnLoadRequestForm(f func(id string))
+	SetOnConvertBody(f func(id, body, from, to string))
+	SetRequestDescriptor(desc protoreflect.MessageDescriptor)
+	SetConnectionState(state string)
+	StartStream()
+	AddStreamMessage(msg domain.GRPCStreamMessage)
+	EndStream(stopped bool, detail domain.GRPCResponseDetail)
 }
 
 type RestContainer interface {
@@ -56,6 +72,14 @@ type RestContainer interface {
 	SetPostRequestSetPreview(preview string)
 	ShowSendingRequestLoading()
 	HideSendingRequestLoading()
+	SetOnCancel(f func(id string))
+	SetCancelled()
+	SetOnReconnect(f func(id, lastEventID string))
+	StartEventStream()
+	AddEvent(event domain.SSEEvent)
+	EndEventStream(err error)
+	StopEventStream()
+	SetTests(tests []domain.TestResult)
 	SetQueryParams(params []domain.KeyValue)
 	SetPathParams(params []domain.KeyValue)
 	SetURL(url string)
@@ -79,6 +103,8 @@ type GraphQLContainer interface {
 	SetPostRequestSetPreview(preview string)
 	ShowSendingRequestLoading()
 	HideSendingRequestLoading()
+	SetOnCancel(f func(id string))
+	SetCancelled()
 	SetURL(url string)
 	SetPostRequestSetValues(set domain.PostRequestSet)
 	SetOnPostRequestSetChanged(f func(id string, statusCode int, item, from, fromKey string))
@@ -88,4 +114,26 @@ type GraphQLContainer interface {
 	SetOnRequestTabChange(f func(id, tab string))
 	SetCollection(collection *domain.Collection)
 	SetOnCopyResponse(f func(gtx layout.Context, dataType, data string))
+	SetOnLoadSchema(f func(id string, refresh bool))
+	SetSchema(schema *gql.Schema, err error)
+	SetSchemaLoading()
+	StartSubscription()
+	AddSubscriptionMessage(msg domain.GraphQLSubscriptionMessage)
+	EndSubscription(err error)
+	StopSubscription()
+	SetTests(tests []domain.TestResult)
+}
+
+type WebSocketContainer interface {
+	Container
+	SetOnConnect(f func(id string))
+	SetOnDisconnect(f func(id string))
+	SetOnSend(f func(id string, msg domain.WebSocketMessage))
+	SetOnPing(f func(id string))
+	SetOnCopyResponse(f func(gtx layout.Context, dataType, data string))
+	SetConnecting()
+	SetConnected(subprotocol string)
+	SetDisconnected(err error)
+	AddFrame(frame domain.WebSocketFrame)
+	SetCollection(collection *domain.Collection)
 }
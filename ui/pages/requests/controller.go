@@ -1,6 +1,8 @@
 package requests
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,10 +16,15 @@ import (
 
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/internal/egress"
+	"github.com/chapar-rest/chapar/internal/egress/graphql"
 	"github.com/chapar-rest/chapar/internal/egress/grpc"
+	"github.com/chapar-rest/chapar/internal/egress/websocket"
+	"github.com/chapar-rest/chapar/internal/gql"
 	"github.com/chapar-rest/chapar/internal/importer"
 	"github.com/chapar-rest/chapar/internal/jsonpath"
+	"github.com/chapar-rest/chapar/internal/oauth2"
 	"github.com/chapar-rest/chapar/internal/repository"
+	"github.com/chapar-rest/chapar/internal/safemap"
 	"github.com/chapar-rest/chapar/internal/state"
 	"github.com/chapar-rest/chapar/ui/explorer"
 	"github.com/chapar-rest/chapar/ui/modals"
@@ -35,11 +42,23 @@ type Controller struct {
 
 	explorer *explorer.Explorer
 
-	grpcService   *grpc.Service
-	egressService *egress.Service
+	grpcService      *grpc.Service
+	graphqlService   *graphql.Service
+	egressService    *egress.Service
+	websocketService *websocket.Service
+	oauth2Service    *oauth2.Service
+
+	// inFlight holds the cancel functions of the requests which are being sent, keyed by request id.
+	inFlight *safemap.Map[context.CancelFunc]
+
+	// connections holds the open websocket connections, keyed by request id.
+	connections *safemap.Map[*websocket.Connection]
+
+	// streams holds the open grpc client and bidi streams, keyed by request id.
+	streams *safemap.Map[*grpc.Stream]
 }
 
-func NewController(view *View, repo repository.RepositoryV2, model *state.Requests, envState *state.Environments, explorer *explorer.Explorer, egressService *egress.Service, grpcService *grpc.Service) *Controller {
+func NewController(view *View, repo repository.RepositoryV2, model *state.Requests, envState *state.Environments, explorer *explorer.Explorer, egressService *egress.Service, grpcService *grpc.Service, graphqlService *graphql.Service, websocketService *websocket.Service, oauth2Service *oauth2.Service) *Controller {
 	c := &Controller{
 		view:     view,
 		model:    model,
@@ -48,10 +67,21 @@ func NewController(view *View, repo repository.RepositoryV2, model *state.Reques
 
 		explorer: explorer,
 
-		egressService: egressService,
-		grpcService:   grpcService,
+		egressService:    egressService,
+		grpcService:      grpcService,
+		graphqlService:   graphqlService,
+		websocketService: websocketService,
+		oauth2Service:    oauth2Service,
+
+		inFlight:    safemap.New[context.CancelFunc](),
+		connections: safemap.New[*websocket.Connection](),
+		streams:     safemap.New[*grpc.Stream](),
 	}
 
+	grpcService.SetOnConnectionStateChanged(func(id, state string) {
+		c.view.SetGRPCConnectionState(id, state)
+	})
+
 	view.SetController(c)
 	return c
 }
@@ -222,24 +252,160 @@ func (c *Controller) doCreateCollectionFromGRPCMethods(requestID, collectionName
 }
 
 func (c *Controller) OnGrpcInvoke(id string) {
+	ctx, ok := c.startRequest(id)
+	if !ok {
+		return
+	}
+
+	// client and bidi streams are left open, messages are sent by OnGrpcStreamSend
+	if c.isGrpcClientStreaming(id) {
+		ctx = egress.WithClientStream(ctx, id)
+	}
+
+	go c.invokeGrpc(ctx, id)
+}
+
+func (c *Controller) isGrpcClientStreaming(id string) bool {
+	req := c.model.GetRequest(id)
+	if req == nil || req.Spec.GRPC == nil {
+		return false
+	}
+
+	m := req.Spec.GRPC.GetMethod(req.Spec.GRPC.LasSelectedMethod)
+	return m != nil && m.IsStreamingClient
+}
+
+// readGrpcStream shows the messages of the stream as they arrive, until the call ends or the request is cancelled.
+func (c *Controller) readGrpcStream(ctx context.Context, id string, stream egress.MessageStream) {
+	c.view.StartGRPCStream(id)
+	for msg := range stream.Messages() {
+		c.view.AddGRPCStreamMessage(id, msg)
+	}
+
+	res := stream.Result()
+	c.finishStream(ctx, id, res)
+	c.view.EndGRPCStream(id, ctx.Err() != nil, domain.GRPCResponseDetail{
+		ResponseMetadata: res.ResponseMetadata,
+		RequestMetadata:  res.RequestMetadata,
+		Trailers:         res.Trailers,
+		StatusCode:       res.StatueCode,
+		Duration:         res.TimePassed,
+		Status:           res.Status,
+		Size:             res.Size,
+		Error:            res.Error,
+		ErrorDetails:     res.ErrorDetails,
+		Tests:            res.Tests,
+	})
+}
+
+// finishStream runs the post request of the ended stream of the request, the results of its tests are set on res.
+func (c *Controller) finishStream(ctx context.Context, id string, res *egress.Response) {
+	if err := c.egressService.FinishStream(ctx, id, c.getActiveEnvID(), res); err != nil {
+		c.view.showError(fmt.Errorf("failed to run the post request, %w", err))
+	}
+}
+
+func (c *Controller) OnGrpcStreamSend(id, body string) {
+	stream, ok := c.streams.Get(id)
+	if !ok {
+		return
+	}
+
+	msg, err := stream.Send(body)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to send message, %w", err))
+		return
+	}
+	c.view.AddGRPCStreamMessage(id, msg)
+}
+
+// OnGrpcSaveMessages asks where to save the messages of the last stream and writes them as JSON Lines.
+func (c *Controller) OnGrpcSaveMessages(id string, messages []domain.GRPCStreamMessage) {
+	data, err := domain.GRPCStreamMessagesToJSONL(messages)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to encode messages, %w", err))
+		return
+	}
+
+	c.explorer.SaveFile("messages.jsonl", data, func(result explorer.Result) {
+		if result.Declined {
+			return
+		}
+
+		if result.Error != nil {
+			c.view.showError(fmt.Errorf("failed to save messages, %w", result.Error))
+			return
+		}
+
+		notifications.Send("Messages saved", notifications.NotificationTypeInfo, 2*time.Second)
+	})
+}
+
+// OnGrpcHalfClose tells the server no more messages are coming, the responses are still received.
+func (c *Controller) OnGrpcHalfClose(id string) {
+	stream, ok := c.streams.Get(id)
+	if !ok {
+		return
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		c.view.showError(fmt.Errorf("failed to half close the stream, %w", err))
+	}
+}
+
+// OnGrpcReconnect replaces the connection of the request with a new one.
+func (c *Controller) OnGrpcReconnect(id string) {
+	go func() {
+		if err := c.grpcService.Reconnect(id, c.getActiveEnvID()); err != nil {
+			c.view.showError(fmt.Errorf("failed to reconnect, %w", err))
+		}
+	}()
+}
+
+// GrpcConnectionState returns the state of the connection the request uses.
+func (c *Controller) GrpcConnectionState(id string) string {
+	return c.grpcService.ConnectionState(id)
+}
+
+func (c *Controller) invokeGrpc(ctx context.Context, id string) {
 	c.view.SetSendingRequestLoading(id)
 	defer c.view.SetSendingRequestLoaded(id)
+	defer c.finishRequest(id)
 
-	res, err := c.egressService.Send(id, c.getActiveEnvID())
-	if err != nil {
+	res, err := c.egressService.Send(ctx, id, c.getActiveEnvID())
+	if errors.Is(err, egress.ErrCancelled) {
+		c.view.SetRequestCancelled(id)
+		return
+	}
+
+	// failed calls come with their response when the server answered, it carries the status and the error details
+	resp, ok := res.(*egress.Response)
+	if err != nil && !ok {
 		c.view.SetGRPCResponse(id, domain.GRPCResponseDetail{
 			Error: err,
 		})
 		return
 	}
 
-	resp, ok := res.(*egress.Response)
 	if !ok {
 		panic("invalid response type")
 	}
 
+	if resp.MessageStream != nil {
+		// both the sent and received messages of client streams are shown until the call ends
+		if stream, ok := resp.MessageStream.(*grpc.Stream); ok && egress.ClientStream(ctx, id) {
+			c.streams.Set(id, stream)
+			defer c.streams.Delete(id)
+		}
+
+		c.readGrpcStream(ctx, id, resp.MessageStream)
+		return
+	}
+
 	c.view.SetGRPCResponse(id, domain.GRPCResponseDetail{
 		Response:         string(resp.Body),
+		ResponseText:     resp.ProtoText,
+		ResponseHex:      resp.HexDump,
 		ResponseMetadata: resp.ResponseMetadata,
 		RequestMetadata:  resp.RequestMetadata,
 		Trailers:         resp.Trailers,
@@ -247,9 +413,37 @@ func (c *Controller) OnGrpcInvoke(id string) {
 		Duration:         resp.TimePassed,
 		Status:           resp.Status,
 		Size:             resp.Size,
+		Error:            resp.Error,
+		ErrorDetails:     resp.ErrorDetails,
+		Tests:            resp.Tests,
 	})
 }
 
+// OnGrpcLoadRequestForm loads the descriptor of the request message to edit the body as a form.
+func (c *Controller) OnGrpcLoadRequestForm(id string) {
+	desc, err := c.grpcService.GetRequestDescriptor(id, c.getActiveEnvID())
+	if err != nil {
+		c.view.ShowGRPCRequestError(id, "Failed to load the request form", err.Error())
+		return
+	}
+
+	c.view.HideGRPCRequestError(id)
+	c.view.SetGRPCRequestDescriptor(id, desc)
+}
+
+// OnGrpcConvertBody converts the body of the request when it's switched between the json and the text format,
+// the body is left as it is when it can't be converted.
+func (c *Controller) OnGrpcConvertBody(id, body, from, to string) {
+	converted, err := c.grpcService.ConvertRequestBody(id, c.getActiveEnvID(), body, from, to)
+	if err != nil {
+		c.view.ShowGRPCRequestError(id, "Failed to convert the body", err.Error())
+		return
+	}
+
+	c.view.HideGRPCRequestError(id)
+	c.view.SetSetGrpcRequestBody(id, converted)
+}
+
 func (c *Controller) OnGrpcLoadRequestExample(id string) {
 	req := c.model.GetRequest(id)
 	if req == nil {
@@ -306,6 +500,7 @@ func (c *Controller) OnPostRequestSetChanged(id string, statusCode int, item, fr
 		cookies      []domain.KeyValue
 		metaData     []domain.KeyValue
 		trailers     []domain.KeyValue
+		errorDetails string
 	)
 
 	switch req.MetaData.Type {
@@ -320,13 +515,14 @@ func (c *Controller) OnPostRequestSetChanged(id string, statusCode int, item, fr
 		responseFrom = clone.Spec.HTTP.Request.PostRequest.PostRequestSet.From
 	case domain.RequestTypeGRPC:
 		responseData := c.view.GetGRPCResponse(id)
-		if responseData == nil || responseData.Response == "" {
+		if responseData == nil || (responseData.Response == "" && responseData.ErrorDetails == "") {
 			return
 		}
 		response = responseData.Response
 		headers = responseData.ResponseMetadata
 		metaData = responseData.ResponseMetadata
 		trailers = responseData.Trailers
+		errorDetails = responseData.ErrorDetails
 		responseFrom = clone.Spec.GRPC.PostRequest.PostRequestSet.From
 	case domain.RequestTypeGraphQL:
 		responseData := c.view.GetGraphQLResponse(id)
@@ -349,6 +545,8 @@ func (c *Controller) OnPostRequestSetChanged(id string, statusCode int, item, fr
 		c.setPreviewFromKeyValue(id, metaData, fromKey)
 	case domain.PostRequestSetFromResponseTrailers:
 		c.setPreviewFromKeyValue(id, trailers, fromKey)
+	case domain.PostRequestSetFromResponseErrorDetails:
+		c.setPreviewFromResponse(id, errorDetails, fromKey)
 	}
 }
 
@@ -432,8 +630,50 @@ func (c *Controller) OnSave(id string) {
 }
 
 func (c *Controller) OnSubmit(id, containerType string) {
-	if containerType == TypeRequest {
-		c.onSubmitRequest(id)
+	if containerType != TypeRequest {
+		return
+	}
+
+	ctx, ok := c.startRequest(id)
+	if !ok {
+		return
+	}
+
+	go c.onSubmitRequest(ctx, id)
+}
+
+// OnReconnect sends the request again to resume its event stream from the given event id.
+func (c *Controller) OnReconnect(id, lastEventID string) {
+	ctx, ok := c.startRequest(id)
+	if !ok {
+		return
+	}
+
+	go c.onSubmitRequest(egress.WithLastEventID(ctx, lastEventID), id)
+}
+
+func (c *Controller) OnCancelRequest(id string) {
+	if cancel, ok := c.inFlight.Get(id); ok {
+		cancel()
+	}
+}
+
+// startRequest registers a cancellable context for the given request, it returns false if
+// the request is already in flight.
+func (c *Controller) startRequest(id string) (context.Context, bool) {
+	if c.inFlight.Has(id) {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.inFlight.Set(id, cancel)
+	return ctx, true
+}
+
+func (c *Controller) finishRequest(id string) {
+	if cancel, ok := c.inFlight.Get(id); ok {
+		cancel()
+		c.inFlight.Delete(id)
 	}
 }
 
@@ -445,9 +685,16 @@ func (c *Controller) OnCopyResponse(gtx layout.Context, dataType, data string) {
 	notifications.Send(fmt.Sprintf("%s copied to clipboard", dataType), notifications.NotificationTypeInfo, 2*time.Second)
 }
 
-func (c *Controller) onSubmitRequest(id string) {
+// OnClearOAuth2Token drops the cached oauth2 tokens, the next request acquires a new one.
+func (c *Controller) OnClearOAuth2Token() {
+	c.oauth2Service.Clear()
+	notifications.Send("OAuth2 tokens cleared", notifications.NotificationTypeInfo, 2*time.Second)
+}
+
+func (c *Controller) onSubmitRequest(ctx context.Context, id string) {
 	c.view.SetSendingRequestLoading(id)
 	defer c.view.SetSendingRequestLoaded(id)
+	defer c.finishRequest(id)
 
 	req := c.model.GetRequest(id)
 	if req == nil {
@@ -455,7 +702,12 @@ func (c *Controller) onSubmitRequest(id string) {
 		return
 	}
 
-	egRes, err := c.egressService.Send(id, c.getActiveEnvID())
+	egRes, err := c.egressService.Send(ctx, id, c.getActiveEnvID())
+	if errors.Is(err, egress.ErrCancelled) {
+		c.view.SetRequestCancelled(id)
+		return
+	}
+
 	if err != nil {
 		// Handle error based on request type
 		switch req.MetaData.Type {
@@ -495,8 +747,14 @@ func (c *Controller) onSubmitRequest(id string) {
 			StatusCode:      res.StatusCode,
 			Duration:        res.TimePassed,
 			Size:            len(res.Body),
+			Timing:          res.Timing,
+			Tests:           res.Tests,
 		})
 
+		if res.EventStream != nil {
+			c.readEventStream(ctx, id, res)
+		}
+
 		return
 	}
 
@@ -518,8 +776,56 @@ func (c *Controller) onSubmitRequest(id string) {
 			StatusCode:      res.StatusCode,
 			Duration:        res.TimePassed,
 			Size:            len(res.Body),
+			Timing:          res.Timing,
+			Tests:           res.Tests,
 		})
+
+		if res.SubscriptionStream != nil {
+			c.readSubscription(ctx, id, res)
+		}
+	}
+}
+
+// readEventStream shows the events of the stream as they arrive, until the stream ends or the request is cancelled.
+// the post request runs once the stream ends, with the data of the last event as the body.
+func (c *Controller) readEventStream(ctx context.Context, id string, res *egress.Response) {
+	stream := res.EventStream
+	stop := context.AfterFunc(ctx, func() { _ = stream.Close() })
+	defer stop()
+
+	var last string
+	c.view.StartHTTPEventStream(id)
+	for event := range stream.Events() {
+		c.view.AddHTTPEvent(id, event)
+		last = event.Data
+	}
+
+	c.view.EndHTTPEventStream(id, ctx.Err() != nil, stream.Err())
+
+	res.SetStreamBody(last)
+	c.finishStream(ctx, id, res)
+	c.view.SetHTTPTests(id, res.Tests)
+}
+
+// readSubscription shows the payloads of the subscription as they arrive, until it ends or the request is cancelled.
+// the post request runs once the subscription ends, with the last payload as the body.
+func (c *Controller) readSubscription(ctx context.Context, id string, res *egress.Response) {
+	stream := res.SubscriptionStream
+	stop := context.AfterFunc(ctx, stream.Close)
+	defer stop()
+
+	var last string
+	c.view.StartGraphQLSubscription(id)
+	for msg := range stream.Messages() {
+		c.view.AddGraphQLSubscriptionMessage(id, msg)
+		last = msg.Payload
 	}
+
+	c.view.EndGraphQLSubscription(id, ctx.Err() != nil, stream.Err())
+
+	res.SetStreamBody(last)
+	c.finishStream(ctx, id, res)
+	c.view.SetGraphQLTests(id, res.Tests)
 }
 
 func cookieToKeyValue(cookies []*http.Cookie) []domain.KeyValue {
@@ -538,6 +844,127 @@ func mapToKeyValue(m map[string]string) []domain.KeyValue {
 	return kvs
 }
 
+// OnWebSocketConnect opens the connection of the websocket request, the received frames are
+// added to the timeline until the connection is closed.
+func (c *Controller) OnWebSocketConnect(id string) {
+	if c.connections.Has(id) {
+		return
+	}
+
+	ctx, ok := c.startRequest(id)
+	if !ok {
+		return
+	}
+
+	go c.connectWebSocket(ctx, id)
+}
+
+func (c *Controller) connectWebSocket(ctx context.Context, id string) {
+	c.view.SetWebSocketConnecting(id)
+
+	conn, err := c.websocketService.Connect(ctx, id, c.getActiveEnvID())
+	if err != nil {
+		c.finishRequest(id)
+		if ctx.Err() != nil {
+			err = nil
+		}
+		c.view.SetWebSocketDisconnected(id, err)
+		return
+	}
+
+	// the connection is set before the request is finished, so disconnecting always finds one of them
+	c.connections.Set(id, conn)
+	cancelled := ctx.Err() != nil
+	c.finishRequest(id)
+	if cancelled {
+		// disconnected while the connection was being made
+		_, _ = conn.Close()
+	}
+
+	c.view.SetWebSocketConnected(id, conn.Subprotocol)
+
+	for frame := range conn.Frames() {
+		c.view.AddWebSocketFrame(id, frame)
+	}
+
+	c.connections.Delete(id)
+	c.view.SetWebSocketDisconnected(id, conn.Err())
+}
+
+// OnWebSocketDisconnect closes the connection or cancels it if it's still connecting.
+func (c *Controller) OnWebSocketDisconnect(id string) {
+	conn, ok := c.connections.Get(id)
+	if !ok {
+		c.OnCancelRequest(id)
+		return
+	}
+
+	frame, err := conn.Close()
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to close the connection, %w", err))
+		return
+	}
+	c.view.AddWebSocketFrame(id, frame)
+}
+
+func (c *Controller) OnWebSocketSend(id string, msg domain.WebSocketMessage) {
+	conn, ok := c.connections.Get(id)
+	if !ok {
+		return
+	}
+
+	frame, err := conn.Send(msg)
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to send message, %w", err))
+		return
+	}
+	c.view.AddWebSocketFrame(id, frame)
+}
+
+func (c *Controller) OnWebSocketPing(id string) {
+	conn, ok := c.connections.Get(id)
+	if !ok {
+		return
+	}
+
+	frame, err := conn.Ping()
+	if err != nil {
+		c.view.showError(fmt.Errorf("failed to send ping, %w", err))
+		return
+	}
+	c.view.AddWebSocketFrame(id, frame)
+}
+
+// OnGraphQLLoadSchema sets the schema of the request on the view, the server is only asked for it when
+// refresh is set, otherwise only the cached schema and schema files are used.
+func (c *Controller) OnGraphQLLoadSchema(id string, refresh bool) {
+	activeEnvironmentID := c.getActiveEnvID()
+	if refresh {
+		c.view.SetGraphQLSchemaLoading(id)
+	}
+
+	go func() {
+		var (
+			schema *gql.Schema
+			err    error
+		)
+		if refresh {
+			schema, err = c.graphqlService.GetSchema(context.Background(), id, activeEnvironmentID, true)
+		} else {
+			schema, err = c.graphqlService.CachedSchema(id, activeEnvironmentID)
+		}
+		c.view.SetGraphQLSchema(id, schema, err)
+	}()
+}
+
+// closeWebSocket drops the connection of the request if it's open, used when its tab is closed.
+func (c *Controller) closeWebSocket(id string) {
+	c.OnCancelRequest(id)
+	if conn, ok := c.connections.Get(id); ok {
+		_, _ = conn.Close()
+	}
+}
+
 func (c *Controller) OnTabClose(id string) {
 	// get Tab to check if it's a request or collection
 	tabType := c.view.GetTabType(id)
@@ -796,6 +1223,7 @@ func (c *Controller) onRequestTabClose(id string) {
 
 	// if data is not changed close the tab
 	if domain.CompareRequests(req, reqFromFile) {
+		c.closeWebSocket(id)
 		c.view.CloseTab(id)
 		return
 	}
@@ -813,6 +1241,7 @@ func (c *Controller) onRequestTabClose(id string) {
 				c.saveRequest(id)
 			}
 
+			c.closeWebSocket(id)
 			c.view.CloseTab(id)
 			c.model.ReloadRequest(id)
 			c.view.SetTreeViewNodePrefix(id, reqFromFile)
@@ -880,6 +1309,8 @@ func (c *Controller) OnNewRequest(requestType domain.RequestType) {
 		req = domain.NewGRPCRequest("New Request")
 	case domain.RequestTypeGraphQL:
 		req = domain.NewGraphQLRequest("New Request")
+	case domain.RequestTypeWebSocket:
+		req = domain.NewWebSocketRequest("New Request")
 	default:
 		return
 	}
@@ -922,7 +1353,7 @@ func (c *Controller) OnImport(importType string) {
 			return importer.ImportOpenAPISpec(data, repo)
 		}
 	case "protofile":
-		fileExtension = "proto"
+		fileExtension = "proto," + strings.Join(domain.DescriptorSetExtensions, ",")
 		importFunc = importer.ImportProtoFile
 	default:
 		c.view.showError(fmt.Errorf("unsupported import type: %s", importType))
@@ -1040,13 +1471,15 @@ func (c *Controller) OnTreeViewMenuClicked(id, action string) {
 		case TypeCollection:
 			c.deleteCollection(id)
 		}
-	case MenuAddHTTPRequest, MenuAddGRPCRequest, MenuAddGraphQLRequest:
+	case MenuAddHTTPRequest, MenuAddGRPCRequest, MenuAddGraphQLRequest, MenuAddWebSocketRequest:
 		requestType := domain.RequestTypeHTTP
 		switch action {
 		case MenuAddGRPCRequest:
 			requestType = domain.RequestTypeGRPC
 		case MenuAddGraphQLRequest:
 			requestType = domain.RequestTypeGraphQL
+		case MenuAddWebSocketRequest:
+			requestType = domain.RequestTypeWebSocket
 		}
 
 		c.addRequestToCollection(id, requestType)
@@ -1068,6 +1501,8 @@ func (c *Controller) addRequestToCollection(id string, requestType domain.Reques
 		req = domain.NewGRPCRequest("New Request")
 	case domain.RequestTypeGraphQL:
 		req = domain.NewGraphQLRequest("New Request")
+	case domain.RequestTypeWebSocket:
+		req = domain.NewWebSocketRequest("New Request")
 	default:
 		return
 	}
@@ -1232,6 +1667,7 @@ func (c *Controller) deleteRequest(id string) {
 		return
 	}
 
+	c.closeWebSocket(id)
 	c.view.RemoveTreeViewNode(id)
 	c.view.CloseTab(id)
 }
@@ -1,6 +1,8 @@
 package graphql
 
 import (
+	"slices"
+
 	"gioui.org/layout"
 	"gioui.org/unit"
 	"gioui.org/widget"
@@ -13,21 +15,34 @@ import (
 type AddressBar struct {
 	url *widgets.PatternEditor
 
+	// operations are the named operations of the query, the selector is shown when there's more than one
+	operations        []string
+	operationDropDown *widgets.DropDown
+
 	sendClickable widget.Clickable
 
-	onURLChanged func(url string)
-	onSubmit     func()
+	// loading is true while a request is in flight, the send button turns into a cancel button.
+	loading bool
+
+	onURLChanged       func(url string)
+	onOperationChanged func(name string)
+	onSubmit           func()
+	onCancel           func()
 }
 
 func NewAddressBar(url string) *AddressBar {
 	a := &AddressBar{
-		url: widgets.NewPatternEditor(),
+		url:               widgets.NewPatternEditor(),
+		operationDropDown: widgets.NewDropDownWithoutBorder(),
 	}
 
 	a.url.SingleLine = true
 	a.url.Submit = true
 	a.url.SetText(url)
 
+	a.operationDropDown.MinWidth = unit.Dp(160)
+	a.operationDropDown.MaxWidth = unit.Dp(200)
+
 	return a
 }
 
@@ -35,21 +50,54 @@ func (a *AddressBar) SetOnURLChanged(onURLChanged func(url string)) {
 	a.onURLChanged = onURLChanged
 }
 
+func (a *AddressBar) SetOnOperationChanged(onOperationChanged func(name string)) {
+	a.onOperationChanged = onOperationChanged
+}
+
 func (a *AddressBar) SetOnSubmit(onSubmit func()) {
 	a.onSubmit = onSubmit
 }
 
+func (a *AddressBar) SetOnCancel(onCancel func()) {
+	a.onCancel = onCancel
+}
+
+func (a *AddressBar) SetLoading(loading bool) {
+	a.loading = loading
+}
+
 func (a *AddressBar) SetURL(url string) {
 	a.url.SetText(url)
 }
 
+// SetOperations sets the named operations of the query and the selected one.
+func (a *AddressBar) SetOperations(names []string, selected string) {
+	if !slices.Equal(names, a.operations) {
+		a.operations = names
+		opts := make([]*widgets.DropDownOption, 0, len(names))
+		for _, name := range names {
+			opts = append(opts, widgets.NewDropDownOption(name).WithValue(name))
+		}
+		a.operationDropDown.SetOptions(opts...)
+	}
+
+	if i := slices.Index(names, selected); i >= 0 {
+		a.operationDropDown.SetSelected(i)
+	}
+}
+
 func (a *AddressBar) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	if a.url.Changed() && a.onURLChanged != nil {
 		a.onURLChanged(a.url.Text())
 	}
-	if a.url.Submitted() && a.onSubmit != nil {
+	if a.url.Submitted() && !a.loading && a.onSubmit != nil {
 		a.onSubmit()
 	}
+	if a.operationDropDown.Changed() && a.onOperationChanged != nil {
+		if selected := a.operationDropDown.GetSelected(); selected != nil {
+			a.onOperationChanged(selected.GetValue())
+		}
+	}
 
 	borderColor := theme.BorderColor
 	if gtx.Focused(a.url) {
@@ -81,14 +129,43 @@ func (a *AddressBar) Layout(gtx layout.Context, theme *chapartheme.Theme) layout
 				})
 			})
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if len(a.operations) < 2 {
+				return layout.Dimensions{}
+			}
+
+			return layout.Inset{Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return widget.Border{
+					Color:        theme.BorderColor,
+					Width:        unit.Dp(1),
+					CornerRadius: unit.Dp(4),
+				}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.Y = gtx.Dp(40)
+					return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return a.operationDropDown.Layout(gtx, theme)
+					})
+				})
+			})
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			if a.sendClickable.Clicked(gtx) {
-				if a.onSubmit != nil {
+				if a.loading {
+					if a.onCancel != nil {
+						a.onCancel()
+					}
+				} else if a.onSubmit != nil {
 					a.onSubmit()
 				}
 			}
 
 			gtx.Constraints.Min.X = gtx.Dp(80)
+			if a.loading {
+				btn := material.Button(theme.Material(), &a.sendClickable, "Cancel")
+				btn.Background = theme.DeleteButtonBgColor
+				btn.Color = theme.ButtonTextColor
+				return btn.Layout(gtx)
+			}
+
 			btn := material.Button(theme.Material(), &a.sendClickable, "Send")
 			btn.Background = theme.ActionButtonBgColor
 			btn.Color = theme.ButtonTextColor
@@ -0,0 +1,346 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/gql"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Docs is the documentation explorer of the schema, it lists the root types and the types matching the
+// search and shows the fields of the selected type with links to their types.
+type Docs struct {
+	schema *gql.Schema
+
+	search *widgets.TextField
+	// history is the stack of the visited types, the last one is shown
+	history    []string
+	backButton widget.Clickable
+
+	rows  []docRow
+	links []widget.Clickable
+	list  widget.List
+
+	// rowsKey is the page and search the rows were built for
+	rowsKey string
+}
+
+// docRow is a line of the explorer, target is the type its link opens.
+type docRow struct {
+	header      bool
+	title       string
+	typ         string
+	target      string
+	description string
+	deprecated  string
+}
+
+func NewDocs() *Docs {
+	d := &Docs{
+		search: widgets.NewTextField("", "Search types"),
+	}
+	d.search.SetIcon(widgets.SearchIcon, widgets.IconPositionEnd)
+	d.list.Axis = layout.Vertical
+	return d
+}
+
+func (d *Docs) SetSchema(schema *gql.Schema) {
+	d.schema = schema
+	d.rowsKey = ""
+
+	// the visited types may be gone from the new schema
+	d.history = d.history[:0]
+}
+
+func (d *Docs) current() string {
+	if len(d.history) == 0 {
+		return ""
+	}
+	return d.history[len(d.history)-1]
+}
+
+func (d *Docs) open(name string) {
+	if d.schema.Type(name) == nil || name == d.current() {
+		return
+	}
+	d.history = append(d.history, name)
+	d.list.Position.First = 0
+	d.list.Position.Offset = 0
+}
+
+func (d *Docs) buildRows() {
+	key := d.current() + "\x00" + d.search.GetText()
+	if key == d.rowsKey {
+		return
+	}
+	d.rowsKey = key
+	d.rows = d.rows[:0]
+
+	if d.current() == "" {
+		d.buildRootRows()
+	} else {
+		d.buildTypeRows(d.schema.Type(d.current()))
+	}
+
+	if len(d.links) < len(d.rows) {
+		d.links = make([]widget.Clickable, len(d.rows))
+	}
+}
+
+func (d *Docs) buildRootRows() {
+	search := strings.ToLower(strings.TrimSpace(d.search.GetText()))
+	if search == "" {
+		d.rows = append(d.rows, docRow{header: true, title: "Root Types"})
+		for _, root := range []struct{ op, name string }{
+			{"query", d.schema.QueryType},
+			{"mutation", d.schema.MutationType},
+			{"subscription", d.schema.SubscriptionType},
+		} {
+			if root.name != "" {
+				d.rows = append(d.rows, docRow{title: root.op, typ: root.name, target: root.name})
+			}
+		}
+	}
+
+	d.rows = append(d.rows, docRow{header: true, title: "All Types"})
+	for _, name := range d.schema.TypeNames() {
+		if search != "" && !strings.Contains(strings.ToLower(name), search) {
+			continue
+		}
+
+		t := d.schema.Types[name]
+		d.rows = append(d.rows, docRow{typ: name, target: name, description: firstLine(t.Description)})
+	}
+}
+
+func (d *Docs) buildTypeRows(t *gql.Type) {
+	if t == nil {
+		return
+	}
+
+	d.rows = append(d.rows, docRow{header: true, title: t.Name, description: t.Description, typ: strings.ToLower(t.Kind)})
+
+	if len(t.Interfaces) > 0 {
+		d.rows = append(d.rows, docRow{header: true, title: "Implements"})
+		for _, name := range t.Interfaces {
+			d.rows = append(d.rows, docRow{typ: name, target: name})
+		}
+	}
+
+	if len(t.Fields) > 0 {
+		d.rows = append(d.rows, docRow{header: true, title: "Fields"})
+		for _, f := range t.Fields {
+			d.rows = append(d.rows, docRow{
+				title:       f.Name + formatArgs(f.Args),
+				typ:         f.Type.String(),
+				target:      f.Type.NamedType(),
+				description: f.Description,
+				deprecated:  f.DeprecationReason,
+			})
+		}
+	}
+
+	if len(t.InputFields) > 0 {
+		d.rows = append(d.rows, docRow{header: true, title: "Input Fields"})
+		for _, f := range t.InputFields {
+			title := f.Name
+			if f.DefaultValue != "" {
+				title += " = " + f.DefaultValue
+			}
+			d.rows = append(d.rows, docRow{title: title, typ: f.Type.String(), target: f.Type.NamedType(), description: f.Description})
+		}
+	}
+
+	if len(t.EnumValues) > 0 {
+		d.rows = append(d.rows, docRow{header: true, title: "Values"})
+		for _, v := range t.EnumValues {
+			d.rows = append(d.rows, docRow{title: v.Name, description: v.Description, deprecated: v.DeprecationReason})
+		}
+	}
+
+	if len(t.PossibleTypes) > 0 {
+		title := "Possible Types"
+		if t.Kind == gql.KindInterface {
+			title = "Implementations"
+		}
+		d.rows = append(d.rows, docRow{header: true, title: title})
+		for _, name := range t.PossibleTypes {
+			d.rows = append(d.rows, docRow{typ: name, target: name})
+		}
+	}
+}
+
+// formatArgs returns the arguments of a field as they're written in the schema.
+func formatArgs(args []*gql.InputValue) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	out := make([]string, 0, len(args))
+	for _, a := range args {
+		arg := fmt.Sprintf("%s: %s", a.Name, a.Type)
+		if a.DefaultValue != "" {
+			arg += " = " + a.DefaultValue
+		}
+		out = append(out, arg)
+	}
+	return "(" + strings.Join(out, ", ") + ")"
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func (d *Docs) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if d.schema == nil {
+		return layout.Inset{Top: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			lb := material.Label(theme.Material(), theme.TextSize, "No schema loaded, load it from the server or a schema file in the Query tab.")
+			lb.Color = theme.TextColor
+			return lb.Layout(gtx)
+		})
+	}
+
+	if d.backButton.Clicked(gtx) && len(d.history) > 0 {
+		d.history = d.history[:len(d.history)-1]
+	}
+
+	for i := range d.rows {
+		if d.links[i].Clicked(gtx) {
+			d.open(d.rows[i].target)
+		}
+	}
+
+	d.buildRows()
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				if len(d.history) > 0 {
+					back := "Back"
+					if len(d.history) > 1 {
+						back = d.history[len(d.history)-2]
+					}
+					btn := widgets.Button(theme, &d.backButton, nil, widgets.IconPositionStart, "< "+back)
+					btn.Inset = layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(8), Right: unit.Dp(8)}
+					return btn.Layout(gtx, theme)
+				}
+
+				gtx.Constraints.Max.X = gtx.Dp(unit.Dp(320))
+				return d.search.Layout(gtx, theme)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(theme.Material(), &d.list).Layout(gtx, len(d.rows), func(gtx layout.Context, i int) layout.Dimensions {
+				return d.rowLayout(gtx, theme, i)
+			})
+		}),
+	)
+}
+
+func (d *Docs) rowLayout(gtx layout.Context, theme *chapartheme.Theme, i int) layout.Dimensions {
+	row := d.rows[i]
+
+	if row.header {
+		return layout.Inset{Top: unit.Dp(10), Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Baseline}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lb := material.Label(theme.Material(), unit.Sp(15), row.title)
+							lb.Color = theme.TextColor
+							lb.Font.Weight = font.Bold
+							return lb.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if row.typ == "" {
+								return layout.Dimensions{}
+							}
+							return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, mutedLabel(theme, row.typ))
+						}),
+					)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if row.description == "" {
+						return layout.Dimensions{}
+					}
+					return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, mutedLabel(theme, row.description))
+				}),
+			)
+		})
+	}
+
+	return layout.Inset{Top: unit.Dp(3), Bottom: unit.Dp(3), Left: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Baseline}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if row.title == "" {
+							return layout.Dimensions{}
+						}
+
+						title := row.title
+						if row.typ != "" {
+							title += ": "
+						}
+						lb := material.Label(theme.Material(), unit.Sp(13), title)
+						lb.Color = theme.TextColor
+						if row.deprecated != "" {
+							lb.Color.A = 0x80
+						}
+						return lb.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if row.typ == "" {
+							return layout.Dimensions{}
+						}
+
+						return d.links[i].Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							lb := material.Label(theme.Material(), unit.Sp(13), row.typ)
+							lb.Color = theme.InfoColor
+							if d.links[i].Hovered() {
+								lb.Font.Weight = font.Bold
+							}
+							return lb.Layout(gtx)
+						})
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if row.deprecated == "" {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: unit.Dp(2)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					lb := material.Label(theme.Material(), unit.Sp(12), "Deprecated: "+row.deprecated)
+					lb.Color = theme.WarningColor
+					return lb.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if row.description == "" {
+					return layout.Dimensions{}
+				}
+				return layout.Inset{Top: unit.Dp(2)}.Layout(gtx, mutedLabel(theme, row.description))
+			}),
+		)
+	})
+}
+
+func mutedLabel(theme *chapartheme.Theme, text string) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		lb := material.Label(theme.Material(), unit.Sp(12), text)
+		lb.Color = theme.TextColor
+		lb.Color.A = 0xa0
+		return lb.Layout(gtx)
+	}
+}
@@ -1,11 +1,14 @@
 package graphql
 
 import (
+	"slices"
+
 	"gioui.org/layout"
 	"gioui.org/unit"
 	giox "gioui.org/x/component"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/gql"
 	"github.com/chapar-rest/chapar/internal/prefs"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
 	"github.com/chapar-rest/chapar/ui/explorer"
@@ -30,6 +33,11 @@ type GraphQL struct {
 	onSave        func(id string)
 	onDataChanged func(id string, data any)
 	onSubmit      func(id string)
+	onCancel      func(id string)
+	onLoadSchema  func(id string, refresh bool)
+
+	// schemaRequested is set once the cached schema is asked for on the first layout
+	schemaRequested bool
 }
 
 func (g *GraphQL) SetOnTitleChanged(f func(title string)) {
@@ -67,6 +75,7 @@ func New(req *domain.Request, theme *chapartheme.Theme, explorer *explorer.Explo
 		Response:   NewResponse(theme),
 	}
 
+	g.updateOperations(req.Spec.GraphQL.Query)
 	g.setupHooks()
 
 	return g
@@ -80,14 +89,30 @@ func (g *GraphQL) setupHooks() {
 		g.onDataChanged(g.Req.MetaData.ID, clone)
 	})
 
+	g.AddressBar.SetOnOperationChanged(func(name string) {
+		clone := g.Req.Clone()
+		clone.Spec.GraphQL.OperationName = name
+		g.Req.Spec.GraphQL.OperationName = name
+		g.onDataChanged(g.Req.MetaData.ID, clone)
+	})
+
 	g.AddressBar.SetOnSubmit(func() {
 		g.onSubmit(g.Req.MetaData.ID)
 	})
 
+	g.AddressBar.SetOnCancel(func() {
+		if g.onCancel != nil {
+			g.onCancel(g.Req.MetaData.ID)
+		}
+	})
+
 	g.Request.Query.SetOnChanged(func(data string) {
+		operationName := g.updateOperations(data)
 		clone := g.Req.Clone()
 		clone.Spec.GraphQL.Query = data
+		clone.Spec.GraphQL.OperationName = operationName
 		g.Req.Spec.GraphQL.Query = data
+		g.Req.Spec.GraphQL.OperationName = operationName
 		g.onDataChanged(g.Req.MetaData.ID, clone)
 	})
 
@@ -147,6 +172,52 @@ func (g *GraphQL) setupHooks() {
 		g.onDataChanged(g.Req.MetaData.ID, clone)
 	})
 
+	g.Request.Proxy.SetOnChange(func(proxy *domain.ProxyConfig) {
+		clone := g.Req.Clone()
+		clone.Spec.GraphQL.Proxy = proxy
+		g.Req.Spec.GraphQL.Proxy = proxy
+		g.onDataChanged(g.Req.MetaData.ID, clone)
+	})
+
+	g.Request.OnSettingsChanged = func(values map[string]any) {
+		transport, _ := values["subscriptionTransport"].(string)
+		persistedQueries, _ := values["persistedQueries"].(bool)
+
+		clone := g.Req.Clone()
+		clone.Spec.GraphQL.SubscriptionTransport = transport
+		clone.Spec.GraphQL.PersistedQueries = persistedQueries
+		g.Req.Spec.GraphQL.SubscriptionTransport = transport
+		g.Req.Spec.GraphQL.PersistedQueries = persistedQueries
+		g.onDataChanged(g.Req.MetaData.ID, clone)
+	}
+
+	g.Request.Uploads.SetOnChanged(func(uploads []domain.GraphQLUpload) {
+		clone := g.Req.Clone()
+		clone.Spec.GraphQL.Uploads = uploads
+		g.Req.Spec.GraphQL.Uploads = uploads
+		g.onDataChanged(g.Req.MetaData.ID, clone)
+	})
+
+	g.Response.SetOnStopSubscription(func() {
+		if g.onCancel != nil {
+			g.onCancel(g.Req.MetaData.ID)
+		}
+	})
+
+	g.Response.SetOnRestartSubscription(func() {
+		g.onSubmit(g.Req.MetaData.ID)
+	})
+
+	g.Request.SchemaFile.SetOnChanged(func(filePath string) {
+		clone := g.Req.Clone()
+		clone.Spec.GraphQL.SchemaFile = filePath
+		g.Req.Spec.GraphQL.SchemaFile = filePath
+		g.onDataChanged(g.Req.MetaData.ID, clone)
+		g.loadSchema(false)
+	})
+
+	g.Request.OnLoadSchema = g.loadSchema
+
 	prefs.AddGlobalConfigChangeListener(func(old, updated domain.GlobalConfig) {
 		isChanged := old.Spec.General.UseHorizontalSplit != updated.Spec.General.UseHorizontalSplit
 		if isChanged {
@@ -159,6 +230,29 @@ func (g *GraphQL) setupHooks() {
 	})
 }
 
+// updateOperations lists the operations of the query in the address bar and returns the selected one,
+// the first operation is selected when the selected one is gone. queries which don't parse keep the last
+// selection.
+func (g *GraphQL) updateOperations(query string) string {
+	selected := g.Req.Spec.GraphQL.OperationName
+
+	doc, err := gql.ParseQuery(query)
+	if err != nil {
+		return selected
+	}
+
+	names := doc.OperationNames()
+	if !slices.Contains(names, selected) {
+		selected = ""
+		if len(names) > 0 {
+			selected = names[0]
+		}
+	}
+
+	g.AddressBar.SetOperations(names, selected)
+	return selected
+}
+
 func (g *GraphQL) SetOnRequestTabChange(f func(id, tab string)) {
 	g.Request.OnTabChange = func(title string) {
 		f(g.Req.MetaData.ID, title)
@@ -197,6 +291,32 @@ func (g *GraphQL) SetOnSubmit(f func(id string)) {
 	g.onSubmit = f
 }
 
+func (g *GraphQL) SetOnCancel(f func(id string)) {
+	g.onCancel = f
+}
+
+func (g *GraphQL) SetOnLoadSchema(f func(id string, refresh bool)) {
+	g.onLoadSchema = f
+}
+
+func (g *GraphQL) loadSchema(refresh bool) {
+	if g.onLoadSchema != nil {
+		g.onLoadSchema(g.Req.MetaData.ID, refresh)
+	}
+}
+
+func (g *GraphQL) SetSchema(schema *gql.Schema, err error) {
+	g.Request.SetSchema(schema, err)
+}
+
+func (g *GraphQL) SetSchemaLoading() {
+	g.Request.SetSchemaLoading()
+}
+
+func (g *GraphQL) SetOnClearOAuth2Token(f func()) {
+	g.Request.Auth.SetOnClearToken(f)
+}
+
 func (g *GraphQL) SetOnCopyResponse(f func(gtx layout.Context, dataType, data string)) {
 	g.Response.SetOnCopyResponse(f)
 }
@@ -207,6 +327,29 @@ func (g *GraphQL) SetGraphQLResponse(detail domain.GraphQLResponseDetail) {
 	g.Response.SetHeaders(detail.RequestHeaders, detail.ResponseHeaders)
 	g.Response.SetError(detail.Error)
 	g.Response.SetStatusParams(detail.StatusCode, detail.Duration, detail.Size)
+	g.Response.SetTiming(detail.Timing)
+	g.Response.SetTests(detail.Tests)
+}
+
+func (g *GraphQL) StartSubscription() {
+	g.Response.StartSubscription()
+}
+
+func (g *GraphQL) AddSubscriptionMessage(msg domain.GraphQLSubscriptionMessage) {
+	g.Response.AddSubscriptionMessage(msg)
+}
+
+func (g *GraphQL) EndSubscription(err error) {
+	g.Response.EndSubscription(err)
+}
+
+func (g *GraphQL) StopSubscription() {
+	g.Response.StopSubscription()
+}
+
+// SetTests shows the results of the tests of the post request script, subscriptions get them once they end.
+func (g *GraphQL) SetTests(tests []domain.TestResult) {
+	g.Response.SetTests(tests)
 }
 
 func (g *GraphQL) GetGraphQLResponse() *domain.GraphQLResponseDetail {
@@ -238,13 +381,19 @@ func (g *GraphQL) ShowPrompt(title, content, modalType string, onSubmit func(sel
 }
 
 func (g *GraphQL) ShowSendingRequestLoading() {
+	g.AddressBar.SetLoading(true)
 	g.Response.SetMessage("Sending request...")
 }
 
 func (g *GraphQL) HideSendingRequestLoading() {
+	g.AddressBar.SetLoading(false)
 	g.Response.SetMessage("")
 }
 
+func (g *GraphQL) SetCancelled() {
+	g.Response.SetCancelled()
+}
+
 func (g *GraphQL) SetURL(url string) {
 	g.AddressBar.SetURL(url)
 	g.Req.Spec.GraphQL.URL = url
@@ -266,6 +415,11 @@ func (g *GraphQL) SetCollection(collection *domain.Collection) {
 func (g *GraphQL) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	g.Prompt.Layout(gtx, theme)
 
+	if !g.schemaRequested && g.onLoadSchema != nil {
+		g.schemaRequested = true
+		g.loadSchema(false)
+	}
+
 	if g.Actions.IsDataChanged && g.Actions.SaveButton.Clicked(gtx) && g.onSave != nil {
 		g.onSave(g.Req.MetaData.ID)
 		g.Actions.IsDataChanged = false
@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/dustin/go-humanize"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/pages/requests/component"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Messages shows the payloads of a subscription as they arrive.
+type Messages struct {
+	// messages are added from the request goroutine while the list is laid out
+	mu        sync.Mutex
+	messages  []domain.GraphQLSubscriptionMessage
+	streaming bool
+	stopped   bool
+	err       error
+
+	list          widget.List
+	stopButton    widget.Clickable
+	restartButton widget.Clickable
+
+	onStop    func()
+	onRestart func()
+}
+
+func NewMessages() *Messages {
+	return &Messages{
+		list: widget.List{
+			List: layout.List{Axis: layout.Vertical, ScrollToEnd: true},
+		},
+	}
+}
+
+func (m *Messages) SetOnStop(f func()) {
+	m.onStop = f
+}
+
+func (m *Messages) SetOnRestart(f func()) {
+	m.onRestart = f
+}
+
+// Start marks the beginning of a subscription, the messages of the previous one are dropped.
+func (m *Messages) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = nil
+	m.streaming = true
+	m.stopped = false
+	m.err = nil
+}
+
+func (m *Messages) Add(msg domain.GraphQLSubscriptionMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+}
+
+// End marks the end of the subscription, err is nil when the server completed it.
+func (m *Messages) End(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streaming = false
+	m.err = err
+}
+
+// Stop marks the subscription as stopped by the user.
+func (m *Messages) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streaming = false
+	m.stopped = true
+}
+
+// String returns the payloads separated by blank lines, used to copy them.
+func (m *Messages) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	payloads := make([]string, 0, len(m.messages))
+	for _, msg := range m.messages {
+		payloads = append(payloads, msg.Payload)
+	}
+	return strings.Join(payloads, "\n\n")
+}
+
+func (m *Messages) status() string {
+	count := fmt.Sprintf("%d messages", len(m.messages))
+	switch {
+	case m.streaming:
+		return "Subscribed, " + count
+	case m.stopped:
+		return "Stopped, " + count
+	case m.err != nil:
+		return fmt.Sprintf("Subscription failed: %s, %s", m.err, count)
+	default:
+		return "Completed by the server, " + count
+	}
+}
+
+func (m *Messages) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.streaming && !m.stopped && m.err == nil && len(m.messages) == 0 {
+		return component.Message(gtx, component.MessageTypeInfo, theme, "Payloads of subscription operations are shown here")
+	}
+
+	if m.stopButton.Clicked(gtx) && m.streaming && m.onStop != nil {
+		m.onStop()
+	}
+
+	if m.restartButton.Clicked(gtx) && !m.streaming && m.onRestart != nil {
+		m.onRestart()
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+					layout.Flexed(1, material.Label(theme.Material(), theme.TextSize, m.status()).Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if m.streaming {
+							btn := widgets.Button(theme, &m.stopButton, widgets.CloseIcon, widgets.IconPositionStart, "Stop")
+							btn.Background = theme.DeleteButtonBgColor
+							return btn.Layout(gtx, theme)
+						}
+
+						btn := widgets.Button(theme, &m.restartButton, widgets.RefreshIcon, widgets.IconPositionStart, "Restart")
+						return btn.Layout(gtx, theme)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(theme.Material(), &m.list).Layout(gtx, len(m.messages), func(gtx layout.Context, i int) layout.Dimensions {
+				return m.messageLayout(gtx, theme, i, m.messages[i])
+			})
+		}),
+	)
+}
+
+func (m *Messages) messageLayout(gtx layout.Context, theme *chapartheme.Theme, i int, msg domain.GraphQLSubscriptionMessage) layout.Dimensions {
+	header := fmt.Sprintf("#%d  %s  %s", i+1, humanize.Bytes(uint64(msg.Size)), msg.Time.Format("15:04:05.000"))
+
+	return layout.Inset{Bottom: unit.Dp(8), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), unit.Sp(12), header)
+				lb.Font.Weight = font.Bold
+				lb.Color = theme.ResponseStatusColor
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), theme.TextSize, msg.Payload)
+				lb.Font.Typeface = theme.Face
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return widgets.DrawLine(gtx, theme.SeparatorColor, unit.Dp(1), unit.Dp(gtx.Metric.PxToDp(gtx.Constraints.Max.X)))
+				})
+			}),
+		)
+	})
+}
@@ -1,10 +1,17 @@
 package graphql
 
 import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"gioui.org/layout"
 	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/gql"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
 	"github.com/chapar-rest/chapar/ui/explorer"
 	"github.com/chapar-rest/chapar/ui/pages/requests/component"
@@ -23,11 +30,33 @@ type Request struct {
 	Headers       *component.Headers
 	VariablesList *component.Variables
 	Auth          *component.Auth
+	Proxy         *component.Proxy
+	Docs          *Docs
+	Uploads       *Uploads
+	Settings      *widgets.Settings
+
+	SchemaFile       *widgets.FileSelector
+	LoadSchemaButton widget.Clickable
+
+	schema        *gql.Schema
+	schemaErr     error
+	schemaLoading bool
+
+	// validationErrors are the errors of validatedQuery against the schema
+	validationErrors []*gql.Error
+	validatedQuery   string
+	validatedSchema  *gql.Schema
 
-	currentTab  string
-	OnTabChange func(title string)
+	currentTab   string
+	OnTabChange  func(title string)
+	OnLoadSchema func(refresh bool)
+
+	OnSettingsChanged func(values map[string]any)
 }
 
+// maxValidationErrors is the number of validation errors listed under the query editor.
+const maxValidationErrors = 5
+
 func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapartheme.Theme) *Request {
 	postRequestDropDown := widgets.NewDropDown(
 		widgets.NewDropDownOption("From Response").WithValue(domain.PostRequestSetFromResponseBody),
@@ -39,29 +68,46 @@ func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapart
 		Tabs: widgets.NewTabs([]*widgets.Tab{
 			{Title: "Query"},
 			{Title: "Variables"},
+			{Title: "Files"},
+			{Title: "Docs"},
 			{Title: "Headers"},
 			{Title: "Auth"},
 			{Title: "Pre Request"},
 			{Title: "Post Request"},
+			{Title: "Settings"},
 		}, nil),
 		PreRequest: component.NewPrePostRequest([]component.Option{
 			{Title: "None", Value: domain.PrePostTypeNone},
 			{Title: "Trigger request", Value: domain.PrePostTypeTriggerRequest, Type: component.TypeTriggerRequest, Hint: "Trigger another request"},
 			{Title: "Python", Value: domain.PrePostTypePython, Type: component.TypeScript, Hint: "Write your pre request python script here"},
+			{Title: "JavaScript", Value: domain.PrePostTypeJavaScript, Type: component.TypeScript, Hint: "Write your pre request javascript script here"},
 		}, nil, theme),
 		PostRequest: component.NewPrePostRequest([]component.Option{
 			{Title: "None", Value: domain.PrePostTypeNone},
 			{Title: "Set Environment Variable", Value: domain.PrePostTypeSetEnv, Type: component.TypeSetEnv, Hint: "Set environment variable"},
 			{Title: "Python", Value: domain.PrePostTypePython, Type: component.TypeScript, Hint: "Write your post request python script here"},
+			{Title: "JavaScript", Value: domain.PrePostTypeJavaScript, Type: component.TypeScript, Hint: "Write your post request javascript script here"},
 		}, postRequestDropDown, theme),
 		Query:         codeeditor.NewCodeEditor("", codeeditor.CodeLanguageGraphQL, theme),
 		Variables:     codeeditor.NewCodeEditor("{}", codeeditor.CodeLanguageJSON, theme),
 		Headers:       component.NewHeaders(nil),
 		VariablesList: component.NewVariables(theme, domain.RequestTypeGraphQL),
 		Auth:          component.NewAuth(domain.Auth{}, theme),
+		Proxy:         component.NewProxy(nil),
+		Docs:          NewDocs(),
+		Uploads:       NewUploads(explorer),
+		Settings: widgets.NewSettings([]*widgets.SettingItem{
+			widgets.NewDropDownItem("Subscription transport", "subscriptionTransport", "How subscription operations are sent, websocket speaks graphql-transport-ws and the legacy subscriptions-transport-ws.", domain.GraphQLSubscriptionTransportWebSocket,
+				widgets.NewDropDownOption("WebSocket").WithValue(domain.GraphQLSubscriptionTransportWebSocket),
+				widgets.NewDropDownOption("SSE").WithValue(domain.GraphQLSubscriptionTransportSSE),
+			),
+			widgets.NewBoolItem("Automatic persisted queries", "persistedQueries", "Send the sha256 hash of the query first and the query itself only when the server doesn't know the hash yet.", false),
+		}),
+		SchemaFile: widgets.NewFileSelector("", explorer, "graphql", "graphqls", "gql", "json"),
 	}
 
 	r.Variables.WithBeautifier(true)
+	r.Query.SetCompleter(r.complete)
 
 	if req.Spec != (domain.RequestSpec{}) && req.Spec.GraphQL != nil {
 		r.Query.SetCode(req.Spec.GraphQL.Query)
@@ -91,11 +137,131 @@ func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapart
 		if req.Spec.GraphQL.VariablesList != nil {
 			r.VariablesList.SetValues(req.Spec.GraphQL.VariablesList)
 		}
+
+		r.Proxy.SetProxy(req.Spec.GraphQL.Proxy)
+		r.SchemaFile.SetFileName(req.Spec.GraphQL.SchemaFile)
+		r.Uploads.SetValues(req.Spec.GraphQL.Uploads)
+		r.Settings.SetValues(map[string]any{"persistedQueries": req.Spec.GraphQL.PersistedQueries})
+		if req.Spec.GraphQL.SubscriptionTransport != "" {
+			r.Settings.SetValues(map[string]any{"subscriptionTransport": req.Spec.GraphQL.SubscriptionTransport})
+		}
 	}
 
 	return r
 }
 
+func (r *Request) SetSchema(schema *gql.Schema, err error) {
+	r.schema = schema
+	r.schemaErr = err
+	r.schemaLoading = false
+	r.Docs.SetSchema(schema)
+}
+
+func (r *Request) SetSchemaLoading() {
+	r.schemaLoading = true
+	r.schemaErr = nil
+}
+
+// complete returns the suggestions of the schema for the query editor, the editor counts runes and
+// the schema bytes.
+func (r *Request) complete(text string, offset int) (int, []codeeditor.Suggestion) {
+	if r.schema == nil {
+		return 0, nil
+	}
+
+	runes := []rune(text)
+	offset = min(offset, len(runes))
+	byteOffset := len(string(runes[:offset]))
+
+	start, suggestions := gql.Complete(r.schema, text, byteOffset)
+	out := make([]codeeditor.Suggestion, 0, len(suggestions))
+	for _, sg := range suggestions {
+		out = append(out, codeeditor.Suggestion{Label: sg.Label, Detail: sg.Detail, Insert: sg.Insert})
+	}
+	return utf8.RuneCountInString(text[:start]), out
+}
+
+// validate checks the query against the schema when either of them has changed since the last check.
+func (r *Request) validate() {
+	query := r.Query.Code()
+	if query == r.validatedQuery && r.schema == r.validatedSchema {
+		return
+	}
+
+	r.validatedQuery = query
+	r.validatedSchema = r.schema
+	r.validationErrors = nil
+	if r.schema == nil || strings.TrimSpace(query) == "" {
+		return
+	}
+
+	r.validationErrors = gql.Validate(r.schema, query)
+}
+
+func (r *Request) schemaStatus() string {
+	switch {
+	case r.schemaLoading:
+		return "Loading schema..."
+	case r.schemaErr != nil:
+		return r.schemaErr.Error()
+	case r.schema != nil:
+		return fmt.Sprintf("Schema: %d types", len(r.schema.Types))
+	default:
+		return "No schema"
+	}
+}
+
+func (r *Request) schemaToolbarLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if r.LoadSchemaButton.Clicked(gtx) && r.OnLoadSchema != nil && !r.schemaLoading {
+		r.OnLoadSchema(true)
+	}
+
+	return layout.Inset{Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), unit.Sp(12), r.schemaStatus())
+				lb.Color = theme.TextColor
+				if r.schemaErr != nil {
+					lb.Color = theme.ErrorColor
+				}
+				lb.MaxLines = 1
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Left: unit.Dp(5), Right: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return r.SchemaFile.Layout(gtx, theme)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				btn := widgets.Button(theme, &r.LoadSchemaButton, widgets.RefreshIcon, widgets.IconPositionStart, "Load Schema")
+				btn.Inset = layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(8), Right: unit.Dp(8)}
+				return btn.Layout(gtx, theme)
+			}),
+		)
+	})
+}
+
+func (r *Request) validationErrorsLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if len(r.validationErrors) == 0 {
+		return layout.Dimensions{}
+	}
+
+	lines := make([]string, 0, maxValidationErrors+1)
+	for i, err := range r.validationErrors {
+		if i == maxValidationErrors {
+			lines = append(lines, fmt.Sprintf("and %d more", len(r.validationErrors)-maxValidationErrors))
+			break
+		}
+		lines = append(lines, err.Error())
+	}
+
+	return layout.Inset{Top: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		lb := material.Label(theme.Material(), unit.Sp(12), strings.Join(lines, "\n"))
+		lb.Color = theme.ErrorColor
+		return lb.Layout(gtx)
+	})
+}
+
 func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	inset := layout.Inset{Top: unit.Dp(10)}
 	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -120,19 +286,51 @@ func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Di
 				case "Post Request":
 					return r.PostRequest.Layout(gtx, theme)
 				case "Query":
+					r.validate()
 					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-						return r.Query.Layout(gtx, theme, "GraphQL Query")
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return r.schemaToolbarLayout(gtx, theme)
+							}),
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return r.Query.Layout(gtx, theme, "GraphQL Query")
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return r.validationErrorsLayout(gtx, theme)
+							}),
+						)
 					})
 				case "Variables":
 					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 						return r.Variables.Layout(gtx, theme, "Variables (JSON)")
 					})
+				case "Files":
+					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return r.Uploads.Layout(gtx, theme)
+					})
+				case "Docs":
+					return layout.Inset{Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return r.Docs.Layout(gtx, theme)
+					})
 				case "Headers":
 					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 						return r.Headers.Layout(gtx, theme)
 					})
 				case "Auth":
 					return r.Auth.Layout(gtx, theme)
+				case "Settings":
+					if r.Settings.Changed() && r.OnSettingsChanged != nil {
+						r.OnSettingsChanged(r.Settings.GetValues())
+					}
+
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return r.Settings.Layout(gtx, theme)
+						}),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return r.Proxy.Layout(gtx, theme)
+						}),
+					)
 				default:
 					return layout.Dimensions{}
 				}
@@ -18,6 +18,15 @@ import (
 	"github.com/chapar-rest/chapar/ui/widgets/codeeditor"
 )
 
+// indexes of the response tabs
+const (
+	responseTabBody = iota
+	responseTabHeaders
+	responseTabMessages
+	responseTabTiming
+	responseTabTests
+)
+
 type Response struct {
 	copyButton *widgets.FlatButton
 	Tabs       *widgets.Tabs
@@ -30,10 +39,14 @@ type Response struct {
 
 	responseHeaders *codeeditor.CodeEditor
 	jsonViewer      *codeeditor.CodeEditor
+	timing          *component.Timing
+	messages        *Messages
+	tests           *component.TestResults
 
-	response string
-	message  string
-	err      error
+	response  string
+	message   string
+	err       error
+	cancelled bool
 
 	onCopyResponse func(gtx layout.Context, dataType, data string)
 
@@ -56,9 +69,15 @@ func NewResponse(theme *chapartheme.Theme) *Response {
 		Tabs: widgets.NewTabs([]*widgets.Tab{
 			{Title: "Body"},
 			{Title: "Headers"},
+			{Title: "Messages"},
+			{Title: "Timing"},
+			{Title: "Tests"},
 		}, nil),
 		jsonViewer:      codeeditor.NewCodeEditor("", codeeditor.CodeLanguageJSON, theme),
 		responseHeaders: codeeditor.NewCodeEditor("", codeeditor.CodeLanguageProperties, theme),
+		timing:          component.NewTiming(),
+		messages:        NewMessages(),
+		tests:           component.NewTestResults(),
 	}
 
 	r.jsonViewer.SetReadOnly(true)
@@ -67,6 +86,40 @@ func NewResponse(theme *chapartheme.Theme) *Response {
 	return r
 }
 
+func (r *Response) SetTiming(timing domain.Timing) {
+	r.timing.SetTiming(timing)
+}
+
+func (r *Response) SetTests(tests []domain.TestResult) {
+	r.tests.SetTests(tests)
+}
+
+func (r *Response) SetOnStopSubscription(f func()) {
+	r.messages.SetOnStop(f)
+}
+
+func (r *Response) SetOnRestartSubscription(f func()) {
+	r.messages.SetOnRestart(f)
+}
+
+// StartSubscription switches to the messages tab, the payloads are added as they arrive by AddSubscriptionMessage.
+func (r *Response) StartSubscription() {
+	r.messages.Start()
+	r.Tabs.SetSelected(responseTabMessages)
+}
+
+func (r *Response) AddSubscriptionMessage(msg domain.GraphQLSubscriptionMessage) {
+	r.messages.Add(msg)
+}
+
+func (r *Response) EndSubscription(err error) {
+	r.messages.End(err)
+}
+
+func (r *Response) StopSubscription() {
+	r.messages.Stop()
+}
+
 func (r *Response) SetOnCopyResponse(f func(gtx layout.Context, dataType, data string)) {
 	r.onCopyResponse = f
 }
@@ -75,6 +128,7 @@ func (r *Response) SetResponse(response string) {
 	r.response = response
 	r.err = nil
 	r.message = ""
+	r.cancelled = false
 	r.isResponseUpdated = false
 	r.responseIsAvailable = true
 }
@@ -111,6 +165,15 @@ func (r *Response) SetMessage(message string) {
 
 func (r *Response) SetError(err error) {
 	r.err = err
+	r.cancelled = false
+}
+
+// SetCancelled marks the last request as cancelled by the user, the previous response (if any) is kept
+// but hidden until the next response arrives.
+func (r *Response) SetCancelled() {
+	r.cancelled = true
+	r.err = nil
+	r.message = ""
 }
 
 func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
@@ -122,6 +185,10 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 		return component.Message(gtx, component.MessageTypeInfo, theme, r.message)
 	}
 
+	if r.cancelled {
+		return component.Message(gtx, component.MessageTypeWarning, theme, "Request cancelled")
+	}
+
 	if !r.responseIsAvailable {
 		return component.Message(gtx, component.MessageTypeInfo, theme, "No response available yet ;)")
 	}
@@ -161,8 +228,14 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 				return layout.Inset{Left: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					switch r.Tabs.Selected() {
-					case 1:
+					case responseTabHeaders:
 						return r.responseHeaders.Layout(gtx, theme, "")
+					case responseTabMessages:
+						return r.messages.Layout(gtx, theme)
+					case responseTabTiming:
+						return r.timing.Layout(gtx, theme)
+					case responseTabTests:
+						return r.tests.Layout(gtx, theme)
 					default:
 						if !r.isResponseUpdated {
 							r.jsonViewer.SetCode(r.response)
@@ -183,8 +256,14 @@ func formatStatus(statueCode int, duration time.Duration, size uint64) string {
 
 func (r *Response) handleCopy(gtx layout.Context) {
 	switch r.Tabs.Selected() {
-	case 1:
+	case responseTabHeaders:
 		r.onCopyResponse(gtx, "Headers", r.responseHeaders.Code())
+	case responseTabMessages:
+		r.onCopyResponse(gtx, "Messages", r.messages.String())
+	case responseTabTiming:
+		r.onCopyResponse(gtx, "Timing", r.timing.String())
+	case responseTabTests:
+		r.onCopyResponse(gtx, "Tests", r.tests.String())
 	default:
 		r.onCopyResponse(gtx, "Response", r.response)
 	}
@@ -0,0 +1,163 @@
+package graphql
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/explorer"
+	"github.com/chapar-rest/chapar/ui/keys"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Uploads maps variables to local files, they're sent following the GraphQL multipart request spec.
+type Uploads struct {
+	explorer *explorer.Explorer
+
+	items     []*uploadItem
+	addButton widget.Clickable
+	list      widget.List
+
+	onChanged func(uploads []domain.GraphQLUpload)
+}
+
+type uploadItem struct {
+	variable     *widget.Editor
+	file         *widgets.FileSelector
+	deleteButton widget.Clickable
+}
+
+func NewUploads(explorer *explorer.Explorer) *Uploads {
+	u := &Uploads{explorer: explorer}
+	u.list.Axis = layout.Vertical
+	return u
+}
+
+func (u *Uploads) SetOnChanged(f func(uploads []domain.GraphQLUpload)) {
+	u.onChanged = f
+}
+
+func (u *Uploads) SetValues(uploads []domain.GraphQLUpload) {
+	u.items = u.items[:0]
+	for _, up := range uploads {
+		u.addItem(up)
+	}
+}
+
+func (u *Uploads) GetValues() []domain.GraphQLUpload {
+	out := make([]domain.GraphQLUpload, 0, len(u.items))
+	for _, item := range u.items {
+		out = append(out, domain.GraphQLUpload{Variable: item.variable.Text(), File: item.file.GetFilePath()})
+	}
+	return out
+}
+
+func (u *Uploads) addItem(up domain.GraphQLUpload) {
+	item := &uploadItem{
+		variable: &widget.Editor{SingleLine: true},
+		file:     widgets.NewFileSelector(up.File, u.explorer),
+	}
+	item.variable.SetText(up.Variable)
+	item.file.SetOnChanged(func(string) {
+		u.triggerChanged()
+	})
+	u.items = append(u.items, item)
+}
+
+func (u *Uploads) triggerChanged() {
+	if u.onChanged != nil {
+		u.onChanged(u.GetValues())
+	}
+}
+
+func (u *Uploads) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if u.addButton.Clicked(gtx) {
+		u.addItem(domain.GraphQLUpload{})
+		u.triggerChanged()
+	}
+
+	for i := 0; i < len(u.items); i++ {
+		if u.items[i].deleteButton.Clicked(gtx) {
+			u.items = append(u.items[:i], u.items[i+1:]...)
+			u.triggerChanged()
+			i--
+		}
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						lb := material.Label(theme.Material(), unit.Sp(12), "Variables like file, files.0 or input.avatar are mapped to the files, the request is sent as multipart when there are any.")
+						lb.Color = theme.TextColor
+						return lb.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := widgets.Button(theme, &u.addButton, widgets.PlusIcon, widgets.IconPositionStart, "Add File")
+						btn.Inset = layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(8), Right: unit.Dp(8)}
+						return btn.Layout(gtx, theme)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			if len(u.items) == 0 {
+				return layout.Dimensions{}
+			}
+
+			return widget.Border{
+				Color:        theme.TableBorderColor,
+				CornerRadius: unit.Dp(4),
+				Width:        unit.Dp(1),
+			}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return material.List(theme.Material(), &u.list).Layout(gtx, len(u.items), func(gtx layout.Context, i int) layout.Dimensions {
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return u.itemLayout(gtx, theme, u.items[i])
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if i == len(u.items)-1 {
+								return layout.Dimensions{}
+							}
+							return widgets.DrawLine(gtx, theme.TableBorderColor, unit.Dp(1), unit.Dp(gtx.Constraints.Max.X))
+						}),
+					)
+				})
+			})
+		}),
+	)
+}
+
+func (u *Uploads) itemLayout(gtx layout.Context, theme *chapartheme.Theme, item *uploadItem) layout.Dimensions {
+	keys.OnEditorChange(gtx, item.variable, u.triggerChanged)
+
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				editor := material.Editor(theme.Material(), item.variable, "Variable")
+				editor.SelectionColor = theme.TextSelectionColor
+				return editor.Layout(gtx)
+			})
+		}),
+		widgets.DrawLineFlex(theme.TableBorderColor, unit.Dp(35), unit.Dp(1)),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return item.file.Layout(gtx, theme)
+			})
+		}),
+		widgets.DrawLineFlex(theme.TableBorderColor, unit.Dp(35), unit.Dp(1)),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			ib := widgets.IconButton{
+				Icon:      widgets.DeleteIcon,
+				Size:      unit.Dp(20),
+				Color:     theme.TextColor,
+				Clickable: &item.deleteButton,
+			}
+			return ib.Layout(gtx, theme)
+		}),
+	)
+}
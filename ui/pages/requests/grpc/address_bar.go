@@ -1,6 +1,8 @@
 package grpc
 
 import (
+	"image/color"
+
 	"gioui.org/layout"
 	"gioui.org/unit"
 	"gioui.org/widget"
@@ -20,9 +22,20 @@ type AddressBar struct {
 
 	sendClickable widget.Clickable
 
+	// loading is true while a request is in flight, the send button turns into a cancel button.
+	loading bool
+	// clientStreaming is true when the selected method is client or bidi streaming, invoking it opens the stream.
+	clientStreaming bool
+
+	// connState is the state of the connection to the server, empty when the calls are not made on a grpc connection.
+	connState          string
+	reconnectClickable widget.Clickable
+
 	onServerAddressChanged func(url string)
 	onMethodChanged        func(method string)
 	onSubmit               func()
+	onCancel               func()
+	onReconnect            func()
 }
 
 func NewAddressBar(theme *chapartheme.Theme, address, lastSelectedMethod string, services []domain.GRPCService) *AddressBar {
@@ -80,11 +93,31 @@ func (a *AddressBar) SetOnSubmit(onSubmit func()) {
 	a.onSubmit = onSubmit
 }
 
+func (a *AddressBar) SetOnCancel(onCancel func()) {
+	a.onCancel = onCancel
+}
+
+func (a *AddressBar) SetOnReconnect(onReconnect func()) {
+	a.onReconnect = onReconnect
+}
+
+func (a *AddressBar) SetConnectionState(state string) {
+	a.connState = state
+}
+
+func (a *AddressBar) SetLoading(loading bool) {
+	a.loading = loading
+}
+
+func (a *AddressBar) SetClientStreaming(streaming bool) {
+	a.clientStreaming = streaming
+}
+
 func (a *AddressBar) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	if a.serverAddress.Changed() && a.onServerAddressChanged != nil {
 		a.onServerAddressChanged(a.serverAddress.Text())
 	}
-	if a.serverAddress.Submitted() && a.onSubmit != nil {
+	if a.serverAddress.Submitted() && !a.loading && a.onSubmit != nil {
 		a.onSubmit()
 	}
 	if a.methodDropDown.Changed() && a.onMethodChanged != nil {
@@ -124,22 +157,90 @@ func (a *AddressBar) Layout(gtx layout.Context, theme *chapartheme.Theme) layout
 							gtx.Constraints.Min.Y = gtx.Dp(20)
 							return a.methodDropDown.Layout(gtx, theme)
 						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return a.connectionStateLayout(gtx, theme)
+						}),
 					)
 				})
 			})
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			if a.sendClickable.Clicked(gtx) {
-				if a.onSubmit != nil {
+				if a.loading {
+					if a.onCancel != nil {
+						a.onCancel()
+					}
+				} else if a.onSubmit != nil {
 					a.onSubmit()
 				}
 			}
 
 			gtx.Constraints.Min.X = gtx.Dp(80)
-			btn := material.Button(theme.Material(), &a.sendClickable, "Invoke")
+			if a.loading {
+				btn := material.Button(theme.Material(), &a.sendClickable, "Cancel")
+				btn.Background = theme.DeleteButtonBgColor
+				btn.Color = theme.ButtonTextColor
+				return btn.Layout(gtx)
+			}
+
+			text := "Invoke"
+			if a.clientStreaming {
+				text = "Open stream"
+			}
+
+			btn := material.Button(theme.Material(), &a.sendClickable, text)
 			btn.Background = theme.ActionButtonBgColor
 			btn.Color = theme.ButtonTextColor
 			return btn.Layout(gtx)
 		}),
 	)
 }
+
+// connectionStateLayout shows the state of the connection and a button to reconnect.
+func (a *AddressBar) connectionStateLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if a.connState == "" {
+		return layout.Dimensions{}
+	}
+
+	if a.reconnectClickable.Clicked(gtx) && a.onReconnect != nil {
+		a.onReconnect()
+	}
+
+	var stateColor color.NRGBA
+	switch a.connState {
+	case domain.GRPCConnReady:
+		stateColor = chapartheme.LightGreen
+	case domain.GRPCConnConnecting:
+		stateColor = chapartheme.LightYellow
+	case domain.GRPCConnFailure:
+		stateColor = chapartheme.LightRed
+	default:
+		stateColor = theme.TextColor
+	}
+
+	return layout.Inset{Left: unit.Dp(5), Right: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				gtx.Constraints.Min.X = gtx.Dp(8)
+				gtx.Constraints.Max.X = gtx.Constraints.Min.X
+				return widgets.CircleIcon.Layout(gtx, stateColor)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), unit.Sp(12), a.connState)
+				lb.Color = theme.TextColor
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				ib := widgets.IconButton{
+					Icon:      widgets.RefreshIcon,
+					Size:      unit.Dp(16),
+					Color:     theme.TextColor,
+					Clickable: &a.reconnectClickable,
+				}
+				return ib.Layout(gtx, theme)
+			}),
+		)
+	})
+}
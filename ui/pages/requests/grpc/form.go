@@ -0,0 +1,634 @@
+package grpc
+
+import (
+	"fmt"
+	"time"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/chapar-rest/chapar/internal/protoform"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// MessageForm edits the request message as a form generated from its descriptor, the form is
+// read from and written to the JSON body so both views show the same message.
+type MessageForm struct {
+	desc protoreflect.MessageDescriptor
+	form *protoform.Form
+	root *messageEditor
+	ctx  *formContext
+
+	list widget.List
+
+	onChanged func(body string)
+}
+
+// formContext is shared by the editors of a form, they flag it when the user changed a value.
+type formContext struct {
+	changed bool
+}
+
+// fieldEditor edits a value of the form, the value is kept in its protoform model.
+type fieldEditor interface {
+	layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions
+}
+
+func NewMessageForm() *MessageForm {
+	return &MessageForm{
+		list: widget.List{List: layout.List{Axis: layout.Vertical}},
+	}
+}
+
+func (f *MessageForm) SetOnChanged(fn func(body string)) {
+	f.onChanged = fn
+}
+
+// HasDescriptor returns true once the descriptor of the message is set.
+func (f *MessageForm) HasDescriptor() bool {
+	return f.desc != nil
+}
+
+// SetDescriptor builds the form of the message and fills it with the body.
+func (f *MessageForm) SetDescriptor(desc protoreflect.MessageDescriptor, body string) {
+	f.desc = desc
+	f.SetBody(body)
+}
+
+// SetBody fills the form with the body, values the form can't show are dropped on the next change.
+func (f *MessageForm) SetBody(body string) {
+	if f.desc == nil {
+		return
+	}
+
+	f.form = protoform.New(f.desc, body)
+	f.ctx = &formContext{}
+	f.root = newMessageEditor(f.ctx, f.form.Root)
+}
+
+func (f *MessageForm) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if f.root == nil {
+		return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			lb := material.Label(theme.Material(), unit.Sp(14), "Select a method to edit its request message as a form")
+			lb.Color = theme.ResponseStatusColor
+			return lb.Layout(gtx)
+		})
+	}
+
+	rows := f.root.rows()
+	dims := layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if f.form.Warning == "" {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), unit.Sp(12), f.form.Warning)
+				lb.Color = chapartheme.LightYellow
+				return lb.Layout(gtx)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			if len(rows) == 0 {
+				lb := material.Label(theme.Material(), unit.Sp(14), fmt.Sprintf("%s has no fields", f.desc.FullName()))
+				lb.Color = theme.ResponseStatusColor
+				return lb.Layout(gtx)
+			}
+
+			return material.List(theme.Material(), &f.list).Layout(gtx, len(rows), func(gtx layout.Context, i int) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(6), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return rows[i].layout(gtx, theme)
+				})
+			})
+		}),
+	)
+
+	if f.ctx.changed {
+		f.ctx.changed = false
+		f.form.Warning = ""
+		if f.onChanged != nil {
+			f.onChanged(f.form.Body())
+		}
+	}
+
+	return dims
+}
+
+type fieldRow struct {
+	fd     protoreflect.FieldDescriptor
+	editor fieldEditor
+}
+
+func (r *fieldRow) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if nested, ok := r.editor.(nestedEditor); ok {
+		return nested.layoutNested(gtx, theme, string(r.fd.Name()), fieldType(r.fd))
+	}
+	return fieldLayout(gtx, theme, string(r.fd.Name()), fieldType(r.fd), r.editor.layout)
+}
+
+// nestedEditor is an editor laid out below its field name, such as messages and lists.
+type nestedEditor interface {
+	layoutNested(gtx layout.Context, theme *chapartheme.Theme, name, typ string) layout.Dimensions
+}
+
+// newEditor returns the editor of the value.
+func newEditor(ctx *formContext, v protoform.Value) fieldEditor {
+	switch v := v.(type) {
+	case *protoform.Message:
+		return newMessageEditor(ctx, v)
+	case *protoform.List:
+		return newListEditor(ctx, v)
+	case *protoform.Map:
+		return newMapEditor(ctx, v)
+	case *protoform.Bool:
+		return newBoolEditor(ctx, v)
+	case *protoform.Enum:
+		return newEnumEditor(ctx, v)
+	default:
+		return newScalarEditor(ctx, v.(*protoform.Scalar))
+	}
+}
+
+// messageEditor edits the fields of a message, the editors are created once the message is
+// expanded so recursive messages are not built all the way down.
+type messageEditor struct {
+	ctx *formContext
+	msg *protoform.Message
+
+	fields   []fieldEditor
+	built    bool
+	expanded bool
+	header   widget.Clickable
+}
+
+func newMessageEditor(ctx *formContext, msg *protoform.Message) *messageEditor {
+	return &messageEditor{ctx: ctx, msg: msg, expanded: msg.Built()}
+}
+
+// expand opens the editor when it's a message, it's used for the values the user just added.
+func expand(editor fieldEditor) fieldEditor {
+	if m, ok := editor.(*messageEditor); ok {
+		m.expanded = true
+	}
+	return editor
+}
+
+func (m *messageEditor) rows() []fieldEditor {
+	if m.built {
+		return m.fields
+	}
+	m.built = true
+
+	for _, row := range m.msg.Rows() {
+		switch row := row.(type) {
+		case *protoform.Oneof:
+			m.fields = append(m.fields, newOneofEditor(m.ctx, row))
+		case *protoform.Field:
+			m.fields = append(m.fields, &fieldRow{fd: row.Desc, editor: newEditor(m.ctx, row.Value)})
+		}
+	}
+	return m.fields
+}
+
+func (m *messageEditor) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	return m.layoutNested(gtx, theme, "", string(m.msg.Desc.FullName()))
+}
+
+func (m *messageEditor) layoutNested(gtx layout.Context, theme *chapartheme.Theme, name, typ string) layout.Dimensions {
+	if m.header.Clicked(gtx) {
+		m.expanded = !m.expanded
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, &m.header, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						icon := widgets.ExpandIcon
+						if !m.expanded {
+							icon = widgets.ForwardIcon
+						}
+						gtx.Constraints.Min.X = gtx.Dp(16)
+						gtx.Constraints.Max.X = gtx.Constraints.Min.X
+						return icon.Layout(gtx, theme.ContrastFg)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return nameLayout(gtx, theme, name, typ)
+					}),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !m.expanded {
+				return layout.Dimensions{}
+			}
+			return nestedLayout(gtx, theme, m.rows())
+		}),
+	)
+}
+
+// oneofEditor selects which field of the oneof is set and edits it.
+type oneofEditor struct {
+	ctx      *formContext
+	oneof    *protoform.Oneof
+	dropDown *widgets.DropDown
+	editors  map[int]fieldEditor
+}
+
+func newOneofEditor(ctx *formContext, oneof *protoform.Oneof) *oneofEditor {
+	o := &oneofEditor{ctx: ctx, oneof: oneof, editors: make(map[int]fieldEditor)}
+
+	options := []*widgets.DropDownOption{widgets.NewDropDownOption("None")}
+	for i := 0; i < oneof.Desc.Fields().Len(); i++ {
+		options = append(options, widgets.NewDropDownOption(string(oneof.Desc.Fields().Get(i).Name())))
+	}
+	if v := oneof.SelectedValue(); v != nil {
+		o.editors[oneof.Selected()] = newEditor(ctx, v)
+	}
+
+	o.dropDown = widgets.NewDropDown(options...)
+	o.dropDown.SetSelected(oneof.Selected() + 1)
+	return o
+}
+
+func (o *oneofEditor) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if o.dropDown.Changed() {
+		i := o.dropDown.SelectedIndex() - 1
+		if v := o.oneof.Select(i); v != nil {
+			if _, ok := o.editors[i]; !ok {
+				o.editors[i] = expand(newEditor(o.ctx, v))
+			}
+		}
+		o.ctx.changed = true
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return fieldLayout(gtx, theme, string(o.oneof.Desc.Name()), "oneof", func(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+				return o.dropDown.Layout(gtx, theme)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			selected := o.oneof.Selected()
+			if selected < 0 {
+				return layout.Dimensions{}
+			}
+			fd := o.oneof.Desc.Fields().Get(selected)
+			row := &fieldRow{fd: fd, editor: o.editors[selected]}
+			return nestedLayout(gtx, theme, []fieldEditor{row})
+		}),
+	)
+}
+
+// listEditor edits the items of a repeated field.
+type listEditor struct {
+	ctx   *formContext
+	list  *protoform.List
+	items []*listItem
+	add   widget.Clickable
+}
+
+type listItem struct {
+	editor fieldEditor
+	remove widget.Clickable
+}
+
+func newListEditor(ctx *formContext, list *protoform.List) *listEditor {
+	l := &listEditor{ctx: ctx, list: list}
+	for _, item := range list.Items {
+		l.items = append(l.items, &listItem{editor: newEditor(ctx, item)})
+	}
+	return l
+}
+
+func (l *listEditor) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	return l.layoutNested(gtx, theme, "", fieldType(l.list.Desc))
+}
+
+func (l *listEditor) layoutNested(gtx layout.Context, theme *chapartheme.Theme, name, typ string) layout.Dimensions {
+	if l.add.Clicked(gtx) {
+		l.items = append(l.items, &listItem{editor: expand(newEditor(l.ctx, l.list.Add()))})
+		l.ctx.changed = true
+	}
+
+	for i, item := range l.items {
+		if item.remove.Clicked(gtx) {
+			l.list.Remove(i)
+			l.items = append(l.items[:i:i], l.items[i+1:]...)
+			l.ctx.changed = true
+			break
+		}
+	}
+
+	rows := make([]fieldEditor, 0, len(l.items))
+	for i, item := range l.items {
+		rows = append(rows, &removableRow{name: fmt.Sprintf("[%d]", i), editor: item.editor, remove: &item.remove})
+	}
+
+	return collectionLayout(gtx, theme, name, typ, &l.add, rows)
+}
+
+// mapEditor edits the entries of a map field.
+type mapEditor struct {
+	ctx     *formContext
+	m       *protoform.Map
+	entries []*mapEntry
+	add     widget.Clickable
+}
+
+type mapEntry struct {
+	key    *scalarEditor
+	editor fieldEditor
+	remove widget.Clickable
+}
+
+func newMapEditor(ctx *formContext, m *protoform.Map) *mapEditor {
+	e := &mapEditor{ctx: ctx, m: m}
+	for _, entry := range m.Entries {
+		e.entries = append(e.entries, e.newEntry(entry))
+	}
+	return e
+}
+
+func (m *mapEditor) newEntry(entry *protoform.MapEntry) *mapEntry {
+	return &mapEntry{
+		key:    newScalarEditor(m.ctx, entry.Key),
+		editor: newEditor(m.ctx, entry.Value),
+	}
+}
+
+func (m *mapEditor) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	return m.layoutNested(gtx, theme, "", fieldType(m.m.Desc))
+}
+
+func (m *mapEditor) layoutNested(gtx layout.Context, theme *chapartheme.Theme, name, typ string) layout.Dimensions {
+	if m.add.Clicked(gtx) {
+		entry := m.newEntry(m.m.Add())
+		expand(entry.editor)
+		m.entries = append(m.entries, entry)
+		m.ctx.changed = true
+	}
+
+	for i, entry := range m.entries {
+		if entry.remove.Clicked(gtx) {
+			m.m.Remove(i)
+			m.entries = append(m.entries[:i:i], m.entries[i+1:]...)
+			m.ctx.changed = true
+			break
+		}
+	}
+
+	rows := make([]fieldEditor, 0, len(m.entries))
+	for _, entry := range m.entries {
+		rows = append(rows, &removableRow{keyEditor: entry.key, editor: entry.editor, remove: &entry.remove})
+	}
+
+	return collectionLayout(gtx, theme, name, typ, &m.add, rows)
+}
+
+// removableRow is an item of a list or an entry of a map.
+type removableRow struct {
+	name      string
+	keyEditor *scalarEditor
+	editor    fieldEditor
+	remove    *widget.Clickable
+}
+
+func (r *removableRow) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	removeButton := func(gtx layout.Context) layout.Dimensions {
+		ib := widgets.IconButton{
+			Icon:      widgets.DeleteIcon,
+			Size:      unit.Dp(18),
+			Color:     theme.TextColor,
+			Clickable: r.remove,
+		}
+		return ib.Layout(gtx, theme)
+	}
+
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Start}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Dp(fieldNameWidth)
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			if r.keyEditor != nil {
+				return layout.Inset{Right: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return r.keyEditor.layout(gtx, theme)
+				})
+			}
+			return nameLayout(gtx, theme, r.name, "")
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			if nested, ok := r.editor.(nestedEditor); ok {
+				return nested.layoutNested(gtx, theme, "", "")
+			}
+			return r.editor.layout(gtx, theme)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+		layout.Rigid(removeButton),
+	)
+}
+
+// scalarEditor edits the values typed as text, such as numbers, timestamps and durations, invalid
+// values get a red border as they are typed.
+type scalarEditor struct {
+	ctx       *formContext
+	scalar    *protoform.Scalar
+	textField *widgets.TextField
+	now       widget.Clickable
+}
+
+func newScalarEditor(ctx *formContext, scalar *protoform.Scalar) *scalarEditor {
+	return &scalarEditor{
+		ctx:       ctx,
+		scalar:    scalar,
+		textField: widgets.NewTextField(scalar.Text, scalar.Placeholder),
+	}
+}
+
+func (s *scalarEditor) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if s.now.Clicked(gtx) {
+		s.textField.SetText(time.Now().UTC().Format(time.RFC3339))
+		s.scalar.Text = s.textField.GetText()
+		s.ctx.changed = true
+	}
+
+	if s.textField.Changed() {
+		s.scalar.Text = s.textField.GetText()
+		s.ctx.changed = true
+	}
+
+	if s.scalar.Valid() {
+		s.textField.SetBorderColor(theme.BorderColor)
+	} else {
+		s.textField.SetBorderColor(chapartheme.LightRed)
+	}
+
+	if s.scalar.Format != protoform.FormatTimestamp {
+		return s.textField.Layout(gtx, theme)
+	}
+
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return s.textField.Layout(gtx, theme)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := widgets.Button(theme, &s.now, nil, widgets.IconPositionStart, "Now")
+			btn.Inset = layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(8), Right: unit.Dp(8)}
+			return btn.Layout(gtx, theme)
+		}),
+	)
+}
+
+type boolEditor struct {
+	ctx     *formContext
+	value   *protoform.Bool
+	checked widget.Bool
+}
+
+func newBoolEditor(ctx *formContext, value *protoform.Bool) *boolEditor {
+	b := &boolEditor{ctx: ctx, value: value}
+	b.checked.Value = value.Checked
+	return b
+}
+
+func (b *boolEditor) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if b.checked.Update(gtx) {
+		b.value.Checked = b.checked.Value
+		b.ctx.changed = true
+	}
+	return widgets.CheckBox(theme, &b.checked, "").Layout(gtx)
+}
+
+type enumEditor struct {
+	ctx      *formContext
+	enum     *protoform.Enum
+	dropDown *widgets.DropDown
+}
+
+func newEnumEditor(ctx *formContext, enum *protoform.Enum) *enumEditor {
+	options := make([]*widgets.DropDownOption, 0, enum.Desc.Values().Len())
+	for i := 0; i < enum.Desc.Values().Len(); i++ {
+		options = append(options, widgets.NewDropDownOption(string(enum.Desc.Values().Get(i).Name())))
+	}
+
+	e := &enumEditor{ctx: ctx, enum: enum, dropDown: widgets.NewDropDown(options...)}
+	e.dropDown.SetSelected(enum.Selected)
+	return e
+}
+
+func (e *enumEditor) layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if e.dropDown.Changed() {
+		e.enum.Selected = e.dropDown.SelectedIndex()
+		e.ctx.changed = true
+	}
+	return e.dropDown.Layout(gtx, theme)
+}
+
+// fieldType is the type of the field as written in the proto file.
+func fieldType(fd protoreflect.FieldDescriptor) string {
+	typeName := func(fd protoreflect.FieldDescriptor) string {
+		switch fd.Kind() {
+		case protoreflect.MessageKind, protoreflect.GroupKind:
+			return string(fd.Message().Name())
+		case protoreflect.EnumKind:
+			return string(fd.Enum().Name())
+		default:
+			return fd.Kind().String()
+		}
+	}
+
+	switch {
+	case fd.IsMap():
+		return fmt.Sprintf("map<%s, %s>", typeName(fd.MapKey()), typeName(fd.MapValue()))
+	case fd.IsList():
+		return "repeated " + typeName(fd)
+	default:
+		return typeName(fd)
+	}
+}
+
+// fieldNameWidth is the width of the column of the field names.
+const fieldNameWidth = 180
+
+func nameLayout(gtx layout.Context, theme *chapartheme.Theme, name, typ string) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if name == "" {
+				return layout.Dimensions{}
+			}
+			lb := material.Label(theme.Material(), unit.Sp(13), name)
+			lb.Font.Weight = font.Medium
+			return lb.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if typ == "" {
+				return layout.Dimensions{}
+			}
+			lb := material.Label(theme.Material(), unit.Sp(11), typ)
+			lb.Color = theme.ResponseStatusColor
+			return lb.Layout(gtx)
+		}),
+	)
+}
+
+func fieldLayout(gtx layout.Context, theme *chapartheme.Theme, name, typ string, editor func(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions) layout.Dimensions {
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Start}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Dp(fieldNameWidth)
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			return nameLayout(gtx, theme, name, typ)
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return editor(gtx, theme)
+		}),
+	)
+}
+
+// nestedLayout lays the rows out below their parent, indented.
+func nestedLayout(gtx layout.Context, theme *chapartheme.Theme, rows []fieldEditor) layout.Dimensions {
+	children := make([]layout.FlexChild, 0, len(rows))
+	for _, row := range rows {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return row.layout(gtx, theme)
+			})
+		}))
+	}
+
+	return layout.Inset{Left: unit.Dp(20)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+}
+
+// collectionLayout lays the items of a list or map out below their field name and an add button.
+func collectionLayout(gtx layout.Context, theme *chapartheme.Theme, name, typ string, add *widget.Clickable, rows []fieldEditor) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if name == "" && typ == "" {
+						return layout.Dimensions{}
+					}
+					gtx.Constraints.Min.X = gtx.Dp(fieldNameWidth)
+					return nameLayout(gtx, theme, name, typ)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := widgets.Button(theme, add, widgets.PlusIcon, widgets.IconPositionStart, "Add")
+					btn.Inset = layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(8), Right: unit.Dp(8)}
+					return btn.Layout(gtx, theme)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if len(rows) == 0 {
+				return layout.Dimensions{}
+			}
+			return nestedLayout(gtx, theme, rows)
+		}),
+	)
+}
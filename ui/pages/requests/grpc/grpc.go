@@ -5,6 +5,7 @@ import (
 	"gioui.org/unit"
 
 	giox "gioui.org/x/component"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	"github.com/chapar-rest/chapar/internal/domain"
 	"github.com/chapar-rest/chapar/internal/prefs"
@@ -32,7 +33,12 @@ type Grpc struct {
 	onSave                        func(id string)
 	onDataChanged                 func(id string, data any)
 	onInvoke                      func(id string)
+	onCancel                      func(id string)
 	onCreateCollectionFromMethods func()
+	onStreamSend                  func(id, body string)
+	onHalfClose                   func(id string)
+	onLoadRequestForm             func(id string)
+	onConvertBody                 func(id, body, from, to string)
 }
 
 func (r *Grpc) SetOnTitleChanged(f func(title string)) {
@@ -75,10 +81,20 @@ func New(req *domain.Request, theme *chapartheme.Theme, explorer *explorer.Explo
 	if len(req.Spec.GRPC.Services) > 0 {
 		r.Request.ServerInfo.SetHasMethods(grpcMethodCount(req.Spec.GRPC.Services) > 0)
 	}
+	r.updateClientStreaming()
 
 	return r
 }
 
+// updateClientStreaming switches the body between a single request and the messages of a stream
+// depending on the selected method.
+func (r *Grpc) updateClientStreaming() {
+	m := r.Req.Spec.GRPC.GetMethod(r.Req.Spec.GRPC.LasSelectedMethod)
+	streaming := m != nil && m.IsStreamingClient
+	r.AddressBar.SetClientStreaming(streaming)
+	r.Request.SetClientStreaming(streaming)
+}
+
 func (r *Grpc) setupHooks() {
 	r.AddressBar.SetOnServerAddressChanged(func(address string) {
 		r.Req.Spec.GRPC.ServerInfo.Address = address
@@ -87,13 +103,67 @@ func (r *Grpc) setupHooks() {
 
 	r.AddressBar.SetOnMethodChanged(func(method string) {
 		r.Req.Spec.GRPC.LasSelectedMethod = method
+		r.updateClientStreaming()
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+		if r.Request.IsFormView() {
+			r.loadRequestForm()
+		}
+	})
+
+	r.Request.SetOnBodyViewChanged(func(form bool) {
+		spec := r.Req.Spec.GRPC
+		if format := r.Request.BodyFormat(); format != spec.BodyFormat {
+			from := spec.BodyFormat
+			spec.BodyFormat = format
+			r.Request.SetBodyFormat(format)
+			r.onDataChanged(r.Req.MetaData.ID, r.Req)
+
+			// binary bodies are kept in files, the body is only converted between json and text
+			if from != domain.GRPCBodyFormatBinary && format != domain.GRPCBodyFormatBinary && r.onConvertBody != nil {
+				r.onConvertBody(r.Req.MetaData.ID, spec.Body, from, format)
+			}
+		}
+
+		if form {
+			// the json may have been edited since the form was shown
+			r.Request.Form.SetBody(r.Req.Spec.GRPC.Body)
+			r.loadRequestForm()
+		}
+	})
+
+	r.Request.BodyFile.SetOnChanged(func(filePath string) {
+		r.Req.Spec.GRPC.BodyFile = filePath
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
+	r.Request.Form.SetOnChanged(func(body string) {
+		r.Req.Spec.GRPC.Body = body
+		r.Request.Body.SetCode(body)
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
+	r.Request.SetOnStreamSend(func() {
+		if r.onStreamSend != nil {
+			r.onStreamSend(r.Req.MetaData.ID, r.Req.Spec.GRPC.Body)
+		}
+	})
+
+	r.Request.SetOnHalfClose(func() {
+		if r.onHalfClose != nil {
+			r.onHalfClose(r.Req.MetaData.ID)
+		}
 	})
 
 	r.AddressBar.SetOnSubmit(func() {
 		r.onInvoke(r.Req.MetaData.ID)
 	})
 
+	r.AddressBar.SetOnCancel(func() {
+		if r.onCancel != nil {
+			r.onCancel(r.Req.MetaData.ID)
+		}
+	})
+
 	r.Request.Body.SetOnChanged(func(data string) {
 		r.Req.Spec.GRPC.Body = data
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
@@ -109,11 +179,21 @@ func (r *Grpc) setupHooks() {
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
+	r.Request.PreRequest.SetOnScriptChanged(func(code string) {
+		r.Req.Spec.GRPC.PreRequest.Script = code
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	r.Request.PostRequest.SetOnDropDownChanged(func(selected string) {
 		r.Req.Spec.GRPC.PostRequest.Type = selected
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
+	r.Request.PostRequest.SetOnScriptChanged(func(code string) {
+		r.Req.Spec.GRPC.PostRequest.Script = code
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	r.Request.ServerInfo.FileSelector.SetOnChanged(func(filePath string) {
 		protoFiles := r.Req.Spec.GRPC.ServerInfo.ProtoFiles
 		if r.Req.Spec.GRPC.ServerInfo.ProtoFiles == nil || filePath == "" {
@@ -161,6 +241,11 @@ func convertSettingsToItems(values map[string]any) domain.GRPCSettings {
 		NameOverride:        "",
 	}
 
+	// native grpc is kept empty so requests saved before the protocol existed don't change
+	if v, ok := values["protocol"]; ok && v.(string) != domain.GRPCProtocolNative {
+		out.Protocol = v.(string)
+	}
+
 	if v, ok := values["insecure"]; ok {
 		out.Insecure = v.(bool)
 	}
@@ -173,6 +258,34 @@ func convertSettingsToItems(values map[string]any) domain.GRPCSettings {
 		out.NameOverride = v.(string)
 	}
 
+	if v, ok := values["compression"]; ok && v.(string) != compressionNone {
+		out.Compression = v.(string)
+	}
+
+	if v, ok := values["maxSendMessageSizeMB"]; ok {
+		out.MaxSendMessageSizeMB = v.(int)
+	}
+
+	if v, ok := values["maxReceiveMessageSizeMB"]; ok {
+		out.MaxReceiveMessageSizeMB = v.(int)
+	}
+
+	if v, ok := values["waitForReady"]; ok {
+		out.WaitForReady = v.(bool)
+	}
+
+	if v, ok := values["keepaliveTimeSeconds"]; ok {
+		out.KeepaliveTimeSeconds = v.(int)
+	}
+
+	if v, ok := values["keepaliveTimeoutSeconds"]; ok {
+		out.KeepaliveTimeoutSeconds = v.(int)
+	}
+
+	if v, ok := values["keepalivePermitWithoutStream"]; ok {
+		out.KeepalivePermitWithoutStream = v.(bool)
+	}
+
 	if v, ok := values["root_cert"]; ok {
 		out.RootCertFile = v.(string)
 	}
@@ -185,6 +298,7 @@ func convertSettingsToItems(values map[string]any) domain.GRPCSettings {
 		out.ClientKeyFile = v.(string)
 	}
 
+	out.Proxy = converter.ProxyFromSettingValues(values)
 	return out
 }
 
@@ -234,7 +348,67 @@ func (r *Grpc) SetOnInvoke(f func(id string)) {
 	r.onInvoke = f
 }
 
+func (r *Grpc) SetOnCancel(f func(id string)) {
+	r.onCancel = f
+}
+
+func (r *Grpc) SetOnStreamSend(f func(id, body string)) {
+	r.onStreamSend = f
+}
+
+func (r *Grpc) SetOnSaveMessages(f func(id string, messages []domain.GRPCStreamMessage)) {
+	r.Response.SetOnSaveMessages(func(messages []domain.GRPCStreamMessage) {
+		f(r.Req.MetaData.ID, messages)
+	})
+}
+
+func (r *Grpc) SetOnHalfClose(f func(id string)) {
+	r.onHalfClose = f
+}
+
+func (r *Grpc) SetOnReconnect(f func(id string)) {
+	r.AddressBar.SetOnReconnect(func() {
+		f(r.Req.MetaData.ID)
+	})
+}
+
+// SetConnectionState shows the state of the connection the request uses.
+func (r *Grpc) SetConnectionState(state string) {
+	r.AddressBar.SetConnectionState(state)
+}
+
+// StartStream shows the stream as open, messages can be sent until it's half closed.
+func (r *Grpc) StartStream() {
+	r.AddressBar.SetLoading(true)
+	r.Request.SetStreamOpen(true)
+	r.Response.StartStream()
+}
+
+func (r *Grpc) AddStreamMessage(msg domain.GRPCStreamMessage) {
+	r.Response.AddStreamMessage(msg)
+}
+
+// EndStream shows the status of the ended stream, stopped is true when it was cancelled by the user.
+func (r *Grpc) EndStream(stopped bool, detail domain.GRPCResponseDetail) {
+	r.AddressBar.SetLoading(false)
+	r.Request.SetStreamOpen(false)
+	if stopped {
+		r.Response.StopStream()
+	} else {
+		r.Response.EndStream(detail.Error)
+	}
+
+	// the error is shown with the messages instead of replacing them
+	detail.Error = nil
+	r.SetResponse(detail)
+}
+
+func (r *Grpc) SetCancelled() {
+	r.Response.SetCancelled()
+}
+
 func (r *Grpc) SetResponseLoading(loading bool) {
+	r.AddressBar.SetLoading(loading)
 	if loading {
 		r.Response.SetMessage("Sending request...")
 		return
@@ -249,6 +423,31 @@ func (r *Grpc) SetOnLoadRequestExample(f func(id string)) {
 	})
 }
 
+func (r *Grpc) SetOnLoadRequestForm(f func(id string)) {
+	r.onLoadRequestForm = f
+}
+
+// SetOnConvertBody sets the function called to convert the body when it's switched between the json
+// and the text format.
+func (r *Grpc) SetOnConvertBody(f func(id, body, from, to string)) {
+	r.onConvertBody = f
+}
+
+func (r *Grpc) loadRequestForm() {
+	if r.onLoadRequestForm != nil {
+		r.onLoadRequestForm(r.Req.MetaData.ID)
+	}
+}
+
+// SetRequestDescriptor builds the form of the body from the descriptor of the request message.
+func (r *Grpc) SetRequestDescriptor(desc protoreflect.MessageDescriptor) {
+	r.Request.Form.SetDescriptor(desc, r.Req.Spec.GRPC.Body)
+}
+
+func (r *Grpc) SetOnClearOAuth2Token(f func()) {
+	r.Request.Auth.SetOnClearToken(f)
+}
+
 func (r *Grpc) SetOnCopyResponse(f func(gtx layout.Context, dataType, data string)) {
 	r.Response.SetOnCopyResponse(f)
 }
@@ -259,14 +458,25 @@ func (r *Grpc) SetRequestBody(body string) {
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	}
 	r.Request.Body.SetCode(body)
+	r.Request.Form.SetBody(body)
 }
 
 func (r *Grpc) SetResponse(detail domain.GRPCResponseDetail) {
 	r.Request.Variables.SetResponseDetail(&domain.ResponseDetail{GRPC: &detail})
 	r.Response.SetResponse(detail.Response)
+	r.Response.SetResponseFormats(detail.ResponseText, detail.ResponseHex)
 	r.Response.SetMetadata(detail.RequestMetadata, detail.ResponseMetadata)
 	r.Response.SetTrailers(detail.Trailers)
-	r.Response.SetError(detail.Error)
+	r.Response.SetErrorDetails(detail.ErrorDetails)
+	r.Response.SetTests(detail.Tests)
+
+	// calls that failed with a status still have their metadata, trailers and details to show,
+	// other errors happened before the call was made
+	if detail.Error != nil && detail.StatusCode != 0 {
+		r.Response.SetStatusError(detail.Error)
+	} else {
+		r.Response.SetError(detail.Error)
+	}
 	r.Response.SetStatusParams(detail.StatusCode, detail.Status, detail.Duration, detail.Size)
 }
 
@@ -275,6 +485,7 @@ func (r *Grpc) GetResponse() *domain.GRPCResponseDetail {
 		Response:         r.Response.response,
 		ResponseMetadata: domain.TextToKeyValue(r.Response.Metadata.Code()),
 		Trailers:         domain.TextToKeyValue(r.Response.Trailers.Code()),
+		ErrorDetails:     r.Response.ErrorDetails.Code(),
 	}
 }
 
@@ -324,6 +535,7 @@ func (r *Grpc) SetServices(services []domain.GRPCService) {
 
 	hasMethods := grpcMethodCount(services) > 0
 	r.Request.ServerInfo.SetHasMethods(hasMethods)
+	r.updateClientStreaming()
 }
 
 func grpcMethodCount(services []domain.GRPCService) int {
@@ -0,0 +1,277 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/dustin/go-humanize"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/pages/requests/component"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Messages shows the messages sent and received on a streaming call in the order they happened.
+type Messages struct {
+	// messages are added from the call goroutine while the list is laid out
+	mu        sync.Mutex
+	messages  []*streamMessage
+	streaming bool
+	stopped   bool
+	err       error
+	// view is the view of the response body the messages are shown in
+	view string
+
+	list         widget.List
+	toggleButton widget.Clickable
+	saveButton   widget.Clickable
+
+	onSave func(messages []domain.GRPCStreamMessage)
+}
+
+type streamMessage struct {
+	domain.GRPCStreamMessage
+
+	collapsed bool
+	header    widget.Clickable
+}
+
+func NewMessages() *Messages {
+	return &Messages{
+		list: widget.List{
+			List: layout.List{Axis: layout.Vertical, ScrollToEnd: true},
+		},
+	}
+}
+
+func (m *Messages) SetOnSave(f func(messages []domain.GRPCStreamMessage)) {
+	m.onSave = f
+}
+
+// SetView shows the messages as json, in the text format or as the hex dumps of their wire bytes.
+func (m *Messages) SetView(view string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.view = view
+}
+
+// body returns the message in the given view.
+func (msg *streamMessage) body(view string) string {
+	switch view {
+	case responseViewText:
+		return msg.Text
+	case responseViewHex:
+		return msg.Hex
+	default:
+		return msg.Body
+	}
+}
+
+// Start clears the messages of the previous call.
+func (m *Messages) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+	m.streaming = true
+	m.stopped = false
+	m.err = nil
+}
+
+// Add adds the message at its place in time, a sent message may be added after the response it caused.
+func (m *Messages) Add(msg domain.GRPCStreamMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := len(m.messages)
+	for i > 0 && m.messages[i-1].Time.After(msg.Time) {
+		i--
+	}
+	m.messages = append(m.messages, nil)
+	copy(m.messages[i+1:], m.messages[i:])
+	m.messages[i] = &streamMessage{GRPCStreamMessage: msg}
+}
+
+// End marks the end of the call, err is nil when the server ended it with an OK status.
+func (m *Messages) End(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streaming = false
+	m.err = err
+}
+
+// Stop marks the call as cancelled by the user.
+func (m *Messages) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streaming = false
+	m.stopped = true
+}
+
+func (m *Messages) IsStreaming() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.streaming
+}
+
+// String returns the messages one after another in the current view, used to copy them.
+func (m *Messages) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+	for _, msg := range m.messages {
+		sb.WriteString(fmt.Sprintf("// %s %s\n%s\n\n", msg.Time.Format("15:04:05.000"), msg.Direction, msg.body(m.view)))
+	}
+	return sb.String()
+}
+
+// Len returns the number of messages.
+func (m *Messages) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.messages)
+}
+
+func (m *Messages) status() string {
+	count := fmt.Sprintf("%d messages", len(m.messages))
+	switch {
+	case m.streaming:
+		return "Streaming, " + count
+	case m.stopped:
+		return "Cancelled, " + count
+	case m.err != nil:
+		return fmt.Sprintf("Stream failed: %s, %s", m.err, count)
+	default:
+		return "Stream ended, " + count
+	}
+}
+
+func (m *Messages) allCollapsed() bool {
+	for _, msg := range m.messages {
+		if !msg.collapsed {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Messages) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.streaming && !m.stopped && m.err == nil && len(m.messages) == 0 {
+		return component.Message(gtx, component.MessageTypeInfo, theme, "Messages of streaming calls are shown here")
+	}
+
+	if m.toggleButton.Clicked(gtx) {
+		collapse := !m.allCollapsed()
+		for _, msg := range m.messages {
+			msg.collapsed = collapse
+		}
+	}
+
+	if m.saveButton.Clicked(gtx) && m.onSave != nil {
+		messages := make([]domain.GRPCStreamMessage, 0, len(m.messages))
+		for _, msg := range m.messages {
+			messages = append(messages, msg.GRPCStreamMessage)
+		}
+		m.onSave(messages)
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, material.Label(theme.Material(), theme.TextSize, m.status()).Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						text := "Collapse all"
+						if len(m.messages) > 0 && m.allCollapsed() {
+							text = "Expand all"
+						}
+						return widgets.Button(theme, &m.toggleButton, widgets.ExpandIcon, widgets.IconPositionStart, text).Layout(gtx, theme)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if len(m.messages) == 0 {
+							gtx = gtx.Disabled()
+						}
+						return widgets.Button(theme, &m.saveButton, widgets.SaveIcon, widgets.IconPositionStart, "Save as JSONL").Layout(gtx, theme)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(theme.Material(), &m.list).Layout(gtx, len(m.messages), func(gtx layout.Context, i int) layout.Dimensions {
+				return m.messageLayout(gtx, theme, m.messages[i])
+			})
+		}),
+	)
+}
+
+func (m *Messages) messageLayout(gtx layout.Context, theme *chapartheme.Theme, msg *streamMessage) layout.Dimensions {
+	if msg.header.Clicked(gtx) {
+		msg.collapsed = !msg.collapsed
+	}
+
+	arrow, arrowColor := "↓", chapartheme.LightGreen
+	if msg.Direction == domain.GRPCMessageSent {
+		arrow, arrowColor = "↑", chapartheme.LightBlue
+	}
+
+	header := fmt.Sprintf("%s  %s  %s", msg.Direction, msg.Time.Format("15:04:05.000"), humanize.Bytes(uint64(msg.Size)))
+
+	return layout.Inset{Bottom: unit.Dp(8), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return material.Clickable(gtx, &msg.header, func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							icon := widgets.ExpandIcon
+							if msg.collapsed {
+								icon = widgets.ForwardIcon
+							}
+							gtx.Constraints.Min.X = gtx.Dp(16)
+							gtx.Constraints.Max.X = gtx.Constraints.Min.X
+							return icon.Layout(gtx, theme.ContrastFg)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lb := material.Label(theme.Material(), unit.Sp(12), arrow+" ")
+							lb.Font.Weight = font.Bold
+							lb.Color = arrowColor
+							return lb.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lb := material.Label(theme.Material(), unit.Sp(12), header)
+							lb.Font.Weight = font.Bold
+							lb.Color = theme.ResponseStatusColor
+							return lb.Layout(gtx)
+						}),
+					)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if msg.collapsed {
+					return layout.Dimensions{}
+				}
+
+				lb := material.Label(theme.Material(), theme.TextSize, msg.body(m.view))
+				lb.Font.Typeface = theme.Face
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return widgets.DrawLine(gtx, theme.SeparatorColor, unit.Dp(1), unit.Dp(gtx.Metric.PxToDp(gtx.Constraints.Max.X)))
+				})
+			}),
+		)
+	})
+}
@@ -3,6 +3,8 @@ package grpc
 import (
 	"gioui.org/layout"
 	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
 
 	"github.com/chapar-rest/chapar/ui/converter"
 	"github.com/chapar-rest/chapar/ui/explorer"
@@ -20,6 +22,9 @@ type Request struct {
 
 	ServerInfo *ServerInfo
 	Body       *codeeditor.CodeEditor
+	BodyView   *widgets.DropDown
+	BodyFile   *widgets.FileSelector
+	Form       *MessageForm
 	Metadata   *widgets.KeyValue
 	Auth       *component.Auth
 	Settings   *widgets.Settings
@@ -30,8 +35,31 @@ type Request struct {
 
 	currentTab  string
 	OnTabChange func(title string)
+
+	// clientStreaming is true when the selected method is client or bidi streaming, the body is then
+	// sent as one message of the open stream each time send is clicked.
+	clientStreaming bool
+	streamOpen      bool
+	halfClosed      bool
+
+	sendButton      widget.Clickable
+	halfCloseButton widget.Clickable
+
+	onStreamSend      func()
+	onHalfClose       func()
+	onBodyViewChanged func(form bool)
 }
 
+const (
+	bodyViewJSON   = "json"
+	bodyViewForm   = "form"
+	bodyViewText   = "text"
+	bodyViewBinary = "binary"
+
+	// compressionNone is the value of the compression setting when messages are not compressed
+	compressionNone = "none"
+)
+
 func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explorer.Explorer) *Request {
 	visibilityFunc := func(values map[string]any) bool {
 		return !values["insecure"].(bool)
@@ -39,10 +67,36 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 
 	certExt := []string{"pem", "crt"}
 
+	protocol := req.Spec.GRPC.Settings.Protocol
+	if protocol == "" {
+		protocol = domain.GRPCProtocolNative
+	}
+
+	compression := req.Spec.GRPC.Settings.Compression
+	if compression == "" {
+		compression = compressionNone
+	}
+
+	bodyView := bodyViewJSON
+	bodyLanguage := codeeditor.CodeLanguageJSON
+	switch req.Spec.GRPC.BodyFormat {
+	case domain.GRPCBodyFormatText:
+		bodyView = bodyViewText
+		bodyLanguage = codeeditor.CodeLanguageProtobuf
+	case domain.GRPCBodyFormatBinary:
+		bodyView = bodyViewBinary
+	}
+
+	keepaliveVisibilityFunc := func(values map[string]any) bool {
+		v, _ := values["keepaliveTimeSeconds"].(int)
+		return v > 0
+	}
+
 	postRequestDropDown := widgets.NewDropDown(
 		widgets.NewDropDownOption("From Response").WithValue(domain.PostRequestSetFromResponseBody),
 		widgets.NewDropDownOption("From Metadata").WithValue(domain.PostRequestSetFromResponseMetaData),
 		widgets.NewDropDownOption("From Trailers").WithValue(domain.PostRequestSetFromResponseTrailers),
+		widgets.NewDropDownOption("From Error details").WithValue(domain.PostRequestSetFromResponseErrorDetails),
 	)
 
 	r := &Request{
@@ -58,23 +112,49 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 			{Title: "Post Request"},
 		}, nil),
 		ServerInfo: NewServerInfo(explorer, req.Spec.GRPC.ServerInfo),
-		Body:       codeeditor.NewCodeEditor(req.Spec.GRPC.Body, codeeditor.CodeLanguageJSON, theme),
+		Body:       codeeditor.NewCodeEditor(req.Spec.GRPC.Body, bodyLanguage, theme),
+		BodyView: widgets.NewDropDown(
+			widgets.NewDropDownOption("JSON").WithValue(bodyViewJSON),
+			widgets.NewDropDownOption("Form").WithValue(bodyViewForm),
+			widgets.NewDropDownOption("Text format").WithValue(bodyViewText),
+			widgets.NewDropDownOption("Binary file").WithValue(bodyViewBinary),
+		),
+		BodyFile: widgets.NewFileSelector(req.Spec.GRPC.BodyFile, explorer),
+		Form:     NewMessageForm(),
 		Metadata: widgets.NewKeyValue(
 			converter.WidgetItemsFromKeyValue(req.Spec.GRPC.Metadata)...,
 		),
 		Auth: component.NewAuth(req.Spec.GRPC.Auth, theme),
-		Settings: widgets.NewSettings([]*widgets.SettingItem{
+		Settings: widgets.NewSettings(append([]*widgets.SettingItem{
+			widgets.NewDropDownItem("Protocol", "protocol", "gRPC-Web and Connect calls are sent over HTTP, server reflection always uses native gRPC.", protocol,
+				widgets.NewDropDownOption("gRPC").WithIdentifier(domain.GRPCProtocolNative).WithValue(domain.GRPCProtocolNative),
+				widgets.NewDropDownOption("gRPC-Web").WithIdentifier(domain.GRPCProtocolWeb).WithValue(domain.GRPCProtocolWeb),
+				widgets.NewDropDownOption("gRPC-Web text").WithIdentifier(domain.GRPCProtocolWebText).WithValue(domain.GRPCProtocolWebText),
+				widgets.NewDropDownOption("Connect JSON").WithIdentifier(domain.GRPCProtocolConnect).WithValue(domain.GRPCProtocolConnect),
+				widgets.NewDropDownOption("Connect Proto").WithIdentifier(domain.GRPCProtocolConnectProto).WithValue(domain.GRPCProtocolConnectProto),
+			),
 			widgets.NewBoolItem("Plain Text", "insecure", "Insecure connection", req.Spec.GRPC.Settings.Insecure),
 			widgets.NewFileItem(explorer, "Trusted Root certificate", "root_cert", "x509 pem trusted root certificate", req.Spec.GRPC.Settings.RootCertFile, certExt...).SetVisibleWhen(visibilityFunc),
 			widgets.NewFileItem(explorer, "Client certificate", "client_public_key", "Public key", req.Spec.GRPC.Settings.ClientCertFile, certExt...).SetVisibleWhen(visibilityFunc),
 			widgets.NewFileItem(explorer, "Client key", "client_private_key", "Private key", req.Spec.GRPC.Settings.ClientKeyFile, certExt...).SetVisibleWhen(visibilityFunc),
-			widgets.NewTextItem("Overwrite server name for certificate verification", "nameOverride", "The value used to validate the common name in the server certificate.", req.Spec.GRPC.Settings.NameOverride).SetVisibleWhen(visibilityFunc),
+			widgets.NewTextItem("Authority", "nameOverride", "Overrides the :authority of the calls, it's also the server name the certificate is verified against.", req.Spec.GRPC.Settings.NameOverride),
 			widgets.NewNumberItem("Timeout", "timeoutMilliseconds", "Timeout for the request in milliseconds", req.Spec.GRPC.Settings.TimeoutMilliseconds),
-		}),
+			widgets.NewDropDownItem("Compression", "compression", "Compression of the messages sent, native gRPC only.", compression,
+				widgets.NewDropDownOption("None").WithIdentifier(compressionNone).WithValue(compressionNone),
+				widgets.NewDropDownOption("gzip").WithIdentifier(domain.GRPCCompressionGzip).WithValue(domain.GRPCCompressionGzip),
+			),
+			widgets.NewNumberItem("Max send message size", "maxSendMessageSizeMB", "In megabytes, 0 means unlimited.", req.Spec.GRPC.Settings.MaxSendMessageSizeMB),
+			widgets.NewNumberItem("Max receive message size", "maxReceiveMessageSizeMB", "In megabytes, 0 means the default of 4MB.", req.Spec.GRPC.Settings.MaxReceiveMessageSizeMB),
+			widgets.NewBoolItem("Wait for ready", "waitForReady", "Calls wait for the connection to be ready until their timeout instead of failing right away.", req.Spec.GRPC.Settings.WaitForReady),
+			widgets.NewNumberItem("Keepalive time", "keepaliveTimeSeconds", "Seconds without activity before a keepalive ping is sent, 0 disables keepalive.", req.Spec.GRPC.Settings.KeepaliveTimeSeconds),
+			widgets.NewNumberItem("Keepalive timeout", "keepaliveTimeoutSeconds", "Seconds to wait for the ping to be acknowledged before closing the connection, 0 means 20 seconds.", req.Spec.GRPC.Settings.KeepaliveTimeoutSeconds).SetVisibleWhen(keepaliveVisibilityFunc),
+			widgets.NewBoolItem("Keepalive without calls", "keepalivePermitWithoutStream", "Send keepalive pings even when there are no calls in progress.", req.Spec.GRPC.Settings.KeepalivePermitWithoutStream).SetVisibleWhen(keepaliveVisibilityFunc),
+		}, converter.ProxySettingItems(req.Spec.GRPC.Settings.Proxy, true)...)),
 		PreRequest: component.NewPrePostRequest([]component.Option{
 			{Title: "None", Value: domain.PrePostTypeNone},
 			{Title: "Trigger request", Value: domain.PrePostTypeTriggerRequest, Type: component.TypeTriggerRequest, Hint: "Trigger another request"},
-			//	{Title: "Python", Value: domain.PostRequestTypePythonScript, Type: component.TypeScript, Hint: "Write your pre request python script here"},
+			{Title: "Python", Value: domain.PrePostTypePython, Type: component.TypeScript, Hint: "Write your pre request python script here"},
+			{Title: "JavaScript", Value: domain.PrePostTypeJavaScript, Type: component.TypeScript, Hint: "Write your pre request javascript script here"},
 			//	{Title: "Shell Script", Value: domain.PostRequestTypeSSHTunnel, Type: component.TypeScript, Hint: "Write your pre request shell script here"},
 			//	{Title: "Kubectl tunnel", Value: domain.PostRequestTypeK8sTunnel, Type: component.TypeScript, Hint: "Run kubectl port-forward command"},
 			//	{Title: "SSH tunnel", Value: domain.PostRequestTypeSSHTunnel, Type: component.TypeScript, Hint: "Run ssh command"},
@@ -82,18 +162,23 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 		PostRequest: component.NewPrePostRequest([]component.Option{
 			{Title: "None", Value: domain.PrePostTypeNone},
 			{Title: "Set Environment Variable", Value: domain.PrePostTypeSetEnv, Type: component.TypeSetEnv, Hint: "Set environment variable"},
-			//	{Title: "Python", Value: domain.PostRequestTypePythonScript, Type: component.TypeScript, Hint: "Write your post request python script here"},
+			{Title: "Python", Value: domain.PrePostTypePython, Type: component.TypeScript, Hint: "Write your post request python script here"},
+			{Title: "JavaScript", Value: domain.PrePostTypeJavaScript, Type: component.TypeScript, Hint: "Write your post request javascript script here"},
 			//	{Title: "Shell Script", Value: domain.PostRequestTypeShellScript, Type: component.TypeScript, Hint: "Write your post request shell script here"},
 		}, postRequestDropDown, theme),
 		Variables: component.NewVariables(theme, domain.RequestTypeGRPC),
 	}
 
+	r.BodyView.SetSelectedByValue(bodyView)
+
 	if req.Spec.GRPC.PreRequest != (domain.PreRequest{}) {
 		r.PreRequest.SetSelectedDropDown(req.Spec.GRPC.PreRequest.Type)
+		r.PreRequest.SetCode(req.Spec.GRPC.PreRequest.Script)
 	}
 
 	if req.Spec.GRPC.PostRequest != (domain.PostRequest{}) {
 		r.PostRequest.SetSelectedDropDown(req.Spec.GRPC.PostRequest.Type)
+		r.PostRequest.SetCode(req.Spec.GRPC.PostRequest.Script)
 
 		if req.Spec.GRPC.PostRequest.PostRequestSet != (domain.PostRequestSet{}) {
 			r.PostRequest.SetPostRequestSetValues(req.Spec.GRPC.PostRequest.PostRequestSet)
@@ -107,6 +192,156 @@ func NewRequest(req *domain.Request, theme *chapartheme.Theme, explorer *explore
 	return r
 }
 
+func (r *Request) SetClientStreaming(streaming bool) {
+	r.clientStreaming = streaming
+}
+
+// SetStreamOpen enables sending messages and half closing the stream while it's open.
+func (r *Request) SetStreamOpen(open bool) {
+	r.streamOpen = open
+	r.halfClosed = false
+}
+
+func (r *Request) SetOnStreamSend(f func()) {
+	r.onStreamSend = f
+}
+
+func (r *Request) SetOnHalfClose(f func()) {
+	r.onHalfClose = f
+}
+
+// SetOnBodyViewChanged sets the function called when the body is switched to another view or format.
+func (r *Request) SetOnBodyViewChanged(f func(form bool)) {
+	r.onBodyViewChanged = f
+}
+
+// IsFormView returns true when the body is edited as a form.
+func (r *Request) IsFormView() bool {
+	return r.BodyView.GetSelected().GetValue() == bodyViewForm
+}
+
+// BodyFormat returns the format of the body of the selected view, the form edits the body as json.
+func (r *Request) BodyFormat() string {
+	switch r.BodyView.GetSelected().GetValue() {
+	case bodyViewText:
+		return domain.GRPCBodyFormatText
+	case bodyViewBinary:
+		return domain.GRPCBodyFormatBinary
+	default:
+		return ""
+	}
+}
+
+// SetBodyFormat updates the editor for the format of the body.
+func (r *Request) SetBodyFormat(format string) {
+	if format == domain.GRPCBodyFormatText {
+		r.Body.SetLanguage(codeeditor.CodeLanguageProtobuf)
+	} else {
+		r.Body.SetLanguage(codeeditor.CodeLanguageJSON)
+	}
+}
+
+func (r *Request) bodyLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if r.BodyView.Changed() && r.onBodyViewChanged != nil {
+		r.onBodyViewChanged(r.IsFormView())
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !r.clientStreaming {
+				return layout.Dimensions{}
+			}
+			return r.streamToolbar(gtx, theme)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return material.Label(theme.Material(), theme.TextSize, "Edit as").Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						r.BodyView.MinWidth = unit.Dp(100)
+						return r.BodyView.Layout(gtx, theme)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			switch r.BodyView.GetSelected().GetValue() {
+			case bodyViewForm:
+				return r.Form.Layout(gtx, theme)
+			case bodyViewText:
+				return r.Body.Layout(gtx, theme, "Text format")
+			case bodyViewBinary:
+				return r.bodyFileLayout(gtx, theme)
+			default:
+				return r.Body.Layout(gtx, theme, "JSON")
+			}
+		}),
+	)
+}
+
+func (r *Request) bodyFileLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return r.BodyFile.Layout(gtx, theme)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			lb := material.Label(theme.Material(), unit.Sp(12), "The file holds the binary encoded message, its bytes are sent as they are.")
+			lb.Color = theme.ResponseStatusColor
+			return lb.Layout(gtx)
+		}),
+	)
+}
+
+func (r *Request) streamToolbar(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	canSend := r.streamOpen && !r.halfClosed
+	if r.sendButton.Clicked(gtx) && canSend && r.onStreamSend != nil {
+		r.onStreamSend()
+	}
+
+	if r.halfCloseButton.Clicked(gtx) && canSend && r.onHalfClose != nil {
+		r.halfClosed = true
+		r.onHalfClose()
+	}
+
+	hint := "Invoke the method to open the stream, then send the body as many times as needed"
+	switch {
+	case r.halfClosed:
+		hint = "Stream is half closed, waiting for the server to end the call"
+	case r.streamOpen:
+		hint = "Stream is open, each send writes the body as one message"
+	}
+
+	return layout.Inset{Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), unit.Sp(12), hint)
+				lb.Color = theme.ResponseStatusColor
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !canSend {
+					gtx = gtx.Disabled()
+				}
+				return widgets.Button(theme, &r.halfCloseButton, widgets.CloseIcon, widgets.IconPositionStart, "Half-close").Layout(gtx, theme)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !canSend {
+					gtx = gtx.Disabled()
+				}
+				btn := widgets.Button(theme, &r.sendButton, widgets.SendIcon, widgets.IconPositionEnd, "Send")
+				btn.Background = theme.ActionButtonBgColor
+				btn.Color = theme.ButtonTextColor
+				return btn.Layout(gtx, theme)
+			}),
+		)
+	})
+}
+
 func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	inset := layout.Inset{Top: unit.Dp(10)}
 	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -134,7 +369,7 @@ func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Di
 					})
 				case "Body":
 					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-						return r.Body.Layout(gtx, theme, "JSON")
+						return r.bodyLayout(gtx, theme)
 					})
 				case "Meta Data":
 					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
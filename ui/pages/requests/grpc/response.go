@@ -17,9 +17,28 @@ import (
 	"github.com/chapar-rest/chapar/ui/widgets/codeeditor"
 )
 
+// indexes of the response tabs
+const (
+	responseTabBody = iota
+	responseTabMetadata
+	responseTabTrailers
+	responseTabMessages
+	responseTabErrorDetails
+	responseTabTests
+)
+
+// views of the response body
+const (
+	responseViewJSON = "json"
+	responseViewText = "text"
+	responseViewHex  = "hex"
+)
+
 type Response struct {
 	copyButton *widgets.FlatButton
 	Tabs       *widgets.Tabs
+	// BodyView switches the body between json, the text format and the hex dump of the wire bytes
+	BodyView *widgets.DropDown
 
 	copyClickable widget.Clickable
 
@@ -28,13 +47,25 @@ type Response struct {
 	duration     time.Duration
 	responseSize int
 
-	Metadata   *codeeditor.CodeEditor
-	Trailers   *codeeditor.CodeEditor
-	jsonViewer *codeeditor.CodeEditor
+	Metadata     *codeeditor.CodeEditor
+	Trailers     *codeeditor.CodeEditor
+	ErrorDetails *codeeditor.CodeEditor
+	jsonViewer   *codeeditor.CodeEditor
+	messages     *Messages
+	tests        *component.TestResults
 
-	response string
-	message  string
-	err      error
+	response  string
+	message   string
+	err       error
+	cancelled bool
+
+	// responseText and responseHex are the response in the text format and its hex dumped wire bytes
+	responseText string
+	responseHex  string
+
+	// statusErr is the status of a failed call, it's shown in place of the body while the
+	// metadata, trailers and error details of the call stay available.
+	statusErr error
 
 	onCopyResponse func(gtx layout.Context, dataType, data string)
 
@@ -58,15 +89,27 @@ func NewResponse(theme *chapartheme.Theme) *Response {
 			{Title: "Body"},
 			{Title: "Metadata"},
 			{Title: "Trailers"},
+			{Title: "Messages"},
+			{Title: "Error details"},
+			{Title: "Tests"},
 		}, nil),
-		jsonViewer: codeeditor.NewCodeEditor("", codeeditor.CodeLanguageJSON, theme),
-		Metadata:   codeeditor.NewCodeEditor("", codeeditor.CodeLanguageProperties, theme),
-		Trailers:   codeeditor.NewCodeEditor("", codeeditor.CodeLanguageProperties, theme),
+		BodyView: widgets.NewDropDown(
+			widgets.NewDropDownOption("JSON").WithValue(responseViewJSON),
+			widgets.NewDropDownOption("Text format").WithValue(responseViewText),
+			widgets.NewDropDownOption("Hex").WithValue(responseViewHex),
+		),
+		jsonViewer:   codeeditor.NewCodeEditor("", codeeditor.CodeLanguageJSON, theme),
+		Metadata:     codeeditor.NewCodeEditor("", codeeditor.CodeLanguageProperties, theme),
+		Trailers:     codeeditor.NewCodeEditor("", codeeditor.CodeLanguageProperties, theme),
+		ErrorDetails: codeeditor.NewCodeEditor("", codeeditor.CodeLanguageJSON, theme),
+		messages:     NewMessages(),
+		tests:        component.NewTestResults(),
 	}
 
 	r.jsonViewer.SetReadOnly(true)
 	r.Metadata.SetReadOnly(true)
 	r.Trailers.SetReadOnly(true)
+	r.ErrorDetails.SetReadOnly(true)
 
 	return r
 }
@@ -77,12 +120,36 @@ func (r *Response) SetOnCopyResponse(f func(gtx layout.Context, dataType, data s
 
 func (r *Response) SetResponse(response string) {
 	r.response = response
+	r.responseText = ""
+	r.responseHex = ""
 	r.err = nil
+	r.statusErr = nil
 	r.message = ""
+	r.cancelled = false
 	r.isResponseUpdated = false
 	r.responseIsAvailable = true
 }
 
+// SetResponseFormats sets the response in the text format and its hex dumped wire bytes, it's called
+// after SetResponse.
+func (r *Response) SetResponseFormats(text, hexDump string) {
+	r.responseText = text
+	r.responseHex = hexDump
+	r.isResponseUpdated = false
+}
+
+// body returns the response in the selected view and the language to show it with.
+func (r *Response) body() (string, string) {
+	switch r.BodyView.GetSelected().GetValue() {
+	case responseViewText:
+		return r.responseText, codeeditor.CodeLanguageProtobuf
+	case responseViewHex:
+		return r.responseHex, ""
+	default:
+		return r.response, codeeditor.CodeLanguageJSON
+	}
+}
+
 func (r *Response) SetStatusParams(code int, status string, duration time.Duration, size int) {
 	r.responseCode = code
 	r.duration = duration
@@ -114,12 +181,70 @@ func (r *Response) SetTrailers(trailers []domain.KeyValue) {
 	r.Trailers.SetCode(domain.KeyValuesToText(trailers))
 }
 
+// SetErrorDetails sets the decoded details of the status error, the details tab is selected when a
+// failed call carries them as they tell more than its empty body.
+func (r *Response) SetErrorDetails(details string) {
+	r.ErrorDetails.SetCode(details)
+	if details != "" && r.Tabs.Selected() == responseTabBody {
+		r.Tabs.SetSelected(responseTabErrorDetails)
+	}
+}
+
+// SetStatusError sets the status of a failed call.
+func (r *Response) SetStatusError(err error) {
+	r.statusErr = err
+}
+
+func (r *Response) SetTests(tests []domain.TestResult) {
+	r.tests.SetTests(tests)
+}
+
 func (r *Response) SetMessage(message string) {
 	r.message = message
 }
 
 func (r *Response) SetError(err error) {
 	r.err = err
+	r.cancelled = false
+}
+
+// SetCancelled marks the last request as cancelled by the user, the previous response (if any) is kept
+// but hidden until the next response arrives.
+func (r *Response) SetCancelled() {
+	r.cancelled = true
+	r.err = nil
+	r.message = ""
+}
+
+// StartStream switches to the messages tab, the messages are added as they are sent and received by AddStreamMessage.
+func (r *Response) StartStream() {
+	r.messages.Start()
+	r.response = ""
+	r.responseText = ""
+	r.responseHex = ""
+	r.err = nil
+	r.message = ""
+	r.cancelled = false
+	r.isResponseUpdated = false
+	r.responseIsAvailable = true
+	r.Tabs.SetSelected(responseTabMessages)
+}
+
+func (r *Response) SetOnSaveMessages(f func(messages []domain.GRPCStreamMessage)) {
+	r.messages.SetOnSave(f)
+}
+
+func (r *Response) AddStreamMessage(msg domain.GRPCStreamMessage) {
+	r.messages.Add(msg)
+}
+
+// EndStream marks the end of the streaming call, err is the status the server ended it with.
+func (r *Response) EndStream(err error) {
+	r.messages.End(err)
+}
+
+func (r *Response) StopStream() {
+	r.messages.Stop()
 }
 
 func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
@@ -131,6 +256,10 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 		return component.Message(gtx, component.MessageTypeInfo, theme, r.message)
 	}
 
+	if r.cancelled {
+		return component.Message(gtx, component.MessageTypeWarning, theme, "Request cancelled")
+	}
+
 	if !r.responseIsAvailable {
 		return component.Message(gtx, component.MessageTypeInfo, theme, "No response available yet ;)")
 	}
@@ -151,8 +280,13 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 				return layout.Flex{Axis: layout.Horizontal, Spacing: layout.SpaceBetween, Alignment: layout.Middle}.Layout(gtx,
 					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 						return layout.Inset{Left: unit.Dp(5), Top: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							text := formatStatus(r.responseCode, r.status, r.duration, uint64(r.responseSize))
+							if r.messages.IsStreaming() {
+								text = "Streaming..."
+							}
+
 							l := material.LabelStyle{
-								Text:     formatStatus(r.responseCode, r.status, r.duration, uint64(r.responseSize)),
+								Text:     text,
 								Color:    theme.ResponseStatusColor,
 								TextSize: theme.TextSize,
 								Shaper:   theme.Shaper,
@@ -161,6 +295,15 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 							return l.Layout(gtx)
 						})
 					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !r.hasBodyViews() {
+							return layout.Dimensions{}
+						}
+						return layout.Inset{Right: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							r.BodyView.MinWidth = unit.Dp(100)
+							return r.BodyView.Layout(gtx, theme)
+						})
+					}),
 					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 						btn := widgets.Button(theme, &r.copyClickable, widgets.CopyIcon, widgets.IconPositionStart, "Copy")
 						btn.Inset = layout.Inset{
@@ -174,18 +317,32 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 				return layout.Inset{Left: unit.Dp(5), Top: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					switch r.Tabs.Selected() {
-					case 0:
+					case responseTabMetadata:
+						return r.Metadata.Layout(gtx, theme, "")
+					case responseTabTrailers:
+						return r.Trailers.Layout(gtx, theme, "")
+					case responseTabMessages:
+						r.messages.SetView(r.BodyView.GetSelected().GetValue())
+						return r.messages.Layout(gtx, theme)
+					case responseTabErrorDetails:
+						if r.ErrorDetails.Code() == "" {
+							return component.Message(gtx, component.MessageTypeInfo, theme, "The details of failed calls are shown here")
+						}
+						return r.ErrorDetails.Layout(gtx, theme, "")
+					case responseTabTests:
+						return r.tests.Layout(gtx, theme)
+					default:
+						if r.statusErr != nil {
+							return component.Message(gtx, component.MessageTypeError, theme, r.statusErr.Error())
+						}
 
-						if !r.isResponseUpdated {
-							r.jsonViewer.SetCode(r.response)
+						if r.BodyView.Changed() || !r.isResponseUpdated {
+							code, lang := r.body()
+							r.jsonViewer.SetLanguage(lang)
+							r.jsonViewer.SetCode(code)
 							r.isResponseUpdated = true
 						}
 						return r.jsonViewer.Layout(gtx, theme, "")
-
-					case 1:
-						return r.Metadata.Layout(gtx, theme, "")
-					default:
-						return r.Trailers.Layout(gtx, theme, "")
 					}
 				})
 			}),
@@ -193,6 +350,19 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 	})
 }
 
+// hasBodyViews reports whether the selected tab can be shown in the other views, they are there for
+// the responses of calls and the messages of streams.
+func (r *Response) hasBodyViews() bool {
+	switch r.Tabs.Selected() {
+	case responseTabBody:
+		return r.responseHex != ""
+	case responseTabMessages:
+		return r.messages.Len() > 0
+	default:
+		return false
+	}
+}
+
 func formatStatus(statueCode int, status string, duration time.Duration, size uint64) string {
 	return fmt.Sprintf("%d %s, %s, %s", statueCode, status, duration, humanize.Bytes(size))
 }
@@ -203,11 +373,18 @@ func (r *Response) handleCopy(gtx layout.Context) {
 	}
 
 	switch r.Tabs.Selected() {
-	case 0:
-		r.onCopyResponse(gtx, "Response", r.response)
-	case 1:
+	case responseTabMetadata:
 		r.onCopyResponse(gtx, "Metadata", r.Metadata.Code())
-	default:
+	case responseTabTrailers:
 		r.onCopyResponse(gtx, "Trailers", r.Trailers.Code())
+	case responseTabMessages:
+		r.onCopyResponse(gtx, "Messages", r.messages.String())
+	case responseTabErrorDetails:
+		r.onCopyResponse(gtx, "Error details", r.ErrorDetails.Code())
+	case responseTabTests:
+		r.onCopyResponse(gtx, "Tests", r.tests.String())
+	default:
+		code, _ := r.body()
+		r.onCopyResponse(gtx, "Response", code)
 	}
 }
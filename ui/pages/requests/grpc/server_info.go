@@ -34,7 +34,7 @@ func NewServerInfo(explorer *explorer.Explorer, info domain.ServerInfo) *ServerI
 
 	s := &ServerInfo{
 		definitionFrom:      new(widget.Enum),
-		FileSelector:        widgets.NewFileSelector(fileName, explorer, ".proto"),
+		FileSelector:        widgets.NewFileSelector(fileName, explorer, ".proto", ".protoset", ".binpb", ".pb"),
 		ReloadButton:        new(widget.Clickable),
 		CreateCollectionBtn: new(widget.Clickable),
 		IsLoading:           false,
@@ -0,0 +1,205 @@
+package restful
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/pages/requests/component"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Events shows the events of a text/event-stream response as they arrive.
+type Events struct {
+	// events are added from the request goroutine while the list is laid out
+	mu        sync.Mutex
+	events    []domain.SSEEvent
+	streaming bool
+	stopped   bool
+	resuming  bool
+	err       error
+
+	list            widget.List
+	reconnectButton widget.Clickable
+
+	onReconnect func(lastEventID string)
+}
+
+func NewEvents() *Events {
+	return &Events{
+		list: widget.List{
+			List: layout.List{Axis: layout.Vertical, ScrollToEnd: true},
+		},
+	}
+}
+
+func (e *Events) SetOnReconnect(f func(lastEventID string)) {
+	e.onReconnect = f
+}
+
+// Start marks the beginning of a stream, the previous events are kept only when the stream is resumed by reconnect.
+func (e *Events) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.resuming {
+		e.events = nil
+	}
+	e.resuming = false
+	e.streaming = true
+	e.stopped = false
+	e.err = nil
+}
+
+func (e *Events) Add(event domain.SSEEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+// End marks the end of the stream, err is nil when the server closed it.
+func (e *Events) End(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.streaming = false
+	e.err = err
+}
+
+// Stop marks the stream as stopped by the user.
+func (e *Events) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.streaming = false
+	e.stopped = true
+}
+
+// Reset drops a pending resume, e.g. when the reconnect failed.
+func (e *Events) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.resuming = false
+}
+
+// IsActive reports whether the last response was an event stream.
+func (e *Events) IsActive() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.streaming || e.stopped || e.err != nil || len(e.events) > 0
+}
+
+func (e *Events) lastEventID() string {
+	for i := len(e.events) - 1; i >= 0; i-- {
+		if e.events[i].ID != "" {
+			return e.events[i].ID
+		}
+	}
+	return ""
+}
+
+// String returns the events in the text/event-stream format, used to copy them.
+func (e *Events) String() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var sb strings.Builder
+	for _, ev := range e.events {
+		sb.WriteString(ev.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func (e *Events) status() string {
+	count := fmt.Sprintf("%d events", len(e.events))
+	switch {
+	case e.streaming:
+		return "Streaming, " + count
+	case e.stopped:
+		return "Stopped, " + count
+	case e.err != nil:
+		return fmt.Sprintf("Stream failed: %s, %s", e.err, count)
+	default:
+		return "Stream closed by the server, " + count
+	}
+}
+
+func (e *Events) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.streaming && !e.stopped && e.err == nil && len(e.events) == 0 {
+		return component.Message(gtx, component.MessageTypeInfo, theme, "Events of text/event-stream responses are shown here")
+	}
+
+	if e.reconnectButton.Clicked(gtx) && !e.streaming && e.onReconnect != nil {
+		e.resuming = true
+		e.onReconnect(e.lastEventID())
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+					layout.Flexed(1, material.Label(theme.Material(), theme.TextSize, e.status()).Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if e.streaming {
+							return layout.Dimensions{}
+						}
+
+						text := "Reconnect"
+						if id := e.lastEventID(); id != "" {
+							text = fmt.Sprintf("Reconnect from %s", id)
+						}
+						btn := widgets.Button(theme, &e.reconnectButton, widgets.RefreshIcon, widgets.IconPositionStart, text)
+						return btn.Layout(gtx, theme)
+					}),
+				)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return material.List(theme.Material(), &e.list).Layout(gtx, len(e.events), func(gtx layout.Context, i int) layout.Dimensions {
+				return e.eventLayout(gtx, theme, e.events[i])
+			})
+		}),
+	)
+}
+
+func (e *Events) eventLayout(gtx layout.Context, theme *chapartheme.Theme, event domain.SSEEvent) layout.Dimensions {
+	header := event.Event
+	if event.ID != "" {
+		header += "  id: " + event.ID
+	}
+	if event.Retry > 0 {
+		header += "  retry: " + event.Retry.String()
+	}
+	header += "  " + event.ReceivedAt.Format("15:04:05.000")
+
+	return layout.Inset{Bottom: unit.Dp(8), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), unit.Sp(12), header)
+				lb.Font.Weight = font.Bold
+				lb.Color = theme.ResponseStatusColor
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), theme.TextSize, event.Data)
+				lb.Font.Typeface = theme.Face
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return widgets.DrawLine(gtx, theme.SeparatorColor, unit.Dp(1), unit.Dp(gtx.Metric.PxToDp(gtx.Constraints.Max.X)))
+				})
+			}),
+		)
+	})
+}
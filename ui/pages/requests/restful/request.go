@@ -22,6 +22,7 @@ type Request struct {
 	Headers   *component.Headers
 	Variables *component.Variables
 	Auth      *component.Auth
+	Proxy     *component.Proxy
 
 	currentTab  string
 	OnTabChange func(title string)
@@ -43,11 +44,13 @@ func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapart
 			{Title: "Variables"},
 			{Title: "Pre Request"},
 			{Title: "Post Request"},
+			{Title: "Settings"},
 		}, nil),
 		PreRequest: component.NewPrePostRequest([]component.Option{
 			{Title: "None", Value: domain.PrePostTypeNone},
 			{Title: "Trigger request", Value: domain.PrePostTypeTriggerRequest, Type: component.TypeTriggerRequest, Hint: "Trigger another request"},
 			{Title: "Python", Value: domain.PrePostTypePython, Type: component.TypeScript, Hint: "Write your pre request python script here"},
+			{Title: "JavaScript", Value: domain.PrePostTypeJavaScript, Type: component.TypeScript, Hint: "Write your pre request javascript script here"},
 			//	{Title: "Shell Script", Value: domain.PostRequestTypeSSHTunnel, Type: component.TypeScript, Hint: "Write your pre request shell script here"},
 			//	{Title: "Kubectl tunnel", Value: domain.PostRequestTypeK8sTunnel, Type: component.TypeScript, Hint: "Run kubectl port-forward command"},
 			//	{Title: "SSH tunnel", Value: domain.PostRequestTypeSSHTunnel, Type: component.TypeScript, Hint: "Run ssh command"},
@@ -56,6 +59,7 @@ func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapart
 			{Title: "None", Value: domain.PrePostTypeNone},
 			{Title: "Set Environment Variable", Value: domain.PrePostTypeSetEnv, Type: component.TypeSetEnv, Hint: "Set environment variable"},
 			{Title: "Python", Value: domain.PrePostTypePython, Type: component.TypeScript, Hint: "Write your post request python script here"},
+			{Title: "JavaScript", Value: domain.PrePostTypeJavaScript, Type: component.TypeScript, Hint: "Write your post request javascript script here"},
 			//	{Title: "Shell Script", Value: domain.PostRequestTypeShellScript, Type: component.TypeScript, Hint: "Write your post request shell script here"},
 		}, postRequestDropDown, theme),
 
@@ -64,6 +68,7 @@ func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapart
 		Headers:   component.NewHeaders(nil),
 		Auth:      component.NewAuth(req.Spec.HTTP.Request.Auth, theme),
 		Variables: component.NewVariables(theme, domain.RequestTypeHTTP),
+		Proxy:     component.NewProxy(nil),
 	}
 
 	if req.Spec != (domain.RequestSpec{}) && req.Spec.HTTP != nil && req.Spec.HTTP.Request != nil {
@@ -88,6 +93,8 @@ func NewRequest(req *domain.Request, explorer *explorer.Explorer, theme *chapart
 		if req.Spec.HTTP.Request.Variables != nil {
 			r.Variables.SetValues(req.Spec.HTTP.Request.Variables)
 		}
+
+		r.Proxy.SetProxy(req.Spec.HTTP.Request.Proxy)
 	}
 
 	return r
@@ -126,6 +133,8 @@ func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Di
 					return r.Variables.Layout(gtx, "Variables", "", theme)
 				case "Body":
 					return r.Body.Layout(gtx, theme)
+				case "Settings":
+					return r.Proxy.Layout(gtx, theme)
 				default:
 					return layout.Dimensions{}
 				}
@@ -18,6 +18,16 @@ import (
 	"github.com/chapar-rest/chapar/ui/widgets/codeeditor"
 )
 
+// indexes of the response tabs
+const (
+	responseTabBody = iota
+	responseTabHeaders
+	responseTabCookies
+	responseTabEvents
+	responseTabTiming
+	responseTabTests
+)
+
 type Response struct {
 	copyButton *widgets.FlatButton
 	Tabs       *widgets.Tabs
@@ -31,10 +41,14 @@ type Response struct {
 	responseHeaders *codeeditor.CodeEditor
 	responseCookies *codeeditor.CodeEditor
 	jsonViewer      *codeeditor.CodeEditor
+	timing          *component.Timing
+	events          *Events
+	tests           *component.TestResults
 
-	response string
-	message  string
-	err      error
+	response  string
+	message   string
+	err       error
+	cancelled bool
 
 	onCopyResponse func(gtx layout.Context, dataType, data string)
 
@@ -58,10 +72,16 @@ func NewResponse(theme *chapartheme.Theme) *Response {
 			{Title: "Body"},
 			{Title: "Headers"},
 			{Title: "Cookies"},
+			{Title: "Events"},
+			{Title: "Timing"},
+			{Title: "Tests"},
 		}, nil),
 		jsonViewer:      codeeditor.NewCodeEditor("", codeeditor.CodeLanguageJSON, theme),
 		responseHeaders: codeeditor.NewCodeEditor("", codeeditor.CodeLanguageProperties, theme),
 		responseCookies: codeeditor.NewCodeEditor("", codeeditor.CodeLanguageProperties, theme),
+		timing:          component.NewTiming(),
+		events:          NewEvents(),
+		tests:           component.NewTestResults(),
 	}
 
 	r.jsonViewer.SetReadOnly(true)
@@ -79,6 +99,7 @@ func (r *Response) SetResponse(response string) {
 	r.response = response
 	r.err = nil
 	r.message = ""
+	r.cancelled = false
 	r.isResponseUpdated = false
 	r.responseIsAvailable = true
 }
@@ -114,12 +135,53 @@ func (r *Response) SetMessage(message string) {
 
 func (r *Response) SetError(err error) {
 	r.err = err
+	r.cancelled = false
+	r.events.Reset()
+}
+
+// SetCancelled marks the last request as cancelled by the user, the previous response (if any) is kept
+// but hidden until the next response arrives.
+func (r *Response) SetCancelled() {
+	r.events.Reset()
+	r.cancelled = true
+	r.err = nil
+	r.message = ""
 }
 
 func (r *Response) SetCookies(cookies []domain.KeyValue) {
 	r.responseCookies.SetCode(domain.KeyValuesToText(cookies))
 }
 
+func (r *Response) SetTiming(timing domain.Timing) {
+	r.timing.SetTiming(timing)
+}
+
+func (r *Response) SetTests(tests []domain.TestResult) {
+	r.tests.SetTests(tests)
+}
+
+func (r *Response) SetOnReconnect(f func(lastEventID string)) {
+	r.events.SetOnReconnect(f)
+}
+
+// StartEventStream switches to the events tab, the events are added as they arrive by AddEvent.
+func (r *Response) StartEventStream() {
+	r.events.Start()
+	r.Tabs.SetSelected(responseTabEvents)
+}
+
+func (r *Response) AddEvent(event domain.SSEEvent) {
+	r.events.Add(event)
+}
+
+func (r *Response) EndEventStream(err error) {
+	r.events.End(err)
+}
+
+func (r *Response) StopEventStream() {
+	r.events.Stop()
+}
+
 func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
 	if r.err != nil {
 		return component.Message(gtx, component.MessageTypeError, theme, r.err.Error())
@@ -129,6 +191,10 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 		return component.Message(gtx, component.MessageTypeInfo, theme, r.message)
 	}
 
+	if r.cancelled {
+		return component.Message(gtx, component.MessageTypeWarning, theme, "Request cancelled")
+	}
+
 	if !r.responseIsAvailable {
 		return component.Message(gtx, component.MessageTypeInfo, theme, "No response available yet ;)")
 	}
@@ -168,10 +234,16 @@ func (r *Response) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.D
 			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 				return layout.Inset{Left: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 					switch r.Tabs.Selected() {
-					case 1:
+					case responseTabHeaders:
 						return r.responseHeaders.Layout(gtx, theme, "")
-					case 2:
+					case responseTabCookies:
 						return r.responseCookies.Layout(gtx, theme, "")
+					case responseTabEvents:
+						return r.events.Layout(gtx, theme)
+					case responseTabTiming:
+						return r.timing.Layout(gtx, theme)
+					case responseTabTests:
+						return r.tests.Layout(gtx, theme)
 					default:
 
 						if !r.isResponseUpdated {
@@ -193,10 +265,16 @@ func formatStatus(statueCode int, duration time.Duration, size uint64) string {
 
 func (r *Response) handleCopy(gtx layout.Context) {
 	switch r.Tabs.Selected() {
-	case 1:
+	case responseTabHeaders:
 		r.onCopyResponse(gtx, "Headers", r.responseHeaders.Code())
-	case 2:
+	case responseTabCookies:
 		r.onCopyResponse(gtx, "Cookies", r.responseCookies.Code())
+	case responseTabEvents:
+		r.onCopyResponse(gtx, "Events", r.events.String())
+	case responseTabTiming:
+		r.onCopyResponse(gtx, "Timing", r.timing.String())
+	case responseTabTests:
+		r.onCopyResponse(gtx, "Tests", r.tests.String())
 	default:
 		r.onCopyResponse(gtx, "Response", r.response)
 	}
@@ -33,6 +33,7 @@ type Restful struct {
 	onSave        func(id string)
 	onDataChanged func(id string, data any)
 	onSubmit      func(id string)
+	onCancel      func(id string)
 
 	SaveButton widget.Clickable
 	CodeButton widget.Clickable
@@ -117,6 +118,37 @@ func (r *Restful) SetOnSubmit(f func(id string)) {
 	r.onSubmit = f
 }
 
+func (r *Restful) SetOnCancel(f func(id string)) {
+	r.onCancel = f
+}
+
+func (r *Restful) SetOnReconnect(f func(id, lastEventID string)) {
+	r.Response.SetOnReconnect(func(lastEventID string) {
+		f(r.Req.MetaData.ID, lastEventID)
+	})
+}
+
+func (r *Restful) StartEventStream() {
+	r.Response.StartEventStream()
+}
+
+func (r *Restful) AddEvent(event domain.SSEEvent) {
+	r.Response.AddEvent(event)
+}
+
+func (r *Restful) EndEventStream(err error) {
+	r.Response.EndEventStream(err)
+}
+
+func (r *Restful) StopEventStream() {
+	r.Response.StopEventStream()
+}
+
+// SetTests shows the results of the tests of the post request script, streams get them once they end.
+func (r *Restful) SetTests(tests []domain.TestResult) {
+	r.Response.SetTests(tests)
+}
+
 func (r *Restful) SetURL(url string) {
 	r.AddressBar.SetURL(url)
 }
@@ -153,6 +185,10 @@ func (r *Restful) SetTitle(title string) {
 	r.Breadcrumb.SetTitle(title)
 }
 
+func (r *Restful) SetOnClearOAuth2Token(f func()) {
+	r.Request.Auth.SetOnClearToken(f)
+}
+
 func (r *Restful) SetOnCopyResponse(f func(gtx layout.Context, dataType, data string)) {
 	r.Response.SetOnCopyResponse(f)
 }
@@ -168,6 +204,8 @@ func (r *Restful) SetHTTPResponse(detail domain.HTTPResponseDetail) {
 	r.Response.SetHeaders(detail.RequestHeaders, detail.ResponseHeaders)
 	r.Response.SetCookies(detail.Cookies)
 	r.Response.SetStatusParams(detail.StatusCode, detail.Duration, detail.Size)
+	r.Response.SetTiming(detail.Timing)
+	r.Response.SetTests(detail.Tests)
 }
 
 func (r *Restful) GetHTTPResponse() *domain.HTTPResponseDetail {
@@ -179,13 +217,19 @@ func (r *Restful) GetHTTPResponse() *domain.HTTPResponseDetail {
 }
 
 func (r *Restful) ShowSendingRequestLoading() {
+	r.AddressBar.SetLoading(true)
 	r.Response.SetMessage("Sending request...")
 }
 
 func (r *Restful) HideSendingRequestLoading() {
+	r.AddressBar.SetLoading(false)
 	r.Response.SetMessage("")
 }
 
+func (r *Restful) SetCancelled() {
+	r.Response.SetCancelled()
+}
+
 func (r *Restful) SetOnSave(f func(id string)) {
 	r.onSave = f
 }
@@ -220,6 +264,12 @@ func (r *Restful) setupHooks() {
 		r.onSubmit(r.Req.MetaData.ID)
 	})
 
+	r.AddressBar.SetOnCancel(func() {
+		if r.onCancel != nil {
+			r.onCancel(r.Req.MetaData.ID)
+		}
+	})
+
 	r.Request.Params.SetOnChange(func(queryParams []domain.KeyValue, urlParams []domain.KeyValue) {
 		r.Req.Spec.HTTP.Request.QueryParams = queryParams
 		r.Req.Spec.HTTP.Request.PathParams = urlParams
@@ -246,10 +296,10 @@ func (r *Restful) setupHooks() {
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
-	// r.Request.PreRequest.SetOnScriptChanged(func(code string) {
-	//	r.Req.Spec.HTTP.Request.PreRequest.Script = code
-	//	r.onDataChanged(r.Req.MetaData.ID, r.Req)
-	// })
+	r.Request.PreRequest.SetOnScriptChanged(func(code string) {
+		r.Req.Spec.HTTP.Request.PreRequest.Script = code
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
 
 	r.Request.PostRequest.SetOnDropDownChanged(func(selected string) {
 		r.Req.Spec.HTTP.Request.PostRequest.Type = selected
@@ -271,6 +321,11 @@ func (r *Restful) setupHooks() {
 		r.onDataChanged(r.Req.MetaData.ID, r.Req)
 	})
 
+	r.Request.Proxy.SetOnChange(func(proxy *domain.ProxyConfig) {
+		r.Req.Spec.HTTP.Request.Proxy = proxy
+		r.onDataChanged(r.Req.MetaData.ID, r.Req)
+	})
+
 	prefs.AddGlobalConfigChangeListener(func(old, updated domain.GlobalConfig) {
 		isChanged := old.Spec.General.UseHorizontalSplit != updated.Spec.General.UseHorizontalSplit
 		if isChanged {
@@ -7,6 +7,7 @@ import (
 	"gioui.org/widget"
 	giox "gioui.org/x/component"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	"github.com/chapar-rest/chapar/ui"
 	"github.com/chapar-rest/chapar/ui/explorer"
@@ -14,8 +15,10 @@ import (
 	"github.com/chapar-rest/chapar/ui/navigator"
 	"github.com/chapar-rest/chapar/ui/pages/requests/graphql"
 	"github.com/chapar-rest/chapar/ui/pages/requests/grpc"
+	"github.com/chapar-rest/chapar/ui/pages/requests/websocket"
 
 	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/gql"
 	"github.com/chapar-rest/chapar/internal/safemap"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
 	"github.com/chapar-rest/chapar/ui/keys"
@@ -28,19 +31,21 @@ import (
 var (
 	_ navigator.View = &View{}
 
-	newGRPCRequest    = modals.NewCreateItem(string(domain.RequestTypeGRPC), widgets.GRPCIcon, "GRPC")
-	newHTTPRequest    = modals.NewCreateItem(string(domain.RequestTypeHTTP), widgets.HTTPIcon, "HTTP")
-	newGraphQLRequest = modals.NewCreateItem(string(domain.RequestTypeGraphQL), widgets.GraphQLIcon, "GraphQL")
-	newHCollection    = modals.NewCreateItem(string(domain.KindCollection), widgets.CollectionIcon, "Collection")
+	newGRPCRequest      = modals.NewCreateItem(string(domain.RequestTypeGRPC), widgets.GRPCIcon, "GRPC")
+	newHTTPRequest      = modals.NewCreateItem(string(domain.RequestTypeHTTP), widgets.HTTPIcon, "HTTP")
+	newGraphQLRequest   = modals.NewCreateItem(string(domain.RequestTypeGraphQL), widgets.GraphQLIcon, "GraphQL")
+	newWebSocketRequest = modals.NewCreateItem(string(domain.RequestTypeWebSocket), widgets.WebSocketIcon, "WebSocket")
+	newHCollection      = modals.NewCreateItem(string(domain.KindCollection), widgets.CollectionIcon, "Collection")
 )
 
 const (
-	MenuDuplicate         = "Duplicate"
-	MenuDelete            = "Delete"
-	MenuAddHTTPRequest    = "Add HTTP Request"
-	MenuAddGRPCRequest    = "Add GRPC Request"
-	MenuAddGraphQLRequest = "Add GraphQL Request"
-	MenuView              = "View"
+	MenuDuplicate           = "Duplicate"
+	MenuDelete              = "Delete"
+	MenuAddHTTPRequest      = "Add HTTP Request"
+	MenuAddGRPCRequest      = "Add GRPC Request"
+	MenuAddGraphQLRequest   = "Add GraphQL Request"
+	MenuAddWebSocketRequest = "Add WebSocket Request"
+	MenuView                = "View"
 )
 
 // RequestController is the interface the View uses to notify its controller of events.
@@ -55,7 +60,10 @@ type RequestController interface {
 	OnDataChanged(id string, data any, containerType string)
 	OnSave(id string)
 	OnSubmit(id, containerType string)
+	OnCancelRequest(id string)
+	OnReconnect(id, lastEventID string)
 	OnCopyResponse(gtx layout.Context, dataType, data string)
+	OnClearOAuth2Token()
 	OnPostRequestSetChanged(id string, statusCode int, item, from, fromKey string)
 	OnSetOnTriggerRequestChanged(id, collectionID, requestID string)
 	OnBinaryFileSelect(id string)
@@ -63,8 +71,20 @@ type RequestController interface {
 	OnServerInfoReload(id string)
 	OnGrpcInvoke(id string)
 	OnGrpcLoadRequestExample(id string)
+	OnGrpcStreamSend(id, body string)
+	OnGrpcHalfClose(id string)
+	OnGrpcSaveMessages(id string, messages []domain.GRPCStreamMessage)
+	OnGrpcReconnect(id string)
+	OnGrpcLoadRequestForm(id string)
+	OnGrpcConvertBody(id, body, from, to string)
+	GrpcConnectionState(id string) string
 	OnRequestTabChanged(id, tab string)
 	OnCreateCollectionFromMethods(requestID string)
+	OnWebSocketConnect(id string)
+	OnWebSocketDisconnect(id string)
+	OnWebSocketSend(id string, msg domain.WebSocketMessage)
+	OnWebSocketPing(id string)
+	OnGraphQLLoadSchema(id string, refresh bool)
 }
 
 type View struct {
@@ -257,7 +277,7 @@ func (v *View) AddCollectionTreeViewNode(collection *domain.Collection) {
 		Text:        collection.MetaData.Name,
 		Identifier:  collection.MetaData.ID,
 		Children:    make([]*widgets.TreeNode, 0),
-		MenuOptions: []string{MenuAddHTTPRequest, MenuAddGRPCRequest, MenuAddGraphQLRequest, MenuDuplicate, MenuView, MenuDelete},
+		MenuOptions: []string{MenuAddHTTPRequest, MenuAddGRPCRequest, MenuAddGraphQLRequest, MenuAddWebSocketRequest, MenuDuplicate, MenuView, MenuDelete},
 		Meta:        safemap.New[string](),
 	}
 
@@ -299,6 +319,23 @@ func (v *View) SetGRPCServices(id string, services []domain.GRPCService) {
 	}
 }
 
+func (v *View) SetGRPCConnectionState(id, state string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.SetConnectionState(state)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetGRPCRequestDescriptor(id string, desc protoreflect.MessageDescriptor) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.SetRequestDescriptor(desc)
+		}
+	}
+}
+
 func (v *View) SetGRPCMethodsLoading(id string, loading bool) {
 	if ct, ok := v.containers.Get(id); ok {
 		if ct, ok := ct.(GrpcContainer); ok {
@@ -419,22 +456,38 @@ func (v *View) OpenRequestContainer(req *domain.Request) {
 
 	if req.MetaData.Type == domain.RequestTypeHTTP {
 		ct := v.createRestfulContainer(req)
-		v.containers.Set(req.MetaData.ID, ct)
+		v.addContainer(req.MetaData.ID, ct)
 	}
 
 	if req.MetaData.Type == domain.RequestTypeGRPC {
 		ct := v.createGrpcContainer(req)
-		v.containers.Set(req.MetaData.ID, ct)
+		v.addContainer(req.MetaData.ID, ct)
 	}
 
 	if req.MetaData.Type == domain.RequestTypeGraphQL {
 		ct := v.createGraphQLContainer(req)
-		v.containers.Set(req.MetaData.ID, ct)
+		v.addContainer(req.MetaData.ID, ct)
+	}
+
+	if req.MetaData.Type == domain.RequestTypeWebSocket {
+		ct := v.createWebSocketContainer(req)
+		v.addContainer(req.MetaData.ID, ct)
 	}
 
 	v.window.Invalidate()
 }
 
+// addContainer sets the hooks shared by all containers and adds it to the open ones.
+func (v *View) addContainer(id string, ct Container) {
+	ct.SetOnClearOAuth2Token(func() {
+		if v.controller != nil {
+			v.controller.OnClearOAuth2Token()
+		}
+	})
+
+	v.containers.Set(id, ct)
+}
+
 func (v *View) createGrpcContainer(req *domain.Request) Container {
 	ct := grpc.New(req, v.theme, v.explorer)
 
@@ -468,6 +521,30 @@ func (v *View) createGrpcContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnCancel(func(id string) {
+		if v.controller != nil {
+			v.controller.OnCancelRequest(id)
+		}
+	})
+
+	ct.SetOnStreamSend(func(id, body string) {
+		if v.controller != nil {
+			v.controller.OnGrpcStreamSend(id, body)
+		}
+	})
+
+	ct.SetOnHalfClose(func(id string) {
+		if v.controller != nil {
+			v.controller.OnGrpcHalfClose(id)
+		}
+	})
+
+	ct.SetOnSaveMessages(func(id string, messages []domain.GRPCStreamMessage) {
+		if v.controller != nil {
+			v.controller.OnGrpcSaveMessages(id, messages)
+		}
+	})
+
 	ct.SetOnSetOnTriggerRequestChanged(func(id, collectionID, requestID string) {
 		if v.controller != nil {
 			v.controller.OnSetOnTriggerRequestChanged(id, collectionID, requestID)
@@ -504,6 +581,28 @@ func (v *View) createGrpcContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnReconnect(func(id string) {
+		if v.controller != nil {
+			v.controller.OnGrpcReconnect(id)
+		}
+	})
+
+	ct.SetOnLoadRequestForm(func(id string) {
+		if v.controller != nil {
+			v.controller.OnGrpcLoadRequestForm(id)
+		}
+	})
+
+	ct.SetOnConvertBody(func(id, body, from, to string) {
+		if v.controller != nil {
+			v.controller.OnGrpcConvertBody(id, body, from, to)
+		}
+	})
+
+	if v.controller != nil {
+		ct.SetConnectionState(v.controller.GrpcConnectionState(req.MetaData.ID))
+	}
+
 	return ct
 }
 
@@ -534,6 +633,18 @@ func (v *View) createRestfulContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnCancel(func(id string) {
+		if v.controller != nil {
+			v.controller.OnCancelRequest(id)
+		}
+	})
+
+	ct.SetOnReconnect(func(id, lastEventID string) {
+		if v.controller != nil {
+			v.controller.OnReconnect(id, lastEventID)
+		}
+	})
+
 	ct.SetOnCopyResponse(func(gtx layout.Context, dataType, data string) {
 		if v.controller != nil {
 			v.controller.OnCopyResponse(gtx, dataType, data)
@@ -600,6 +711,12 @@ func (v *View) createGraphQLContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnCancel(func(id string) {
+		if v.controller != nil {
+			v.controller.OnCancelRequest(id)
+		}
+	})
+
 	ct.SetOnCopyResponse(func(gtx layout.Context, dataType, data string) {
 		if v.controller != nil {
 			v.controller.OnCopyResponse(gtx, dataType, data)
@@ -624,6 +741,66 @@ func (v *View) createGraphQLContainer(req *domain.Request) Container {
 		}
 	})
 
+	ct.SetOnLoadSchema(func(id string, refresh bool) {
+		if v.controller != nil {
+			v.controller.OnGraphQLLoadSchema(id, refresh)
+		}
+	})
+
+	return ct
+}
+
+func (v *View) createWebSocketContainer(req *domain.Request) Container {
+	ct := websocket.New(req, v.theme)
+
+	ct.SetOnTitleChanged(func(text string) {
+		if v.controller != nil {
+			v.controller.OnTitleChanged(req.MetaData.ID, text, TypeRequest)
+		}
+	})
+
+	ct.SetOnSave(func(id string) {
+		if v.controller != nil {
+			v.controller.OnSave(id)
+		}
+	})
+
+	ct.SetOnDataChanged(func(id string, data any) {
+		if v.controller != nil {
+			v.controller.OnDataChanged(id, data, TypeRequest)
+		}
+	})
+
+	ct.SetOnConnect(func(id string) {
+		if v.controller != nil {
+			v.controller.OnWebSocketConnect(id)
+		}
+	})
+
+	ct.SetOnDisconnect(func(id string) {
+		if v.controller != nil {
+			v.controller.OnWebSocketDisconnect(id)
+		}
+	})
+
+	ct.SetOnSend(func(id string, msg domain.WebSocketMessage) {
+		if v.controller != nil {
+			v.controller.OnWebSocketSend(id, msg)
+		}
+	})
+
+	ct.SetOnPing(func(id string) {
+		if v.controller != nil {
+			v.controller.OnWebSocketPing(id)
+		}
+	})
+
+	ct.SetOnCopyResponse(func(gtx layout.Context, dataType, data string) {
+		if v.controller != nil {
+			v.controller.OnCopyResponse(gtx, dataType, data)
+		}
+	})
+
 	return ct
 }
 
@@ -636,6 +813,11 @@ func (v *View) SetRequestCollection(id string, collection *domain.Collection) {
 
 		if ct, ok := ct.(GraphQLContainer); ok {
 			ct.SetCollection(collection)
+			return
+		}
+
+		if ct, ok := ct.(WebSocketContainer); ok {
+			ct.SetCollection(collection)
 		}
 		// TODO: Add support for gRPC containers if needed
 	}
@@ -680,6 +862,8 @@ func (v *View) UpdateCollectionForOpenRequests(collectionID string, collection *
 }
 
 func (v *View) SetSendingRequestLoading(id string) {
+	defer v.window.Invalidate()
+
 	if ct, ok := v.containers.Get(id); ok {
 		if ct, ok := ct.(RestContainer); ok {
 			ct.ShowSendingRequestLoading()
@@ -698,6 +882,8 @@ func (v *View) SetSendingRequestLoading(id string) {
 }
 
 func (v *View) SetSendingRequestLoaded(id string) {
+	defer v.window.Invalidate()
+
 	if ct, ok := v.containers.Get(id); ok {
 		if ct, ok := ct.(RestContainer); ok {
 			ct.HideSendingRequestLoading()
@@ -715,6 +901,26 @@ func (v *View) SetSendingRequestLoaded(id string) {
 	}
 }
 
+func (v *View) SetRequestCancelled(id string) {
+	defer v.window.Invalidate()
+
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(RestContainer); ok {
+			ct.SetCancelled()
+			return
+		}
+
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.SetCancelled()
+			return
+		}
+
+		if ct, ok := ct.(GraphQLContainer); ok {
+			ct.SetCancelled()
+		}
+	}
+}
+
 func (v *View) SetQueryParams(id string, params []domain.KeyValue) {
 	if ct, ok := v.containers.Get(id); ok {
 		if ct, ok := ct.(RestContainer); ok {
@@ -749,7 +955,7 @@ func (v *View) OpenCollectionContainer(collection *domain.Collection) {
 		return
 	}
 
-	ct := collections.New(collection, v.theme)
+	ct := collections.New(collection, v.theme, v.explorer)
 	ct.SetOnTitleChanged(func(text string) {
 		if v.controller != nil {
 			v.controller.OnTitleChanged(collection.MetaData.ID, text, TypeCollection)
@@ -768,7 +974,7 @@ func (v *View) OpenCollectionContainer(collection *domain.Collection) {
 		}
 	})
 
-	v.containers.Set(collection.MetaData.ID, ct)
+	v.addContainer(collection.MetaData.ID, ct)
 }
 
 func (v *View) SetHTTPResponse(id string, response domain.HTTPResponseDetail) {
@@ -780,6 +986,48 @@ func (v *View) SetHTTPResponse(id string, response domain.HTTPResponseDetail) {
 	}
 }
 
+func (v *View) StartHTTPEventStream(id string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(RestContainer); ok {
+			ct.StartEventStream()
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) AddHTTPEvent(id string, event domain.SSEEvent) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(RestContainer); ok {
+			ct.AddEvent(event)
+			v.window.Invalidate()
+		}
+	}
+}
+
+// EndHTTPEventStream marks the event stream of the request as ended, stopped is true when it was stopped by the user.
+func (v *View) EndHTTPEventStream(id string, stopped bool, err error) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(RestContainer); ok {
+			if stopped {
+				ct.StopEventStream()
+			} else {
+				ct.EndEventStream(err)
+			}
+			v.window.Invalidate()
+		}
+	}
+}
+
+// SetHTTPTests shows the results of the tests of the post request script of the request.
+func (v *View) SetHTTPTests(id string, tests []domain.TestResult) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(RestContainer); ok {
+			ct.SetTests(tests)
+			v.window.Invalidate()
+		}
+	}
+}
+
 func (v *View) SetGRPCResponse(id string, response domain.GRPCResponseDetail) {
 	if ct, ok := v.containers.Get(id); ok {
 		if ct, ok := ct.(GrpcContainer); ok {
@@ -789,6 +1037,34 @@ func (v *View) SetGRPCResponse(id string, response domain.GRPCResponseDetail) {
 	}
 }
 
+func (v *View) StartGRPCStream(id string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.StartStream()
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) AddGRPCStreamMessage(id string, msg domain.GRPCStreamMessage) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.AddStreamMessage(msg)
+			v.window.Invalidate()
+		}
+	}
+}
+
+// EndGRPCStream shows the status of the ended stream of the request, stopped is true when it was cancelled by the user.
+func (v *View) EndGRPCStream(id string, stopped bool, response domain.GRPCResponseDetail) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GrpcContainer); ok {
+			ct.EndStream(stopped, response)
+			v.window.Invalidate()
+		}
+	}
+}
+
 func (v *View) SetGraphQLResponse(id string, response domain.GraphQLResponseDetail) {
 	if ct, ok := v.containers.Get(id); ok {
 		if ct, ok := ct.(GraphQLContainer); ok {
@@ -798,6 +1074,102 @@ func (v *View) SetGraphQLResponse(id string, response domain.GraphQLResponseDeta
 	}
 }
 
+func (v *View) StartGraphQLSubscription(id string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GraphQLContainer); ok {
+			ct.StartSubscription()
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) AddGraphQLSubscriptionMessage(id string, msg domain.GraphQLSubscriptionMessage) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GraphQLContainer); ok {
+			ct.AddSubscriptionMessage(msg)
+			v.window.Invalidate()
+		}
+	}
+}
+
+// EndGraphQLSubscription marks the subscription of the request as ended, stopped is true when it was stopped by the user.
+func (v *View) EndGraphQLSubscription(id string, stopped bool, err error) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GraphQLContainer); ok {
+			if stopped {
+				ct.StopSubscription()
+			} else {
+				ct.EndSubscription(err)
+			}
+			v.window.Invalidate()
+		}
+	}
+}
+
+// SetGraphQLTests shows the results of the tests of the post request script of the request.
+func (v *View) SetGraphQLTests(id string, tests []domain.TestResult) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GraphQLContainer); ok {
+			ct.SetTests(tests)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetGraphQLSchema(id string, schema *gql.Schema, err error) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GraphQLContainer); ok {
+			ct.SetSchema(schema, err)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetGraphQLSchemaLoading(id string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(GraphQLContainer); ok {
+			ct.SetSchemaLoading()
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetWebSocketConnecting(id string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(WebSocketContainer); ok {
+			ct.SetConnecting()
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetWebSocketConnected(id, subprotocol string) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(WebSocketContainer); ok {
+			ct.SetConnected(subprotocol)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) SetWebSocketDisconnected(id string, err error) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(WebSocketContainer); ok {
+			ct.SetDisconnected(err)
+			v.window.Invalidate()
+		}
+	}
+}
+
+func (v *View) AddWebSocketFrame(id string, frame domain.WebSocketFrame) {
+	if ct, ok := v.containers.Get(id); ok {
+		if ct, ok := ct.(WebSocketContainer); ok {
+			ct.AddFrame(frame)
+			v.window.Invalidate()
+		}
+	}
+}
+
 func (v *View) GetHTTPResponse(id string) *domain.HTTPResponseDetail {
 	if ct, ok := v.containers.Get(id); ok {
 		if ct, ok := ct.(RestContainer); ok {
@@ -881,7 +1253,7 @@ func (v *View) PopulateTreeView(requests []*domain.Request, collections []*domai
 			Text:        cl.MetaData.Name,
 			Identifier:  cl.MetaData.ID,
 			Children:    make([]*widgets.TreeNode, 0),
-			MenuOptions: []string{MenuAddHTTPRequest, MenuAddGRPCRequest, MenuAddGraphQLRequest, MenuDuplicate, MenuView, MenuDelete},
+			MenuOptions: []string{MenuAddHTTPRequest, MenuAddGRPCRequest, MenuAddGraphQLRequest, MenuAddWebSocketRequest, MenuDuplicate, MenuView, MenuDelete},
 			Meta:        safemap.New[string](),
 		}
 		parentNode.Meta.Set(TypeMeta, TypeCollection)
@@ -970,6 +1342,9 @@ func setNodePrefix(req *domain.Request, node *widgets.TreeNode) {
 	case domain.RequestTypeGraphQL:
 		node.Prefix = "GraphQL"
 		node.PrefixColor = chapartheme.GetRequestPrefixColor("GraphQL")
+	case domain.RequestTypeWebSocket:
+		node.Prefix = "WS"
+		node.PrefixColor = chapartheme.GetRequestPrefixColor("WS")
 	case domain.RequestTypeHTTP:
 		if req.Spec.HTTP != nil {
 			node.Prefix = req.Spec.HTTP.Method
@@ -1038,7 +1413,7 @@ func (v *View) requestList(gtx layout.Context, theme *chapartheme.Theme) layout.
 }
 
 func (v *View) showCreateNewModal() {
-	items := []*modals.CreateItem{newGRPCRequest, newHTTPRequest, newGraphQLRequest, newHCollection}
+	items := []*modals.CreateItem{newGRPCRequest, newHTTPRequest, newGraphQLRequest, newWebSocketRequest, newHCollection}
 
 	m := modals.NewCreateModal(items)
 	v.SetModal(func(gtx layout.Context) layout.Dimensions {
@@ -1089,6 +1464,8 @@ func (v *View) createNew(itemType domain.RequestType) {
 		v.controller.OnNewRequest(domain.RequestTypeHTTP)
 	case domain.RequestTypeGraphQL:
 		v.controller.OnNewRequest(domain.RequestTypeGraphQL)
+	case domain.RequestTypeWebSocket:
+		v.controller.OnNewRequest(domain.RequestTypeWebSocket)
 	case domain.KindCollection:
 		v.controller.OnNewCollection()
 	}
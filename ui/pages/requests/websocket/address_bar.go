@@ -0,0 +1,128 @@
+package websocket
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+const (
+	stateDisconnected = iota
+	stateConnecting
+	stateConnected
+)
+
+type AddressBar struct {
+	url *widgets.PatternEditor
+
+	connectClickable widget.Clickable
+
+	state int
+
+	onURLChanged func(url string)
+	onConnect    func()
+	onDisconnect func()
+}
+
+func NewAddressBar(url string) *AddressBar {
+	a := &AddressBar{
+		url: widgets.NewPatternEditor(),
+	}
+
+	a.url.SingleLine = true
+	a.url.Submit = true
+	a.url.SetText(url)
+
+	return a
+}
+
+func (a *AddressBar) SetOnURLChanged(onURLChanged func(url string)) {
+	a.onURLChanged = onURLChanged
+}
+
+func (a *AddressBar) SetOnConnect(onConnect func()) {
+	a.onConnect = onConnect
+}
+
+func (a *AddressBar) SetOnDisconnect(onDisconnect func()) {
+	a.onDisconnect = onDisconnect
+}
+
+func (a *AddressBar) SetURL(url string) {
+	a.url.SetText(url)
+}
+
+func (a *AddressBar) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if a.url.Changed() && a.onURLChanged != nil {
+		a.onURLChanged(a.url.Text())
+	}
+	if a.url.Submitted() && a.state == stateDisconnected && a.onConnect != nil {
+		a.onConnect()
+	}
+
+	borderColor := theme.BorderColor
+	if gtx.Focused(a.url) {
+		borderColor = theme.BorderColorFocused
+	}
+
+	border := widget.Border{
+		Color:        borderColor,
+		Width:        unit.Dp(1),
+		CornerRadius: unit.Dp(4),
+	}
+
+	return layout.Flex{
+		Axis:      layout.Horizontal,
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.Y = gtx.Dp(40)
+			return layout.Inset{Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return border.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{
+						Left:   unit.Dp(10),
+						Right:  unit.Dp(5),
+						Top:    unit.Dp(8),
+						Bottom: unit.Dp(8),
+					}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return a.url.Layout(gtx, theme, "wss://example.com/socket")
+					})
+				})
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.connectClickable.Clicked(gtx) {
+				if a.state == stateDisconnected {
+					if a.onConnect != nil {
+						a.onConnect()
+					}
+				} else if a.onDisconnect != nil {
+					a.onDisconnect()
+				}
+			}
+
+			gtx.Constraints.Min.X = gtx.Dp(100)
+			switch a.state {
+			case stateConnecting:
+				btn := material.Button(theme.Material(), &a.connectClickable, "Cancel")
+				btn.Background = theme.DeleteButtonBgColor
+				btn.Color = theme.ButtonTextColor
+				return btn.Layout(gtx)
+			case stateConnected:
+				btn := material.Button(theme.Material(), &a.connectClickable, "Disconnect")
+				btn.Background = theme.DeleteButtonBgColor
+				btn.Color = theme.ButtonTextColor
+				return btn.Layout(gtx)
+			default:
+				btn := material.Button(theme.Material(), &a.connectClickable, "Connect")
+				btn.Background = theme.ActionButtonBgColor
+				btn.Color = theme.ButtonTextColor
+				return btn.Layout(gtx)
+			}
+		}),
+	)
+}
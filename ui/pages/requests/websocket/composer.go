@@ -0,0 +1,274 @@
+package websocket
+
+import (
+	"fmt"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/google/uuid"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/widgets"
+	"github.com/chapar-rest/chapar/ui/widgets/codeeditor"
+)
+
+// Composer is where messages are written and sent, the messages can be saved as templates on the request.
+type Composer struct {
+	editor      *codeeditor.CodeEditor
+	messageType *widgets.DropDown
+	name        *widgets.TextField
+
+	sendButton widget.Clickable
+	pingButton widget.Clickable
+	saveButton widget.Clickable
+
+	templates []*messageTemplate
+	list      widget.List
+
+	connected bool
+
+	onSend             func(msg domain.WebSocketMessage)
+	onPing             func()
+	onTemplatesChanged func(messages []domain.WebSocketMessage)
+}
+
+type messageTemplate struct {
+	message domain.WebSocketMessage
+
+	loadButton   widget.Clickable
+	deleteButton widget.Clickable
+}
+
+func NewComposer(messages []domain.WebSocketMessage, theme *chapartheme.Theme) *Composer {
+	c := &Composer{
+		editor: codeeditor.NewCodeEditor("", codeeditor.CodeLanguageJSON, theme),
+		messageType: widgets.NewDropDown(
+			widgets.NewDropDownOption("Text").WithValue(domain.WebSocketMessageTypeText),
+			widgets.NewDropDownOption("Binary (base64)").WithValue(domain.WebSocketMessageTypeBinary),
+		),
+		name: widgets.NewTextField("", "Message name"),
+		list: widget.List{
+			List: layout.List{Axis: layout.Vertical},
+		},
+	}
+
+	c.name.SetMinWidth(150)
+	c.SetMessages(messages)
+	return c
+}
+
+func (c *Composer) SetMessages(messages []domain.WebSocketMessage) {
+	c.templates = make([]*messageTemplate, 0, len(messages))
+	for _, m := range messages {
+		c.templates = append(c.templates, &messageTemplate{message: m})
+	}
+}
+
+func (c *Composer) SetConnected(connected bool) {
+	c.connected = connected
+}
+
+func (c *Composer) SetOnSend(f func(msg domain.WebSocketMessage)) {
+	c.onSend = f
+}
+
+func (c *Composer) SetOnPing(f func()) {
+	c.onPing = f
+}
+
+func (c *Composer) SetOnTemplatesChanged(f func(messages []domain.WebSocketMessage)) {
+	c.onTemplatesChanged = f
+}
+
+func (c *Composer) message() domain.WebSocketMessage {
+	return domain.WebSocketMessage{
+		Name: c.name.GetText(),
+		Type: c.messageType.GetSelected().GetValue(),
+		Data: c.editor.Code(),
+	}
+}
+
+func (c *Composer) messages() []domain.WebSocketMessage {
+	out := make([]domain.WebSocketMessage, 0, len(c.templates))
+	for _, t := range c.templates {
+		out = append(out, t.message)
+	}
+	return out
+}
+
+// saveTemplate saves the message in the composer, a template with the same name is overwritten.
+func (c *Composer) saveTemplate() {
+	msg := c.message()
+	if msg.Name == "" {
+		msg.Name = fmt.Sprintf("Message %d", len(c.templates)+1)
+		c.name.SetText(msg.Name)
+	}
+
+	saved := false
+	for _, t := range c.templates {
+		if t.message.Name == msg.Name {
+			msg.ID = t.message.ID
+			t.message = msg
+			saved = true
+			break
+		}
+	}
+
+	if !saved {
+		msg.ID = uuid.NewString()
+		c.templates = append(c.templates, &messageTemplate{message: msg})
+	}
+
+	if c.onTemplatesChanged != nil {
+		c.onTemplatesChanged(c.messages())
+	}
+}
+
+func (c *Composer) loadTemplate(msg domain.WebSocketMessage) {
+	c.name.SetText(msg.Name)
+	c.messageType.SetSelectedByValue(msg.Type)
+	c.editor.SetCode(msg.Data)
+}
+
+func (c *Composer) deleteTemplate(index int) {
+	c.templates = append(c.templates[:index], c.templates[index+1:]...)
+	if c.onTemplatesChanged != nil {
+		c.onTemplatesChanged(c.messages())
+	}
+}
+
+func (c *Composer) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if c.sendButton.Clicked(gtx) && c.connected && c.onSend != nil {
+		c.onSend(c.message())
+	}
+
+	if c.pingButton.Clicked(gtx) && c.connected && c.onPing != nil {
+		c.onPing()
+	}
+
+	if c.saveButton.Clicked(gtx) {
+		c.saveTemplate()
+	}
+
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Top: unit.Dp(5), Bottom: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return c.toolbar(gtx, theme)
+					})
+				}),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return c.editor.Layout(gtx, theme, "Message")
+					})
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Dp(180)
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			return c.templatesLayout(gtx, theme)
+		}),
+	)
+}
+
+func (c *Composer) toolbar(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Max.X = gtx.Dp(160)
+			return c.messageType.Layout(gtx, theme)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return c.name.Layout(gtx, theme)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return widgets.Button(theme, &c.saveButton, widgets.SaveIcon, widgets.IconPositionStart, "Save").Layout(gtx, theme)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !c.connected {
+				gtx = gtx.Disabled()
+			}
+			return widgets.Button(theme, &c.pingButton, widgets.RefreshIcon, widgets.IconPositionStart, "Ping").Layout(gtx, theme)
+		}),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !c.connected {
+				gtx = gtx.Disabled()
+			}
+			btn := widgets.Button(theme, &c.sendButton, widgets.SendIcon, widgets.IconPositionEnd, "Send")
+			btn.Background = theme.ActionButtonBgColor
+			btn.Color = theme.ButtonTextColor
+			return btn.Layout(gtx, theme)
+		}),
+	)
+}
+
+func (c *Composer) templatesLayout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(10), Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				lb := material.Label(theme.Material(), theme.TextSize, "Saved messages")
+				lb.Font.Weight = font.Bold
+				return lb.Layout(gtx)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			if len(c.templates) == 0 {
+				lb := material.Label(theme.Material(), unit.Sp(12), "Save a message to reuse it later")
+				lb.Color = theme.ResponseStatusColor
+				return lb.Layout(gtx)
+			}
+
+			// deleting while the list is laid out would skip the next item
+			deleted := -1
+			dims := material.List(theme.Material(), &c.list).Layout(gtx, len(c.templates), func(gtx layout.Context, i int) layout.Dimensions {
+				t := c.templates[i]
+				if t.loadButton.Clicked(gtx) {
+					c.loadTemplate(t.message)
+				}
+
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return material.Clickable(gtx, &t.loadButton, func(gtx layout.Context) layout.Dimensions {
+							return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								gtx.Constraints.Min.X = gtx.Constraints.Max.X
+								text := t.message.Name
+								if t.message.Type == domain.WebSocketMessageTypeBinary {
+									text += " (binary)"
+								}
+								return material.Label(theme.Material(), theme.TextSize, text).Layout(gtx)
+							})
+						})
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						ib := widgets.IconButton{
+							Icon:      widgets.DeleteIcon,
+							Size:      unit.Dp(18),
+							Color:     theme.TextColor,
+							Clickable: &t.deleteButton,
+						}
+						dims := ib.Layout(gtx, theme)
+						if ib.Clicked() {
+							deleted = i
+						}
+						return dims
+					}),
+				)
+			})
+
+			if deleted >= 0 {
+				c.deleteTemplate(deleted)
+			}
+
+			return dims
+		}),
+	)
+}
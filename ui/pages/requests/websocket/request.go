@@ -0,0 +1,79 @@
+package websocket
+
+import (
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/converter"
+	"github.com/chapar-rest/chapar/ui/pages/requests/component"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+type Request struct {
+	Tabs *widgets.Tabs
+
+	Composer *Composer
+	Headers  *component.Headers
+	Auth     *component.Auth
+	Settings *widgets.Settings
+}
+
+func NewRequest(req *domain.Request, theme *chapartheme.Theme) *Request {
+	spec := req.Spec.WebSocket
+
+	r := &Request{
+		Tabs: widgets.NewTabs([]*widgets.Tab{
+			{Title: "Message"},
+			{Title: "Headers"},
+			{Title: "Auth"},
+			{Title: "Settings"},
+		}, nil),
+		Composer: NewComposer(spec.Messages, theme),
+		Headers:  component.NewHeaders(nil),
+		Auth:     component.NewAuth(spec.Auth, theme),
+		Settings: widgets.NewSettings(append([]*widgets.SettingItem{
+			widgets.NewTextItem("Subprotocols", "subprotocols", "Comma separated subprotocols to offer to the server e.g. graphql-transport-ws", strings.Join(spec.Subprotocols, ", ")).MinWidth(unit.Dp(400)).TextAlignment(text.Start),
+		}, converter.ProxySettingItems(spec.Proxy, true)...)),
+	}
+
+	if len(spec.Headers) > 0 {
+		r.Headers.SetHeaders(spec.Headers)
+	}
+
+	return r
+}
+
+func (r *Request) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	inset := layout.Inset{Top: unit.Dp(10)}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis:      layout.Vertical,
+			Alignment: layout.Start,
+		}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return r.Tabs.Layout(gtx, theme)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				switch r.Tabs.SelectedTab().Title {
+				case "Message":
+					return r.Composer.Layout(gtx, theme)
+				case "Headers":
+					return layout.Inset{Top: unit.Dp(5), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return r.Headers.Layout(gtx, theme)
+					})
+				case "Auth":
+					return r.Auth.Layout(gtx, theme)
+				case "Settings":
+					return r.Settings.Layout(gtx, theme)
+				default:
+					return layout.Dimensions{}
+				}
+			}),
+		)
+	})
+}
@@ -0,0 +1,195 @@
+package websocket
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"sync"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/pages/requests/component"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+// Timeline shows the frames sent and received on the connection in the order they happened.
+type Timeline struct {
+	// frames are added from the connection goroutine while the list is laid out
+	mu          sync.Mutex
+	frames      []domain.WebSocketFrame
+	state       int
+	subprotocol string
+	err         error
+
+	list        widget.List
+	clearButton widget.Clickable
+	copyButton  widget.Clickable
+
+	onCopy func(gtx layout.Context, dataType, data string)
+}
+
+func NewTimeline() *Timeline {
+	return &Timeline{
+		list: widget.List{
+			List: layout.List{Axis: layout.Vertical, ScrollToEnd: true},
+		},
+	}
+}
+
+func (t *Timeline) SetOnCopy(f func(gtx layout.Context, dataType, data string)) {
+	t.onCopy = f
+}
+
+// SetConnecting clears the frames of the previous connection.
+func (t *Timeline) SetConnecting() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.frames = nil
+	t.state = stateConnecting
+	t.err = nil
+}
+
+func (t *Timeline) SetConnected(subprotocol string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state = stateConnected
+	t.subprotocol = subprotocol
+}
+
+// SetDisconnected marks the connection as closed, err is nil when it was closed normally.
+func (t *Timeline) SetDisconnected(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state = stateDisconnected
+	t.err = err
+}
+
+func (t *Timeline) AddFrame(frame domain.WebSocketFrame) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.frames = append(t.frames, frame)
+}
+
+// String returns the frames one per line, used to copy them.
+func (t *Timeline) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var sb strings.Builder
+	for _, f := range t.frames {
+		sb.WriteString(fmt.Sprintf("%s %s %s %s\n", f.Time.Format("15:04:05.000"), f.Direction, f.Type, f.String()))
+	}
+	return sb.String()
+}
+
+func (t *Timeline) status() string {
+	count := fmt.Sprintf("%d frames", len(t.frames))
+	switch {
+	case t.state == stateConnecting:
+		return "Connecting..."
+	case t.state == stateConnected && t.subprotocol != "":
+		return fmt.Sprintf("Connected (%s), %s", t.subprotocol, count)
+	case t.state == stateConnected:
+		return "Connected, " + count
+	case t.err != nil:
+		return fmt.Sprintf("Connection failed: %s, %s", t.err, count)
+	default:
+		return "Disconnected, " + count
+	}
+}
+
+func (t *Timeline) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if t.copyButton.Clicked(gtx) && t.onCopy != nil {
+		t.onCopy(gtx, "Frames", t.String())
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.clearButton.Clicked(gtx) {
+		t.frames = nil
+	}
+
+	if t.state == stateDisconnected && t.err == nil && len(t.frames) == 0 {
+		return component.Message(gtx, component.MessageTypeInfo, theme, "Connect to see the sent and received frames")
+	}
+
+	return layout.Inset{Top: unit.Dp(10), Left: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Bottom: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Flexed(1, material.Label(theme.Material(), theme.TextSize, t.status()).Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return widgets.Button(theme, &t.copyButton, widgets.CopyIcon, widgets.IconPositionStart, "Copy").Layout(gtx, theme)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(5)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return widgets.Button(theme, &t.clearButton, widgets.CleanIcon, widgets.IconPositionStart, "Clear").Layout(gtx, theme)
+						}),
+					)
+				})
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return material.List(theme.Material(), &t.list).Layout(gtx, len(t.frames), func(gtx layout.Context, i int) layout.Dimensions {
+					return t.frameLayout(gtx, theme, t.frames[i])
+				})
+			}),
+		)
+	})
+}
+
+func (t *Timeline) frameLayout(gtx layout.Context, theme *chapartheme.Theme, frame domain.WebSocketFrame) layout.Dimensions {
+	arrow, arrowColor := "↓", chapartheme.LightGreen
+	if frame.Direction == domain.WebSocketDirectionSent {
+		arrow, arrowColor = "↑", chapartheme.LightBlue
+	}
+
+	header := fmt.Sprintf("%s  %s", frame.Type, frame.Time.Format("15:04:05.000"))
+	if frame.Type == domain.WebSocketFrameText || frame.Type == domain.WebSocketFrameBinary {
+		header += fmt.Sprintf("  %d bytes", len(frame.Data))
+	}
+
+	return layout.Inset{Bottom: unit.Dp(8), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return t.label(gtx, theme, arrow+" ", arrowColor)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return t.label(gtx, theme, header, theme.ResponseStatusColor)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				data := frame.String()
+				if data == "" {
+					return layout.Dimensions{}
+				}
+
+				lb := material.Label(theme.Material(), theme.TextSize, data)
+				lb.Font.Typeface = theme.Face
+				return lb.Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return widgets.DrawLine(gtx, theme.SeparatorColor, unit.Dp(1), unit.Dp(gtx.Metric.PxToDp(gtx.Constraints.Max.X)))
+				})
+			}),
+		)
+	})
+}
+
+func (t *Timeline) label(gtx layout.Context, theme *chapartheme.Theme, text string, c color.NRGBA) layout.Dimensions {
+	lb := material.Label(theme.Material(), unit.Sp(12), text)
+	lb.Font.Weight = font.Bold
+	lb.Color = c
+	return lb.Layout(gtx)
+}
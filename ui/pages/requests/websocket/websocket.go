@@ -0,0 +1,274 @@
+package websocket
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	giox "gioui.org/x/component"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/internal/prefs"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/converter"
+	"github.com/chapar-rest/chapar/ui/pages/requests/component"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+type WebSocket struct {
+	Prompt *widgets.Prompt
+
+	Req *domain.Request
+
+	Breadcrumb *component.Breadcrumb
+	AddressBar *AddressBar
+	Actions    *component.Actions
+
+	Request  *Request
+	Timeline *Timeline
+
+	split widgets.SplitView
+
+	onSave        func(id string)
+	onDataChanged func(id string, data any)
+	onConnect     func(id string)
+	onDisconnect  func(id string)
+	onSend        func(id string, msg domain.WebSocketMessage)
+	onPing        func(id string)
+}
+
+func New(req *domain.Request, theme *chapartheme.Theme) *WebSocket {
+	splitAxis := layout.Vertical
+	if prefs.GetGlobalConfig().Spec.General.UseHorizontalSplit {
+		splitAxis = layout.Horizontal
+	}
+
+	w := &WebSocket{
+		Req:        req,
+		Prompt:     widgets.NewPrompt("", "", ""),
+		Breadcrumb: component.NewBreadcrumb(req.MetaData.ID, req.CollectionName, "WebSocket", req.MetaData.Name),
+		split: widgets.SplitView{
+			Resize: giox.Resize{
+				Ratio: 0.5,
+				Axis:  splitAxis,
+			},
+			BarWidth: unit.Dp(2),
+		},
+		AddressBar: NewAddressBar(req.Spec.WebSocket.URL),
+		Actions:    component.NewActions(false),
+		Request:    NewRequest(req, theme),
+		Timeline:   NewTimeline(),
+	}
+
+	w.setupHooks()
+
+	return w
+}
+
+func (w *WebSocket) setupHooks() {
+	w.AddressBar.SetOnURLChanged(func(url string) {
+		clone := w.Req.Clone()
+		clone.Spec.WebSocket.URL = url
+		w.Req.Spec.WebSocket.URL = url
+		w.onDataChanged(w.Req.MetaData.ID, clone)
+	})
+
+	w.AddressBar.SetOnConnect(func() {
+		if w.onConnect != nil {
+			w.onConnect(w.Req.MetaData.ID)
+		}
+	})
+
+	w.AddressBar.SetOnDisconnect(func() {
+		if w.onDisconnect != nil {
+			w.onDisconnect(w.Req.MetaData.ID)
+		}
+	})
+
+	w.Request.Composer.SetOnSend(func(msg domain.WebSocketMessage) {
+		if w.onSend != nil {
+			w.onSend(w.Req.MetaData.ID, msg)
+		}
+	})
+
+	w.Request.Composer.SetOnPing(func() {
+		if w.onPing != nil {
+			w.onPing(w.Req.MetaData.ID)
+		}
+	})
+
+	w.Request.Composer.SetOnTemplatesChanged(func(messages []domain.WebSocketMessage) {
+		clone := w.Req.Clone()
+		clone.Spec.WebSocket.Messages = messages
+		w.Req.Spec.WebSocket.Messages = messages
+		w.onDataChanged(w.Req.MetaData.ID, clone)
+	})
+
+	w.Request.Headers.SetOnChange(func(headers []domain.KeyValue) {
+		clone := w.Req.Clone()
+		clone.Spec.WebSocket.Headers = headers
+		w.Req.Spec.WebSocket.Headers = headers
+		w.onDataChanged(w.Req.MetaData.ID, clone)
+	})
+
+	w.Request.Auth.SetOnChange(func(auth domain.Auth) {
+		clone := w.Req.Clone()
+		clone.Spec.WebSocket.Auth = auth
+		w.Req.Spec.WebSocket.Auth = auth
+		w.onDataChanged(w.Req.MetaData.ID, clone)
+	})
+
+	prefs.AddGlobalConfigChangeListener(func(old, updated domain.GlobalConfig) {
+		isChanged := old.Spec.General.UseHorizontalSplit != updated.Spec.General.UseHorizontalSplit
+		if isChanged {
+			if updated.Spec.General.UseHorizontalSplit {
+				w.split.Axis = layout.Horizontal
+			} else {
+				w.split.Axis = layout.Vertical
+			}
+		}
+	})
+}
+
+func (w *WebSocket) onSettingsChanged() {
+	values := w.Request.Settings.GetValues()
+	subprotocols, _ := values["subprotocols"].(string)
+
+	clone := w.Req.Clone()
+	clone.Spec.WebSocket.Subprotocols = domain.ParseSubprotocols(subprotocols)
+	clone.Spec.WebSocket.Proxy = converter.ProxyFromSettingValues(values)
+	w.Req.Spec.WebSocket.Subprotocols = clone.Spec.WebSocket.Subprotocols
+	w.Req.Spec.WebSocket.Proxy = clone.Spec.WebSocket.Proxy
+	w.onDataChanged(w.Req.MetaData.ID, clone)
+}
+
+func (w *WebSocket) SetOnTitleChanged(f func(title string)) {
+	w.Breadcrumb.SetOnTitleChanged(f)
+}
+
+func (w *WebSocket) SetTitle(title string) {
+	w.Breadcrumb.SetTitle(title)
+}
+
+func (w *WebSocket) SetDataChanged(changed bool) {
+	w.Actions.IsDataChanged = changed
+}
+
+func (w *WebSocket) SetOnDataChanged(f func(id string, data any)) {
+	w.onDataChanged = f
+}
+
+func (w *WebSocket) SetOnSave(f func(id string)) {
+	w.onSave = f
+}
+
+func (w *WebSocket) SetOnConnect(f func(id string)) {
+	w.onConnect = f
+}
+
+func (w *WebSocket) SetOnDisconnect(f func(id string)) {
+	w.onDisconnect = f
+}
+
+func (w *WebSocket) SetOnSend(f func(id string, msg domain.WebSocketMessage)) {
+	w.onSend = f
+}
+
+func (w *WebSocket) SetOnPing(f func(id string)) {
+	w.onPing = f
+}
+
+func (w *WebSocket) SetOnClearOAuth2Token(f func()) {
+	w.Request.Auth.SetOnClearToken(f)
+}
+
+func (w *WebSocket) SetOnCopyResponse(f func(gtx layout.Context, dataType, data string)) {
+	w.Timeline.SetOnCopy(f)
+}
+
+func (w *WebSocket) SetConnecting() {
+	w.AddressBar.state = stateConnecting
+	w.Request.Composer.SetConnected(false)
+	w.Timeline.SetConnecting()
+}
+
+func (w *WebSocket) SetConnected(subprotocol string) {
+	w.AddressBar.state = stateConnected
+	w.Request.Composer.SetConnected(true)
+	w.Timeline.SetConnected(subprotocol)
+}
+
+func (w *WebSocket) SetDisconnected(err error) {
+	w.AddressBar.state = stateDisconnected
+	w.Request.Composer.SetConnected(false)
+	w.Timeline.SetDisconnected(err)
+}
+
+func (w *WebSocket) AddFrame(frame domain.WebSocketFrame) {
+	w.Timeline.AddFrame(frame)
+}
+
+func (w *WebSocket) SetCollection(collection *domain.Collection) {
+	if collection == nil {
+		return
+	}
+
+	w.Request.Headers.SetCollectionHeaders(collection.Spec.Headers)
+	w.Request.Auth.SetCollectionAuth(&collection.Spec.Auth)
+}
+
+func (w *WebSocket) HidePrompt() {
+	w.Prompt.Hide()
+}
+
+func (w *WebSocket) ShowPrompt(title, content, modalType string, onSubmit func(selectedOption string, remember bool), options ...widgets.Option) {
+	w.Prompt.Type = modalType
+	w.Prompt.Title = title
+	w.Prompt.Content = content
+	w.Prompt.SetOptions(options...)
+	w.Prompt.WithoutRememberBool()
+	w.Prompt.SetOnSubmit(onSubmit)
+	w.Prompt.Show()
+}
+
+func (w *WebSocket) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if w.Actions.IsDataChanged && w.Actions.SaveButton.Clicked(gtx) && w.onSave != nil {
+		w.onSave(w.Req.MetaData.ID)
+		w.Actions.IsDataChanged = false
+	}
+
+	if w.Request.Settings.Changed() {
+		w.onSettingsChanged()
+	}
+
+	return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.Prompt.Layout(gtx, theme)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return layout.Inset{Bottom: unit.Dp(15), Top: unit.Dp(5)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return w.Breadcrumb.Layout(gtx, theme)
+						})
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return w.Actions.Layout(gtx, theme)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return w.AddressBar.Layout(gtx, theme)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return w.split.Layout(gtx, theme,
+					func(gtx layout.Context) layout.Dimensions {
+						return w.Request.Layout(gtx, theme)
+					},
+					func(gtx layout.Context) layout.Dimensions {
+						return w.Timeline.Layout(gtx, theme)
+					},
+				)
+			}),
+		)
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/chapar-rest/chapar/internal/safemap"
 	"github.com/chapar-rest/chapar/ui"
 	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/converter"
 	"github.com/chapar-rest/chapar/ui/keys"
 	"github.com/chapar-rest/chapar/ui/modals"
 	"github.com/chapar-rest/chapar/ui/navigator"
@@ -151,7 +152,7 @@ func (v *View) Load(config domain.GlobalConfig) {
 	}
 
 	// General settings
-	generalSettings := widgets.NewSettings([]*widgets.SettingItem{
+	generalItems := []*widgets.SettingItem{
 		widgets.NewHeaderItem("Request"),
 		widgets.NewDropDownItem("HTTP request version", "httpVersion", "Select the HTTP version to use for sending the request.", config.Spec.General.HTTPVersion,
 			widgets.NewDropDownOption("HTTP/1.1").WithIdentifier("http/1.1").WithValue("http/1.1"),
@@ -161,6 +162,9 @@ func (v *View) Load(config domain.GlobalConfig) {
 		widgets.NewNumberItem("Response Size MB", "responseSizeMb", "Maximum size of the response to download. zero mean unlimited", config.Spec.General.ResponseSizeMb),
 		widgets.NewBoolItem("Follow redirects", "followRedirects", "When enabled, the HTTP client follows 3xx redirects (e.g. 307). When disabled, the first response is returned.", config.Spec.General.FollowRedirects),
 		widgets.NewBoolItem("Validate TLS Certificates", "validateTLSCertificates", "When enabled, the HTTP client validates TLS certificates.", config.Spec.General.VaidateTLSCertificates),
+	}
+	generalItems = append(generalItems, converter.ProxySettingItems(&config.Spec.General.Proxy, false)...)
+	generalItems = append(generalItems,
 		widgets.NewHeaderItem("Headers"),
 		widgets.NewBoolItem("Send no-cache header", "sendNoCacheHeader", "Add and send no-cache header in http requests", config.Spec.General.SendNoCacheHeader),
 		widgets.NewBoolItem("Send Chapar agent header", "sendChaparAgentHeader", "Add and send Chapar agent header in http requests", config.Spec.General.SendChaparAgentHeader),
@@ -176,28 +180,34 @@ func (v *View) Load(config domain.GlobalConfig) {
 			widgets.NewDropDownOption("Catppuccin Mocha").WithIdentifier("catppuccin-mocha").WithValue("catppuccin-mocha"),
 		),
 		widgets.NewBoolItem("Use horizontal split for request and response", "useHorizontalSplit", "If enabled, the request and response views are arranged top to bottom.", config.Spec.General.UseHorizontalSplit),
-	})
+	)
+	generalSettings := widgets.NewSettings(generalItems)
 	v.settings.Set("general", generalSettings)
 
+	// the javascript engine is embedded, docker and the server script are only for python
+	pythonVisibility := func(values map[string]any) bool {
+		return values["language"] == "python"
+	}
+
 	dockerVisibility := func(values map[string]any) bool {
-		return values["useDocker"].(bool)
+		return pythonVisibility(values) && values["useDocker"].(bool)
 	}
 
 	localEngineVisibility := func(values map[string]any) bool {
-		return !values["useDocker"].(bool)
+		return pythonVisibility(values) && !values["useDocker"].(bool)
 	}
 
 	scriptingSettings := widgets.NewSettings([]*widgets.SettingItem{
 		widgets.NewBoolItem("Enable", "enable", "Enable scripting for pre and post request triggers", config.Spec.Scripting.Enabled),
 		widgets.NewDropDownItem("Language", "language", "Select the scripting language you would like to have for scripting", config.Spec.Scripting.Language,
 			widgets.NewDropDownOption("Python").WithIdentifier("python").WithValue("python"),
-			// widgets.NewDropDownOption("Javascript").WithIdentifier("javascript").WithValue("javascript"),
+			widgets.NewDropDownOption("JavaScript").WithIdentifier("javascript").WithValue("javascript"),
 		),
-		widgets.NewBoolItem("Use Docker", "useDocker", "Use docker to run the scripting engine (right now docker is only working option)", config.Spec.Scripting.UseDocker),
+		widgets.NewBoolItem("Use Docker", "useDocker", "Use docker to run the scripting engine (right now docker is only working option)", config.Spec.Scripting.UseDocker).SetVisibleWhen(pythonVisibility),
 		widgets.NewTextItem("Docker image", "dockerImage", "The docker image to use for the scripting engine", config.Spec.Scripting.DockerImage).MinWidth(unit.Dp(400)).TextAlignment(text.Start).SetVisibleWhen(dockerVisibility),
 		widgets.NewTextItem("Executable path", "executable", "The absolute path to the executable binary", config.Spec.Scripting.ExecutablePath).MinWidth(unit.Dp(400)).TextAlignment(text.Start).SetVisibleWhen(localEngineVisibility),
 		widgets.NewTextItem("Server script path", "serverScriptPath", "The absolute path to where Chapar can use to create server script", config.Spec.Scripting.ServerScriptPath).MinWidth(unit.Dp(400)).TextAlignment(text.Start).SetVisibleWhen(localEngineVisibility),
-		widgets.NewNumberItem("Port", "port", "Http port that server script is listening to", config.Spec.Scripting.Port),
+		widgets.NewNumberItem("Port", "port", "Http port that server script is listening to", config.Spec.Scripting.Port).SetVisibleWhen(pythonVisibility),
 	})
 	v.settings.Set("scripting", scriptingSettings)
 
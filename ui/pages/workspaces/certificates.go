@@ -0,0 +1,181 @@
+package workspaces
+
+import (
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/chapar-rest/chapar/internal/domain"
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+	"github.com/chapar-rest/chapar/ui/explorer"
+	"github.com/chapar-rest/chapar/ui/widgets"
+)
+
+var certExtensions = []string{"pem", "crt", "cer", "key"}
+
+// Certificates is the editor of the per host client certificates and ca bundles of a workspace.
+type Certificates struct {
+	items     []*certificateItem
+	addButton widget.Clickable
+
+	explorer *explorer.Explorer
+	onChange func(certs []domain.HostCertificate)
+}
+
+type certificateItem struct {
+	host         *widgets.TextField
+	caCert       *widgets.FileSelector
+	clientCert   *widgets.FileSelector
+	clientKey    *widgets.FileSelector
+	deleteButton widget.Clickable
+	deleted      bool
+}
+
+func NewCertificates(certs []domain.HostCertificate, explorer *explorer.Explorer) *Certificates {
+	c := &Certificates{
+		explorer: explorer,
+	}
+
+	for _, cert := range certs {
+		c.addItem(cert)
+	}
+
+	return c
+}
+
+func (c *Certificates) SetOnChange(f func(certs []domain.HostCertificate)) {
+	c.onChange = f
+}
+
+func (c *Certificates) addItem(cert domain.HostCertificate) {
+	item := &certificateItem{
+		host:       widgets.NewTextField(cert.Host, "Host pattern e.g. *.example.com"),
+		caCert:     widgets.NewFileSelector(cert.CACertFile, c.explorer, certExtensions...),
+		clientCert: widgets.NewFileSelector(cert.ClientCertFile, c.explorer, certExtensions...),
+		clientKey:  widgets.NewFileSelector(cert.ClientKeyFile, c.explorer, certExtensions...),
+	}
+
+	item.host.SetMinWidth(300)
+	for _, fs := range []*widgets.FileSelector{item.caCert, item.clientCert, item.clientKey} {
+		fs.SetOnChanged(func(string) { c.triggerChange() })
+	}
+
+	c.items = append(c.items, item)
+}
+
+func (c *Certificates) values() []domain.HostCertificate {
+	out := make([]domain.HostCertificate, 0, len(c.items))
+	for _, item := range c.items {
+		out = append(out, domain.HostCertificate{
+			Host:           item.host.GetText(),
+			CACertFile:     item.caCert.GetFilePath(),
+			ClientCertFile: item.clientCert.GetFilePath(),
+			ClientKeyFile:  item.clientKey.GetFilePath(),
+		})
+	}
+	return out
+}
+
+func (c *Certificates) triggerChange() {
+	if c.onChange != nil {
+		c.onChange(c.values())
+	}
+}
+
+func (c *Certificates) Layout(gtx layout.Context, theme *chapartheme.Theme) layout.Dimensions {
+	if c.addButton.Clicked(gtx) {
+		c.addItem(domain.HostCertificate{})
+		c.triggerChange()
+	}
+
+	for i, item := range c.items {
+		if item.deleted {
+			c.items = append(c.items[:i], c.items[i+1:]...)
+			c.triggerChange()
+			break
+		}
+	}
+
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					lb := material.Label(theme.Material(), theme.TextSize, "Client certificates and CA bundles used by HTTP and GraphQL requests.\nThe first entry whose host pattern matches the request host is used.")
+					return lb.Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := widgets.Button(theme, &c.addButton, widgets.PlusIcon, widgets.IconPositionStart, "Add")
+					return btn.Layout(gtx, theme)
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout),
+	}
+
+	for _, item := range c.items {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return c.itemLayout(gtx, theme, item)
+			})
+		}))
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+func (c *Certificates) itemLayout(gtx layout.Context, theme *chapartheme.Theme, item *certificateItem) layout.Dimensions {
+	fileLayout := func(label string, fs *widgets.FileSelector) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+					layout.Rigid(material.Label(theme.Material(), unit.Sp(12), label).Layout),
+					layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return fs.Layout(gtx, theme)
+					}),
+				)
+			})
+		})
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Max.X = gtx.Dp(300)
+					dims := item.host.Layout(gtx, theme)
+					if item.host.Changed() {
+						c.triggerChange()
+					}
+					return dims
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					ib := widgets.IconButton{
+						Icon:      widgets.DeleteIcon,
+						Size:      unit.Dp(20),
+						Color:     theme.TextColor,
+						Clickable: &item.deleteButton,
+					}
+					dims := ib.Layout(gtx, theme)
+					if ib.Clicked() {
+						// removed on the next frame so the items are not modified while they're being laid out
+						item.deleted = true
+						gtx.Execute(op.InvalidateCmd{})
+					}
+					return dims
+				}),
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				fileLayout("CA certificate", item.caCert),
+				fileLayout("Client certificate", item.clientCert),
+				fileLayout("Client key", item.clientKey),
+			)
+		}),
+	)
+}
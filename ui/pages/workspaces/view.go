@@ -54,7 +54,10 @@ func (v *View) Info() navigator.Info {
 }
 
 type Item struct {
-	deleteButton widget.Clickable
+	deleteButton      widget.Clickable
+	certificateButton widget.Clickable
+	showCertificates  bool
+	certificates      *Certificates
 
 	Name     *widgets.EditableLabel
 	readOnly bool
@@ -139,7 +142,16 @@ func (v *View) AddItem(item *domain.Workspace) {
 	nameEditable := widgets.NewEditableLabel(item.MetaData.Name)
 	nameEditable.SetReadOnly(readonly)
 
-	v.items = append(v.items, &Item{w: item, Name: nameEditable, readOnly: readonly})
+	it := &Item{w: item, Name: nameEditable, readOnly: readonly}
+	it.certificates = NewCertificates(item.Spec.Certificates, v.Explorer)
+	it.certificates.SetOnChange(func(certs []domain.HostCertificate) {
+		it.w.Spec.Certificates = certs
+		if v.controller != nil {
+			v.controller.OnUpdate(it.w)
+		}
+	})
+
+	v.items = append(v.items, it)
 
 	sort.Slice(v.items, func(i, j int) bool {
 		return v.items[i].w.MetaData.Name < v.items[j].w.MetaData.Name
@@ -162,9 +174,24 @@ func (v *View) itemLayout(gtx layout.Context, theme *chapartheme.Theme, item *It
 			return dims
 		})
 
+		certificateIconButton := layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			ib := widgets.IconButton{
+				Icon:      widgets.CertificateIcon,
+				Size:      unit.Dp(20),
+				Color:     theme.TextColor,
+				Clickable: &item.certificateButton,
+			}
+
+			dims := ib.Layout(gtx, theme)
+			if ib.Clicked() {
+				item.showCertificates = !item.showCertificates
+			}
+			return dims
+		})
+
 		// NOTE(Isaac799) don't show delete button for active workspace
 		if isActive {
-			return layoutFlex.Layout(gtx, editableLabel)
+			return layoutFlex.Layout(gtx, editableLabel, certificateIconButton)
 		}
 
 		deleteIconButton := layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -187,13 +214,28 @@ func (v *View) itemLayout(gtx layout.Context, theme *chapartheme.Theme, item *It
 			}
 			return dims
 		})
-		return layoutFlex.Layout(gtx, editableLabel, deleteIconButton)
+		return layoutFlex.Layout(gtx, editableLabel, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+				certificateIconButton,
+				layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				deleteIconButton,
+			)
+		}))
 	})
 
 	return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			return content
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !item.showCertificates {
+				return layout.Dimensions{}
+			}
+
+			return layout.Inset{Left: unit.Dp(10), Bottom: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return item.certificates.Layout(gtx, theme)
+			})
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			// only if it's not the last item
 			if isLast {
@@ -43,6 +43,7 @@ const (
 	CodeLanguageDotNet     = "Shell"
 	CodeLanguageProperties = "properties"
 	CodeLanguageGraphQL    = "GraphQL"
+	CodeLanguageProtobuf   = "protobuf"
 )
 
 type CodeEditor struct {
@@ -74,6 +75,8 @@ type CodeEditor struct {
 	yScroll widget.Scrollbar
 
 	editorConfig domain.EditorConfig
+
+	completion *completion
 }
 
 func NewCodeEditor(code string, lang string, theme *chapartheme.Theme) *CodeEditor {
@@ -234,6 +237,18 @@ func (c *CodeEditor) SetOnChanged(f func(text string)) {
 	c.onChange = f
 }
 
+// SetCompleter enables the completion popup, it shows the suggestions of the completer while typing or
+// when ctrl+space is pressed.
+func (c *CodeEditor) SetCompleter(completer Completer) {
+	if c.completion != nil {
+		c.completion.completer = completer
+		return
+	}
+
+	c.completion = newCompletion(c.editor, c.theme, completer)
+	c.editor.WithOptions(gvcode.WithAutoCompletion(c.completion))
+}
+
 func (c *CodeEditor) SetReadOnly(readOnly bool) {
 	c.editor.WithOptions(gvcode.ReadOnlyMode(readOnly))
 }
@@ -278,6 +293,7 @@ func (c *CodeEditor) Layout(gtx layout.Context, theme *chapartheme.Theme, hint s
 				st := c.stylingText(c.editor.Text())
 				c.tokens = st
 				c.editor.SetSyntaxTokens(st...)
+				c.editor.OnTextEdit()
 				if c.onChange != nil {
 					c.onChange(c.editor.Text())
 					c.code = c.editor.Text()
@@ -0,0 +1,233 @@
+package codeeditor
+
+import (
+	"image"
+
+	"gioui.org/font"
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/oligo/gvcode"
+
+	"github.com/chapar-rest/chapar/ui/chapartheme"
+)
+
+// maxVisibleSuggestions is the number of rows the popup shows before scrolling.
+const maxVisibleSuggestions = 8
+
+// Suggestion is an item of the completion popup.
+type Suggestion struct {
+	Label  string
+	Detail string
+	// Insert replaces the text between the start offset given by the completer and the caret.
+	Insert string
+}
+
+// Completer returns the suggestions for the caret at offset and the offset of the text they replace,
+// the offsets count runes.
+type Completer func(text string, offset int) (start int, suggestions []Suggestion)
+
+// completion shows the suggestions of a completer in a popup under the caret, it implements
+// gvcode.Completion.
+type completion struct {
+	editor    *gvcode.Editor
+	theme     *chapartheme.Theme
+	completer Completer
+
+	items    []Suggestion
+	clicks   []widget.Clickable
+	selected int
+	// start and end are the rune offsets of the text replaced by the selected item
+	start, end int
+	coords     image.Point
+	active     bool
+	// confirmed skips the text change made by confirming an item, so the popup doesn't show up again
+	confirmed bool
+	// trigger is only used for its address, it tags the shortcut showing the popup which stays
+	// registered when the popup is closed
+	trigger bool
+
+	list widget.List
+}
+
+func newCompletion(editor *gvcode.Editor, theme *chapartheme.Theme, completer Completer) *completion {
+	c := &completion{editor: editor, theme: theme, completer: completer}
+	c.list.Axis = layout.Vertical
+
+	editor.RegisterCommand(&c.trigger, key.Filter{Name: key.NameSpace, Required: key.ModShortcut},
+		func(gtx layout.Context, evt key.Event) gvcode.EditorEvent {
+			c.show(editor.GetCompletionContext())
+			return nil
+		})
+	return c
+}
+
+// AddCompletor is not used, the suggestions come from the completer.
+func (c *completion) AddCompletor(_ gvcode.Completor, _ gvcode.CompletionPopup) error {
+	return nil
+}
+
+func (c *completion) OnText(ctx gvcode.CompletionContext) {
+	if c.confirmed {
+		c.confirmed = false
+		return
+	}
+
+	// the text was not typed, it's set by the code or pasted
+	if ctx.Input == "" {
+		c.Cancel()
+		return
+	}
+
+	c.show(ctx)
+}
+
+func (c *completion) show(ctx gvcode.CompletionContext) {
+	start, items := c.completer(c.editor.Text(), ctx.Position.Runes)
+	if len(items) == 0 {
+		c.Cancel()
+		return
+	}
+
+	c.items = items
+	if len(c.clicks) < len(items) {
+		c.clicks = make([]widget.Clickable, len(items))
+	}
+	c.start, c.end = start, ctx.Position.Runes
+	c.coords = ctx.Coords
+	c.selected = 0
+	c.list.Position = layout.Position{}
+
+	if !c.active {
+		c.active = true
+		c.registerCommands()
+	}
+}
+
+func (c *completion) registerCommands() {
+	move := func(delta int) gvcode.CommandHandler {
+		return func(gtx layout.Context, evt key.Event) gvcode.EditorEvent {
+			c.selected = (c.selected + delta + len(c.items)) % len(c.items)
+			if c.selected < c.list.Position.First || c.selected >= c.list.Position.First+maxVisibleSuggestions {
+				c.list.ScrollTo(c.selected)
+			}
+			return nil
+		}
+	}
+
+	confirm := func(gtx layout.Context, evt key.Event) gvcode.EditorEvent {
+		c.OnConfirm(c.selected)
+		return nil
+	}
+
+	c.editor.RegisterCommand(c, key.Filter{Name: key.NameUpArrow}, move(-1))
+	c.editor.RegisterCommand(c, key.Filter{Name: key.NameDownArrow}, move(1))
+	c.editor.RegisterCommand(c, key.Filter{Name: key.NameReturn}, confirm)
+	c.editor.RegisterCommand(c, key.Filter{Name: key.NameEnter}, confirm)
+	c.editor.RegisterCommand(c, key.Filter{Name: key.NameTab}, confirm)
+	c.editor.RegisterCommand(c, key.Filter{Name: key.NameEscape}, func(gtx layout.Context, evt key.Event) gvcode.EditorEvent {
+		c.Cancel()
+		return nil
+	})
+}
+
+func (c *completion) OnConfirm(idx int) {
+	if idx < 0 || idx >= len(c.items) {
+		return
+	}
+
+	insert := c.items[idx].Insert
+	c.Cancel()
+	c.confirmed = true
+	c.editor.SetCaret(c.start, c.end)
+	c.editor.Insert(insert)
+}
+
+func (c *completion) Cancel() {
+	if c.active {
+		c.editor.RemoveCommands(c)
+	}
+	c.active = false
+	c.items = nil
+}
+
+func (c *completion) IsActive() bool {
+	return c.active
+}
+
+func (c *completion) Offset() image.Point {
+	return c.coords
+}
+
+func (c *completion) Layout(gtx layout.Context) layout.Dimensions {
+	if !c.active {
+		return layout.Dimensions{}
+	}
+
+	for i := range c.items {
+		if c.clicks[i].Clicked(gtx) {
+			c.OnConfirm(i)
+			return layout.Dimensions{}
+		}
+	}
+
+	gtx.Constraints.Min = image.Point{}
+	gtx.Constraints.Max.X = gtx.Dp(unit.Dp(360))
+	gtx.Constraints.Max.Y = gtx.Dp(unit.Dp(24 * maxVisibleSuggestions))
+
+	border := widget.Border{Color: c.theme.BorderColor, Width: unit.Dp(1), CornerRadius: unit.Dp(4)}
+	return border.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Background{}.Layout(gtx,
+			func(gtx layout.Context) layout.Dimensions {
+				rect := clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Min}, gtx.Dp(unit.Dp(4)))
+				paint.FillShape(gtx.Ops, c.theme.DropDownMenuBgColor, rect.Op(gtx.Ops))
+				return layout.Dimensions{Size: gtx.Constraints.Min}
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return material.List(c.theme.Material(), &c.list).Layout(gtx, len(c.items), c.itemLayout)
+			},
+		)
+	})
+}
+
+func (c *completion) itemLayout(gtx layout.Context, i int) layout.Dimensions {
+	item := c.items[i]
+	return c.clicks[i].Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Background{}.Layout(gtx,
+			func(gtx layout.Context) layout.Dimensions {
+				if i == c.selected || c.clicks[i].Hovered() {
+					paint.FillShape(gtx.Ops, c.theme.TextSelectionColor, clip.Rect{Max: gtx.Constraints.Min}.Op())
+				}
+				return layout.Dimensions{Size: gtx.Constraints.Min}
+			},
+			func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(8), Right: unit.Dp(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							lb := material.Label(c.theme.Material(), unit.Sp(13), item.Label)
+							lb.Color = c.theme.DropDownTextColor
+							lb.Font.Weight = font.Medium
+							lb.MaxLines = 1
+							return lb.Layout(gtx)
+						}),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return layout.E.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+									lb := material.Label(c.theme.Material(), unit.Sp(12), item.Detail)
+									lb.Color = c.theme.TextColor
+									lb.Color.A = 0xa0
+									lb.MaxLines = 1
+									return lb.Layout(gtx)
+								})
+							})
+						}),
+					)
+				})
+			},
+		)
+	})
+}
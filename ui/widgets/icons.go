@@ -198,6 +198,11 @@ var CodeIcon *widget.Icon = func() *widget.Icon {
 	return icon
 }()
 
+var CertificateIcon *widget.Icon = func() *widget.Icon {
+	icon, _ := widget.NewIcon(icons.ActionVerifiedUser)
+	return icon
+}()
+
 var Notifications *widget.Icon = func() *widget.Icon {
 	icon, _ := widget.NewIcon(icons.SocialNotifications)
 	return icon
@@ -209,6 +214,7 @@ var VerticalSplitIcon *SvgIcon = loadSvgIcon("splitscreen_v")
 var GRPCIcon *SvgIcon = loadSvgIcon("grpc")
 var HTTPIcon *SvgIcon = loadSvgIcon("http")
 var GraphQLIcon *SvgIcon = loadSvgIcon("graphql")
+var WebSocketIcon *SvgIcon = loadSvgIcon("websocket")
 var CollectionIcon *SvgIcon = loadSvgIcon("collection")
 var TerminalIcon *SvgIcon = loadSvgIcon("terminal")
 var FormatIcon *SvgIcon = loadSvgIcon("format")